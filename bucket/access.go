@@ -0,0 +1,134 @@
+package bucket
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+// ErrNoSTS is returned by GrantTemporaryAccess when the Bucket is not configured with WithSTS.
+var ErrNoSTS = errors.New("bucket: STS client is not configured")
+
+// A Permission is a set of actions granted by GrantTemporaryAccess. They can be combined with bitwise OR.
+type Permission int
+
+// Permissions for GrantTemporaryAccess.
+const (
+	// PermissionRead allows s3:GetObject.
+	PermissionRead Permission = 1 << iota
+
+	// PermissionWrite allows s3:PutObject, including multipart uploads.
+	PermissionWrite
+
+	// PermissionDelete allows s3:DeleteObject.
+	PermissionDelete
+
+	// PermissionList allows s3:ListBucket restricted to the prefix.
+	PermissionList
+)
+
+// WithSTS returns an Option that uses svc to issue credentials in GrantTemporaryAccess.
+// The credentials are issued by AssumeRole on roleARN if roleARN is not empty, otherwise by GetFederationToken.
+func WithSTS(svc stsiface.STSAPI, roleARN string) Option {
+	return func(b *Bucket) {
+		b.sts = svc
+		b.stsRoleARN = roleARN
+	}
+}
+
+// GrantTemporaryAccess returns temporary credentials that are restricted by an inline session policy
+// to perms on the objects under keyPrefix in the bucket. The credentials expire after ttl.
+func (b *Bucket) GrantTemporaryAccess(ctx aws.Context, keyPrefix string, perms Permission, ttl time.Duration) (*sts.Credentials, error) {
+	if b.sts == nil {
+		return nil, ErrNoSTS
+	}
+
+	policy, err := json.Marshal(b.accessPolicy(b.objectKey(keyPrefix), perms))
+	if err != nil {
+		return nil, err
+	}
+
+	duration := aws.Int64(int64(ttl / time.Second))
+
+	if b.stsRoleARN != "" {
+		resp, err := b.sts.AssumeRoleWithContext(ctx, &sts.AssumeRoleInput{
+			RoleArn:         aws.String(b.stsRoleARN),
+			RoleSessionName: aws.String("aws-go-s3-" + strconv.FormatInt(time.Now().UnixNano(), 10)),
+			Policy:          aws.String(string(policy)),
+			DurationSeconds: duration,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.Credentials, nil
+	}
+
+	resp, err := b.sts.GetFederationTokenWithContext(ctx, &sts.GetFederationTokenInput{
+		Name:            aws.String("aws-go-s3"),
+		Policy:          aws.String(string(policy)),
+		DurationSeconds: duration,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Credentials, nil
+}
+
+// accessPolicy returns the session policy for GrantTemporaryAccess.
+func (b *Bucket) accessPolicy(keyPrefix string, perms Permission) PolicyDocument {
+	var actions []string
+	if perms&PermissionRead != 0 {
+		actions = append(actions, "s3:GetObject")
+	}
+	if perms&PermissionWrite != 0 {
+		actions = append(actions, "s3:PutObject", "s3:AbortMultipartUpload", "s3:ListMultipartUploadParts")
+	}
+	if perms&PermissionDelete != 0 {
+		actions = append(actions, "s3:DeleteObject")
+	}
+
+	doc := PolicyDocument{Version: "2012-10-17"}
+
+	if len(actions) > 0 {
+		doc.Statement = append(doc.Statement, PolicyStatement{
+			Effect:   "Allow",
+			Action:   actions,
+			Resource: []string{b.arn() + "/" + keyPrefix + "*"},
+		})
+	}
+
+	if perms&PermissionList != 0 {
+		doc.Statement = append(doc.Statement, PolicyStatement{
+			Effect:   "Allow",
+			Action:   []string{"s3:ListBucket"},
+			Resource: []string{b.arn()},
+			Condition: map[string]map[string]string{
+				"StringLike": {"s3:prefix": keyPrefix + "*"},
+			},
+		})
+	}
+
+	return doc
+}
+
+// arn returns the ARN of the bucket.
+func (b *Bucket) arn() string {
+	return "arn:" + b.partition() + ":s3:::" + aws.StringValue(b.Name)
+}
+
+// partition returns the partition of the client, e.g. "aws-cn", or "aws" if it is unknown.
+func (b *Bucket) partition() string {
+	if c, ok := b.S3.(*s3.S3); ok && c.Client != nil && c.ClientInfo.PartitionID != "" {
+		return c.ClientInfo.PartitionID
+	}
+
+	return "aws"
+}
@@ -0,0 +1,132 @@
+package bucket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stsStub records the requests for credentials.
+type stsStub struct {
+	stsiface.STSAPI
+
+	assumeRole      *sts.AssumeRoleInput
+	federationToken *sts.GetFederationTokenInput
+}
+
+func (s *stsStub) AssumeRoleWithContext(_ aws.Context, in *sts.AssumeRoleInput, _ ...request.Option) (*sts.AssumeRoleOutput, error) {
+	s.assumeRole = in
+	return &sts.AssumeRoleOutput{Credentials: &sts.Credentials{AccessKeyId: aws.String("role")}}, nil
+}
+
+func (s *stsStub) GetFederationTokenWithContext(_ aws.Context, in *sts.GetFederationTokenInput, _ ...request.Option) (*sts.GetFederationTokenOutput, error) {
+	s.federationToken = in
+	return &sts.GetFederationTokenOutput{Credentials: &sts.Credentials{AccessKeyId: aws.String("federation")}}, nil
+}
+
+func TestGrantTemporaryAccessPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		perms  Permission
+		policy string
+	}{
+		{
+			name:  "read",
+			perms: PermissionRead,
+			policy: `{"Version": "2012-10-17", "Statement": [
+				{"Effect": "Allow", "Action": ["s3:GetObject"], "Resource": ["arn:aws:s3:::bucket/users/alice/*"]}
+			]}`,
+		},
+		{
+			name:  "write",
+			perms: PermissionWrite,
+			policy: `{"Version": "2012-10-17", "Statement": [
+				{"Effect": "Allow", "Action": ["s3:PutObject", "s3:AbortMultipartUpload", "s3:ListMultipartUploadParts"], "Resource": ["arn:aws:s3:::bucket/users/alice/*"]}
+			]}`,
+		},
+		{
+			name:  "delete",
+			perms: PermissionDelete,
+			policy: `{"Version": "2012-10-17", "Statement": [
+				{"Effect": "Allow", "Action": ["s3:DeleteObject"], "Resource": ["arn:aws:s3:::bucket/users/alice/*"]}
+			]}`,
+		},
+		{
+			name:  "list",
+			perms: PermissionList,
+			policy: `{"Version": "2012-10-17", "Statement": [
+				{"Effect": "Allow", "Action": ["s3:ListBucket"], "Resource": ["arn:aws:s3:::bucket"], "Condition": {"StringLike": {"s3:prefix": "users/alice/*"}}}
+			]}`,
+		},
+		{
+			name:  "all",
+			perms: PermissionRead | PermissionWrite | PermissionDelete | PermissionList,
+			policy: `{"Version": "2012-10-17", "Statement": [
+				{"Effect": "Allow", "Action": ["s3:GetObject", "s3:PutObject", "s3:AbortMultipartUpload", "s3:ListMultipartUploadParts", "s3:DeleteObject"], "Resource": ["arn:aws:s3:::bucket/users/alice/*"]},
+				{"Effect": "Allow", "Action": ["s3:ListBucket"], "Resource": ["arn:aws:s3:::bucket"], "Condition": {"StringLike": {"s3:prefix": "users/alice/*"}}}
+			]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stsStub{}
+			b := New(&s3.S3{}, "bucket", WithSTS(stub, "")).WithPrefix("users/")
+
+			_, err := b.GrantTemporaryAccess(aws.BackgroundContext(), "alice/", tc.perms, time.Hour)
+			require.NoError(t, err)
+			require.NotNil(t, stub.federationToken)
+			assert.JSONEq(t, tc.policy, aws.StringValue(stub.federationToken.Policy))
+		})
+	}
+}
+
+func TestGrantTemporaryAccessPartition(t *testing.T) {
+	svc := s3.New(session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("cn-north-1"),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+	})))
+	stub := &stsStub{}
+	b := New(svc, "bucket", WithSTS(stub, ""))
+
+	_, err := b.GrantTemporaryAccess(aws.BackgroundContext(), "", PermissionRead|PermissionList, time.Hour)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Version": "2012-10-17", "Statement": [
+		{"Effect": "Allow", "Action": ["s3:GetObject"], "Resource": ["arn:aws-cn:s3:::bucket/*"]},
+		{"Effect": "Allow", "Action": ["s3:ListBucket"], "Resource": ["arn:aws-cn:s3:::bucket"], "Condition": {"StringLike": {"s3:prefix": "*"}}}
+	]}`, aws.StringValue(stub.federationToken.Policy))
+}
+
+func TestGrantTemporaryAccessSTS(t *testing.T) {
+	ctx := aws.BackgroundContext()
+
+	_, err := New(&s3.S3{}, "bucket").GrantTemporaryAccess(ctx, "", PermissionRead, time.Hour)
+	assert.Equal(t, ErrNoSTS, err)
+
+	// GetFederationToken without a role
+	stub := &stsStub{}
+	creds, err := New(&s3.S3{}, "bucket", WithSTS(stub, "")).GrantTemporaryAccess(ctx, "", PermissionRead, 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "federation", aws.StringValue(creds.AccessKeyId))
+	assert.Nil(t, stub.assumeRole)
+	assert.Equal(t, "aws-go-s3", aws.StringValue(stub.federationToken.Name))
+	assert.Equal(t, int64(900), aws.Int64Value(stub.federationToken.DurationSeconds))
+
+	// AssumeRole with a role
+	stub = &stsStub{}
+	creds, err = New(&s3.S3{}, "bucket", WithSTS(stub, "arn:aws:iam::123456789012:role/uploader")).GrantTemporaryAccess(ctx, "", PermissionRead, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "role", aws.StringValue(creds.AccessKeyId))
+	assert.Nil(t, stub.federationToken)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/uploader", aws.StringValue(stub.assumeRole.RoleArn))
+	assert.Regexp(t, `^aws-go-s3-\d+$`, aws.StringValue(stub.assumeRole.RoleSessionName))
+	assert.Equal(t, int64(3600), aws.Int64Value(stub.assumeRole.DurationSeconds))
+	assert.NotEmpty(t, aws.StringValue(stub.assumeRole.Policy))
+}
@@ -0,0 +1,49 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// GetObjectACL returns the ACL of the object.
+func (b *Bucket) GetObjectACL(key string, opts ...option.GetObjectACLInput) (*s3.GetObjectAclOutput, error) {
+	return b.GetObjectACLWithContext(aws.BackgroundContext(), key, opts...)
+}
+
+// GetObjectACLWithContext is the same as GetObjectACL with the context ctx.
+func (b *Bucket) GetObjectACLWithContext(ctx aws.Context, key string, opts ...option.GetObjectACLInput) (*s3.GetObjectAclOutput, error) {
+	req := &s3.GetObjectAclInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.GetObjectAclWithContext(ctx, req, b.reqOpts...)
+}
+
+// PutObjectACL replaces the ACL of the object with the canned ACL acl, e.g. s3.ObjectCannedACLPrivate.
+// If acl is empty, the ACL is made of the grants set by opts, e.g. option.GrantRead.
+func (b *Bucket) PutObjectACL(key, acl string, opts ...option.PutObjectACLInput) (*s3.PutObjectAclOutput, error) {
+	return b.PutObjectACLWithContext(aws.BackgroundContext(), key, acl, opts...)
+}
+
+// PutObjectACLWithContext is the same as PutObjectACL with the context ctx.
+func (b *Bucket) PutObjectACLWithContext(ctx aws.Context, key, acl string, opts ...option.PutObjectACLInput) (*s3.PutObjectAclOutput, error) {
+	req := &s3.PutObjectAclInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+	if acl != "" {
+		req.ACL = aws.String(acl)
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.PutObjectAclWithContext(ctx, req, b.reqOpts...)
+}
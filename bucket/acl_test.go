@@ -0,0 +1,33 @@
+package bucket
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutObjectACL(t *testing.T) {
+	var header http.Header
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/bucket/key", r.URL.Path)
+		header = r.Header.Clone()
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.PutObjectACL("key", s3.ObjectCannedACLPrivate)
+	require.NoError(t, err)
+	assert.Equal(t, "private", header.Get("X-Amz-Acl"))
+
+	_, err = b.PutObjectACL("key", "",
+		option.GrantRead(option.GranteeURI(option.AllUsers), option.GranteeID("reader")),
+		option.GrantFullControl(option.GranteeID("owner")),
+	)
+	require.NoError(t, err)
+	assert.Empty(t, header.Get("X-Amz-Acl"))
+	assert.Equal(t, `uri="http://acs.amazonaws.com/groups/global/AllUsers", id="reader"`, header.Get("X-Amz-Grant-Read"))
+	assert.Equal(t, `id="owner"`, header.Get("X-Amz-Grant-Full-Control"))
+}
@@ -0,0 +1,92 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// CreateBucket creates the bucket. It is created in the region of the client unless opts set LocationConstraint.
+func (b *Bucket) CreateBucket(opts ...option.CreateBucketInput) (*s3.CreateBucketOutput, error) {
+	return b.CreateBucketWithContext(aws.BackgroundContext(), opts...)
+}
+
+// CreateBucketWithContext is the same as CreateBucket with the context ctx.
+func (b *Bucket) CreateBucketWithContext(ctx aws.Context, opts ...option.CreateBucketInput) (*s3.CreateBucketOutput, error) {
+	req := &s3.CreateBucketInput{
+		Bucket: b.Name,
+	}
+
+	// us-east-1 is the default location and S3 rejects it as a location constraint.
+	if region := b.region(); region != "" && region != "us-east-1" {
+		req.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(region),
+		}
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.CreateBucketWithContext(ctx, req, b.reqOpts...)
+}
+
+// DeleteBucket deletes the bucket. The bucket must be empty.
+func (b *Bucket) DeleteBucket() (*s3.DeleteBucketOutput, error) {
+	return b.DeleteBucketWithContext(aws.BackgroundContext())
+}
+
+// DeleteBucketWithContext is the same as DeleteBucket with the context ctx.
+func (b *Bucket) DeleteBucketWithContext(ctx aws.Context) (*s3.DeleteBucketOutput, error) {
+	return b.S3.DeleteBucketWithContext(ctx, &s3.DeleteBucketInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+}
+
+// HeadBucket checks that the bucket exists and the caller has permission to access it.
+func (b *Bucket) HeadBucket() (*s3.HeadBucketOutput, error) {
+	return b.HeadBucketWithContext(aws.BackgroundContext())
+}
+
+// HeadBucketWithContext is the same as HeadBucket with the context ctx.
+func (b *Bucket) HeadBucketWithContext(ctx aws.Context) (*s3.HeadBucketOutput, error) {
+	return b.S3.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+}
+
+// Exists reports whether the bucket exists.
+func (b *Bucket) Exists() (bool, error) {
+	return b.ExistsWithContext(aws.BackgroundContext())
+}
+
+// ExistsWithContext is the same as Exists with the context ctx.
+func (b *Bucket) ExistsWithContext(ctx aws.Context) (bool, error) {
+	_, err := b.HeadBucketWithContext(ctx)
+	if err == nil {
+		return true, nil
+	}
+
+	if IsNotFound(err) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// WaitUntilBucketExists waits until HeadBucket succeeds, e.g. after CreateBucket, or ctx is done.
+func (b *Bucket) WaitUntilBucketExists(ctx aws.Context) error {
+	return b.S3.WaitUntilBucketExistsWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: b.Name,
+	}, request.WithWaiterRequestOptions(b.reqOpts...))
+}
+
+// region returns the region the client is configured with or empty if it is unknown.
+func (b *Bucket) region() string {
+	if c, ok := b.S3.(*s3.S3); ok && c.Client != nil {
+		return aws.StringValue(c.Config.Region)
+	}
+
+	return ""
+}
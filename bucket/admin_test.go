@@ -0,0 +1,61 @@
+package bucket
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBucket(t *testing.T) {
+	var location string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		var conf struct {
+			LocationConstraint string
+		}
+		if r.ContentLength > 0 {
+			require.NoError(t, xml.NewDecoder(r.Body).Decode(&conf))
+		}
+		location = conf.LocationConstraint
+	})
+
+	b := New(svc, "bucket")
+	_, err := b.CreateBucket()
+	require.NoError(t, err)
+	assert.Empty(t, location, "us-east-1 must not be sent as a location constraint")
+
+	svc.Config.Region = aws.String("eu-west-1")
+	_, err = b.CreateBucket()
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", location)
+
+	_, err = b.CreateBucket(option.LocationConstraint("ap-northeast-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "ap-northeast-1", location)
+}
+
+func TestExists(t *testing.T) {
+	status := http.StatusNotFound
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		w.WriteHeader(status)
+	})
+	b := New(svc, "bucket")
+
+	ok, err := b.Exists()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	status = http.StatusOK
+	ok, err = b.Exists()
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	status = http.StatusForbidden
+	_, err = b.Exists()
+	assert.Error(t, err)
+}
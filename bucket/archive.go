@@ -0,0 +1,152 @@
+package bucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// maxCopyObjectSize is the maximum size of an object CopyObject can copy.
+const maxCopyObjectSize = 5 << 30
+
+// ArchiveOptions controls ArchiveColdObjects.
+type ArchiveOptions struct {
+	// DryRun lists the objects that would be archived without copying them.
+	DryRun bool
+
+	// ManifestKey is the key the manifest is written to as JSON if it is not empty.
+	ManifestKey string
+
+	// Filter is called for each object not modified within the window. The object is archived only if it returns true.
+	// All objects are archived if it is nil.
+	Filter func(o *s3.Object) bool
+}
+
+// An ArchivedObject is an object in ArchiveManifest.
+type ArchivedObject struct {
+	Key          string `json:"key"`
+	Size         int64  `json:"size"`
+	ETag         string `json:"etag"`
+	StorageClass string `json:"storageClass"`
+
+	// VersionID is the version written by the copy. It is empty for a dry run or an unversioned bucket.
+	VersionID string `json:"versionId,omitempty"`
+}
+
+// An ArchiveManifest records the objects archived by ArchiveColdObjects.
+type ArchiveManifest struct {
+	Prefix       string           `json:"prefix"`
+	StorageClass string           `json:"storageClass"`
+	Cutoff       time.Time        `json:"cutoff"`
+	DryRun       bool             `json:"dryRun"`
+	Objects      []ArchivedObject `json:"objects"`
+
+	// TooLarge are the objects skipped since they are larger than CopyObject can copy.
+	TooLarge []string `json:"tooLarge,omitempty"`
+}
+
+// ArchiveColdObjects moves the objects with the given prefix not modified within olderThan to targetClass,
+// e.g. s3.StorageClassGlacier, by copying each object onto itself. Objects already in targetClass, GLACIER or DEEP_ARCHIVE
+// are left as they are. Metadata, tags and server-side encryption are carried over as in UpdateObjectMetadata.
+//
+// The manifest lists the objects archived before an error, if any, and is written to opts.ManifestKey in both cases.
+func (b *Bucket) ArchiveColdObjects(ctx aws.Context, prefix string, olderThan time.Duration, targetClass string, opts ArchiveOptions) (*ArchiveManifest, error) {
+	m := &ArchiveManifest{
+		Prefix:       prefix,
+		StorageClass: targetClass,
+		Cutoff:       time.Now().Add(-olderThan).UTC(),
+		DryRun:       opts.DryRun,
+		Objects:      []ArchivedObject{},
+	}
+
+	var cold []*s3.Object
+	err := b.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			switch aws.StringValue(o.StorageClass) {
+			case targetClass, s3.ObjectStorageClassGlacier, s3.ObjectStorageClassDeepArchive:
+				continue
+			}
+
+			if aws.TimeValue(o.LastModified).Before(m.Cutoff) && (opts.Filter == nil || opts.Filter(o)) {
+				cold = append(cold, o)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range cold {
+		key := aws.StringValue(o.Key)
+
+		if aws.Int64Value(o.Size) > maxCopyObjectSize {
+			m.TooLarge = append(m.TooLarge, key)
+			continue
+		}
+
+		entry := ArchivedObject{
+			Key:          key,
+			Size:         aws.Int64Value(o.Size),
+			ETag:         aws.StringValue(o.ETag),
+			StorageClass: aws.StringValue(o.StorageClass),
+		}
+
+		if !opts.DryRun {
+			versionID, err := b.archiveObject(ctx, key, o.ETag, targetClass)
+			if err != nil {
+				return m, b.writeManifest(ctx, opts.ManifestKey, m, err)
+			}
+
+			entry.VersionID = versionID
+		}
+
+		m.Objects = append(m.Objects, entry)
+	}
+
+	return m, b.writeManifest(ctx, opts.ManifestKey, m, nil)
+}
+
+// archiveObject copies key onto itself in targetClass if its ETag is still etag and returns the new VersionId.
+func (b *Bucket) archiveObject(ctx aws.Context, key string, etag *string, targetClass string) (string, error) {
+	head, err := b.S3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:  b.Name,
+		Key:     b.key(key),
+		IfMatch: etag,
+	}, b.reqOpts...)
+	if err != nil {
+		return "", err
+	}
+
+	req := b.selfCopyInput(key, head)
+	req.StorageClass = aws.String(targetClass)
+
+	resp, err := b.S3.CopyObjectWithContext(ctx, req, b.reqOpts...)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(resp.VersionId), nil
+}
+
+// writeManifest writes m to key if key is not empty. It returns err if it is not nil, otherwise the error of the write.
+func (b *Bucket) writeManifest(ctx aws.Context, key string, m *ArchiveManifest, err error) error {
+	if key == "" {
+		return err
+	}
+
+	data, merr := json.Marshal(m)
+	if merr == nil {
+		_, merr = b.PutObjectWithContext(ctx, key, bytes.NewReader(data), option.ContentType("application/json"))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return merr
+}
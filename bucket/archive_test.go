@@ -0,0 +1,48 @@
+package bucket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type objectsStub struct {
+	s3iface.S3API
+
+	objects []*s3.Object
+}
+
+func (s *objectsStub) ListObjectsV2PagesWithContext(_ aws.Context, _ *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	fn(&s3.ListObjectsV2Output{Contents: s.objects}, true)
+	return nil
+}
+
+func TestArchiveColdObjectsDryRun(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	obj := func(key, class string, size int64, modified time.Time) *s3.Object {
+		return &s3.Object{Key: aws.String(key), StorageClass: aws.String(class), Size: aws.Int64(size), LastModified: aws.Time(modified)}
+	}
+
+	stub := &objectsStub{objects: []*s3.Object{
+		obj("cold", "STANDARD", 1, old),
+		obj("hot", "STANDARD", 1, time.Now()),
+		obj("archived", "GLACIER", 1, old),
+		obj("huge", "STANDARD", 6<<30, old),
+		obj("filtered", "STANDARD", 0, old),
+	}}
+
+	m, err := New(stub, "bucket").ArchiveColdObjects(aws.BackgroundContext(), "", 24*time.Hour, s3.StorageClassGlacier, ArchiveOptions{
+		DryRun: true,
+		Filter: func(o *s3.Object) bool { return aws.Int64Value(o.Size) > 0 },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []ArchivedObject{{Key: "cold", Size: 1, StorageClass: "STANDARD"}}, m.Objects)
+	assert.Equal(t, []string{"huge"}, m.TooLarge)
+}
@@ -0,0 +1,29 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// GetObjectAttributes returns the ETag, the checksum, the parts, the storage class and the size of the object for key
+// without reading its body. Use option.Attributes to request only some of them.
+// The parts are returned only for an object uploaded with a checksum in a multipart upload.
+func (b *Bucket) GetObjectAttributes(key string, opts ...option.GetObjectAttributesInput) (*s3.GetObjectAttributesOutput, error) {
+	return b.GetObjectAttributesWithContext(aws.BackgroundContext(), key, opts...)
+}
+
+// GetObjectAttributesWithContext is the same as GetObjectAttributes with the context ctx.
+func (b *Bucket) GetObjectAttributesWithContext(ctx aws.Context, key string, opts ...option.GetObjectAttributesInput) (*s3.GetObjectAttributesOutput, error) {
+	req := &s3.GetObjectAttributesInput{
+		Bucket:           b.Name,
+		Key:              b.key(key),
+		ObjectAttributes: aws.StringSlice(s3.ObjectAttributes_Values()),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.GetObjectAttributesWithContext(ctx, req, b.reqOpts...)
+}
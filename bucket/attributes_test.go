@@ -0,0 +1,41 @@
+package bucket
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetObjectAttributes(t *testing.T) {
+	var attrs string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/bucket/tenant/key", r.URL.Path)
+		_, ok := r.URL.Query()["attributes"]
+		assert.True(t, ok)
+
+		attrs = r.Header.Get("X-Amz-Object-Attributes")
+		w.Write([]byte(`<GetObjectAttributesResponse><ETag>etag</ETag><StorageClass>STANDARD</StorageClass><ObjectSize>42</ObjectSize>` +
+			`<Checksum><ChecksumSHA256>c2hhMjU2</ChecksumSHA256></Checksum>` +
+			`<ObjectParts><PartsCount>2</PartsCount><Part><PartNumber>1</PartNumber><Size>21</Size></Part></ObjectParts>` +
+			`</GetObjectAttributesResponse>`))
+	})
+	b := New(svc, "bucket").WithPrefix("tenant/")
+
+	resp, err := b.GetObjectAttributes("key")
+	require.NoError(t, err)
+	assert.Equal(t, "ETag,Checksum,ObjectParts,StorageClass,ObjectSize", attrs)
+	assert.Equal(t, "etag", aws.StringValue(resp.ETag))
+	assert.Equal(t, int64(42), aws.Int64Value(resp.ObjectSize))
+	assert.Equal(t, "c2hhMjU2", aws.StringValue(resp.Checksum.ChecksumSHA256))
+	assert.Equal(t, int64(2), aws.Int64Value(resp.ObjectParts.TotalPartsCount))
+	require.Len(t, resp.ObjectParts.Parts, 1)
+
+	_, err = b.GetObjectAttributes("key", option.Attributes(s3.ObjectAttributesChecksum, s3.ObjectAttributesObjectSize))
+	require.NoError(t, err)
+	assert.Equal(t, "Checksum,ObjectSize", attrs)
+}
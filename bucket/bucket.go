@@ -1,15 +1,19 @@
 package bucket
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	"github.com/nabeken/aws-go-s3/bucket/option"
 )
 
@@ -17,33 +21,89 @@ import (
 type Bucket struct {
 	S3   s3iface.S3API
 	Name *string
+
+	// reqOpts are applied to every request made through the bucket.
+	reqOpts []request.Option
+
+	retry *retryState
+
+	// prefix is joined with every key. See WithPrefix.
+	prefix string
+
+	// codec encodes every key if set. See WithKeyCodec.
+	codec KeyCodec
+
+	// maxObjectSize is the upload size limit set by WithMaxObjectSize. Zero means no limit.
+	maxObjectSize int64
+
+	// maxListObjects is the limit of ListAllObjects set by WithMaxListObjects. Zero means no limit.
+	maxListObjects int
+
+	// spool configures PutObjectFromReader. See WithSpool.
+	spool spoolConfig
+
+	// invalidator invalidates CloudFront paths if set. See WithCloudFrontInvalidation.
+	invalidator *invalidator
+
+	// defaultPutOpts, defaultGetOpts and defaultCopyOpts are applied before the options of each call.
+	// See WithDefaultPutOptions, WithDefaultGetOptions and WithDefaultCopyOptions.
+	defaultPutOpts  []option.PutObjectInput
+	defaultGetOpts  []option.GetObjectInput
+	defaultCopyOpts []option.CopyObjectInput
+
+	// contentTypeAuto detects Content-Type of every object written. See WithContentTypeAuto.
+	contentTypeAuto bool
+
+	sts        stsiface.STSAPI
+	stsRoleARN string
 }
 
 // New returns Bucket instance with bucket name name.
-func New(s s3iface.S3API, name string) *Bucket {
-	return &Bucket{
-		S3:   s,
-		Name: aws.String(name),
+func New(s s3iface.S3API, name string, opts ...Option) *Bucket {
+	b := &Bucket{
+		S3:    s,
+		Name:  aws.String(name),
+		retry: &retryState{},
+		spool: spoolConfig{memLimit: defaultSpoolMemLimit},
 	}
+
+	for _, f := range opts {
+		f(b)
+	}
+
+	b.reqOpts = append(b.reqOpts, b.retry.requestOption, sentinelRequestOption)
+
+	return b
 }
 
 // GetObject returns the s3.GetObjectOutput.
 func (b *Bucket) GetObject(key string, opts ...option.GetObjectInput) (*s3.GetObjectOutput, error) {
+	return b.GetObjectWithContext(aws.BackgroundContext(), key, opts...)
+}
+
+// GetObjectWithContext is the same as GetObject with the context ctx.
+func (b *Bucket) GetObjectWithContext(ctx aws.Context, key string, opts ...option.GetObjectInput) (*s3.GetObjectOutput, error) {
 	req := &s3.GetObjectInput{
 		Bucket: b.Name,
-		Key:    aws.String(key),
+		Key:    b.key(key),
 	}
 
-	for _, f := range opts {
+	for _, f := range b.getOptions(opts) {
 		f(req)
 	}
 
-	return b.S3.GetObject(req)
+	return b.S3.GetObjectWithContext(ctx, req, b.reqOpts...)
 }
 
 // GetObjectReader returns a reader assosiated with body. A caller of this MUST close the reader when it finishes reading.
 func (b *Bucket) GetObjectReader(key string, opts ...option.GetObjectInput) (io.ReadCloser, error) {
-	resp, err := b.GetObject(key, opts...)
+	return b.GetObjectReaderWithContext(aws.BackgroundContext(), key, opts...)
+}
+
+// GetObjectReaderWithContext is the same as GetObjectReader with the context ctx.
+// The context also applies to reading the body.
+func (b *Bucket) GetObjectReaderWithContext(ctx aws.Context, key string, opts ...option.GetObjectInput) (io.ReadCloser, error) {
+	resp, err := b.GetObjectWithContext(ctx, key, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -55,96 +115,191 @@ func (b *Bucket) GetObjectReader(key string, opts ...option.GetObjectInput) (io.
 func (b *Bucket) GetObjectRequest(key string, opts ...option.GetObjectInput) (*request.Request, *s3.GetObjectOutput) {
 	req := &s3.GetObjectInput{
 		Bucket: b.Name,
-		Key:    aws.String(key),
+		Key:    b.key(key),
 	}
 
-	for _, f := range opts {
+	for _, f := range b.getOptions(opts) {
 		f(req)
 	}
 
-	return b.S3.GetObjectRequest(req)
+	r, resp := b.S3.GetObjectRequest(req)
+	r.ApplyOptions(b.reqOpts...)
+
+	return r, resp
 }
 
 // HeadObject retrieves an object metadata for key.
 func (b *Bucket) HeadObject(key string, opts ...option.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	return b.HeadObjectWithContext(aws.BackgroundContext(), key, opts...)
+}
+
+// HeadObjectWithContext is the same as HeadObject with the context ctx.
+func (b *Bucket) HeadObjectWithContext(ctx aws.Context, key string, opts ...option.HeadObjectInput) (*s3.HeadObjectOutput, error) {
 	req := &s3.HeadObjectInput{
 		Bucket: b.Name,
-		Key:    aws.String(key),
+		Key:    b.key(key),
 	}
 
 	for _, f := range opts {
 		f(req)
 	}
 
-	return b.S3.HeadObject(req)
+	return b.S3.HeadObjectWithContext(ctx, req, b.reqOpts...)
 }
 
 // ExistsObject returns true if key does not exist on bucket.
 func (b *Bucket) ExistsObject(key string, opts ...option.HeadObjectInput) (bool, error) {
-	_, err := b.HeadObject(key, opts...)
-	if err == nil {
-		return true, nil
-	}
+	return b.ExistsObjectWithContext(aws.BackgroundContext(), key, opts...)
+}
+
+// ExistsObjectWithContext is the same as ExistsObject with the context ctx.
+func (b *Bucket) ExistsObjectWithContext(ctx aws.Context, key string, opts ...option.HeadObjectInput) (bool, error) {
+	_, exists, err := b.StatObjectWithContext(ctx, key, opts...)
+
+	return exists, err
+}
+
+// ObjectInfo is the metadata of an object returned by StatObject.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+	StorageClass string
+	ContentType  string
+	VersionID    string
+	Metadata     map[string]string
+}
+
+// StatObject returns the metadata of the object for key in a single HeadObject.
+// It returns false with no error if key does not exist on bucket.
+func (b *Bucket) StatObject(key string, opts ...option.HeadObjectInput) (*ObjectInfo, bool, error) {
+	return b.StatObjectWithContext(aws.BackgroundContext(), key, opts...)
+}
 
+// StatObjectWithContext is the same as StatObject with the context ctx.
+func (b *Bucket) StatObjectWithContext(ctx aws.Context, key string, opts ...option.HeadObjectInput) (*ObjectInfo, bool, error) {
+	resp, err := b.HeadObjectWithContext(ctx, key, opts...)
 	if s3err, ok := err.(awserr.RequestFailure); ok && s3err.StatusCode() == http.StatusNotFound {
 		// actually key does not exist
-		return false, nil
+		return nil, false, nil
+	}
+	if err != nil {
+		// in some error situation
+		return nil, false, err
 	}
 
-	// in some error situation
-	return false, err
+	info := &ObjectInfo{
+		Key:          key,
+		Size:         aws.Int64Value(resp.ContentLength),
+		ETag:         aws.StringValue(resp.ETag),
+		LastModified: aws.TimeValue(resp.LastModified),
+		StorageClass: aws.StringValue(resp.StorageClass),
+		ContentType:  aws.StringValue(resp.ContentType),
+		VersionID:    aws.StringValue(resp.VersionId),
+		Metadata:     aws.StringValueMap(resp.Metadata),
+	}
+
+	// S3 omits the storage class of the objects in STANDARD.
+	if info.StorageClass == "" {
+		info.StorageClass = s3.StorageClassStandard
+	}
+
+	return info, true, nil
 }
 
 // PutObject puts an object with reading data from reader.
 func (b *Bucket) PutObject(key string, rs io.ReadSeeker, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	return b.PutObjectWithContext(aws.BackgroundContext(), key, rs, opts...)
+}
+
+// PutObjectWithContext is the same as PutObject with the context ctx.
+func (b *Bucket) PutObjectWithContext(ctx aws.Context, key string, rs io.ReadSeeker, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
 	req := &s3.PutObjectInput{
 		Bucket: b.Name,
-		Key:    aws.String(key),
 		Body:   rs,
 	}
 
-	for _, f := range opts {
-		f(req)
-	}
+	b.applyPutOptions(req, key, opts)
 
-	return b.S3.PutObject(req)
+	return b.S3.PutObjectWithContext(ctx, req, b.reqOpts...)
 }
 
 // DeleteObject deletes an object for key.
 func (b *Bucket) DeleteObject(key string) (*s3.DeleteObjectOutput, error) {
+	return b.DeleteObjectWithContext(aws.BackgroundContext(), key)
+}
+
+// DeleteObjectWithContext is the same as DeleteObject with the context ctx.
+func (b *Bucket) DeleteObjectWithContext(ctx aws.Context, key string) (*s3.DeleteObjectOutput, error) {
 	req := &s3.DeleteObjectInput{
 		Bucket: b.Name,
-		Key:    aws.String(key),
+		Key:    b.key(key),
 	}
 
-	return b.S3.DeleteObject(req)
+	return b.S3.DeleteObjectWithContext(ctx, req, b.reqOpts...)
 }
 
 // DeleteObjects deletes each object for the given identifiers.
-// A maximum of 1000 objects can be deleted at a time with this method.
+// A maximum of 1000 objects can be deleted at a time with this method. See DeleteObjectsAll for more.
 func (b *Bucket) DeleteObjects(identifiers []*s3.ObjectIdentifier) (*s3.DeleteObjectsOutput, error) {
+	return b.DeleteObjectsWithContext(aws.BackgroundContext(), identifiers)
+}
+
+// DeleteObjectsWithContext is the same as DeleteObjects with the context ctx.
+func (b *Bucket) DeleteObjectsWithContext(ctx aws.Context, identifiers []*s3.ObjectIdentifier) (*s3.DeleteObjectsOutput, error) {
 	req := &s3.DeleteObjectsInput{
 		Bucket: b.Name,
 		Delete: &s3.Delete{
-			Objects: identifiers,
+			Objects: make([]*s3.ObjectIdentifier, 0, len(identifiers)),
 		},
 	}
 
-	return b.S3.DeleteObjects(req)
+	for _, id := range identifiers {
+		id := *id
+		b.mapKey(&id.Key)
+		req.Delete.Objects = append(req.Delete.Objects, &id)
+	}
+
+	resp, err := b.S3.DeleteObjectsWithContext(ctx, req, b.reqOpts...)
+
+	// the objects are deleted if only the hook of WithWriteHook failed
+	var cerr *CallbackError
+	if err != nil && !errors.As(err, &cerr) {
+		return nil, err
+	}
+
+	b.unmapDeleteObjectsOutput(resp)
+
+	return resp, err
 }
 
 // ListObjects lists objects that has prefix.
 func (b *Bucket) ListObjects(prefix string, opts ...option.ListObjectsInput) (*s3.ListObjectsOutput, error) {
+	return b.ListObjectsWithContext(aws.BackgroundContext(), prefix, opts...)
+}
+
+// ListObjectsWithContext is the same as ListObjects with the context ctx.
+func (b *Bucket) ListObjectsWithContext(ctx aws.Context, prefix string, opts ...option.ListObjectsInput) (*s3.ListObjectsOutput, error) {
 	req := &s3.ListObjectsInput{
 		Bucket: b.Name,
-		Prefix: aws.String(prefix),
+		Prefix: b.key(prefix),
 	}
 
 	for _, f := range opts {
 		f(req)
 	}
 
-	return b.S3.ListObjects(req)
+	b.mapKey(&req.Marker)
+
+	resp, err := b.S3.ListObjectsWithContext(ctx, req, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	b.unmapListObjectsOutput(resp)
+
+	return resp, nil
 }
 
 // ListObjectsV2PagesWithContext will page through objects with the given prefix.
@@ -156,14 +311,48 @@ func (b *Bucket) ListObjectsV2PagesWithContext(
 ) error {
 	req := &s3.ListObjectsV2Input{
 		Bucket: b.Name,
-		Prefix: aws.String(prefix),
+		Prefix: b.key(prefix),
 	}
 
 	for _, f := range opts {
 		f(req)
 	}
 
-	return b.S3.ListObjectsV2PagesWithContext(ctx, req, pageFunc)
+	b.mapKey(&req.StartAfter)
+
+	return b.S3.ListObjectsV2PagesWithContext(ctx, req, func(page *s3.ListObjectsV2Output, last bool) bool {
+		b.unmapListObjectsV2Output(page)
+		return pageFunc(page, last)
+	}, b.reqOpts...)
+}
+
+// ListDir lists prefix as a directory with the delimiter "/" and returns the objects directly under prefix
+// and the common prefixes of the objects in the subdirectories, e.g. "dir/sub/", across all pages.
+// prefix should be empty or end with "/". The directory marker object whose key is prefix itself is not returned.
+func (b *Bucket) ListDir(ctx aws.Context, prefix string, opts ...option.ListObjectsV2Input) (files []*s3.Object, dirs []string, err error) {
+	opts = append(opts, func(req *s3.ListObjectsV2Input) {
+		req.Delimiter = aws.String("/")
+	})
+
+	err = b.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			if prefix != "" && aws.StringValue(o.Key) == prefix {
+				continue
+			}
+			files = append(files, o)
+		}
+
+		for _, cp := range page.CommonPrefixes {
+			dirs = append(dirs, aws.StringValue(cp.Prefix))
+		}
+
+		return true
+	}, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, dirs, nil
 }
 
 // ListObjectVersionsPagesWithContext will page through all versions of all objects with the given prefix.
@@ -175,27 +364,71 @@ func (b *Bucket) ListObjectVersionsPagesWithContext(
 ) error {
 	req := &s3.ListObjectVersionsInput{
 		Bucket: b.Name,
-		Prefix: aws.String(prefix),
+		Prefix: b.key(prefix),
 	}
 
 	for _, f := range opts {
 		f(req)
 	}
 
-	return b.S3.ListObjectVersionsPagesWithContext(ctx, req, pageFunc)
+	b.mapKey(&req.KeyMarker)
+
+	return b.S3.ListObjectVersionsPagesWithContext(ctx, req, func(page *s3.ListObjectVersionsOutput, last bool) bool {
+		b.unmapListObjectVersionsOutput(page)
+		return pageFunc(page, last)
+	}, b.reqOpts...)
 }
 
-// CopyObject copies an object within the bucket.
+// CopyObject copies an object within the bucket. Objects larger than 5 GiB must be copied by CopyObjectMultipart.
 func (b *Bucket) CopyObject(dest, src string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	return b.CopyObjectWithContext(aws.BackgroundContext(), dest, src, opts...)
+}
+
+// CopyObjectWithContext is the same as CopyObject with the context ctx.
+func (b *Bucket) CopyObjectWithContext(ctx aws.Context, dest, src string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	return b.CopyObjectFromWithContext(ctx, dest, aws.StringValue(b.Name), b.objectKey(src), opts...)
+}
+
+// CopyObjectFrom copies the object srcKey in the bucket srcBucket, which may be in another region, to dest in the bucket.
+// srcKey is the key stored in S3, i.e. it is not joined with the prefix of the Bucket.
+func (b *Bucket) CopyObjectFrom(dest, srcBucket, srcKey string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	return b.CopyObjectFromWithContext(aws.BackgroundContext(), dest, srcBucket, srcKey, opts...)
+}
+
+// CopyObjectFromWithContext is the same as CopyObjectFrom with the context ctx.
+func (b *Bucket) CopyObjectFromWithContext(ctx aws.Context, dest, srcBucket, srcKey string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
 	req := &s3.CopyObjectInput{
 		Bucket:     b.Name,
-		Key:        aws.String(dest),
-		CopySource: aws.String(aws.StringValue(b.Name) + "/" + url.QueryEscape(src)),
+		Key:        b.key(dest),
+		CopySource: aws.String(copySource(srcBucket, srcKey)),
 	}
 
-	for _, f := range opts {
+	for _, f := range b.copyOptions(opts) {
 		f(req)
 	}
 
-	return b.S3.CopyObject(req)
+	return b.S3.CopyObjectWithContext(ctx, req, b.reqOpts...)
+}
+
+// CopyObjectVersionFrom is the same as CopyObjectFrom but copies the version versionID of the source object.
+func (b *Bucket) CopyObjectVersionFrom(dest, srcBucket, srcKey, versionID string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	return b.CopyObjectVersionFromWithContext(aws.BackgroundContext(), dest, srcBucket, srcKey, versionID, opts...)
+}
+
+// CopyObjectVersionFromWithContext is the same as CopyObjectVersionFrom with the context ctx.
+func (b *Bucket) CopyObjectVersionFromWithContext(ctx aws.Context, dest, srcBucket, srcKey, versionID string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	opts = append([]option.CopyObjectInput{option.CopySourceVersionID(versionID)}, opts...)
+
+	return b.CopyObjectFromWithContext(ctx, dest, srcBucket, srcKey, opts...)
+}
+
+// copySource returns the value of CopySource for key in bucket.
+// Each segment of key is path-escaped. "+" is escaped as well since S3 may decode it as a space.
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = strings.Replace(url.PathEscape(seg), "+", "%2B", -1)
+	}
+
+	return bucket + "/" + strings.Join(segments, "/")
 }
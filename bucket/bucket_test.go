@@ -2,16 +2,21 @@ package bucket
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/nabeken/aws-go-s3/bucket/option"
 	"github.com/nabeken/aws-go-s3/ioutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -126,6 +131,25 @@ func (s *BucketSuite) TestObject() {
 	}
 }
 
+func (s *BucketSuite) TestPutObjectAndConfirm() {
+	key := "test-object-confirm"
+
+	_, err := s.bucket.PutObjectAndConfirm(
+		context.Background(),
+		key,
+		bytes.NewReader(s.testdata),
+		option.ContentType("application/json"),
+	)
+	s.Require().NoError(err)
+
+	exists, err := s.bucket.ExistsObject(key)
+	s.NoError(err)
+	s.True(exists)
+
+	_, err = s.bucket.DeleteObject(key)
+	s.Require().NoError(err)
+}
+
 func TestBucketSuite(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test")
@@ -133,3 +157,67 @@ func TestBucketSuite(t *testing.T) {
 
 	suite.Run(t, new(BucketSuite))
 }
+
+func TestStatObject(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bucket/key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Length", "5")
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("x-amz-meta-owner", "alice")
+	})
+	b := New(svc, "bucket")
+
+	info, exists, err := b.StatObject("key")
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, &ObjectInfo{
+		Key:          "key",
+		Size:         5,
+		ETag:         `"etag"`,
+		LastModified: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		StorageClass: s3.StorageClassStandard,
+		ContentType:  "text/plain",
+		Metadata:     map[string]string{"Owner": "alice"},
+	}, info)
+
+	info, exists, err = b.StatObject("missing")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Nil(t, info)
+}
+
+func TestListDir(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "/", q.Get("delimiter"))
+		assert.Equal(t, "tenant/dir/", q.Get("prefix"))
+
+		if q.Get("continuation-token") == "" {
+			w.Write([]byte(`<ListBucketResult><IsTruncated>true</IsTruncated><NextContinuationToken>next</NextContinuationToken>` +
+				`<Contents><Key>tenant/dir/</Key></Contents><Contents><Key>tenant/dir/a.txt</Key><Size>1</Size></Contents>` +
+				`<CommonPrefixes><Prefix>tenant/dir/sub1/</Prefix></CommonPrefixes></ListBucketResult>`))
+			return
+		}
+
+		w.Write([]byte(`<ListBucketResult><IsTruncated>false</IsTruncated>` +
+			`<Contents><Key>tenant/dir/b.txt</Key><Size>2</Size></Contents>` +
+			`<CommonPrefixes><Prefix>tenant/dir/sub2/</Prefix></CommonPrefixes></ListBucketResult>`))
+	})
+	b := New(svc, "bucket").WithPrefix("tenant/")
+
+	files, dirs, err := b.ListDir(context.Background(), "dir/")
+	require.NoError(t, err)
+
+	var keys []string
+	for _, o := range files {
+		keys = append(keys, aws.StringValue(o.Key))
+	}
+	assert.Equal(t, []string{"dir/a.txt", "dir/b.txt"}, keys)
+	assert.Equal(t, []string{"dir/sub1/", "dir/sub2/"}, dirs)
+}
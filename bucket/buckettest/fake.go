@@ -0,0 +1,284 @@
+// Package buckettest provides an in-memory implementation of s3iface.S3API for the tests of the code using bucket.Bucket.
+//
+//	fake := buckettest.New("bucket")
+//	b := bucket.New(fake, "bucket")
+//
+// The Fake keeps the objects with their metadata, tags and versions, pages the listings and assembles multipart
+// uploads like S3 does. The errors have the same codes and status codes as the ones of S3 and match the sentinel
+// errors of package bucket with errors.Is.
+//
+// The Fake implements the WithContext variants of the object, listing, multipart, tagging and versioning
+// operations, and the Pages variants of the listings. The other operations panic.
+// Since no HTTP request is made, the request options, and the Options of bucket.Bucket built on them, e.g.
+// bucket.WithRetryPolicy or bucket.WithMetrics, have no effect. The exception is the If-Match and If-None-Match
+// headers set on PutObject, e.g. by bucket.PutObjectIfMatch and bucket.PutObjectIfNotExists, which are honored.
+package buckettest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/nabeken/aws-go-s3/bucket"
+)
+
+// A Fake is an in-memory S3. It is safe for concurrent use.
+type Fake struct {
+	// S3API is nil so that the operations the Fake does not implement panic.
+	s3iface.S3API
+
+	// Now returns the time used for Last-Modified and the other timestamps. It is time.Now if nil.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*fakeBucket
+	seq     int
+}
+
+type fakeBucket struct {
+	created    time.Time
+	versioning string
+
+	// objects holds the versions of each key from the newest to the oldest.
+	objects map[string][]*object
+	uploads map[string]*upload
+}
+
+// New returns a Fake with the empty buckets.
+func New(buckets ...string) *Fake {
+	f := &Fake{buckets: map[string]*fakeBucket{}}
+	for _, name := range buckets {
+		f.buckets[name] = f.newBucket()
+	}
+
+	return f
+}
+
+func (f *Fake) newBucket() *fakeBucket {
+	return &fakeBucket{
+		created: f.now(),
+		objects: map[string][]*object{},
+		uploads: map[string]*upload{},
+	}
+}
+
+func (f *Fake) now() time.Time {
+	now := time.Now
+	if f.Now != nil {
+		now = f.Now
+	}
+
+	// HTTP dates have no fractional seconds.
+	return now().UTC().Truncate(time.Second)
+}
+
+// nextID returns an identifier unique in f, e.g. for versions and uploads.
+func (f *Fake) nextID() string {
+	f.seq++
+
+	return fmt.Sprintf("%016d", f.seq)
+}
+
+// canceled returns the error of the SDK if ctx is done.
+func canceled(ctx aws.Context) error {
+	if err := ctx.Err(); err != nil {
+		return awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+
+	return nil
+}
+
+// begin locks f and returns the bucket called name unless ctx is done or the bucket does not exist.
+// The caller must call f.mu.Unlock when it returns no error.
+func (f *Fake) begin(ctx aws.Context, name *string) (*fakeBucket, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+
+	b, ok := f.buckets[aws.StringValue(name)]
+	if !ok {
+		f.mu.Unlock()
+		return nil, errNoSuchBucket()
+	}
+
+	return b, nil
+}
+
+// A requestFailure is an error of S3 that matches a sentinel error of package bucket.
+type requestFailure struct {
+	awserr.RequestFailure
+	sentinel error
+}
+
+func (e *requestFailure) Is(target error) bool {
+	return e.sentinel != nil && target == e.sentinel
+}
+
+func (e *requestFailure) Unwrap() error {
+	return e.RequestFailure
+}
+
+func failure(code, message string, status int, sentinel error) error {
+	return &requestFailure{
+		RequestFailure: awserr.NewRequestFailure(awserr.New(code, message, nil), status, "buckettest"),
+		sentinel:       sentinel,
+	}
+}
+
+// headFailure returns the error of a HEAD request. The SDK takes the code from the status as the response has no body.
+func headFailure(status int, sentinel error) error {
+	return failure(strings.ReplaceAll(http.StatusText(status), " ", ""), http.StatusText(status), status, sentinel)
+}
+
+func errNoSuchBucket() error {
+	return failure("NoSuchBucket", "The specified bucket does not exist", http.StatusNotFound, bucket.ErrNoSuchBucket)
+}
+
+func errNoSuchKey() error {
+	return failure("NoSuchKey", "The specified key does not exist.", http.StatusNotFound, bucket.ErrNoSuchKey)
+}
+
+func errNoSuchVersion() error {
+	return failure("NoSuchVersion", "The specified version does not exist.", http.StatusNotFound, nil)
+}
+
+func errNoSuchUpload() error {
+	return failure("NoSuchUpload", "The specified upload does not exist.", http.StatusNotFound, nil)
+}
+
+func errPreconditionFailed() error {
+	return failure("PreconditionFailed", "At least one of the pre-conditions you specified did not hold", http.StatusPreconditionFailed, bucket.ErrPreconditionFailed)
+}
+
+func errNotModified() error {
+	return failure("NotModified", "Not Modified", http.StatusNotModified, bucket.ErrNotModified)
+}
+
+func errInvalidArgument(message string) error {
+	return failure("InvalidArgument", message, http.StatusBadRequest, nil)
+}
+
+// CreateBucketWithContext creates the bucket.
+func (f *Fake) CreateBucketWithContext(ctx aws.Context, in *s3.CreateBucketInput, _ ...request.Option) (*s3.CreateBucketOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(in.Bucket)
+	if _, ok := f.buckets[name]; ok {
+		return nil, failure("BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it.", http.StatusConflict, nil)
+	}
+
+	f.buckets[name] = f.newBucket()
+
+	return &s3.CreateBucketOutput{Location: aws.String("/" + name)}, nil
+}
+
+// HeadBucketWithContext checks that the bucket exists.
+func (f *Fake) HeadBucketWithContext(ctx aws.Context, in *s3.HeadBucketInput, _ ...request.Option) (*s3.HeadBucketOutput, error) {
+	_, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		if rerr, ok := err.(awserr.RequestFailure); ok {
+			return nil, headFailure(rerr.StatusCode(), bucket.ErrNoSuchBucket)
+		}
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	return &s3.HeadBucketOutput{}, nil
+}
+
+// WaitUntilBucketExistsWithContext returns nil if the bucket exists.
+func (f *Fake) WaitUntilBucketExistsWithContext(ctx aws.Context, in *s3.HeadBucketInput, _ ...request.WaiterOption) error {
+	_, err := f.HeadBucketWithContext(ctx, in)
+
+	return err
+}
+
+// DeleteBucketWithContext deletes the bucket if it has no objects.
+func (f *Fake) DeleteBucketWithContext(ctx aws.Context, in *s3.DeleteBucketInput, _ ...request.Option) (*s3.DeleteBucketOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	if len(b.objects) > 0 {
+		return nil, failure("BucketNotEmpty", "The bucket you tried to delete is not empty", http.StatusConflict, nil)
+	}
+
+	delete(f.buckets, aws.StringValue(in.Bucket))
+
+	return &s3.DeleteBucketOutput{}, nil
+}
+
+// ListBucketsWithContext returns the buckets.
+func (f *Fake) ListBucketsWithContext(ctx aws.Context, _ *s3.ListBucketsInput, _ ...request.Option) (*s3.ListBucketsOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := &s3.ListBucketsOutput{}
+	for _, name := range sortedKeys(f.buckets) {
+		out.Buckets = append(out.Buckets, &s3.Bucket{
+			Name:         aws.String(name),
+			CreationDate: aws.Time(f.buckets[name].created),
+		})
+	}
+
+	return out, nil
+}
+
+// PutBucketVersioningWithContext enables or suspends the versioning of the bucket.
+func (f *Fake) PutBucketVersioningWithContext(ctx aws.Context, in *s3.PutBucketVersioningInput, _ ...request.Option) (*s3.PutBucketVersioningOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	status := ""
+	if in.VersioningConfiguration != nil {
+		status = aws.StringValue(in.VersioningConfiguration.Status)
+	}
+
+	switch status {
+	case s3.BucketVersioningStatusEnabled, s3.BucketVersioningStatusSuspended:
+		b.versioning = status
+	default:
+		return nil, failure("MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema", http.StatusBadRequest, nil)
+	}
+
+	return &s3.PutBucketVersioningOutput{}, nil
+}
+
+// GetBucketVersioningWithContext returns the versioning status of the bucket.
+func (f *Fake) GetBucketVersioningWithContext(ctx aws.Context, in *s3.GetBucketVersioningInput, _ ...request.Option) (*s3.GetBucketVersioningOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	out := &s3.GetBucketVersioningOutput{}
+	if b.versioning != "" {
+		out.Status = aws.String(b.versioning)
+	}
+
+	return out, nil
+}
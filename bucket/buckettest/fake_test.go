@@ -0,0 +1,226 @@
+package buckettest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeObjects(t *testing.T) {
+	b := bucket.New(New("bucket"), "bucket")
+
+	metadata := func(req *s3.PutObjectInput) {
+		req.Metadata = aws.StringMap(map[string]string{"owner": "alice"})
+	}
+
+	_, err := b.PutObject("dir/key", strings.NewReader("hello"), option.ContentType("text/plain"), metadata)
+	require.NoError(t, err)
+
+	resp, err := b.GetObject("dir/key")
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, "text/plain", aws.StringValue(resp.ContentType))
+	assert.Equal(t, map[string]string{"Owner": "alice"}, aws.StringValueMap(resp.Metadata))
+
+	resp, err = b.GetObject("dir/key", option.Range(1, 2))
+	require.NoError(t, err)
+	body, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "el", string(body))
+	assert.Equal(t, "bytes 1-2/5", aws.StringValue(resp.ContentRange))
+
+	_, err = b.GetObject("missing")
+	assert.ErrorIs(t, err, bucket.ErrNoSuchKey)
+	_, err = b.HeadObject("missing")
+	assert.ErrorIs(t, err, bucket.ErrNoSuchKey)
+	assert.True(t, bucket.IsNotFound(err))
+	_, err = b.GetObject("dir/key", option.GetIfNoneMatch(aws.StringValue(resp.ETag)))
+	assert.ErrorIs(t, err, bucket.ErrNotModified)
+
+	_, err = b.CopyObject("copy", "dir/key")
+	require.NoError(t, err)
+	info, exists, err := b.StatObject("copy")
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, int64(5), info.Size)
+	assert.Equal(t, map[string]string{"Owner": "alice"}, info.Metadata)
+
+	_, err = bucket.New(New("bucket"), "other").GetObject("key")
+	assert.ErrorIs(t, err, bucket.ErrNoSuchBucket)
+}
+
+func TestFakeListing(t *testing.T) {
+	b := bucket.New(New("bucket"), "bucket")
+
+	for i := 0; i < 2500; i++ {
+		_, err := b.PutObject(fmt.Sprintf("p/%04d", i), strings.NewReader(""))
+		require.NoError(t, err)
+	}
+	_, err := b.PutObject("p/sub/key", strings.NewReader(""))
+	require.NoError(t, err)
+
+	var pages, n int
+	require.NoError(t, b.ListObjectsV2PagesWithContext(aws.BackgroundContext(), "p/", func(page *s3.ListObjectsV2Output, _ bool) bool {
+		pages++
+		n += len(page.Contents)
+		return true
+	}))
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, 2501, n)
+
+	resp, err := b.ListObjects("p/", option.ListDelimiter("/"), option.ListMarker("p/2000"))
+	require.NoError(t, err)
+	require.Len(t, resp.CommonPrefixes, 1)
+	assert.Equal(t, "p/sub/", aws.StringValue(resp.CommonPrefixes[0].Prefix))
+	assert.Len(t, resp.Contents, 499)
+	assert.False(t, aws.BoolValue(resp.IsTruncated))
+
+	deleted, err := b.DeletePrefix(aws.BackgroundContext(), "p/")
+	require.NoError(t, err)
+	assert.Equal(t, 2501, deleted)
+}
+
+func TestFakeVersions(t *testing.T) {
+	fake := New("bucket")
+	now := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	fake.Now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	b := bucket.New(fake, "bucket")
+
+	_, err := b.EnableVersioning()
+	require.NoError(t, err)
+
+	v1, err := b.PutObject("key", strings.NewReader("v1"))
+	require.NoError(t, err)
+	_, err = b.PutObject("key", strings.NewReader("v2"))
+	require.NoError(t, err)
+	_, err = b.DeleteObject("key")
+	require.NoError(t, err)
+
+	_, err = b.GetObject("key")
+	assert.ErrorIs(t, err, bucket.ErrNoSuchKey)
+
+	resp, err := b.GetObject("key", option.GetVersionID(aws.StringValue(v1.VersionId)))
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(body))
+
+	var entries []bucket.VersionEntry
+	for e, err := range b.ObjectVersions(aws.BackgroundContext(), "") {
+		require.NoError(t, err)
+		entries = append(entries, e)
+	}
+	require.Len(t, entries, 3)
+	assert.True(t, entries[0].IsDeleteMarker)
+	assert.True(t, entries[0].IsLatest)
+	assert.Equal(t, aws.StringValue(v1.VersionId), entries[2].VersionID)
+}
+
+func TestFakeConditionalPut(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	b := bucket.New(New("bucket"), "bucket")
+
+	t.Run("IfNotExists", func(t *testing.T) {
+		_, err := b.PutObjectIfNotExists(ctx, "new", strings.NewReader("v1"))
+		require.NoError(t, err)
+
+		_, err = b.PutObjectIfNotExists(ctx, "new", strings.NewReader("v2"))
+		assert.True(t, bucket.IsPreconditionFailed(err), err)
+		assert.ErrorIs(t, err, bucket.ErrPreconditionFailed)
+
+		body, err := b.GetObjectReader("new")
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "v1", string(data))
+	})
+
+	t.Run("IfMatch", func(t *testing.T) {
+		put, err := b.PutObject("match", strings.NewReader("v1"))
+		require.NoError(t, err)
+
+		_, err = b.PutObjectIfMatch(ctx, "match", `"wrong"`, strings.NewReader("v2"))
+		assert.True(t, bucket.IsPreconditionFailed(err), err)
+
+		put2, err := b.PutObjectIfMatch(ctx, "match", aws.StringValue(put.ETag), strings.NewReader("v2"))
+		require.NoError(t, err)
+
+		_, err = b.PutObjectIfMatch(ctx, "match", aws.StringValue(put.ETag), strings.NewReader("v3"))
+		assert.True(t, bucket.IsPreconditionFailed(err), err)
+
+		head, err := b.HeadObject("match")
+		require.NoError(t, err)
+		assert.Equal(t, aws.StringValue(put2.ETag), aws.StringValue(head.ETag))
+
+		_, err = b.PutObjectIfMatch(ctx, "missing", aws.StringValue(put.ETag), strings.NewReader("v1"))
+		assert.ErrorIs(t, err, bucket.ErrNoSuchKey)
+	})
+}
+
+func TestFakeMultipart(t *testing.T) {
+	fake := New("bucket")
+	b := bucket.New(fake, "bucket")
+
+	data := bytes.Repeat([]byte("0123456789"), 2<<20)
+	path := filepath.Join(t.TempDir(), "data.bin")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	put, err := b.PutObjectFromFile(aws.BackgroundContext(), "key", path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(aws.StringValue(put.ETag), `-3"`))
+
+	resp, err := b.GetObject("key")
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, data, body)
+
+	uploads, err := fake.ListMultipartUploadsWithContext(aws.BackgroundContext(), &s3.ListMultipartUploadsInput{Bucket: aws.String("bucket")})
+	require.NoError(t, err)
+	assert.Empty(t, uploads.Uploads)
+}
+
+func TestFakeCopySpecialKeys(t *testing.T) {
+	fake := New("bucket")
+	b := bucket.New(fake, "bucket")
+
+	_, err := b.EnableVersioning()
+	require.NoError(t, err)
+
+	for _, key := range []string{"with space", "a+b", "100%", "日本語/ファイル", "query?x=1&y#z", "/leading//double", "tilde~(1)"} {
+		v1, err := b.PutObject(key, strings.NewReader("v1"))
+		require.NoError(t, err, key)
+		_, err = b.PutObject(key, strings.NewReader("v2"))
+		require.NoError(t, err, key)
+
+		_, err = b.CopyObject("copy/"+key, key)
+		require.NoError(t, err, key)
+		_, err = b.CopyObjectVersionFrom("version/"+key, "bucket", key, aws.StringValue(v1.VersionId))
+		require.NoError(t, err, key)
+
+		for dest, expect := range map[string]string{"copy/" + key: "v2", "version/" + key: "v1"} {
+			resp, err := b.GetObject(dest)
+			require.NoError(t, err, dest)
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, expect, string(body), dest)
+		}
+	}
+}
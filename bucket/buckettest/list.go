@@ -0,0 +1,309 @@
+package buckettest
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// defaultMaxKeys is the number of entries S3 returns in a page by default.
+const defaultMaxKeys = 1000
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func maxKeys(n *int64) int {
+	if n == nil || *n <= 0 || *n > defaultMaxKeys {
+		return defaultMaxKeys
+	}
+
+	return int(*n)
+}
+
+// A listing is a page of the latest versions of the objects.
+type listing struct {
+	objects   []*object
+	prefixes  []string
+	truncated bool
+
+	// last is the key or the common prefix listed last.
+	last string
+}
+
+// list returns the page of up to max latest versions of the objects with prefix after the key after.
+// The keys with delimiter after prefix are rolled up into the common prefixes.
+func list(b *fakeBucket, prefix, delimiter, after string, max int) *listing {
+	l := &listing{}
+
+	for _, key := range sortedKeys(b.objects) {
+		versions := b.objects[key]
+		if !strings.HasPrefix(key, prefix) || key <= after || versions[0].deleteMarker {
+			continue
+		}
+
+		entry, common := key, false
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				entry, common = key[:len(prefix)+i+len(delimiter)], true
+			}
+		}
+
+		if common && (entry == after || entry == l.last) {
+			// the common prefix is already listed
+			continue
+		}
+
+		if len(l.objects)+len(l.prefixes) == max {
+			l.truncated = true
+			break
+		}
+
+		if common {
+			l.prefixes = append(l.prefixes, entry)
+		} else {
+			l.objects = append(l.objects, versions[0])
+		}
+		l.last = entry
+	}
+
+	return l
+}
+
+func (l *listing) contents() []*s3.Object {
+	var contents []*s3.Object
+	for _, o := range l.objects {
+		contents = append(contents, &s3.Object{
+			Key:          aws.String(o.key),
+			Size:         aws.Int64(int64(len(o.data))),
+			ETag:         aws.String(o.etag),
+			LastModified: aws.Time(o.lastModified),
+			StorageClass: aws.String(o.storageClass),
+		})
+	}
+
+	return contents
+}
+
+func (l *listing) commonPrefixes() []*s3.CommonPrefix {
+	var prefixes []*s3.CommonPrefix
+	for _, p := range l.prefixes {
+		prefixes = append(prefixes, &s3.CommonPrefix{Prefix: aws.String(p)})
+	}
+
+	return prefixes
+}
+
+// ListObjectsV2WithContext returns a page of the objects.
+func (f *Fake) ListObjectsV2WithContext(ctx aws.Context, in *s3.ListObjectsV2Input, _ ...request.Option) (*s3.ListObjectsV2Output, error) {
+	after := aws.StringValue(in.StartAfter)
+	if in.ContinuationToken != nil {
+		token, err := base64.RawURLEncoding.DecodeString(*in.ContinuationToken)
+		if err != nil {
+			return nil, errInvalidArgument("The continuation token provided is incorrect")
+		}
+		after = string(token)
+	}
+
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	max := maxKeys(in.MaxKeys)
+	l := list(b, aws.StringValue(in.Prefix), aws.StringValue(in.Delimiter), after, max)
+
+	out := &s3.ListObjectsV2Output{
+		Name:              in.Bucket,
+		Prefix:            in.Prefix,
+		Delimiter:         in.Delimiter,
+		StartAfter:        in.StartAfter,
+		ContinuationToken: in.ContinuationToken,
+		MaxKeys:           aws.Int64(int64(max)),
+		KeyCount:          aws.Int64(int64(len(l.objects) + len(l.prefixes))),
+		IsTruncated:       aws.Bool(l.truncated),
+		Contents:          l.contents(),
+		CommonPrefixes:    l.commonPrefixes(),
+	}
+	if l.truncated {
+		out.NextContinuationToken = aws.String(base64.RawURLEncoding.EncodeToString([]byte(l.last)))
+	}
+
+	return out, nil
+}
+
+// ListObjectsV2PagesWithContext calls fn with each page of the objects until fn returns false.
+func (f *Fake) ListObjectsV2PagesWithContext(ctx aws.Context, in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	req := *in
+	for {
+		page, err := f.ListObjectsV2WithContext(ctx, &req)
+		if err != nil {
+			return err
+		}
+
+		last := !aws.BoolValue(page.IsTruncated)
+		if !fn(page, last) || last {
+			return nil
+		}
+
+		req.ContinuationToken = page.NextContinuationToken
+	}
+}
+
+// ListObjectsWithContext returns a page of the objects with the version 1 API.
+func (f *Fake) ListObjectsWithContext(ctx aws.Context, in *s3.ListObjectsInput, _ ...request.Option) (*s3.ListObjectsOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	max := maxKeys(in.MaxKeys)
+	l := list(b, aws.StringValue(in.Prefix), aws.StringValue(in.Delimiter), aws.StringValue(in.Marker), max)
+
+	out := &s3.ListObjectsOutput{
+		Name:           in.Bucket,
+		Prefix:         in.Prefix,
+		Delimiter:      in.Delimiter,
+		Marker:         in.Marker,
+		MaxKeys:        aws.Int64(int64(max)),
+		IsTruncated:    aws.Bool(l.truncated),
+		Contents:       l.contents(),
+		CommonPrefixes: l.commonPrefixes(),
+	}
+	if l.truncated && in.Delimiter != nil {
+		// S3 returns NextMarker only with a delimiter. The SDK uses the last key otherwise.
+		out.NextMarker = aws.String(l.last)
+	}
+
+	return out, nil
+}
+
+// ListObjectsPagesWithContext calls fn with each page of the objects until fn returns false.
+func (f *Fake) ListObjectsPagesWithContext(ctx aws.Context, in *s3.ListObjectsInput, fn func(*s3.ListObjectsOutput, bool) bool, _ ...request.Option) error {
+	req := *in
+	for {
+		page, err := f.ListObjectsWithContext(ctx, &req)
+		if err != nil {
+			return err
+		}
+
+		last := !aws.BoolValue(page.IsTruncated)
+		if !fn(page, last) || last {
+			return nil
+		}
+
+		if page.NextMarker != nil {
+			req.Marker = page.NextMarker
+		} else {
+			req.Marker = page.Contents[len(page.Contents)-1].Key
+		}
+	}
+}
+
+// ListObjectVersionsWithContext returns a page of the versions and the delete markers of the objects.
+// Delimiter is not supported.
+func (f *Fake) ListObjectVersionsWithContext(ctx aws.Context, in *s3.ListObjectVersionsInput, _ ...request.Option) (*s3.ListObjectVersionsOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	max := maxKeys(in.MaxKeys)
+	out := &s3.ListObjectVersionsOutput{
+		Name:            in.Bucket,
+		Prefix:          in.Prefix,
+		KeyMarker:       in.KeyMarker,
+		VersionIdMarker: in.VersionIdMarker,
+		MaxKeys:         aws.Int64(int64(max)),
+		IsTruncated:     aws.Bool(false),
+	}
+
+	var (
+		n                  int
+		keyMarker          = aws.StringValue(in.KeyMarker)
+		versionIDMarker    = aws.StringValue(in.VersionIdMarker)
+		lastKey, lastVerID string
+	)
+
+keys:
+	for _, key := range sortedKeys(b.objects) {
+		if !strings.HasPrefix(key, aws.StringValue(in.Prefix)) || key < keyMarker || key == keyMarker && versionIDMarker == "" {
+			continue
+		}
+
+		versions := b.objects[key]
+		if key == keyMarker {
+			for i, v := range versions {
+				if v.versionID == versionIDMarker {
+					versions = versions[i+1:]
+					break
+				}
+			}
+		}
+
+		for _, v := range versions {
+			if n == max {
+				out.IsTruncated = aws.Bool(true)
+				out.NextKeyMarker = aws.String(lastKey)
+				out.NextVersionIdMarker = aws.String(lastVerID)
+				break keys
+			}
+
+			latest := v == b.objects[key][0]
+			if v.deleteMarker {
+				out.DeleteMarkers = append(out.DeleteMarkers, &s3.DeleteMarkerEntry{
+					Key:          aws.String(v.key),
+					VersionId:    aws.String(v.versionID),
+					IsLatest:     aws.Bool(latest),
+					LastModified: aws.Time(v.lastModified),
+				})
+			} else {
+				out.Versions = append(out.Versions, &s3.ObjectVersion{
+					Key:          aws.String(v.key),
+					VersionId:    aws.String(v.versionID),
+					IsLatest:     aws.Bool(latest),
+					LastModified: aws.Time(v.lastModified),
+					ETag:         aws.String(v.etag),
+					Size:         aws.Int64(int64(len(v.data))),
+					StorageClass: aws.String(v.storageClass),
+				})
+			}
+
+			n++
+			lastKey, lastVerID = v.key, v.versionID
+		}
+	}
+
+	return out, nil
+}
+
+// ListObjectVersionsPagesWithContext calls fn with each page of the versions until fn returns false.
+func (f *Fake) ListObjectVersionsPagesWithContext(ctx aws.Context, in *s3.ListObjectVersionsInput, fn func(*s3.ListObjectVersionsOutput, bool) bool, _ ...request.Option) error {
+	req := *in
+	for {
+		page, err := f.ListObjectVersionsWithContext(ctx, &req)
+		if err != nil {
+			return err
+		}
+
+		last := !aws.BoolValue(page.IsTruncated)
+		if !fn(page, last) || last {
+			return nil
+		}
+
+		req.KeyMarker, req.VersionIdMarker = page.NextKeyMarker, page.NextVersionIdMarker
+	}
+}
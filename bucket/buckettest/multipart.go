@@ -0,0 +1,334 @@
+package buckettest
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// minPartSize is the minimum size of a part of a multipart upload except the last one.
+const minPartSize = 5 << 20
+
+// An upload is a multipart upload in progress.
+type upload struct {
+	id        string
+	initiated time.Time
+
+	// object holds the key, the metadata and the tags of the object to be completed.
+	object *object
+	parts  map[int64]*part
+}
+
+type part struct {
+	data         []byte
+	etag         string
+	lastModified time.Time
+}
+
+func (f *Fake) findUpload(b *fakeBucket, key, uploadID *string) (*upload, error) {
+	u, ok := b.uploads[aws.StringValue(uploadID)]
+	if !ok || u.object.key != aws.StringValue(key) {
+		return nil, errNoSuchUpload()
+	}
+
+	return u, nil
+}
+
+// CreateMultipartUploadWithContext starts a multipart upload.
+func (f *Fake) CreateMultipartUploadWithContext(ctx aws.Context, in *s3.CreateMultipartUploadInput, _ ...request.Option) (*s3.CreateMultipartUploadOutput, error) {
+	tags, err := parseTagging(in.Tagging)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	u := &upload{
+		id:        f.nextID(),
+		initiated: f.now(),
+		object: &object{
+			key:          aws.StringValue(in.Key),
+			storageClass: storageClass(in.StorageClass),
+			headers: objectHeaders{
+				CacheControl:       in.CacheControl,
+				ContentDisposition: in.ContentDisposition,
+				ContentEncoding:    in.ContentEncoding,
+				ContentLanguage:    in.ContentLanguage,
+				ContentType:        in.ContentType,
+				Expires:            in.Expires,
+			},
+			encryption: objectEncryption{ServerSideEncryption: in.ServerSideEncryption, SSEKMSKeyId: in.SSEKMSKeyId},
+			metadata:   canonicalMetadata(in.Metadata),
+			tags:       tags,
+		},
+		parts: map[int64]*part{},
+	}
+	b.uploads[u.id] = u
+
+	return &s3.CreateMultipartUploadOutput{
+		Bucket:   in.Bucket,
+		Key:      in.Key,
+		UploadId: aws.String(u.id),
+	}, nil
+}
+
+// UploadPartWithContext uploads a part of the multipart upload.
+func (f *Fake) UploadPartWithContext(ctx aws.Context, in *s3.UploadPartInput, _ ...request.Option) (*s3.UploadPartOutput, error) {
+	data, err := readAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	u, err := f.findUpload(b, in.Key, in.UploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := f.putPart(u, in.PartNumber, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3.UploadPartOutput{ETag: aws.String(p.etag)}, nil
+}
+
+func (f *Fake) putPart(u *upload, number *int64, data []byte) (*part, error) {
+	num := aws.Int64Value(number)
+	if num < 1 || num > 10000 {
+		return nil, errInvalidArgument("Part number must be an integer between 1 and 10000, inclusive")
+	}
+
+	p := &part{data: data, etag: md5ETag(data), lastModified: f.now()}
+	u.parts[num] = p
+
+	return p, nil
+}
+
+// UploadPartCopyWithContext uploads a part of the multipart upload by copying CopySourceRange of CopySource.
+func (f *Fake) UploadPartCopyWithContext(ctx aws.Context, in *s3.UploadPartCopyInput, _ ...request.Option) (*s3.UploadPartCopyOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	u, err := f.findUpload(b, in.Key, in.UploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := f.copySource(in.CopySource)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := (conditions{in.CopySourceIfMatch, in.CopySourceIfNoneMatch, in.CopySourceIfModifiedSince, in.CopySourceIfUnmodifiedSince}).check(src); err != nil {
+		return nil, errPreconditionFailed()
+	}
+
+	data := src.data
+	if in.CopySourceRange != nil {
+		start, end, ok, err := byteRange(in.CopySourceRange, int64(len(data)))
+		if err != nil || !ok {
+			return nil, errInvalidArgument("The x-amz-copy-source-range value must be of the form bytes=first-last where first and last are the zero-based offsets of the first and last bytes to copy")
+		}
+		data = data[start : end+1]
+	}
+
+	p, err := f.putPart(u, in.PartNumber, append([]byte(nil), data...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3.UploadPartCopyOutput{
+		CopyPartResult:      &s3.CopyPartResult{ETag: aws.String(p.etag), LastModified: aws.Time(p.lastModified)},
+		CopySourceVersionId: aws.String(src.versionID),
+	}, nil
+}
+
+// CompleteMultipartUploadWithContext assembles the parts into the object.
+func (f *Fake) CompleteMultipartUploadWithContext(ctx aws.Context, in *s3.CompleteMultipartUploadInput, _ ...request.Option) (*s3.CompleteMultipartUploadOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	u, err := f.findUpload(b, in.Key, in.UploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	var completed []*s3.CompletedPart
+	if in.MultipartUpload != nil {
+		completed = in.MultipartUpload.Parts
+	}
+	if len(completed) == 0 {
+		return nil, failure("MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema", http.StatusBadRequest, nil)
+	}
+
+	var (
+		data []byte
+		sums []byte
+		prev int64
+	)
+	for i, c := range completed {
+		num := aws.Int64Value(c.PartNumber)
+		if num <= prev {
+			return nil, failure("InvalidPartOrder", "The list of parts was not in ascending order. Parts must be ordered by part number.", http.StatusBadRequest, nil)
+		}
+		prev = num
+
+		p, ok := u.parts[num]
+		if !ok || aws.StringValue(c.ETag) != p.etag {
+			return nil, failure("InvalidPart", "One or more of the specified parts could not be found.", http.StatusBadRequest, nil)
+		}
+		if i < len(completed)-1 && len(p.data) < minPartSize {
+			return nil, failure("EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size.", http.StatusBadRequest, nil)
+		}
+
+		data = append(data, p.data...)
+
+		sum, _ := hex.DecodeString(strings.Trim(p.etag, `"`))
+		sums = append(sums, sum...)
+	}
+
+	sum := md5.Sum(sums)
+
+	o := u.object
+	o.data = data
+	o.etag = fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(completed))
+	f.put(b, o)
+	delete(b.uploads, u.id)
+
+	return &s3.CompleteMultipartUploadOutput{
+		Bucket:    in.Bucket,
+		Key:       in.Key,
+		Location:  aws.String("/" + aws.StringValue(in.Bucket) + "/" + o.key),
+		ETag:      aws.String(o.etag),
+		VersionId: versionOutput(b, o),
+	}, nil
+}
+
+// AbortMultipartUploadWithContext discards the multipart upload and its parts.
+func (f *Fake) AbortMultipartUploadWithContext(ctx aws.Context, in *s3.AbortMultipartUploadInput, _ ...request.Option) (*s3.AbortMultipartUploadOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	u, err := f.findUpload(b, in.Key, in.UploadId)
+	if err != nil {
+		return nil, err
+	}
+	delete(b.uploads, u.id)
+
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+// ListPartsWithContext returns the parts uploaded to the multipart upload.
+func (f *Fake) ListPartsWithContext(ctx aws.Context, in *s3.ListPartsInput, _ ...request.Option) (*s3.ListPartsOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	u, err := f.findUpload(b, in.Key, in.UploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	nums := make([]int64, 0, len(u.parts))
+	for num := range u.parts {
+		nums = append(nums, num)
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+
+	out := &s3.ListPartsOutput{
+		Bucket:      in.Bucket,
+		Key:         in.Key,
+		UploadId:    in.UploadId,
+		IsTruncated: aws.Bool(false),
+	}
+	for _, num := range nums {
+		p := u.parts[num]
+		out.Parts = append(out.Parts, &s3.Part{
+			PartNumber:   aws.Int64(num),
+			ETag:         aws.String(p.etag),
+			Size:         aws.Int64(int64(len(p.data))),
+			LastModified: aws.Time(p.lastModified),
+		})
+	}
+
+	return out, nil
+}
+
+// ListMultipartUploadsWithContext returns the multipart uploads in progress. All of them are returned in one page.
+func (f *Fake) ListMultipartUploadsWithContext(ctx aws.Context, in *s3.ListMultipartUploadsInput, _ ...request.Option) (*s3.ListMultipartUploadsOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	var uploads []*upload
+	for _, u := range b.uploads {
+		if strings.HasPrefix(u.object.key, aws.StringValue(in.Prefix)) {
+			uploads = append(uploads, u)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if uploads[i].object.key != uploads[j].object.key {
+			return uploads[i].object.key < uploads[j].object.key
+		}
+		return uploads[i].id < uploads[j].id
+	})
+
+	out := &s3.ListMultipartUploadsOutput{
+		Bucket:      in.Bucket,
+		Prefix:      in.Prefix,
+		IsTruncated: aws.Bool(false),
+		MaxUploads:  aws.Int64(int64(len(uploads))),
+	}
+	for _, u := range uploads {
+		out.Uploads = append(out.Uploads, &s3.MultipartUpload{
+			Key:          aws.String(u.object.key),
+			UploadId:     aws.String(u.id),
+			Initiated:    aws.Time(u.initiated),
+			StorageClass: aws.String(u.object.storageClass),
+		})
+	}
+
+	return out, nil
+}
+
+// ListMultipartUploadsPagesWithContext calls fn with the page of ListMultipartUploadsWithContext.
+func (f *Fake) ListMultipartUploadsPagesWithContext(ctx aws.Context, in *s3.ListMultipartUploadsInput, fn func(*s3.ListMultipartUploadsOutput, bool) bool, _ ...request.Option) error {
+	page, err := f.ListMultipartUploadsWithContext(ctx, in)
+	if err != nil {
+		return err
+	}
+	fn(page, true)
+
+	return nil
+}
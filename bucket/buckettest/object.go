@@ -0,0 +1,676 @@
+package buckettest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+)
+
+// nullVersion is the version ID of the objects put while the versioning of the bucket is not enabled.
+const nullVersion = "null"
+
+// An object is a version of an object or a delete marker.
+type object struct {
+	key          string
+	versionID    string
+	deleteMarker bool
+	lastModified time.Time
+
+	data         []byte
+	etag         string
+	storageClass string
+	headers      objectHeaders
+	encryption   objectEncryption
+	metadata     map[string]*string
+	tags         []*s3.Tag
+}
+
+// objectEncryption is the server-side encryption requested for an object. It is recorded but not applied.
+type objectEncryption struct {
+	ServerSideEncryption *string
+	SSEKMSKeyId          *string
+}
+
+// objectHeaders are the standard headers stored with an object.
+type objectHeaders struct {
+	CacheControl       *string
+	ContentDisposition *string
+	ContentEncoding    *string
+	ContentLanguage    *string
+	ContentType        *string
+	Expires            *time.Time
+}
+
+func md5ETag(data []byte) string {
+	sum := md5.Sum(data)
+
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// canonicalMetadata returns the metadata with the keys in the form the SDK returns them.
+func canonicalMetadata(metadata map[string]*string) map[string]*string {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	m := make(map[string]*string, len(metadata))
+	for k, v := range metadata {
+		m[http.CanonicalHeaderKey(k)] = aws.String(aws.StringValue(v))
+	}
+
+	return m
+}
+
+func parseTagging(tagging *string) ([]*s3.Tag, error) {
+	if tagging == nil {
+		return nil, nil
+	}
+
+	q, err := url.ParseQuery(*tagging)
+	if err != nil {
+		return nil, errInvalidArgument("The tagging is not URL-encoded")
+	}
+
+	var tags []*s3.Tag
+	for _, k := range sortedKeys(q) {
+		tags = append(tags, &s3.Tag{Key: aws.String(k), Value: aws.String(q.Get(k))})
+	}
+
+	return tags, nil
+}
+
+func storageClass(class *string) string {
+	if c := aws.StringValue(class); c != "" {
+		return c
+	}
+
+	return s3.StorageClassStandard
+}
+
+// put stores o as the latest version of its key.
+func (f *Fake) put(b *fakeBucket, o *object) {
+	o.lastModified = f.now()
+
+	versions := b.objects[o.key]
+	if b.versioning == s3.BucketVersioningStatusEnabled {
+		o.versionID = f.nextID()
+	} else {
+		o.versionID = nullVersion
+		versions = removeVersion(versions, nullVersion)
+	}
+
+	b.objects[o.key] = append([]*object{o}, versions...)
+}
+
+func removeVersion(versions []*object, versionID string) []*object {
+	for i, v := range versions {
+		if v.versionID == versionID {
+			return append(versions[:i:i], versions[i+1:]...)
+		}
+	}
+
+	return versions
+}
+
+// versionOutput returns the version ID to return for o or nil if the versioning of b has never been enabled.
+func versionOutput(b *fakeBucket, o *object) *string {
+	if b.versioning == "" {
+		return nil
+	}
+
+	return aws.String(o.versionID)
+}
+
+// find returns the version of key or the latest one if versionID is nil.
+func find(b *fakeBucket, key string, versionID *string) (*object, error) {
+	versions := b.objects[key]
+
+	if versionID == nil {
+		if len(versions) == 0 || versions[0].deleteMarker {
+			return nil, errNoSuchKey()
+		}
+
+		return versions[0], nil
+	}
+
+	for _, v := range versions {
+		if v.versionID == *versionID {
+			if v.deleteMarker {
+				return nil, failure("MethodNotAllowed", "The specified method is not allowed against this resource.", http.StatusMethodNotAllowed, nil)
+			}
+			return v, nil
+		}
+	}
+
+	return nil, errNoSuchVersion()
+}
+
+// conditions holds the conditional headers of a request.
+type conditions struct {
+	ifMatch, ifNoneMatch               *string
+	ifModifiedSince, ifUnmodifiedSince *time.Time
+}
+
+// check returns the error of the request on o with c as S3 evaluates the conditions.
+func (c conditions) check(o *object) error {
+	if c.ifMatch != nil && aws.StringValue(c.ifMatch) != o.etag && aws.StringValue(c.ifMatch) != "*" {
+		return errPreconditionFailed()
+	}
+	if c.ifMatch == nil && c.ifUnmodifiedSince != nil && o.lastModified.After(*c.ifUnmodifiedSince) {
+		return errPreconditionFailed()
+	}
+	if c.ifNoneMatch != nil && (aws.StringValue(c.ifNoneMatch) == o.etag || aws.StringValue(c.ifNoneMatch) == "*") {
+		return errNotModified()
+	}
+	if c.ifNoneMatch == nil && c.ifModifiedSince != nil && !o.lastModified.After(*c.ifModifiedSince) {
+		return errNotModified()
+	}
+
+	return nil
+}
+
+// byteRange returns the first and the last byte of rng, e.g. "bytes=0-99", in an object of size bytes.
+// ok is false if rng is empty.
+func byteRange(rng *string, size int64) (start, end int64, ok bool, err error) {
+	if rng == nil {
+		return 0, 0, false, nil
+	}
+
+	invalid := failure("InvalidRange", "The requested range is not satisfiable", http.StatusRequestedRangeNotSatisfiable, nil)
+
+	spec, isBytes := strings.CutPrefix(*rng, "bytes=")
+	first, last, hasDash := strings.Cut(spec, "-")
+	if !isBytes || !hasDash || strings.Contains(spec, ",") {
+		// S3 ignores the ranges it does not support.
+		return 0, 0, false, nil
+	}
+
+	switch {
+	case first == "":
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false, invalid
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true, nil
+	default:
+		start, err := strconv.ParseInt(first, 10, 64)
+		if err != nil || start >= size {
+			return 0, 0, false, invalid
+		}
+
+		end := size - 1
+		if last != "" {
+			end, err = strconv.ParseInt(last, 10, 64)
+			if err != nil || end < start {
+				return 0, 0, false, invalid
+			}
+			if end >= size {
+				end = size - 1
+			}
+		}
+		return start, end, true, nil
+	}
+}
+
+// putConditions returns the conditional headers that opts set on the PutObject request for in, e.g. the ones of
+// bucket.PutObjectIfNotExists, since s3.PutObjectInput of the SDK does not have them.
+// The build handlers of opts are run on a request that is never sent.
+func putConditions(in *s3.PutObjectInput, opts []request.Option) conditions {
+	r := request.New(aws.Config{}, metadata.ClientInfo{}, request.Handlers{}, nil,
+		&request.Operation{Name: "PutObject", HTTPMethod: http.MethodPut, HTTPPath: "/{Bucket}/{Key+}"}, in, nil)
+	r.ApplyOptions(opts...)
+	r.Handlers.Build.Run(r)
+
+	var c conditions
+	if v := r.HTTPRequest.Header.Get("If-Match"); v != "" {
+		c.ifMatch = aws.String(v)
+	}
+	if v := r.HTTPRequest.Header.Get("If-None-Match"); v != "" {
+		c.ifNoneMatch = aws.String(v)
+	}
+
+	return c
+}
+
+// checkPut returns the error of a conditional write of key in b with c as S3 evaluates it.
+// If-Match on a missing key fails with NoSuchKey and If-None-Match on an existing one with PreconditionFailed.
+func (c conditions) checkPut(b *fakeBucket, key string) error {
+	if c.ifMatch == nil && c.ifNoneMatch == nil {
+		return nil
+	}
+
+	o, err := find(b, key, nil)
+	if err != nil {
+		if c.ifMatch != nil {
+			return err
+		}
+		return nil
+	}
+
+	if c.ifMatch != nil && aws.StringValue(c.ifMatch) != o.etag {
+		return errPreconditionFailed()
+	}
+	if c.ifNoneMatch != nil && aws.StringValue(c.ifNoneMatch) == "*" {
+		return errPreconditionFailed()
+	}
+
+	return nil
+}
+
+// PutObjectWithContext stores the object.
+// It honors the If-Match and If-None-Match headers set by the request options opts.
+func (f *Fake) PutObjectWithContext(ctx aws.Context, in *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	cond := putConditions(in, opts)
+
+	data, err := readAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := parseTagging(in.Tagging)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	if err := cond.checkPut(b, aws.StringValue(in.Key)); err != nil {
+		return nil, err
+	}
+
+	o := &object{
+		key:          aws.StringValue(in.Key),
+		data:         data,
+		etag:         md5ETag(data),
+		storageClass: storageClass(in.StorageClass),
+		headers: objectHeaders{
+			CacheControl:       in.CacheControl,
+			ContentDisposition: in.ContentDisposition,
+			ContentEncoding:    in.ContentEncoding,
+			ContentLanguage:    in.ContentLanguage,
+			ContentType:        in.ContentType,
+			Expires:            in.Expires,
+		},
+		encryption: objectEncryption{ServerSideEncryption: in.ServerSideEncryption, SSEKMSKeyId: in.SSEKMSKeyId},
+		metadata:   canonicalMetadata(in.Metadata),
+		tags:       tags,
+	}
+	f.put(b, o)
+
+	return &s3.PutObjectOutput{
+		ETag:                 aws.String(o.etag),
+		VersionId:            versionOutput(b, o),
+		ServerSideEncryption: o.encryption.ServerSideEncryption,
+		SSEKMSKeyId:          o.encryption.SSEKMSKeyId,
+	}, nil
+}
+
+// GetObjectWithContext returns the object or the byte range of it.
+func (f *Fake) GetObjectWithContext(ctx aws.Context, in *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	o, err := find(b, aws.StringValue(in.Key), in.VersionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := (conditions{in.IfMatch, in.IfNoneMatch, in.IfModifiedSince, in.IfUnmodifiedSince}).check(o); err != nil {
+		return nil, err
+	}
+
+	size := int64(len(o.data))
+	start, end, partial, err := byteRange(in.Range, size)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &s3.GetObjectOutput{
+		AcceptRanges:         aws.String("bytes"),
+		CacheControl:         o.headers.CacheControl,
+		ContentDisposition:   o.headers.ContentDisposition,
+		ContentEncoding:      o.headers.ContentEncoding,
+		ContentLanguage:      o.headers.ContentLanguage,
+		ContentType:          o.headers.ContentType,
+		ETag:                 aws.String(o.etag),
+		LastModified:         aws.Time(o.lastModified),
+		Metadata:             o.metadata,
+		VersionId:            versionOutput(b, o),
+		ServerSideEncryption: o.encryption.ServerSideEncryption,
+		SSEKMSKeyId:          o.encryption.SSEKMSKeyId,
+	}
+	if o.headers.Expires != nil {
+		out.Expires = aws.String(o.headers.Expires.Format(http.TimeFormat))
+	}
+	if o.storageClass != s3.StorageClassStandard {
+		out.StorageClass = aws.String(o.storageClass)
+	}
+	if len(o.tags) > 0 {
+		out.TagCount = aws.Int64(int64(len(o.tags)))
+	}
+
+	data := o.data
+	if partial {
+		data = data[start : end+1]
+		out.ContentRange = aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	}
+	out.ContentLength = aws.Int64(int64(len(data)))
+	out.Body = ioutil.NopCloser(bytes.NewReader(append([]byte(nil), data...)))
+
+	return out, nil
+}
+
+// HeadObjectWithContext returns the metadata of the object.
+func (f *Fake) HeadObjectWithContext(ctx aws.Context, in *s3.HeadObjectInput, _ ...request.Option) (*s3.HeadObjectOutput, error) {
+	resp, err := f.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket:            in.Bucket,
+		Key:               in.Key,
+		VersionId:         in.VersionId,
+		Range:             in.Range,
+		IfMatch:           in.IfMatch,
+		IfNoneMatch:       in.IfNoneMatch,
+		IfModifiedSince:   in.IfModifiedSince,
+		IfUnmodifiedSince: in.IfUnmodifiedSince,
+	})
+	if err != nil {
+		if rerr, ok := err.(*requestFailure); ok {
+			sentinel := rerr.sentinel
+			if rerr.StatusCode() == http.StatusNotFound {
+				sentinel = bucket.ErrNoSuchKey
+			}
+			return nil, headFailure(rerr.StatusCode(), sentinel)
+		}
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return &s3.HeadObjectOutput{
+		AcceptRanges:         resp.AcceptRanges,
+		CacheControl:         resp.CacheControl,
+		ContentDisposition:   resp.ContentDisposition,
+		ContentEncoding:      resp.ContentEncoding,
+		ContentLanguage:      resp.ContentLanguage,
+		ContentLength:        resp.ContentLength,
+		ContentType:          resp.ContentType,
+		ETag:                 resp.ETag,
+		Expires:              resp.Expires,
+		LastModified:         resp.LastModified,
+		Metadata:             resp.Metadata,
+		StorageClass:         resp.StorageClass,
+		VersionId:            resp.VersionId,
+		ServerSideEncryption: resp.ServerSideEncryption,
+		SSEKMSKeyId:          resp.SSEKMSKeyId,
+	}, nil
+}
+
+// DeleteObjectWithContext deletes the object, or the version of it if VersionId is set.
+// It puts a delete marker instead if the bucket is versioned.
+func (f *Fake) DeleteObjectWithContext(ctx aws.Context, in *s3.DeleteObjectInput, _ ...request.Option) (*s3.DeleteObjectOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	return f.delete(b, aws.StringValue(in.Key), in.VersionId), nil
+}
+
+func (f *Fake) delete(b *fakeBucket, key string, versionID *string) *s3.DeleteObjectOutput {
+	versions := b.objects[key]
+
+	if versionID != nil {
+		out := &s3.DeleteObjectOutput{VersionId: versionID}
+		for _, v := range versions {
+			if v.versionID == *versionID && v.deleteMarker {
+				out.DeleteMarker = aws.Bool(true)
+			}
+		}
+
+		if versions = removeVersion(versions, *versionID); len(versions) > 0 {
+			b.objects[key] = versions
+		} else {
+			delete(b.objects, key)
+		}
+
+		return out
+	}
+
+	if b.versioning == "" {
+		delete(b.objects, key)
+		return &s3.DeleteObjectOutput{}
+	}
+
+	marker := &object{key: key, deleteMarker: true}
+	f.put(b, marker)
+
+	return &s3.DeleteObjectOutput{DeleteMarker: aws.Bool(true), VersionId: aws.String(marker.versionID)}
+}
+
+// DeleteObjectsWithContext deletes the objects like DeleteObjectWithContext does.
+func (f *Fake) DeleteObjectsWithContext(ctx aws.Context, in *s3.DeleteObjectsInput, _ ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	if in.Delete == nil || len(in.Delete.Objects) > 1000 {
+		return nil, failure("MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema", http.StatusBadRequest, nil)
+	}
+
+	out := &s3.DeleteObjectsOutput{}
+	for _, id := range in.Delete.Objects {
+		resp := f.delete(b, aws.StringValue(id.Key), id.VersionId)
+		if aws.BoolValue(in.Delete.Quiet) {
+			continue
+		}
+
+		deleted := &s3.DeletedObject{Key: id.Key, VersionId: id.VersionId, DeleteMarker: resp.DeleteMarker}
+		if id.VersionId == nil && aws.BoolValue(resp.DeleteMarker) {
+			deleted.DeleteMarkerVersionId = resp.VersionId
+		}
+		out.Deleted = append(out.Deleted, deleted)
+	}
+
+	return out, nil
+}
+
+// copySource returns the version of the object that source, e.g. "bucket/key?versionId=v1", refers to.
+// f.mu must be held.
+func (f *Fake) copySource(source *string) (*object, error) {
+	src := strings.TrimPrefix(aws.StringValue(source), "/")
+
+	var versionID *string
+	if path, query, found := strings.Cut(src, "?"); found {
+		q, err := url.ParseQuery(query)
+		if err != nil || !q.Has("versionId") {
+			return nil, errInvalidArgument("Invalid copy source")
+		}
+		src, versionID = path, aws.String(q.Get("versionId"))
+	}
+
+	src, err := url.PathUnescape(src)
+	if err != nil {
+		return nil, errInvalidArgument("Invalid copy source encoding")
+	}
+
+	name, key, found := strings.Cut(src, "/")
+	if !found || key == "" {
+		return nil, errInvalidArgument("Invalid copy source")
+	}
+
+	b, ok := f.buckets[name]
+	if !ok {
+		return nil, errNoSuchBucket()
+	}
+
+	return find(b, key, versionID)
+}
+
+// CopyObjectWithContext copies the object in CopySource.
+func (f *Fake) CopyObjectWithContext(ctx aws.Context, in *s3.CopyObjectInput, _ ...request.Option) (*s3.CopyObjectOutput, error) {
+	tags, err := parseTagging(in.Tagging)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	src, err := f.copySource(in.CopySource)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := (conditions{in.CopySourceIfMatch, in.CopySourceIfNoneMatch, in.CopySourceIfModifiedSince, in.CopySourceIfUnmodifiedSince}).check(src); err != nil {
+		// S3 fails the copy with 412 for all the conditions.
+		return nil, errPreconditionFailed()
+	}
+
+	o := &object{
+		key:          aws.StringValue(in.Key),
+		data:         src.data,
+		etag:         src.etag,
+		storageClass: storageClass(in.StorageClass),
+		headers:      src.headers,
+		encryption:   objectEncryption{ServerSideEncryption: in.ServerSideEncryption, SSEKMSKeyId: in.SSEKMSKeyId},
+		metadata:     src.metadata,
+		tags:         src.tags,
+	}
+
+	if aws.StringValue(in.MetadataDirective) == s3.MetadataDirectiveReplace {
+		o.headers = objectHeaders{
+			CacheControl:       in.CacheControl,
+			ContentDisposition: in.ContentDisposition,
+			ContentEncoding:    in.ContentEncoding,
+			ContentLanguage:    in.ContentLanguage,
+			ContentType:        in.ContentType,
+			Expires:            in.Expires,
+		}
+		o.metadata = canonicalMetadata(in.Metadata)
+	} else if src == latestOf(b, o.key) && in.StorageClass == nil && in.ServerSideEncryption == nil {
+		return nil, errInvalidArgument("This copy request is illegal because it is trying to copy an object to itself without changing the object's metadata, storage class, website redirect location or encryption attributes.")
+	}
+
+	if aws.StringValue(in.TaggingDirective) == s3.TaggingDirectiveReplace {
+		o.tags = tags
+	}
+
+	f.put(b, o)
+
+	return &s3.CopyObjectOutput{
+		CopyObjectResult: &s3.CopyObjectResult{
+			ETag:         aws.String(o.etag),
+			LastModified: aws.Time(o.lastModified),
+		},
+		VersionId:           versionOutput(b, o),
+		CopySourceVersionId: aws.String(src.versionID),
+	}, nil
+}
+
+func latestOf(b *fakeBucket, key string) *object {
+	o, _ := find(b, key, nil)
+
+	return o
+}
+
+// GetObjectTaggingWithContext returns the tags of the object.
+func (f *Fake) GetObjectTaggingWithContext(ctx aws.Context, in *s3.GetObjectTaggingInput, _ ...request.Option) (*s3.GetObjectTaggingOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	o, err := find(b, aws.StringValue(in.Key), in.VersionId)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []*s3.Tag{}
+	for _, t := range o.tags {
+		tags = append(tags, &s3.Tag{Key: aws.String(aws.StringValue(t.Key)), Value: aws.String(aws.StringValue(t.Value))})
+	}
+
+	return &s3.GetObjectTaggingOutput{TagSet: tags, VersionId: versionOutput(b, o)}, nil
+}
+
+// PutObjectTaggingWithContext replaces the tags of the object.
+func (f *Fake) PutObjectTaggingWithContext(ctx aws.Context, in *s3.PutObjectTaggingInput, _ ...request.Option) (*s3.PutObjectTaggingOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	o, err := find(b, aws.StringValue(in.Key), in.VersionId)
+	if err != nil {
+		return nil, err
+	}
+
+	o.tags = nil
+	if in.Tagging != nil {
+		for _, t := range in.Tagging.TagSet {
+			o.tags = append(o.tags, &s3.Tag{Key: aws.String(aws.StringValue(t.Key)), Value: aws.String(aws.StringValue(t.Value))})
+		}
+	}
+	sort.Slice(o.tags, func(i, j int) bool {
+		return aws.StringValue(o.tags[i].Key) < aws.StringValue(o.tags[j].Key)
+	})
+
+	return &s3.PutObjectTaggingOutput{VersionId: versionOutput(b, o)}, nil
+}
+
+// DeleteObjectTaggingWithContext deletes the tags of the object.
+func (f *Fake) DeleteObjectTaggingWithContext(ctx aws.Context, in *s3.DeleteObjectTaggingInput, _ ...request.Option) (*s3.DeleteObjectTaggingOutput, error) {
+	b, err := f.begin(ctx, in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer f.mu.Unlock()
+
+	o, err := find(b, aws.StringValue(in.Key), in.VersionId)
+	if err != nil {
+		return nil, err
+	}
+	o.tags = nil
+
+	return &s3.DeleteObjectTaggingOutput{VersionId: versionOutput(b, o)}, nil
+}
+
+// readAll reads r for the operations taking a body.
+func readAll(r io.Reader) ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	return ioutil.ReadAll(r)
+}
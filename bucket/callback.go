@@ -0,0 +1,193 @@
+package bucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	callbackAttempts   = 3
+	callbackMinBackoff = 100 * time.Millisecond
+)
+
+// UploadInfo describes an object uploaded through a Bucket. It is passed to the callback of WithUploadCallback.
+type UploadInfo struct {
+	Bucket string `json:"bucket"`
+
+	// Key is relative to the Bucket given to New, i.e. it includes the prefix of views.
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+	ETag      string `json:"etag"`
+	VersionID string `json:"versionId,omitempty"`
+}
+
+// A CallbackError is returned by the upload helpers when the callback of WithUploadCallback fails, and by any write
+// when the hook of WithWriteHook fails. The object is uploaded, or deleted, and the output is returned along with the error.
+type CallbackError struct {
+	Key string
+	Err error
+}
+
+func (e *CallbackError) Error() string {
+	return fmt.Sprintf("bucket: callback for %s failed: %s", e.Key, e.Err)
+}
+
+func (e *CallbackError) Unwrap() error {
+	return e.Err
+}
+
+// WithUploadCallback returns an Option that calls fn after each successful PutObject, CompleteMultipartUpload and
+// CopyObject made through the Bucket, i.e. after every upload and copy including the ones made by the helpers
+// such as PutObjectIfMatch, PutObjectFromFile, PutObjectStream and CopyObjectMultipart. A failed call is retried
+// up to 3 times in total with exponential backoff and then *CallbackError is returned by the upload.
+//
+// S3 does not report the size of a copy made by CopyObject, so the object is read with HeadObject unless a helper
+// knows it. The objects written by the packages built on a Bucket, such as the usage objects of package quota and
+// the lock object of package maintenance, are uploads as well and fn should skip them by Key if needed.
+func WithUploadCallback(fn func(ctx aws.Context, info UploadInfo) error) Option {
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			var name, key *string
+			switch in := r.Params.(type) {
+			case *s3.PutObjectInput:
+				name, key = in.Bucket, in.Key
+			case *s3.CompleteMultipartUploadInput:
+				name, key = in.Bucket, in.Key
+			case *s3.CopyObjectInput:
+				name, key = in.Bucket, in.Key
+			default:
+				return
+			}
+
+			r.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+				if r.Error != nil {
+					return
+				}
+
+				info := UploadInfo{
+					Bucket: aws.StringValue(name),
+					Key:    b.userKey(aws.StringValue(key)),
+				}
+
+				var err error
+				switch out := r.Data.(type) {
+				case *s3.PutObjectOutput:
+					info.ETag, info.VersionID = aws.StringValue(out.ETag), aws.StringValue(out.VersionId)
+					info.Size = r.HTTPRequest.ContentLength
+					if in := r.Params.(*s3.PutObjectInput); in.ContentLength != nil {
+						info.Size = aws.Int64Value(in.ContentLength)
+					}
+				case *s3.CompleteMultipartUploadOutput:
+					info.ETag, info.VersionID = aws.StringValue(out.ETag), aws.StringValue(out.VersionId)
+					info.Size, err = b.uploadedSize(r.Context(), r.Params, info.VersionID)
+				case *s3.CopyObjectOutput:
+					if out.CopyObjectResult != nil {
+						info.ETag = aws.StringValue(out.CopyObjectResult.ETag)
+					}
+					info.VersionID = aws.StringValue(out.VersionId)
+					info.Size, err = b.uploadedSize(r.Context(), r.Params, info.VersionID)
+				}
+
+				if err == nil {
+					err = callUploadCallback(r.Context(), fn, info)
+				}
+				if err != nil {
+					// the upload must not be retried for the callback
+					r.Error = &CallbackError{Key: info.Key, Err: err}
+					r.Retryable = aws.Bool(false)
+				}
+			})
+		})
+	}
+}
+
+// uploadSizeKey is the context key of the size of an upload that S3 does not report in the response.
+type uploadSizeKey struct{}
+
+// withUploadSize returns ctx carrying size as the size of the upload made with it.
+func withUploadSize(ctx aws.Context, size int64) aws.Context {
+	return context.WithValue(ctx, uploadSizeKey{}, size)
+}
+
+// uploadedSize returns the size carried by ctx or the size of the object written by in reported by HeadObject.
+// The key, the SSE-C key and the request payer of the HeadObject are taken from in.
+func (b *Bucket) uploadedSize(ctx aws.Context, in interface{}, versionID string) (int64, error) {
+	if size, ok := ctx.Value(uploadSizeKey{}).(int64); ok {
+		return size, nil
+	}
+
+	head := &s3.HeadObjectInput{}
+	awsutil.Copy(head, in)
+	if versionID != "" {
+		head.VersionId = aws.String(versionID)
+	}
+
+	resp, err := b.S3.HeadObjectWithContext(ctx, head, b.reqOpts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return aws.Int64Value(resp.ContentLength), nil
+}
+
+func callUploadCallback(ctx aws.Context, fn func(aws.Context, UploadInfo) error, info UploadInfo) error {
+	backoff := callbackMinBackoff
+
+	var err error
+	for i := 0; i < callbackAttempts; i++ {
+		if i > 0 {
+			if serr := aws.SleepWithContext(ctx, backoff); serr != nil {
+				return err
+			}
+			backoff *= 2
+		}
+
+		if err = fn(ctx, info); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// WebhookCallback returns a callback for WithUploadCallback that POSTs UploadInfo as JSON to url with client.
+// http.DefaultClient is used if client is nil. A response other than 2xx is an error.
+func WebhookCallback(url string, client *http.Client) func(aws.Context, UploadInfo) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx aws.Context, info UploadInfo) error {
+		body, err := json.Marshal(info)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("webhook returned %s", resp.Status)
+		}
+
+		return nil
+	}
+}
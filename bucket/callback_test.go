@@ -0,0 +1,190 @@
+package bucket
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestS3 returns an S3 client sending requests to an HTTP server serving h.
+func newTestS3(t *testing.T, h http.HandlerFunc) *s3.S3 {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	return s3.New(session.Must(session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(srv.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		MaxRetries:       aws.Int(0),
+	})))
+}
+
+func TestWithUploadCallback(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("x-amz-version-id", "v1")
+	})
+
+	var (
+		infos []UploadInfo
+		fail  = 1
+	)
+	b := New(svc, "bucket", WithUploadCallback(func(_ aws.Context, info UploadInfo) error {
+		infos = append(infos, info)
+		if fail > 0 {
+			fail--
+			return errors.New("unavailable")
+		}
+		return nil
+	})).WithPrefix("dir/")
+
+	resp, err := b.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, `"etag"`, aws.StringValue(resp.ETag))
+
+	// the first call fails and is retried
+	require.Len(t, infos, 2)
+	assert.Equal(t, UploadInfo{Bucket: "bucket", Key: "dir/key", Size: 5, ETag: `"etag"`, VersionID: "v1"}, infos[1])
+
+	fail = callbackAttempts
+	resp, err = b.PutObject("key", strings.NewReader("hello"))
+
+	var cerr *CallbackError
+	require.ErrorAs(t, err, &cerr)
+	assert.Equal(t, "dir/key", cerr.Key)
+	assert.Equal(t, "v1", aws.StringValue(resp.VersionId))
+}
+
+func TestWithUploadCallbackMultipartAndCopy(t *testing.T) {
+	t.Run("Succeeds", func(t *testing.T) {
+		var (
+			mu    sync.Mutex
+			heads []string
+		)
+		svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			q := r.URL.Query()
+			switch {
+			case r.Method == http.MethodHead:
+				heads = append(heads, r.URL.Path)
+				w.Header().Set("Content-Length", "42")
+			case r.Method == http.MethodPost && q.Has("uploads"):
+				w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+			case r.Method == http.MethodPost:
+				w.Header().Set("x-amz-version-id", "v2")
+				w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"etag-2"</ETag></CompleteMultipartUploadResult>`))
+			case r.Header.Get("X-Amz-Copy-Source") != "":
+				w.Header().Set("x-amz-version-id", "v3")
+				w.Write([]byte(`<CopyObjectResult><ETag>"copy"</ETag></CopyObjectResult>`))
+			default:
+				ioutil.ReadAll(r.Body)
+				w.Header().Set("ETag", `"part"`)
+			}
+		})
+
+		var infos []UploadInfo
+		b := New(svc, "bucket", WithUploadCallback(func(_ aws.Context, info UploadInfo) error {
+			infos = append(infos, info)
+			return nil
+		}))
+
+		size := minPartSize + 1
+		_, err := b.PutObjectStream("key", io.MultiReader(bytes.NewReader(make([]byte, size))))
+		require.NoError(t, err)
+		require.Len(t, infos, 1, "the callback is called once for the multipart upload")
+		assert.Equal(t, UploadInfo{Bucket: "bucket", Key: "key", Size: int64(size), ETag: `"etag-2"`, VersionID: "v2"}, infos[0])
+		assert.Empty(t, heads)
+
+		_, err = b.CopyObjectMultipart(aws.BackgroundContext(), "dst", "key", MultipartCopyOptions{})
+		require.NoError(t, err)
+		require.Len(t, infos, 2)
+		assert.Equal(t, UploadInfo{Bucket: "bucket", Key: "dst", Size: 42, ETag: `"copy"`, VersionID: "v3"}, infos[1])
+		assert.Equal(t, []string{"/bucket/key"}, heads, "the size of the source is used")
+
+		_, err = b.CopyObject("dst", "key")
+		require.NoError(t, err)
+		require.Len(t, infos, 3)
+		assert.Equal(t, UploadInfo{Bucket: "bucket", Key: "dst", Size: 42, ETag: `"copy"`, VersionID: "v3"}, infos[2])
+		assert.Equal(t, []string{"/bucket/key", "/bucket/dst"}, heads, "the size of the copy is read with HeadObject")
+	})
+
+	// the output is returned along with *CallbackError
+	t.Run("Fails", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			aborted bool
+		)
+		svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			q := r.URL.Query()
+			switch {
+			case r.Method == http.MethodHead:
+				w.Header().Set("Content-Length", fmt.Sprint(maxCopyObjectSize+1))
+				w.Header().Set("ETag", `"src"`)
+			case r.Method == http.MethodGet && q.Has("tagging"):
+				w.Write([]byte(`<Tagging><TagSet></TagSet></Tagging>`))
+			case r.Method == http.MethodPost && q.Has("uploads"):
+				w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+			case r.Method == http.MethodPost:
+				w.Header().Set("x-amz-version-id", "v2")
+				w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"etag-2"</ETag></CompleteMultipartUploadResult>`))
+			case r.Method == http.MethodDelete:
+				aborted = true
+			case r.Header.Get("X-Amz-Copy-Source") != "":
+				w.Write([]byte(`<CopyPartResult><ETag>"part"</ETag></CopyPartResult>`))
+			default:
+				ioutil.ReadAll(r.Body)
+				w.Header().Set("ETag", `"part"`)
+			}
+		})
+
+		b := New(svc, "bucket", WithUploadCallback(func(aws.Context, UploadInfo) error {
+			return assert.AnError
+		}))
+
+		var cerr *CallbackError
+
+		resp, err := b.PutObjectStream("key", io.MultiReader(bytes.NewReader(make([]byte, minPartSize+1))))
+		require.ErrorAs(t, err, &cerr)
+		assert.ErrorIs(t, err, assert.AnError)
+		require.NotNil(t, resp, "the output is returned along with the error")
+		assert.Equal(t, `"etag-2"`, aws.StringValue(resp.ETag))
+		assert.Equal(t, "v2", aws.StringValue(resp.VersionId))
+
+		path := filepath.Join(t.TempDir(), "data.bin")
+		require.NoError(t, os.WriteFile(path, make([]byte, minPartSize+1), 0o600))
+		resp, err = b.PutObjectFromFile(aws.BackgroundContext(), "key", path)
+		require.ErrorAs(t, err, &cerr)
+		require.NotNil(t, resp, "the output is returned along with the error")
+		assert.Equal(t, `"etag-2"`, aws.StringValue(resp.ETag))
+
+		copied, err := b.CopyObjectMultipart(aws.BackgroundContext(), "dst", "key", MultipartCopyOptions{PartSize: maxCopyObjectSize + 1})
+		require.ErrorAs(t, err, &cerr)
+		require.NotNil(t, copied, "the output is returned along with the error")
+		assert.Equal(t, `"etag-2"`, aws.StringValue(copied.CopyObjectResult.ETag))
+		assert.Equal(t, "v2", aws.StringValue(copied.VersionId))
+
+		assert.False(t, aborted, "the uploads are completed")
+	})
+}
@@ -0,0 +1,149 @@
+package bucket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// ErrVersioningDisabled is returned by PutObjectExpectingVersion when versioning is not enabled on the bucket.
+var ErrVersioningDisabled = errors.New("bucket: versioning is not enabled on the bucket")
+
+// A VersionConflictError is returned by PutObjectExpectingVersion when the current version of the key is not the expected one.
+// Nothing is written when it is returned unless Written is set.
+type VersionConflictError struct {
+	Key      string
+	Expected string
+	Actual   string
+
+	// Written is the VersionId of the version written before the conflict is detected, which the caller may delete.
+	// It is empty if nothing is written.
+	Written string
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("bucket: %s: current version is %q instead of %q", e.Key, e.Actual, e.Expected)
+}
+
+// PutObjectExpectingVersion puts an object only if the current version of key is expectedVersionID and returns the new VersionId.
+// An empty expectedVersionID expects that key does not exist. It fails with ErrVersioningDisabled without writing
+// anything if versioning is not enabled on the bucket.
+//
+// S3 has no conditional write on VersionId, so the current version is checked with HeadObject and the write is made
+// conditional on the ETag returned with it, or on the absence of key. If another writer wins the race between them,
+// nothing is written and *VersionConflictError is returned.
+//
+// The ETag does not tell the versions with the same content apart, so a version written in the meantime with the
+// content of the expected one passes the condition. The version before the new one is therefore checked with
+// ListObjectVersions after the write, and *VersionConflictError with Written set is returned if it is not
+// expectedVersionID.
+func (b *Bucket) PutObjectExpectingVersion(ctx aws.Context, key, expectedVersionID string, rs io.ReadSeeker, opts ...option.PutObjectInput) (string, error) {
+	status, err := b.GetVersioningStatusWithContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if status.Status != s3.BucketVersioningStatusEnabled {
+		return "", ErrVersioningDisabled
+	}
+
+	current, etag, err := b.currentVersion(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	if current != expectedVersionID {
+		return "", &VersionConflictError{Key: key, Expected: expectedVersionID, Actual: current}
+	}
+
+	var resp *s3.PutObjectOutput
+	if expectedVersionID == "" {
+		resp, err = b.putObjectWithHeader(ctx, "If-None-Match", "*", key, rs, opts...)
+	} else {
+		resp, err = b.putObjectWithHeader(ctx, "If-Match", etag, key, rs, opts...)
+	}
+
+	// S3 returns NoSuchKey for If-Match if the key is deleted in the meantime.
+	if IsPreconditionFailed(err) || (expectedVersionID != "" && errors.Is(err, ErrNoSuchKey)) {
+		actual, _, herr := b.currentVersion(ctx, key)
+		if herr != nil {
+			return "", herr
+		}
+
+		return "", &VersionConflictError{Key: key, Expected: expectedVersionID, Actual: actual}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	newVersionID := aws.StringValue(resp.VersionId)
+	if expectedVersionID == "" {
+		return newVersionID, nil
+	}
+
+	previous, ok, err := b.previousVersion(ctx, key, newVersionID)
+	if err != nil {
+		return "", err
+	}
+
+	if ok && previous != expectedVersionID {
+		return "", &VersionConflictError{Key: key, Expected: expectedVersionID, Actual: previous, Written: newVersionID}
+	}
+
+	return newVersionID, nil
+}
+
+// previousVersion returns the VersionId of the version of key before versionID. It is empty if there is none or
+// the one before is a delete marker. ok is false if versionID is not found, e.g. because it is deleted in the meantime.
+func (b *Bucket) previousVersion(ctx aws.Context, key, versionID string) (previous string, ok bool, err error) {
+	stored := b.objectKey(key)
+
+	err = b.S3.ListObjectVersionsPagesWithContext(ctx, &s3.ListObjectVersionsInput{
+		Bucket: b.Name,
+		Prefix: aws.String(stored),
+	}, func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+		// the versions of key come before the ones of the other keys with the prefix
+		for _, e := range mergeVersions(page.Versions, page.DeleteMarkers) {
+			if e.Key != stored {
+				return false
+			}
+
+			if ok {
+				previous = e.VersionID
+				if e.IsDeleteMarker {
+					previous = ""
+				}
+				return false
+			}
+
+			ok = e.VersionID == versionID
+		}
+
+		return true
+	}, b.reqOpts...)
+	if err != nil {
+		return "", false, err
+	}
+
+	return previous, ok, nil
+}
+
+// currentVersion returns the VersionId and the ETag of the current version of key or empty if key does not exist.
+func (b *Bucket) currentVersion(ctx aws.Context, key string) (string, string, error) {
+	resp, err := b.S3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}, b.reqOpts...)
+	if IsNotFound(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return aws.StringValue(resp.VersionId), aws.StringValue(resp.ETag), nil
+}
@@ -0,0 +1,192 @@
+package bucket
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersionedTestS3 returns a Bucket on a versioned server keeping the versions of "key" from the newest to the oldest.
+// The ETag of a version is the one in etags or its VersionId quoted. PutObject honors If-Match and If-None-Match.
+// race is called on each PutObject before the condition is checked, e.g. to write another version first.
+func newVersionedTestS3(t *testing.T, versions *[]string, etags map[string]string, race func()) *Bucket {
+	etag := func(v string) string {
+		if e, ok := etags[v]; ok {
+			return e
+		}
+		return `"` + v + `"`
+	}
+
+	seq := 0
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if !r.URL.Query().Has("versions") {
+				w.Write([]byte(`<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`))
+				return
+			}
+
+			w.Write([]byte(`<ListVersionsResult>`))
+			for i, v := range *versions {
+				fmt.Fprintf(w, `<Version><Key>key</Key><VersionId>%s</VersionId><IsLatest>%t</IsLatest></Version>`, v, i == 0)
+			}
+			w.Write([]byte(`<Version><Key>key2</Key><VersionId>key2</VersionId><IsLatest>true</IsLatest></Version></ListVersionsResult>`))
+		case http.MethodHead:
+			if len(*versions) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("x-amz-version-id", (*versions)[0])
+			w.Header().Set("ETag", etag((*versions)[0]))
+		case http.MethodPut:
+			if race != nil {
+				race()
+			}
+
+			switch {
+			case r.Header.Get("If-None-Match") == "*" && len(*versions) > 0,
+				r.Header.Get("If-Match") != "" && len(*versions) > 0 && r.Header.Get("If-Match") != etag((*versions)[0]):
+				w.WriteHeader(http.StatusPreconditionFailed)
+				w.Write([]byte(`<Error><Code>PreconditionFailed</Code></Error>`))
+				return
+			case r.Header.Get("If-Match") != "" && len(*versions) == 0:
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>NoSuchKey</Code></Error>`))
+				return
+			}
+
+			seq++
+			v := fmt.Sprintf("new%d", seq)
+			*versions = append([]string{v}, *versions...)
+			w.Header().Set("x-amz-version-id", v)
+		}
+	})
+
+	return New(svc, "bucket")
+}
+
+func TestPutObjectExpectingVersion(t *testing.T) {
+	versions := []string{}
+	b := newVersionedTestS3(t, &versions, nil, nil)
+
+	v1, err := b.PutObjectExpectingVersion(aws.BackgroundContext(), "key", "", strings.NewReader("v1"))
+	require.NoError(t, err)
+	assert.Equal(t, "new1", v1)
+
+	v2, err := b.PutObjectExpectingVersion(aws.BackgroundContext(), "key", v1, strings.NewReader("v2"))
+	require.NoError(t, err)
+	assert.Equal(t, "new2", v2)
+	assert.Equal(t, []string{"new2", "new1"}, versions)
+}
+
+func TestPutObjectExpectingVersionMismatch(t *testing.T) {
+	versions := []string{"v2", "v1"}
+	b := newVersionedTestS3(t, &versions, nil, nil)
+
+	_, err := b.PutObjectExpectingVersion(aws.BackgroundContext(), "key", "v1", strings.NewReader("v3"))
+
+	var cerr *VersionConflictError
+	require.ErrorAs(t, err, &cerr)
+	assert.Equal(t, VersionConflictError{Key: "key", Expected: "v1", Actual: "v2"}, *cerr)
+	assert.Equal(t, []string{"v2", "v1"}, versions, "nothing is written")
+
+	_, err = b.PutObjectExpectingVersion(aws.BackgroundContext(), "key", "", strings.NewReader("v3"))
+	require.ErrorAs(t, err, &cerr)
+	assert.Equal(t, "v2", cerr.Actual, "the key is expected not to exist")
+}
+
+func TestPutObjectExpectingVersionRace(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		versions []string
+		expected string
+		race     func(versions []string) []string
+		actual   string
+		want     []string
+	}{
+		{
+			name:     "Overwritten",
+			versions: []string{"v1"},
+			expected: "v1",
+			race:     func(versions []string) []string { return append([]string{"other"}, versions...) },
+			actual:   "other",
+			want:     []string{"other", "v1"},
+		},
+		{
+			name:     "Created",
+			expected: "",
+			race:     func(versions []string) []string { return append([]string{"other"}, versions...) },
+			actual:   "other",
+			want:     []string{"other"},
+		},
+		{
+			name:     "Deleted",
+			versions: []string{"v1"},
+			expected: "v1",
+			race:     func([]string) []string { return nil },
+			actual:   "",
+			want:     nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			versions := tc.versions
+
+			// another writer lands between HeadObject and PutObject
+			b := newVersionedTestS3(t, &versions, nil, func() {
+				versions = tc.race(versions)
+			})
+
+			newVersionID, err := b.PutObjectExpectingVersion(aws.BackgroundContext(), "key", tc.expected, strings.NewReader("v2"))
+			assert.Empty(t, newVersionID)
+
+			var cerr *VersionConflictError
+			require.ErrorAs(t, err, &cerr)
+			assert.Equal(t, VersionConflictError{Key: "key", Expected: tc.expected, Actual: tc.actual}, *cerr)
+			assert.Equal(t, tc.want, versions, "nothing is written")
+		})
+	}
+}
+
+func TestPutObjectExpectingVersionSameContent(t *testing.T) {
+	versions := []string{"v1"}
+	etags := map[string]string{}
+
+	// another writer puts the content of v1 again between HeadObject and PutObject, which keeps the ETag
+	b := newVersionedTestS3(t, &versions, etags, func() {
+		if versions[0] == "v1" {
+			versions = append([]string{"other"}, versions...)
+			etags["other"] = `"v1"`
+		}
+	})
+
+	newVersionID, err := b.PutObjectExpectingVersion(aws.BackgroundContext(), "key", "v1", strings.NewReader("v2"))
+	assert.Empty(t, newVersionID)
+
+	var cerr *VersionConflictError
+	require.ErrorAs(t, err, &cerr)
+	assert.Equal(t, VersionConflictError{Key: "key", Expected: "v1", Actual: "other", Written: "new1"}, *cerr)
+	assert.Equal(t, []string{"new1", "other", "v1"}, versions)
+}
+
+func TestPutObjectExpectingVersionDisabled(t *testing.T) {
+	var puts int
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`<VersioningConfiguration><Status>Suspended</Status></VersioningConfiguration>`))
+		case http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			puts++
+		}
+	})
+
+	_, err := New(svc, "bucket").PutObjectExpectingVersion(aws.BackgroundContext(), "key", "", strings.NewReader("v1"))
+	assert.ErrorIs(t, err, ErrVersioningDisabled)
+	assert.Zero(t, puts, "nothing is written")
+}
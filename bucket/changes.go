@@ -0,0 +1,58 @@
+package bucket
+
+import (
+	"iter"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// Changes returns an iterator over the writes and deletes of the objects with the given prefix at or after since,
+// ordered from the oldest to the newest. Writes are object versions and deletes are delete markers.
+// It is meant for versioned buckets to process changes incrementally by passing the LastModified of the last entry
+// processed as since.
+//
+// LastModified has a precision of a second, so the entries in the second of since are yielded again to not miss
+// the ones written in the same second after the last call. Skip the entries already processed by Key and VersionID.
+//
+// All versions under the prefix are listed before the first entry is yielded since S3 does not list them by time.
+// An error ends the iteration after it is yielded.
+func (b *Bucket) Changes(ctx aws.Context, prefix string, since time.Time) iter.Seq2[VersionEntry, error] {
+	// a since with a fraction of a second, e.g. time.Now(), would skip the entries written earlier in its second
+	since = since.Truncate(time.Second)
+
+	return func(yield func(VersionEntry, error) bool) {
+		var changes []VersionEntry
+		for e, err := range b.ObjectVersions(ctx, prefix) {
+			if err != nil {
+				yield(VersionEntry{}, err)
+				return
+			}
+
+			if !e.LastModified.Before(since) {
+				changes = append(changes, e)
+			}
+		}
+
+		sortChanges(changes)
+
+		for _, e := range changes {
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+// sortChanges sorts entries from the oldest to the newest. The versions of a key listed from the newest
+// to the oldest by S3 are kept in the reverse order if they have the same LastModified.
+func sortChanges(entries []VersionEntry) {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastModified.Before(entries[j].LastModified)
+	})
+}
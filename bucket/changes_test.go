@@ -0,0 +1,62 @@
+package bucket
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortChanges(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// as listed by S3: by key and then from the newest
+	entries := []VersionEntry{
+		{Key: "a", VersionID: "a3", IsDeleteMarker: true, LastModified: t0.Add(2 * time.Second)},
+		{Key: "a", VersionID: "a2", LastModified: t0},
+		{Key: "a", VersionID: "a1", LastModified: t0},
+		{Key: "b", VersionID: "b1", LastModified: t0.Add(time.Second)},
+	}
+
+	sortChanges(entries)
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.VersionID)
+	}
+
+	assert.Equal(t, []string{"a1", "a2", "b1", "a3"}, got)
+}
+
+func TestChanges(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<ListVersionsResult>
+<Version><Key>a</Key><VersionId>a2</VersionId><LastModified>2024-01-01T00:00:01.000Z</LastModified></Version>
+<Version><Key>a</Key><VersionId>a1</VersionId><LastModified>2024-01-01T00:00:00.000Z</LastModified></Version>
+<DeleteMarker><Key>b</Key><VersionId>b2</VersionId><LastModified>2024-01-01T00:00:02.000Z</LastModified></DeleteMarker>
+<Version><Key>b</Key><VersionId>b1</VersionId><LastModified>2024-01-01T00:00:01.000Z</LastModified></Version>
+</ListVersionsResult>`))
+	})
+
+	for _, tc := range []struct {
+		name  string
+		since time.Time
+	}{
+		// the entries in the second of since are yielded again
+		{name: "second", since: time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)},
+		{name: "sub-second", since: time.Date(2024, 1, 1, 0, 0, 1, 500*int(time.Millisecond), time.UTC)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for e, err := range New(svc, "bucket").Changes(aws.BackgroundContext(), "", tc.since) {
+				require.NoError(t, err)
+				got = append(got, e.VersionID)
+			}
+
+			assert.Equal(t, []string{"b1", "a2", "b2"}, got)
+		})
+	}
+}
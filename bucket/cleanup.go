@@ -0,0 +1,183 @@
+package bucket
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// AbortStaleMultipartUploads aborts the multipart uploads with the given prefix initiated more than olderThan ago
+// and returns the number of aborted uploads.
+func (b *Bucket) AbortStaleMultipartUploads(ctx aws.Context, prefix string, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+
+	var stale []*s3.MultipartUpload
+	err := b.S3.ListMultipartUploadsPagesWithContext(ctx, &s3.ListMultipartUploadsInput{
+		Bucket: b.Name,
+		Prefix: b.key(prefix),
+	}, func(page *s3.ListMultipartUploadsOutput, _ bool) bool {
+		for _, u := range page.Uploads {
+			if aws.TimeValue(u.Initiated).Before(cutoff) {
+				stale = append(stale, u)
+			}
+		}
+		return true
+	}, b.reqOpts...)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, u := range stale {
+		// the key is already the key stored in S3
+		if _, err := b.S3.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   b.Name,
+			Key:      u.Key,
+			UploadId: u.UploadId,
+		}, b.reqOpts...); err != nil && !IsNotFound(err) {
+			return i, err
+		}
+	}
+
+	return len(stale), nil
+}
+
+// PurgeExpiredDeleteMarkers deletes the delete markers with the given prefix that have no noncurrent versions behind them
+// and returns the number of deleted markers. Such markers are left behind when the versions are expired by lifecycle rules.
+func (b *Bucket) PurgeExpiredDeleteMarkers(ctx aws.Context, prefix string) (int, error) {
+	var (
+		expired []*s3.ObjectIdentifier
+		cur     VersionEntry
+		count   int
+	)
+
+	flush := func() {
+		if count == 1 && cur.IsDeleteMarker {
+			expired = append(expired, &s3.ObjectIdentifier{Key: aws.String(cur.Key), VersionId: aws.String(cur.VersionID)})
+		}
+	}
+
+	for e, err := range b.ObjectVersions(ctx, prefix) {
+		if err != nil {
+			return 0, err
+		}
+
+		if e.Key != cur.Key || count == 0 {
+			flush()
+			cur, count = e, 0
+		}
+		count++
+	}
+	flush()
+
+	return b.deleteAll(ctx, expired)
+}
+
+// DeleteObjectsOlderThan deletes the objects with the given prefix last modified more than ttl ago
+// and returns the number of deleted objects. In a versioned bucket, the objects are deleted by delete markers.
+func (b *Bucket) DeleteObjectsOlderThan(ctx aws.Context, prefix string, ttl time.Duration) (int, error) {
+	cutoff := time.Now().Add(-ttl)
+
+	var old []*s3.ObjectIdentifier
+	err := b.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			if aws.TimeValue(o.LastModified).Before(cutoff) {
+				old = append(old, &s3.ObjectIdentifier{Key: o.Key})
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return b.deleteAll(ctx, old)
+}
+
+// DeletePrefix deletes every object with the given prefix and returns the number of deleted objects.
+// The objects are deleted with DeleteObjects in batches of 1000 while they are listed.
+func (b *Bucket) DeletePrefix(ctx aws.Context, prefix string) (int, error) {
+	return b.DeletePrefixConcurrently(ctx, prefix, 1)
+}
+
+// DeletePrefixConcurrently is the same as DeletePrefix but sends up to concurrency DeleteObjects requests at the same time.
+func (b *Bucket) DeletePrefixConcurrently(ctx aws.Context, prefix string, concurrency int) (int, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		deleted int
+		derr    error
+		batches = make(chan []*s3.ObjectIdentifier)
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for batch := range batches {
+				n, err := b.deleteAll(ctx, batch)
+
+				mu.Lock()
+				deleted += n
+				if err != nil && derr == nil {
+					derr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	send := func(batch []*s3.ObjectIdentifier) bool {
+		select {
+		case batches <- batch:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var (
+		batch []*s3.ObjectIdentifier
+		lerr  error
+	)
+	for o, err := range b.Objects(ctx, prefix) {
+		if err != nil {
+			lerr = err
+			break
+		}
+
+		batch = append(batch, &s3.ObjectIdentifier{Key: o.Key})
+		if len(batch) == maxDeleteObjects {
+			if !send(batch) {
+				break
+			}
+			batch = nil
+		}
+	}
+	if len(batch) > 0 && lerr == nil {
+		send(batch)
+	}
+
+	close(batches)
+	wg.Wait()
+
+	if derr != nil {
+		return deleted, derr
+	}
+	if lerr != nil {
+		return deleted, lerr
+	}
+
+	return deleted, nil
+}
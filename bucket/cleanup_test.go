@@ -0,0 +1,103 @@
+package bucket
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionsStub struct {
+	s3iface.S3API
+
+	page    *s3.ListObjectVersionsOutput
+	deleted []*s3.ObjectIdentifier
+}
+
+func (s *versionsStub) ListObjectVersionsPagesWithContext(_ aws.Context, _ *s3.ListObjectVersionsInput, fn func(*s3.ListObjectVersionsOutput, bool) bool, _ ...request.Option) error {
+	fn(s.page, true)
+	return nil
+}
+
+func (s *versionsStub) DeleteObjectsWithContext(_ aws.Context, in *s3.DeleteObjectsInput, _ ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	out := &s3.DeleteObjectsOutput{}
+	for _, id := range in.Delete.Objects {
+		s.deleted = append(s.deleted, id)
+		out.Deleted = append(out.Deleted, &s3.DeletedObject{Key: id.Key, VersionId: id.VersionId})
+	}
+
+	return out, nil
+}
+
+func TestPurgeExpiredDeleteMarkers(t *testing.T) {
+	marker := func(key, id string) *s3.DeleteMarkerEntry {
+		return &s3.DeleteMarkerEntry{Key: aws.String(key), VersionId: aws.String(id), IsLatest: aws.Bool(true)}
+	}
+
+	stub := &versionsStub{page: &s3.ListObjectVersionsOutput{
+		Versions: []*s3.ObjectVersion{
+			{Key: aws.String("b"), VersionId: aws.String("b1")},
+		},
+		DeleteMarkers: []*s3.DeleteMarkerEntry{
+			marker("a", "a2"),
+			marker("b", "b2"),
+			marker("c", "c1"),
+		},
+	}}
+
+	n, err := New(stub, "bucket").PurgeExpiredDeleteMarkers(aws.BackgroundContext(), "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	var deleted []string
+	for _, id := range stub.deleted {
+		deleted = append(deleted, aws.StringValue(id.Key)+"@"+aws.StringValue(id.VersionId))
+	}
+	assert.Equal(t, []string{"a@a2", "c@c1"}, deleted)
+}
+
+type prefixStub struct {
+	objectsStub
+
+	mu      sync.Mutex
+	batches []int
+}
+
+func (s *prefixStub) DeleteObjectsWithContext(_ aws.Context, in *s3.DeleteObjectsInput, _ ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches = append(s.batches, len(in.Delete.Objects))
+
+	out := &s3.DeleteObjectsOutput{}
+	for _, id := range in.Delete.Objects {
+		out.Deleted = append(out.Deleted, &s3.DeletedObject{Key: id.Key})
+	}
+
+	return out, nil
+}
+
+func TestDeletePrefix(t *testing.T) {
+	objects := make([]*s3.Object, 2500)
+	for i := range objects {
+		objects[i] = &s3.Object{Key: aws.String(fmt.Sprintf("logs/%04d", i))}
+	}
+
+	for _, concurrency := range []int{1, 3} {
+		stub := &prefixStub{objectsStub: objectsStub{objects: objects}}
+
+		n, err := New(stub, "bucket").DeletePrefixConcurrently(aws.BackgroundContext(), "logs/", concurrency)
+		require.NoError(t, err)
+		assert.Equal(t, 2500, n)
+
+		sort.Ints(stub.batches)
+		assert.Equal(t, []int{500, 1000, 1000}, stub.batches)
+	}
+}
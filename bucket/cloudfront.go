@@ -0,0 +1,178 @@
+package bucket
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go/service/cloudfront/cloudfrontiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	// invalidationDelay is the quiet period after the last write before the pending paths are invalidated.
+	invalidationDelay = time.Second
+
+	// invalidationMaxDelay is the longest the first pending path waits while writes keep coming.
+	invalidationMaxDelay = 10 * time.Second
+
+	// maxInvalidationPaths is the number of paths sent in an invalidation at most.
+	maxInvalidationPaths = 1000
+)
+
+// WithCloudFrontInvalidation returns an Option that invalidates the paths of the keys written or deleted through the Bucket
+// in the CloudFront distribution distributionID. pathMapper returns the path for a key, or empty to skip the key.
+// If pathMapper is nil, the path is "/" followed by the key.
+//
+// Invalidations are batched until no key is written for a second, or for 10 seconds at most, and are split into
+// batches of 1000 paths. Errors of the batches are returned by FlushInvalidations.
+func WithCloudFrontInvalidation(cf cloudfrontiface.CloudFrontAPI, distributionID string, pathMapper func(key string) string) Option {
+	if pathMapper == nil {
+		pathMapper = func(key string) string { return "/" + key }
+	}
+
+	return func(b *Bucket) {
+		inv := &invalidator{
+			cf:             cf,
+			distributionID: distributionID,
+			paths:          map[string]struct{}{},
+		}
+		b.invalidator = inv
+
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			r.Handlers.Complete.PushBack(func(r *request.Request) {
+				if r.Error != nil {
+					return
+				}
+
+				for _, key := range modifiedKeys(r.Params) {
+					if path := pathMapper(b.userKey(key)); path != "" {
+						inv.add(path)
+					}
+				}
+			})
+		})
+	}
+}
+
+// modifiedKeys returns the keys stored in S3 that are modified by the request with params.
+func modifiedKeys(params interface{}) []string {
+	switch in := params.(type) {
+	case *s3.PutObjectInput:
+		return []string{aws.StringValue(in.Key)}
+	case *s3.CopyObjectInput:
+		return []string{aws.StringValue(in.Key)}
+	case *s3.CompleteMultipartUploadInput:
+		return []string{aws.StringValue(in.Key)}
+	case *s3.DeleteObjectInput:
+		return []string{aws.StringValue(in.Key)}
+	case *s3.DeleteObjectsInput:
+		var keys []string
+		if in.Delete != nil {
+			for _, id := range in.Delete.Objects {
+				keys = append(keys, aws.StringValue(id.Key))
+			}
+		}
+		return keys
+	}
+
+	return nil
+}
+
+// FlushInvalidations invalidates the pending paths at once. It returns the error of a batch invalidated in the background
+// since the last call if any. It does nothing if the Bucket is not configured with WithCloudFrontInvalidation.
+func (b *Bucket) FlushInvalidations(ctx aws.Context) error {
+	if b.invalidator == nil {
+		return nil
+	}
+
+	return b.invalidator.flush(ctx)
+}
+
+type invalidator struct {
+	cf             cloudfrontiface.CloudFrontAPI
+	distributionID string
+	seq            int64
+
+	mu    sync.Mutex
+	paths map[string]struct{}
+	first time.Time
+	timer *time.Timer
+	err   error
+}
+
+// add adds path to the pending paths and resets the timer unless the first pending path has waited long enough.
+func (inv *invalidator) add(path string) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.paths[path] = struct{}{}
+
+	if inv.timer == nil {
+		inv.first = time.Now()
+		inv.timer = time.AfterFunc(invalidationDelay, func() {
+			if err := inv.flush(aws.BackgroundContext()); err != nil {
+				inv.mu.Lock()
+				inv.err = err
+				inv.mu.Unlock()
+			}
+		})
+
+		return
+	}
+
+	if time.Since(inv.first) < invalidationMaxDelay-invalidationDelay {
+		inv.timer.Reset(invalidationDelay)
+	}
+}
+
+// flush invalidates the pending paths and returns the first error, including the one recorded in the background.
+func (inv *invalidator) flush(ctx aws.Context) error {
+	inv.mu.Lock()
+	if inv.timer != nil {
+		inv.timer.Stop()
+		inv.timer = nil
+	}
+
+	paths := make([]string, 0, len(inv.paths))
+	for p := range inv.paths {
+		paths = append(paths, p)
+	}
+	inv.paths = map[string]struct{}{}
+
+	err := inv.err
+	inv.err = nil
+	inv.mu.Unlock()
+
+	sort.Strings(paths)
+
+	for len(paths) > 0 {
+		n := len(paths)
+		if n > maxInvalidationPaths {
+			n = maxInvalidationPaths
+		}
+
+		ref := strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatInt(atomic.AddInt64(&inv.seq, 1), 36)
+		if _, ierr := inv.cf.CreateInvalidationWithContext(ctx, &cloudfront.CreateInvalidationInput{
+			DistributionId: aws.String(inv.distributionID),
+			InvalidationBatch: &cloudfront.InvalidationBatch{
+				CallerReference: aws.String(ref),
+				Paths: &cloudfront.Paths{
+					Items:    aws.StringSlice(paths[:n]),
+					Quantity: aws.Int64(int64(n)),
+				},
+			},
+		}); ierr != nil && err == nil {
+			err = ierr
+		}
+
+		paths = paths[n:]
+	}
+
+	return err
+}
@@ -0,0 +1,48 @@
+package bucket
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go/service/cloudfront/cloudfrontiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cloudFrontStub struct {
+	cloudfrontiface.CloudFrontAPI
+
+	batches [][]string
+}
+
+func (s *cloudFrontStub) CreateInvalidationWithContext(_ aws.Context, in *cloudfront.CreateInvalidationInput, _ ...request.Option) (*cloudfront.CreateInvalidationOutput, error) {
+	s.batches = append(s.batches, aws.StringValueSlice(in.InvalidationBatch.Paths.Items))
+	return &cloudfront.CreateInvalidationOutput{}, nil
+}
+
+func TestWithCloudFrontInvalidation(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {})
+	cf := &cloudFrontStub{}
+
+	b := New(svc, "bucket", WithCloudFrontInvalidation(cf, "dist", nil)).WithPrefix("static/")
+
+	for i := 0; i < maxInvalidationPaths+1; i++ {
+		_, err := b.PutObject(strconv.Itoa(i), strings.NewReader(""))
+		require.NoError(t, err)
+	}
+
+	_, err := b.DeleteObject("0")
+	require.NoError(t, err)
+
+	require.NoError(t, b.FlushInvalidations(aws.BackgroundContext()))
+
+	require.Len(t, cf.batches, 2)
+	assert.Len(t, cf.batches[0], maxInvalidationPaths)
+	assert.Equal(t, "/static/0", cf.batches[0][0])
+	assert.Len(t, cf.batches[1], 1)
+}
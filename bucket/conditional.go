@@ -0,0 +1,58 @@
+package bucket
+
+import (
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// PutObjectIfMatch puts an object only if the current ETag of key is etag.
+// It fails with an error satisfying IsPreconditionFailed if the condition does not hold.
+func (b *Bucket) PutObjectIfMatch(ctx aws.Context, key, etag string, rs io.ReadSeeker, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	return b.putObjectWithHeader(ctx, "If-Match", etag, key, rs, opts...)
+}
+
+// PutObjectIfNotExists puts an object only if key does not exist.
+// It fails with an error satisfying IsPreconditionFailed if the condition does not hold.
+func (b *Bucket) PutObjectIfNotExists(ctx aws.Context, key string, rs io.ReadSeeker, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	return b.putObjectWithHeader(ctx, "If-None-Match", "*", key, rs, opts...)
+}
+
+// putObjectWithHeader puts an object with the HTTP header name set to value.
+// The header is set on the request since s3.PutObjectInput of the SDK does not have the conditional fields.
+func (b *Bucket) putObjectWithHeader(ctx aws.Context, name, value, key string, rs io.ReadSeeker, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	req := &s3.PutObjectInput{
+		Bucket: b.Name,
+		Body:   rs,
+	}
+
+	b.applyPutOptions(req, key, opts)
+
+	reqOpts := append(append([]request.Option(nil), b.reqOpts...), func(r *request.Request) {
+		r.Handlers.Build.PushBack(func(r *request.Request) {
+			r.HTTPRequest.Header.Set(name, value)
+		})
+	})
+
+	return b.S3.PutObjectWithContext(ctx, req, reqOpts...)
+}
+
+// GetObjectIfNoneMatch gets an object only if its ETag is not etag, e.g. to revalidate a cached copy.
+// modified is false and the output is nil if the ETag is still etag.
+func (b *Bucket) GetObjectIfNoneMatch(ctx aws.Context, key, etag string, opts ...option.GetObjectInput) (*s3.GetObjectOutput, bool, error) {
+	opts = append(append([]option.GetObjectInput(nil), opts...), option.GetIfNoneMatch(etag))
+
+	resp, err := b.GetObjectWithContext(ctx, key, opts...)
+	if errors.Is(err, ErrNotModified) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return resp, true, nil
+}
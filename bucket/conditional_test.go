@@ -0,0 +1,63 @@
+package bucket
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetObjectIfNoneMatch(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"current"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"current"`)
+		w.Write([]byte("hello"))
+	})
+	b := New(svc, "bucket")
+
+	resp, modified, err := b.GetObjectIfNoneMatch(aws.BackgroundContext(), "key", `"old"`)
+	require.NoError(t, err)
+	assert.True(t, modified)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+
+	resp, modified, err = b.GetObjectIfNoneMatch(aws.BackgroundContext(), "key", `"current"`)
+	require.NoError(t, err)
+	assert.False(t, modified)
+	assert.Nil(t, resp)
+}
+
+func TestConditionalErrors(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("If-Match") != "":
+			w.WriteHeader(http.StatusPreconditionFailed)
+		case r.Header.Get("If-Modified-Since") != "":
+			w.WriteHeader(http.StatusNotModified)
+		}
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.GetObject("key", option.GetIfMatch(`"old"`))
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.NotErrorIs(t, err, ErrNotModified)
+	assert.True(t, IsPreconditionFailed(err))
+
+	_, err = b.HeadObject("key", option.HeadIfModifiedSince(time.Now()))
+	assert.ErrorIs(t, err, ErrNotModified)
+	assert.False(t, IsNotFound(err))
+
+	_, err = b.HeadObject("key")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,92 @@
+package bucket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+const (
+	confirmTimeout    = 30 * time.Second
+	confirmMinBackoff = 50 * time.Millisecond
+	confirmMaxBackoff = 2 * time.Second
+)
+
+// ErrNotConfirmed is returned by PutObjectAndConfirm when the written object is not observed within 30 seconds.
+var ErrNotConfirmed = errors.New("bucket: the written object was not observed before the timeout")
+
+// PutObjectAndConfirm puts an object and then polls HeadObject until the ETag and VersionId returned by the PUT are observed.
+// It waits up to 30 seconds and then fails with ErrNotConfirmed. If ctx is done before that, ctx.Err() is returned.
+// The output of the PUT is returned with the error of the confirmation.
+func (b *Bucket) PutObjectAndConfirm(ctx aws.Context, key string, rs io.ReadSeeker, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	req := &s3.PutObjectInput{
+		Bucket: b.Name,
+		Body:   rs,
+	}
+
+	b.applyPutOptions(req, key, opts)
+
+	resp, err := b.S3.PutObjectWithContext(ctx, req, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.confirmObject(ctx, key, resp.ETag, resp.VersionId, confirmTimeout); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// confirmObject polls HeadObject with exponential backoff until the object for key has the given ETag and VersionId.
+// It returns ErrNotConfirmed after timeout or the error of parent if parent is done first.
+func (b *Bucket) confirmObject(parent aws.Context, key string, etag, versionID *string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	notConfirmed := func() error {
+		if err := parent.Err(); err != nil {
+			return err
+		}
+		return ErrNotConfirmed
+	}
+
+	req := &s3.HeadObjectInput{
+		Bucket:    b.Name,
+		Key:       b.key(key),
+		VersionId: versionID,
+	}
+
+	backoff := confirmMinBackoff
+	for {
+		resp, err := b.S3.HeadObjectWithContext(ctx, req, b.reqOpts...)
+		switch {
+		case err == nil:
+			if aws.StringValue(resp.ETag) == aws.StringValue(etag) &&
+				aws.StringValue(resp.VersionId) == aws.StringValue(versionID) {
+				return nil
+			}
+		case ctx.Err() != nil:
+			return notConfirmed()
+		default:
+			if s3err, ok := err.(awserr.RequestFailure); !ok || s3err.StatusCode() != http.StatusNotFound {
+				return err
+			}
+		}
+
+		if err := aws.SleepWithContext(ctx, backoff); err != nil {
+			return notConfirmed()
+		}
+
+		if backoff *= 2; backoff > confirmMaxBackoff {
+			backoff = confirmMaxBackoff
+		}
+	}
+}
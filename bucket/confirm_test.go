@@ -0,0 +1,78 @@
+package bucket
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newConfirmTestS3 returns a Bucket whose PUT returns the ETag "new" and version "v2".
+// HEAD returns 404 for the first notFound calls and then the ETag etag.
+func newConfirmTestS3(t *testing.T, notFound int, etag string) (*Bucket, func() int) {
+	var (
+		mu    sync.Mutex
+		heads int
+	)
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			w.Header().Set("ETag", `"new"`)
+			w.Header().Set("x-amz-version-id", "v2")
+		case http.MethodHead:
+			mu.Lock()
+			heads++
+			n := heads
+			mu.Unlock()
+
+			assert.Equal(t, "v2", r.URL.Query().Get("versionId"))
+			if n <= notFound {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			w.Header().Set("x-amz-version-id", "v2")
+		}
+	})
+
+	return New(svc, "bucket"), func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return heads
+	}
+}
+
+func TestPutObjectAndConfirm(t *testing.T) {
+	b, heads := newConfirmTestS3(t, 2, `"new"`)
+
+	resp, err := b.PutObjectAndConfirm(aws.BackgroundContext(), "key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", aws.StringValue(resp.VersionId))
+	assert.Equal(t, 3, heads(), "HEAD is retried while it returns 404")
+}
+
+func TestConfirmObjectTimeout(t *testing.T) {
+	b, heads := newConfirmTestS3(t, 0, `"old"`)
+
+	start := time.Now()
+	err := b.confirmObject(aws.BackgroundContext(), "key", aws.String(`"new"`), aws.String("v2"), 300*time.Millisecond)
+	assert.Equal(t, ErrNotConfirmed, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Greater(t, heads(), 1)
+}
+
+func TestConfirmObjectContextDone(t *testing.T) {
+	b, _ := newConfirmTestS3(t, 0, `"old"`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	err := b.confirmObject(ctx, "key", aws.String(`"new"`), aws.String("v2"), confirmTimeout)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,85 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// NewWithAssumedRole returns Bucket instance with bucket name name whose requests are signed with credentials of roleARN.
+// The credentials are obtained with sess and refreshed automatically before they expire.
+// externalID is passed to AssumeRole unless it is empty.
+func NewWithAssumedRole(sess client.ConfigProvider, roleARN, externalID, name string, opts ...Option) *Bucket {
+	creds := stscreds.NewCredentials(sess, roleARN, func(p *stscreds.AssumeRoleProvider) {
+		if externalID != "" {
+			p.ExternalID = aws.String(externalID)
+		}
+	})
+
+	return New(s3.New(sess, &aws.Config{Credentials: creds}), name, opts...)
+}
+
+// NewWithWebIdentity returns Bucket instance with bucket name name whose requests are signed with credentials of roleARN
+// obtained by the web identity token in tokenFile, e.g. the service account token projected by EKS (IRSA).
+// The token file is read again whenever the credentials are refreshed.
+func NewWithWebIdentity(tokenFile, roleARN, region, name string, opts ...Option) (*Bucket, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, err
+	}
+
+	creds := stscreds.NewWebIdentityCredentials(sess, roleARN, "", tokenFile)
+
+	return New(s3.New(sess, &aws.Config{Credentials: creds}), name, opts...), nil
+}
+
+// NewFromProfile returns Bucket instance with bucket name name using the shared config profile, including SSO profiles.
+// An MFA token is read from stdin if the profile requires it.
+// The region of the bucket is looked up when the profile does not specify a region.
+func NewFromProfile(profile, name string, opts ...Option) (*Bucket, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Profile:                 profile,
+		SharedConfigState:       session.SharedConfigEnable,
+		AssumeRoleTokenProvider: stscreds.StdinTokenProvider,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.StringValue(sess.Config.Region) == "" {
+		region, err := s3manager.GetBucketRegion(aws.BackgroundContext(), sess, name, "us-east-1")
+		if err != nil {
+			return nil, err
+		}
+
+		sess = sess.Copy(&aws.Config{Region: aws.String(region)})
+	}
+
+	return New(s3.New(sess), name, opts...), nil
+}
+
+// NewWithEndpoint returns Bucket instance with bucket name name on an S3-compatible service at endpoint, e.g. MinIO,
+// Ceph RGW or localstack. The requests use path-style addressing and are signed for region, which is "us-east-1"
+// if empty, with creds, or with the default credential chain if creds is nil.
+// An endpoint without a scheme uses HTTPS. Use WithInsecureSkipVerify for a server with a self-signed certificate.
+func NewWithEndpoint(endpoint, region, name string, creds *credentials.Credentials, opts ...Option) (*Bucket, error) {
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Region:           aws.String(region),
+		Credentials:      creds,
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return New(s3.New(sess), name, opts...), nil
+}
@@ -0,0 +1,181 @@
+package bucket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithEndpoint(t *testing.T) {
+	// the environment may point the SDK to a CA bundle that does not exist
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	var path, auth string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+	}))
+	t.Cleanup(srv.Close)
+
+	creds := credentials.NewStaticCredentials("minio", "minio123", "")
+
+	b, err := NewWithEndpoint(srv.URL, "", "bucket", creds)
+	require.NoError(t, err)
+
+	_, err = b.PutObject("key", strings.NewReader("hello"))
+	require.Error(t, err, "the certificate of the server is self-signed")
+
+	b, err = NewWithEndpoint(srv.URL, "", "bucket", creds, WithInsecureSkipVerify())
+	require.NoError(t, err)
+
+	_, err = b.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "/bucket/key", path)
+	assert.Contains(t, auth, "Credential=minio/")
+	assert.Contains(t, auth, "/us-east-1/s3/")
+}
+
+func TestNewWithAssumedRole(t *testing.T) {
+	var (
+		assumeRole = map[string]string{}
+		path, auth string
+	)
+	// the server plays both STS and S3
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			require.NoError(t, r.ParseForm())
+			for _, k := range []string{"Action", "RoleArn", "ExternalId"} {
+				assumeRole[k] = r.PostForm.Get(k)
+			}
+			w.Write([]byte(`<AssumeRoleResponse><AssumeRoleResult><Credentials>
+<AccessKeyId>ASIAROLE</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>
+<Expiration>2100-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`))
+			return
+		}
+
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+	}))
+	t.Cleanup(srv.Close)
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Endpoint:         aws.String(srv.URL),
+		Region:           aws.String("eu-west-1"),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+	}))
+
+	b := NewWithAssumedRole(sess, "arn:aws:iam::123456789012:role/reader", "external", "bucket")
+
+	svc := b.S3.(*s3.S3)
+	assert.Equal(t, "eu-west-1", aws.StringValue(svc.Config.Region))
+
+	creds, err := svc.Config.Credentials.Get()
+	require.NoError(t, err)
+	assert.Equal(t, stscreds.ProviderName, creds.ProviderName)
+	assert.Equal(t, "ASIAROLE", creds.AccessKeyID)
+	assert.Equal(t, map[string]string{
+		"Action":     "AssumeRole",
+		"RoleArn":    "arn:aws:iam::123456789012:role/reader",
+		"ExternalId": "external",
+	}, assumeRole)
+
+	_, err = b.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "/bucket/key", path)
+	assert.Contains(t, auth, "Credential=ASIAROLE/")
+	assert.Contains(t, auth, "/eu-west-1/s3/")
+
+	// ExternalId is not sent if it is empty
+	b = NewWithAssumedRole(sess, "arn:aws:iam::123456789012:role/reader", "", "bucket")
+	_, err = b.S3.(*s3.S3).Config.Credentials.Get()
+	require.NoError(t, err)
+	assert.Empty(t, assumeRole["ExternalId"])
+}
+
+func TestNewWithWebIdentity(t *testing.T) {
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+
+	b, err := NewWithWebIdentity(tokenFile, "arn:aws:iam::123456789012:role/irsa", "ap-northeast-1", "bucket")
+	require.NoError(t, err)
+
+	svc := b.S3.(*s3.S3)
+	assert.Equal(t, "ap-northeast-1", aws.StringValue(svc.Config.Region))
+	assert.Equal(t, "ap-northeast-1", svc.SigningRegion)
+
+	// the token file is read when the credentials are retrieved
+	_, err = svc.Config.Credentials.Get()
+	var aerr awserr.Error
+	require.ErrorAs(t, err, &aerr)
+	assert.Equal(t, stscreds.ErrCodeWebIdentity, aerr.Code())
+	assert.Contains(t, err.Error(), tokenFile)
+}
+
+func TestNewFromProfile(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(config, []byte(`[profile dev]
+region = eu-central-1
+
+[profile noregion]
+`), 0o600))
+	shared := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(shared, []byte(`[dev]
+aws_access_key_id = AKIDDEV
+aws_secret_access_key = secret
+
+[noregion]
+aws_access_key_id = AKIDNOREGION
+aws_secret_access_key = secret
+`), 0o600))
+
+	t.Setenv("AWS_CONFIG_FILE", config)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", shared)
+	t.Setenv("AWS_CA_BUNDLE", "")
+	for _, env := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_DEFAULT_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		t.Setenv(env, "")
+	}
+
+	for _, tc := range []struct {
+		profile   string
+		envRegion string
+		region    string
+		keyID     string
+	}{
+		{profile: "dev", region: "eu-central-1", keyID: "AKIDDEV"},
+
+		// the region of the environment is used without looking up the region of the bucket
+		{profile: "noregion", envRegion: "us-west-2", region: "us-west-2", keyID: "AKIDNOREGION"},
+	} {
+		t.Run(tc.profile, func(t *testing.T) {
+			t.Setenv("AWS_REGION", tc.envRegion)
+
+			b, err := NewFromProfile(tc.profile, "bucket")
+			require.NoError(t, err)
+
+			svc := b.S3.(*s3.S3)
+			assert.Equal(t, tc.region, aws.StringValue(svc.Config.Region))
+			assert.Equal(t, tc.region, svc.SigningRegion)
+
+			creds, err := svc.Config.Credentials.Get()
+			require.NoError(t, err)
+			assert.Equal(t, tc.keyID, creds.AccessKeyID)
+			assert.Equal(t, "SharedConfigCredentials: "+shared, creds.ProviderName)
+		})
+	}
+
+	_, err := NewFromProfile("missing", "bucket")
+	assert.Error(t, err)
+}
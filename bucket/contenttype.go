@@ -0,0 +1,191 @@
+package bucket
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/ioutils"
+)
+
+const (
+	// defaultContentType is the Content-Type S3 assigns to objects uploaded without one.
+	defaultContentType = "binary/octet-stream"
+
+	svgContentType = "image/svg+xml"
+)
+
+// activeContentTypes are the media types a browser may run scripts in. A wildcard of the allowlist does not match them.
+var activeContentTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+	svgContentType:          true,
+}
+
+// A ContentTypeError is returned when an upload has a Content-Type outside the allowlist of the Bucket.
+type ContentTypeError struct {
+	Key         string
+	ContentType string
+
+	// Sniffed reports whether ContentType is detected from the body rather than declared.
+	Sniffed bool
+}
+
+func (e *ContentTypeError) Error() string {
+	kind := "declared"
+	if e.Sniffed {
+		kind = "detected"
+	}
+
+	return fmt.Sprintf("bucket: %s: %s content type %q is not allowed", e.Key, kind, e.ContentType)
+}
+
+// WithContentTypeAllowlist returns an Option that rejects PutObject, multipart uploads and CopyObject with
+// MetadataDirective=REPLACE through the Bucket with *ContentTypeError unless their Content-Type matches one of types.
+// A type may end with "/*" to match any subtype, e.g. "image/*", except the types a browser renders as active content,
+// HTML, XHTML and SVG, which match only when listed explicitly. An upload without Content-Type is checked as
+// binary/octet-stream.
+//
+// The body of PutObject is also sniffed with http.DetectContentType and checked, so an HTML document declared as
+// image/png is rejected. An SVG document, which http.DetectContentType reports as text, is detected as image/svg+xml.
+// Other sniffed results that carry no information, plain text and octet streams, are not checked.
+func WithContentTypeAllowlist(types ...string) Option {
+	allowlist := append([]string(nil), types...)
+
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			r.Handlers.Validate.PushBack(func(r *request.Request) {
+				if err := checkContentType(r.Params, allowlist); err != nil {
+					r.Error = err
+				}
+			})
+		})
+	}
+}
+
+func checkContentType(params interface{}, allowlist []string) error {
+	var (
+		key, declared *string
+		in            *s3.PutObjectInput
+	)
+
+	switch v := params.(type) {
+	case *s3.PutObjectInput:
+		key, declared, in = v.Key, v.ContentType, v
+	case *s3.CreateMultipartUploadInput:
+		key, declared = v.Key, v.ContentType
+	case *s3.CopyObjectInput:
+		// the Content-Type of the source is kept unless the metadata is replaced
+		if aws.StringValue(v.MetadataDirective) != s3.MetadataDirectiveReplace {
+			return nil
+		}
+		key, declared = v.Key, v.ContentType
+	default:
+		return nil
+	}
+
+	ct := aws.StringValue(declared)
+	if ct == "" {
+		ct = defaultContentType
+	}
+
+	if !matchContentType(ct, allowlist) {
+		return &ContentTypeError{Key: aws.StringValue(key), ContentType: ct}
+	}
+
+	if in == nil || in.Body == nil {
+		return nil
+	}
+
+	head, err := ioutils.Peek(in.Body, 512)
+	if err != nil {
+		return err
+	}
+
+	sniffed := http.DetectContentType(head)
+	if isSVG(head) {
+		sniffed = svgContentType
+	}
+
+	if strings.HasPrefix(sniffed, "text/plain") || strings.HasPrefix(sniffed, "application/octet-stream") {
+		return nil
+	}
+
+	if !matchContentType(sniffed, allowlist) {
+		return &ContentTypeError{Key: aws.StringValue(key), ContentType: sniffed, Sniffed: true}
+	}
+
+	return nil
+}
+
+// matchContentType reports whether the media type of ct matches one of allowlist.
+func matchContentType(ct string, allowlist []string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range allowlist {
+		allowed = strings.ToLower(allowed)
+		if mt == allowed {
+			return true
+		}
+
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(allowed, "*")) && !activeContentTypes[mt] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSVG reports whether head is the beginning of an SVG document, i.e. its root element is svg
+// after an optional XML declaration, comments and a DOCTYPE.
+func isSVG(head []byte) bool {
+	rest := bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+
+	for {
+		rest = bytes.TrimLeft(rest, "\t\n\x0c\r ")
+
+		var end string
+		switch {
+		case len(rest) >= 4 && bytes.EqualFold(rest[:4], []byte("<svg")):
+			return len(rest) == 4 || !isNameByte(rest[4])
+		case bytes.HasPrefix(rest, []byte("<?")):
+			end = "?>"
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			end = "-->"
+		case bytes.HasPrefix(rest, []byte("<!")):
+			end = ">"
+
+			// the internal subset of a DOCTYPE may have declarations ending with '>'
+			if i := bytes.IndexAny(rest, "[>"); i >= 0 && rest[i] == '[' {
+				j := bytes.IndexByte(rest[i:], ']')
+				if j < 0 {
+					return false
+				}
+				rest = rest[i+j:]
+			}
+		default:
+			return false
+		}
+
+		i := bytes.Index(rest, []byte(end))
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(end):]
+	}
+}
+
+// isNameByte reports whether c can follow the first characters of an XML element name.
+// A namespace prefix, e.g. "svg:svg", is treated as svg.
+func isNameByte(c byte) bool {
+	return c == '-' || c == '_' || c == '.' ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
@@ -0,0 +1,164 @@
+package bucket
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckContentType(t *testing.T) {
+	const (
+		png  = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+		html = "<!DOCTYPE html><html><script>alert(1)</script></html>"
+		svg  = `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`
+	)
+
+	images := []string{"image/*"}
+
+	for _, tc := range []struct {
+		name      string
+		allowlist []string
+		params    interface{}
+
+		// want is the rejected content type or empty if the params are allowed.
+		want    string
+		sniffed bool
+	}{
+		{
+			name:      "Allowed",
+			allowlist: images,
+			params:    &s3.PutObjectInput{ContentType: aws.String("image/png"), Body: strings.NewReader(png)},
+		},
+		{
+			name:      "NoContentType",
+			allowlist: images,
+			params:    &s3.PutObjectInput{Body: strings.NewReader(png)},
+			want:      defaultContentType,
+		},
+		{
+			name:      "DeclaredHTML",
+			allowlist: images,
+			params:    &s3.PutObjectInput{ContentType: aws.String("text/html; charset=utf-8"), Body: strings.NewReader(html)},
+			want:      "text/html; charset=utf-8",
+		},
+		{
+			name:      "DeclaredHTMLByWildcard",
+			allowlist: []string{"text/*"},
+			params:    &s3.PutObjectInput{ContentType: aws.String("text/html")},
+			want:      "text/html",
+		},
+		{
+			name:      "DeclaredSVGByWildcard",
+			allowlist: images,
+			params:    &s3.PutObjectInput{ContentType: aws.String("image/svg+xml"), Body: strings.NewReader(svg)},
+			want:      "image/svg+xml",
+		},
+		{
+			name:      "DeclaredSVGExplicitly",
+			allowlist: []string{"image/*", "image/svg+xml"},
+			params:    &s3.PutObjectInput{ContentType: aws.String("image/svg+xml"), Body: strings.NewReader(svg)},
+		},
+		{
+			name:      "SniffedHTML",
+			allowlist: images,
+			params:    &s3.PutObjectInput{ContentType: aws.String("image/png"), Body: strings.NewReader(html)},
+			want:      "text/html; charset=utf-8",
+			sniffed:   true,
+		},
+		{
+			name:      "SniffedSVG",
+			allowlist: images,
+			params:    &s3.PutObjectInput{ContentType: aws.String("image/png"), Body: strings.NewReader(svg)},
+			want:      "image/svg+xml",
+			sniffed:   true,
+		},
+		{
+			name:      "SniffedSVGWithProlog",
+			allowlist: images,
+			params: &s3.PutObjectInput{ContentType: aws.String("image/png"), Body: strings.NewReader(
+				"\xef\xbb\xbf<?xml version=\"1.0\"?>\n<!-- logo -->\n" +
+					`<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd" [<!ENTITY x "y">]>` +
+					"\n" + svg,
+			)},
+			want:    "image/svg+xml",
+			sniffed: true,
+		},
+		{
+			name:      "SniffedSVGAsPlainText",
+			allowlist: []string{"image/png", "text/plain"},
+			params:    &s3.PutObjectInput{ContentType: aws.String("text/plain"), Body: strings.NewReader(svg)},
+			want:      "image/svg+xml",
+			sniffed:   true,
+		},
+		{
+			name:      "PlainTextMentioningSVG",
+			allowlist: []string{"text/plain"},
+			params:    &s3.PutObjectInput{ContentType: aws.String("text/plain"), Body: strings.NewReader("see <svg> in the docs")},
+		},
+		{
+			name:      "Multipart",
+			allowlist: images,
+			params:    &s3.CreateMultipartUploadInput{ContentType: aws.String("text/html")},
+			want:      "text/html",
+		},
+		{
+			name:      "CopyReplacingMetadata",
+			allowlist: images,
+			params: &s3.CopyObjectInput{
+				ContentType:       aws.String("text/html"),
+				MetadataDirective: aws.String(s3.MetadataDirectiveReplace),
+			},
+			want: "text/html",
+		},
+		{
+			name:      "CopyKeepingMetadata",
+			allowlist: images,
+			params:    &s3.CopyObjectInput{ContentType: aws.String("text/html")},
+		},
+		{
+			name:      "OtherOperation",
+			allowlist: images,
+			params:    &s3.GetObjectInput{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkContentType(tc.params, tc.allowlist)
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var cterr *ContentTypeError
+			require.ErrorAs(t, err, &cterr)
+			assert.Equal(t, tc.want, cterr.ContentType)
+			assert.Equal(t, tc.sniffed, cterr.Sniffed)
+		})
+	}
+}
+
+func TestWithContentTypeAllowlist(t *testing.T) {
+	var requests int
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+	})
+	b := New(svc, "bucket", WithContentTypeAllowlist("image/*"))
+
+	_, err := b.PutObject("key", strings.NewReader("<html><body>hi</body></html>"), option.ContentType("image/png"))
+	var cterr *ContentTypeError
+	require.ErrorAs(t, err, &cterr)
+	assert.Equal(t, "key", cterr.Key)
+
+	_, err = b.CopyObject("key", "src", option.CopyMetadataDirective(s3.MetadataDirectiveReplace), option.CopyContentType("text/html"))
+	require.ErrorAs(t, err, &cterr)
+	assert.Zero(t, requests, "nothing is sent")
+
+	_, err = b.CopyObject("key", "src", option.CopyMetadataDirective(s3.MetadataDirectiveReplace), option.CopyContentType("image/png"))
+	require.NoError(t, err)
+}
@@ -0,0 +1,40 @@
+package bucket
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithContextCanceled(t *testing.T) {
+	hits := 0
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	})
+	b := New(svc, "bucket")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := map[string]func() error{
+		"GetObjectReader": func() error { _, err := b.GetObjectReaderWithContext(ctx, "key"); return err },
+		"ExistsObject":    func() error { _, err := b.ExistsObjectWithContext(ctx, "key"); return err },
+		"PutObject":       func() error { _, err := b.PutObjectWithContext(ctx, "key", strings.NewReader("body")); return err },
+		"DeleteObject":    func() error { _, err := b.DeleteObjectWithContext(ctx, "key"); return err },
+		"ListObjects":     func() error { _, err := b.ListObjectsWithContext(ctx, ""); return err },
+		"CopyObject":      func() error { _, err := b.CopyObjectWithContext(ctx, "dst", "src"); return err },
+	}
+
+	for name, call := range calls {
+		err := call()
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), request.CanceledErrorCode, name)
+		}
+	}
+
+	assert.Zero(t, hits)
+}
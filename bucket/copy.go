@@ -0,0 +1,250 @@
+package bucket
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+const (
+	// defaultCopyPartSize is the size of a part copied by CopyObjectMultipart unless MultipartCopyOptions.PartSize is set.
+	defaultCopyPartSize = 512 << 20
+
+	// defaultCopyConcurrency is the number of parts copied at the same time unless MultipartCopyOptions.Concurrency is set.
+	defaultCopyConcurrency = 5
+)
+
+// MultipartCopyOptions controls how CopyObjectMultipart copies an object.
+type MultipartCopyOptions struct {
+	// PartSize is the size of a part. It defaults to 512 MiB and is raised if the object needs more than 10000 parts.
+	PartSize int64
+
+	// Concurrency is the number of parts copied at the same time. It defaults to 5.
+	Concurrency int
+}
+
+// CopyObjectMultipart copies src to dest as CopyObject does but copies an object larger than 5 GiB,
+// which CopyObject cannot copy, in parts with UploadPartCopy. Which one is used is decided by the size of src
+// reported by HeadObject.
+//
+// Unless opts replace them, the metadata and the tags of src are read and set on dest since a multipart upload
+// does not copy them. Each part is copied only if the ETag of src is still the one reported by HeadObject.
+// The upload is aborted if it fails.
+func (b *Bucket) CopyObjectMultipart(ctx aws.Context, dest, src string, copyOpts MultipartCopyOptions, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	return b.CopyObjectMultipartFrom(ctx, dest, aws.StringValue(b.Name), b.objectKey(src), copyOpts, opts...)
+}
+
+// CopyObjectMultipartFrom is the same as CopyObjectMultipart but copies the object srcKey in the bucket srcBucket
+// as CopyObjectFrom does. srcKey is the key stored in S3, e.g. the one returned by ObjectKey of the source Bucket.
+// The S3 client of the Bucket must be able to read the source.
+func (b *Bucket) CopyObjectMultipartFrom(ctx aws.Context, dest, srcBucket, srcKey string, copyOpts MultipartCopyOptions, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	req := &s3.CopyObjectInput{
+		Bucket:     b.Name,
+		Key:        b.key(dest),
+		CopySource: aws.String(copySource(srcBucket, srcKey)),
+	}
+
+	for _, f := range b.copyOptions(opts) {
+		f(req)
+	}
+
+	head := &s3.HeadObjectInput{
+		Bucket:               aws.String(srcBucket),
+		Key:                  aws.String(srcKey),
+		IfMatch:              req.CopySourceIfMatch,
+		SSECustomerAlgorithm: req.CopySourceSSECustomerAlgorithm,
+		SSECustomerKey:       req.CopySourceSSECustomerKey,
+		SSECustomerKeyMD5:    req.CopySourceSSECustomerKeyMD5,
+		RequestPayer:         req.RequestPayer,
+		ExpectedBucketOwner:  req.ExpectedSourceBucketOwner,
+	}
+	if versionID := sourceVersionID(aws.StringValue(req.CopySource)); versionID != "" {
+		head.VersionId = aws.String(versionID)
+	}
+
+	obj, err := b.S3.HeadObjectWithContext(ctx, head, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	size := aws.Int64Value(obj.ContentLength)
+	if size <= maxCopyObjectSize {
+		return b.S3.CopyObjectWithContext(withUploadSize(ctx, size), req, b.reqOpts...)
+	}
+
+	if aws.StringValue(req.MetadataDirective) != s3.MetadataDirectiveReplace {
+		req.Metadata = obj.Metadata
+		req.ContentType = obj.ContentType
+		req.ContentEncoding = obj.ContentEncoding
+		req.ContentDisposition = obj.ContentDisposition
+		req.ContentLanguage = obj.ContentLanguage
+		req.CacheControl = obj.CacheControl
+		req.WebsiteRedirectLocation = obj.WebsiteRedirectLocation
+		if expires, err := http.ParseTime(aws.StringValue(obj.Expires)); err == nil {
+			req.Expires = aws.Time(expires)
+		}
+	}
+
+	if aws.StringValue(req.TaggingDirective) != s3.TaggingDirectiveReplace {
+		tags, err := b.S3.GetObjectTaggingWithContext(ctx, &s3.GetObjectTaggingInput{
+			Bucket:              head.Bucket,
+			Key:                 head.Key,
+			VersionId:           head.VersionId,
+			RequestPayer:        head.RequestPayer,
+			ExpectedBucketOwner: head.ExpectedBucketOwner,
+		}, b.reqOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Tagging = nil
+		if len(tags.TagSet) > 0 {
+			v := url.Values{}
+			for k, val := range TagMap(tags.TagSet) {
+				v.Set(k, val)
+			}
+			req.Tagging = aws.String(v.Encode())
+		}
+	}
+
+	out, err := b.multipartUpload(ctx, dest, []option.PutObjectInput{func(put *s3.PutObjectInput) {
+		awsutil.Copy(put, req)
+	}}, func(upload *s3.CreateMultipartUploadOutput, put *s3.PutObjectInput) ([]*s3.CompletedPart, int64, error) {
+		parts, err := b.copyParts(ctx, upload, req, obj.ETag, size, copyOpts)
+		return parts, size, err
+	})
+	if out == nil {
+		return nil, err
+	}
+
+	// err is *CallbackError if it is not nil
+	resp := &s3.CopyObjectOutput{}
+	awsutil.Copy(resp, out)
+	resp.CopyObjectResult = &s3.CopyObjectResult{ETag: out.ETag}
+
+	return resp, err
+}
+
+// copyParts copies size bytes of the source of req in parts concurrently and returns the completed parts in order.
+func (b *Bucket) copyParts(ctx aws.Context, upload *s3.CreateMultipartUploadOutput, req *s3.CopyObjectInput, etag *string, size int64, opts MultipartCopyOptions) ([]*s3.CompletedPart, error) {
+	partSize := opts.PartSize
+	if partSize <= 0 {
+		partSize = defaultCopyPartSize
+	}
+	if size/maxParts >= partSize {
+		partSize = size/maxParts + 1
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultCopyConcurrency
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		parts []*s3.CompletedPart
+		perr  error
+		sem   = make(chan struct{}, concurrency)
+	)
+
+	for num, off := int64(1), int64(0); off < size; num, off = num+1, off+partSize {
+		end := off + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+
+		mu.Lock()
+		failed := perr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(num int64, rng string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp, err := b.S3.UploadPartCopyWithContext(ctx, &s3.UploadPartCopyInput{
+				Bucket:                         upload.Bucket,
+				Key:                            upload.Key,
+				UploadId:                       upload.UploadId,
+				PartNumber:                     aws.Int64(num),
+				CopySource:                     req.CopySource,
+				CopySourceRange:                aws.String(rng),
+				CopySourceIfMatch:              etag,
+				CopySourceSSECustomerAlgorithm: req.CopySourceSSECustomerAlgorithm,
+				CopySourceSSECustomerKey:       req.CopySourceSSECustomerKey,
+				CopySourceSSECustomerKeyMD5:    req.CopySourceSSECustomerKeyMD5,
+				SSECustomerAlgorithm:           req.SSECustomerAlgorithm,
+				SSECustomerKey:                 req.SSECustomerKey,
+				SSECustomerKeyMD5:              req.SSECustomerKeyMD5,
+				RequestPayer:                   req.RequestPayer,
+				ExpectedBucketOwner:            req.ExpectedBucketOwner,
+				ExpectedSourceBucketOwner:      req.ExpectedSourceBucketOwner,
+			}, b.reqOpts...)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if perr == nil {
+					perr = err
+				}
+				return
+			}
+
+			parts = append(parts, &s3.CompletedPart{ETag: resp.CopyPartResult.ETag, PartNumber: aws.Int64(num)})
+		}(num, fmt.Sprintf("bytes=%d-%d", off, end))
+	}
+
+	wg.Wait()
+
+	if perr != nil {
+		return nil, perr
+	}
+
+	sortParts(parts)
+
+	return parts, nil
+}
+
+// sourceVersionID returns the version in the CopySource value source or empty if it has none.
+func sourceVersionID(source string) string {
+	_, query, ok := strings.Cut(source, "?")
+	if !ok {
+		return ""
+	}
+
+	v, err := url.ParseQuery(query)
+	if err != nil {
+		return ""
+	}
+
+	return v.Get("versionId")
+}
+
+// parseCopySource returns the bucket and the key of the CopySource source, e.g. "bucket/dir/key?versionId=v1".
+func parseCopySource(source string) (bucket, key string, err error) {
+	path, _, _ := strings.Cut(strings.TrimPrefix(source, "/"), "?")
+	bucket, escaped, _ := strings.Cut(path, "/")
+
+	key, err = url.PathUnescape(escaped)
+	if err != nil {
+		return "", "", err
+	}
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("bucket: invalid copy source %q", source)
+	}
+
+	return bucket, key, nil
+}
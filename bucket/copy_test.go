@@ -0,0 +1,122 @@
+package bucket
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopySource(t *testing.T) {
+	for _, tc := range []struct {
+		key    string
+		expect string
+	}{
+		{"plain", "bucket/plain"},
+		{"dir/sub/file.txt", "bucket/dir/sub/file.txt"},
+		{"with space", "bucket/with%20space"},
+		{"a+b", "bucket/a%2Bb"},
+		{"100%", "bucket/100%25"},
+		{"日本語/ファイル", "bucket/%E6%97%A5%E6%9C%AC%E8%AA%9E/%E3%83%95%E3%82%A1%E3%82%A4%E3%83%AB"},
+		{"query?x=1&y", "bucket/query%3Fx=1&y"},
+		{"/leading//double", "bucket//leading//double"},
+	} {
+		assert.Equal(t, tc.expect, copySource("bucket", tc.key), tc.key)
+	}
+}
+
+func TestCopySourceVersionID(t *testing.T) {
+	req := &s3.CopyObjectInput{CopySource: aws.String(copySource("bucket", "a b"))}
+	option.CopySourceVersionID("v+1")(req)
+
+	assert.Equal(t, "bucket/a%20b?versionId=v%2B1", aws.StringValue(req.CopySource))
+}
+
+func TestCopyObjectFrom(t *testing.T) {
+	var source string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		source = r.Header.Get("X-Amz-Copy-Source")
+		w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+	})
+	b := New(svc, "dst").WithPrefix("backup/")
+
+	_, err := b.CopyObjectFrom("a b", "src", "data/a b")
+	require.NoError(t, err)
+	assert.Equal(t, "src/data/a%20b", source)
+
+	_, err = b.CopyObjectVersionFrom("a b", "src", "data/a b", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "src/data/a%20b?versionId=v1", source)
+
+	_, err = b.CopyObject("c", "a b")
+	require.NoError(t, err)
+	assert.Equal(t, "dst/backup/a%20b", source)
+}
+
+func TestCopyObjectMultipart(t *testing.T) {
+	const size = 5<<30 + 1
+
+	var (
+		mu      sync.Mutex
+		ranges  []string
+		created http.Header
+	)
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		_, tagging := r.URL.Query()["tagging"]
+		_, uploads := r.URL.Query()["uploads"]
+
+		switch {
+		case r.Method == http.MethodHead:
+			w.Header().Set("Content-Length", strconv.Itoa(size))
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("ETag", `"src"`)
+			w.Header().Set("x-amz-meta-owner", "alice")
+		case tagging:
+			w.Write([]byte(`<Tagging><TagSet><Tag><Key>env</Key><Value>prod</Value></Tag></TagSet></Tagging>`))
+		case uploads:
+			created = r.Header.Clone()
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>dst</Key><UploadId>u1</UploadId></InitiateMultipartUploadResult>`))
+		case r.Method == http.MethodPut:
+			assert.Equal(t, "bucket/src", r.Header.Get("X-Amz-Copy-Source"))
+			assert.Equal(t, `"src"`, r.Header.Get("X-Amz-Copy-Source-If-Match"))
+
+			mu.Lock()
+			ranges = append(ranges, r.URL.Query().Get("partNumber")+":"+r.Header.Get("X-Amz-Copy-Source-Range"))
+			mu.Unlock()
+
+			w.Write([]byte(`<CopyPartResult><ETag>"part"</ETag></CopyPartResult>`))
+		default:
+			var complete struct {
+				Parts []struct{ PartNumber int } `xml:"Part"`
+			}
+			require.NoError(t, xml.NewDecoder(r.Body).Decode(&complete))
+			assert.Len(t, complete.Parts, 3)
+
+			w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"dst"</ETag></CompleteMultipartUploadResult>`))
+		}
+	})
+	b := New(svc, "bucket")
+
+	resp, err := b.CopyObjectMultipart(aws.BackgroundContext(), "dst", "src", MultipartCopyOptions{PartSize: 2 << 30})
+	require.NoError(t, err)
+	assert.Equal(t, `"dst"`, aws.StringValue(resp.CopyObjectResult.ETag))
+
+	assert.Equal(t, "text/plain", created.Get("Content-Type"))
+	assert.Equal(t, "alice", created.Get("X-Amz-Meta-Owner"))
+	assert.Equal(t, "env=prod", created.Get("X-Amz-Tagging"))
+
+	sort.Strings(ranges)
+	assert.Equal(t, []string{
+		"1:bytes=0-2147483647",
+		"2:bytes=2147483648-4294967295",
+		"3:bytes=4294967296-5368709120",
+	}, ranges)
+}
@@ -0,0 +1,56 @@
+package bucket
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// secretPattern matches the values in the signing information that must not be written out.
+var secretPattern = regexp.MustCompile(`(?i)((?:x-amz-security-token|x-amz-server-side-encryption-customer-key|x-amz-copy-source-server-side-encryption-customer-key)[:=][ \t]*)[^&\s]*`)
+
+// WithSignatureDebug returns an Option that writes the canonical request, the string to sign and the signed headers
+// of failed requests to w. Security tokens and SSE-C keys are redacted. w may be shared across goroutines.
+func WithSignatureDebug(w io.Writer) Option {
+	var mu sync.Mutex
+
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			logger := &bufferLogger{}
+			r.Config.Logger = logger
+			r.Config.LogLevel = aws.LogLevel(aws.LogDebugWithSigning)
+
+			r.Handlers.Complete.PushBack(func(r *request.Request) {
+				if r.Error == nil {
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				fmt.Fprintf(w, "%s %s failed: %s\n", r.Operation.Name, r.HTTPRequest.URL.Path, r.Error)
+				for _, msg := range logger.msgs {
+					fmt.Fprintln(w, secretPattern.ReplaceAllString(msg, "${1}REDACTED"))
+				}
+			})
+		})
+	}
+}
+
+// bufferLogger is aws.Logger that keeps messages for a single request.
+type bufferLogger struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (l *bufferLogger) Log(args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.msgs = append(l.msgs, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
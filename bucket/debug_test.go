@@ -0,0 +1,23 @@
+package bucket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretPattern(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		expect string
+	}{
+		{"x-amz-security-token:tok", "x-amz-security-token:REDACTED"},
+		{"X-Amz-Security-Token: tok", "X-Amz-Security-Token: REDACTED"},
+		{"a=b&X-Amz-Security-Token=tok&c=d", "a=b&X-Amz-Security-Token=REDACTED&c=d"},
+		{"x-amz-server-side-encryption-customer-key:c2VjcmV0", "x-amz-server-side-encryption-customer-key:REDACTED"},
+		{"x-amz-server-side-encryption-customer-key-md5:bWQ1", "x-amz-server-side-encryption-customer-key-md5:bWQ1"},
+		{"x-amz-date:20200101T000000Z", "x-amz-date:20200101T000000Z"},
+	} {
+		assert.Equal(t, tc.expect, secretPattern.ReplaceAllString(tc.in, "${1}REDACTED"), tc.in)
+	}
+}
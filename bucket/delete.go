@@ -0,0 +1,78 @@
+package bucket
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// maxDeleteObjects is the maximum number of objects DeleteObjects accepts at a time.
+const maxDeleteObjects = 1000
+
+// A DeleteResult is the combined result of the DeleteObjects requests made by DeleteObjectsAll.
+type DeleteResult struct {
+	Deleted []*s3.DeletedObject
+	Errors  []*s3.Error
+}
+
+// Err returns *DeleteObjectsError if any object failed to be deleted, and nil otherwise.
+func (r *DeleteResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+
+	return &DeleteObjectsError{Errors: r.Errors}
+}
+
+// A DeleteObjectsError reports the objects that DeleteObjects failed to delete.
+type DeleteObjectsError struct {
+	Errors []*s3.Error
+}
+
+func (e *DeleteObjectsError) Error() string {
+	first := e.Errors[0]
+	return fmt.Sprintf("bucket: failed to delete %d objects: %s: %s", len(e.Errors), aws.StringValue(first.Key), aws.StringValue(first.Message))
+}
+
+// DeleteObjectsAll deletes each object for the given identifiers with DeleteObjects in batches of 1000.
+// The per-key errors of every batch are collected in the result. An error is returned only if a request fails,
+// in which case the result holds the batches done so far.
+func (b *Bucket) DeleteObjectsAll(identifiers []*s3.ObjectIdentifier) (*DeleteResult, error) {
+	return b.DeleteObjectsAllWithContext(aws.BackgroundContext(), identifiers)
+}
+
+// DeleteObjectsAllWithContext is the same as DeleteObjectsAll with the context ctx.
+func (b *Bucket) DeleteObjectsAllWithContext(ctx aws.Context, identifiers []*s3.ObjectIdentifier) (*DeleteResult, error) {
+	result := &DeleteResult{}
+	for len(identifiers) > 0 {
+		n := len(identifiers)
+		if n > maxDeleteObjects {
+			n = maxDeleteObjects
+		}
+
+		resp, err := b.DeleteObjectsWithContext(ctx, identifiers[:n])
+		if resp != nil {
+			result.Deleted = append(result.Deleted, resp.Deleted...)
+			result.Errors = append(result.Errors, resp.Errors...)
+		}
+		if err != nil {
+			return result, err
+		}
+
+		identifiers = identifiers[n:]
+	}
+
+	return result, nil
+}
+
+// deleteAll deletes the objects with DeleteObjects in batches and returns the number of deleted objects.
+// It fails with *DeleteObjectsError if any object is not deleted.
+func (b *Bucket) deleteAll(ctx aws.Context, identifiers []*s3.ObjectIdentifier) (int, error) {
+	result, err := b.DeleteObjectsAllWithContext(ctx, identifiers)
+	if err != nil {
+		return len(result.Deleted), err
+	}
+
+	return len(result.Deleted), result.Err()
+}
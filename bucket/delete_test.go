@@ -0,0 +1,57 @@
+package bucket
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deleteStub fails to delete the keys in failing.
+type deleteStub struct {
+	s3iface.S3API
+
+	requests int
+	failing  map[string]bool
+}
+
+func (s *deleteStub) DeleteObjectsWithContext(_ aws.Context, in *s3.DeleteObjectsInput, _ ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	s.requests++
+
+	out := &s3.DeleteObjectsOutput{}
+	for _, id := range in.Delete.Objects {
+		if s.failing[aws.StringValue(id.Key)] {
+			out.Errors = append(out.Errors, &s3.Error{Key: id.Key, Code: aws.String("AccessDenied"), Message: aws.String("Access Denied")})
+			continue
+		}
+
+		out.Deleted = append(out.Deleted, &s3.DeletedObject{Key: id.Key})
+	}
+
+	return out, nil
+}
+
+func TestDeleteObjectsAll(t *testing.T) {
+	ids := make([]*s3.ObjectIdentifier, 2001)
+	for i := range ids {
+		ids[i] = &s3.ObjectIdentifier{Key: aws.String(fmt.Sprintf("%04d", i))}
+	}
+
+	stub := &deleteStub{failing: map[string]bool{"0001": true, "1500": true}}
+
+	result, err := New(stub, "bucket").DeleteObjectsAll(ids)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stub.requests)
+	assert.Len(t, result.Deleted, 1999)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, "1500", aws.StringValue(result.Errors[1].Key))
+
+	var derr *DeleteObjectsError
+	require.ErrorAs(t, result.Err(), &derr)
+	assert.Equal(t, "bucket: failed to delete 2 objects: 0001: Access Denied", derr.Error())
+}
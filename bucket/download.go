@@ -0,0 +1,134 @@
+package bucket
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+const (
+	// downloadPartSize is the size of a byte range Download gets at a time.
+	downloadPartSize = 8 << 20
+
+	// downloadConcurrency is the number of byte ranges Download gets at the same time.
+	downloadConcurrency = 5
+)
+
+// Download writes the object for key to w and returns the number of bytes written.
+// The object is split into byte ranges that are downloaded concurrently like s3manager.Downloader does,
+// but through the Bucket so that its options apply.
+//
+// The ranges after the first one are requested with If-Match on the ETag of the first one, so an object
+// overwritten during the download fails it instead of being mixed up.
+func (b *Bucket) Download(key string, w io.WriterAt, opts ...option.GetObjectInput) (int64, error) {
+	return b.DownloadWithContext(aws.BackgroundContext(), key, w, opts...)
+}
+
+// DownloadWithContext is the same as Download with the context ctx.
+func (b *Bucket) DownloadWithContext(ctx aws.Context, key string, w io.WriterAt, opts ...option.GetObjectInput) (int64, error) {
+	first, err := b.GetObjectWithContext(ctx, key, append(opts, option.Range(0, downloadPartSize-1))...)
+	if statusCode(err) == http.StatusRequestedRangeNotSatisfiable {
+		// the object is empty
+		resp, err := b.GetObjectWithContext(ctx, key, opts...)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := objectSize(first)
+	if err != nil {
+		first.Body.Close()
+		return 0, err
+	}
+
+	n, err := writeRange(w, 0, first.Body)
+	if err != nil {
+		return n, err
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		written = n
+		derr    error
+		sem     = make(chan struct{}, downloadConcurrency)
+	)
+
+	etag := aws.StringValue(first.ETag)
+
+	for off := int64(downloadPartSize); off < size; off += downloadPartSize {
+		mu.Lock()
+		failed := derr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(off int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			n, err := b.downloadRange(ctx, key, w, off, etag, opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			written += n
+			if err != nil && derr == nil {
+				derr = err
+			}
+		}(off)
+	}
+
+	wg.Wait()
+
+	return written, derr
+}
+
+// downloadRange writes the byte range of key from off to w.
+func (b *Bucket) downloadRange(ctx aws.Context, key string, w io.WriterAt, off int64, etag string, opts []option.GetObjectInput) (int64, error) {
+	rangeOpts := append(opts[:len(opts):len(opts)], option.Range(off, off+downloadPartSize-1), func(req *s3.GetObjectInput) {
+		if etag != "" {
+			req.IfMatch = aws.String(etag)
+		}
+	})
+
+	resp, err := b.GetObjectWithContext(ctx, key, rangeOpts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return writeRange(w, off, resp.Body)
+}
+
+// writeRange copies body to w at off and closes body.
+func writeRange(w io.WriterAt, off int64, body io.ReadCloser) (int64, error) {
+	defer body.Close()
+
+	return io.Copy(io.NewOffsetWriter(w, off), body)
+}
+
+// objectSize returns the size of the whole object from Content-Range of a ranged GetObject.
+// The whole object is returned without Content-Range if the range is ignored.
+func objectSize(resp *s3.GetObjectOutput) (int64, error) {
+	cr := aws.StringValue(resp.ContentRange)
+	if cr == "" {
+		return aws.Int64Value(resp.ContentLength), nil
+	}
+
+	_, _, size, err := parseContentRange(cr)
+
+	return size, err
+}
@@ -0,0 +1,47 @@
+package bucket
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownload(t *testing.T) {
+	data := make([]byte, 2*downloadPartSize+123)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	var hits int32
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("ETag", `"etag"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	})
+	b := New(svc, "bucket")
+
+	buf := aws.NewWriteAtBuffer(nil)
+	n, err := b.Download("key", buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	assert.True(t, bytes.Equal(data, buf.Bytes()))
+	assert.Equal(t, int32(3), hits)
+
+	t.Run("EmptyObject", func(t *testing.T) {
+		svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				w.Write([]byte("<Error><Code>InvalidRange</Code></Error>"))
+			}
+		})
+
+		n, err := New(svc, "bucket").Download("key", aws.NewWriteAtBuffer(nil))
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
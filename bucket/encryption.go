@@ -0,0 +1,84 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// SSES3Rule returns a default encryption rule that encrypts new objects with SSE-S3 (AES256).
+func SSES3Rule() *s3.ServerSideEncryptionRule {
+	return &s3.ServerSideEncryptionRule{
+		ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
+			SSEAlgorithm: aws.String(s3.ServerSideEncryptionAes256),
+		},
+	}
+}
+
+// SSEKMSRule returns a default encryption rule that encrypts new objects with SSE-KMS using the KMS key keyID,
+// or the AWS managed key if keyID is empty. bucketKey enables S3 Bucket Keys to reduce the requests to KMS.
+func SSEKMSRule(keyID string, bucketKey bool) *s3.ServerSideEncryptionRule {
+	rule := &s3.ServerSideEncryptionRule{
+		ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
+			SSEAlgorithm: aws.String(s3.ServerSideEncryptionAwsKms),
+		},
+		BucketKeyEnabled: aws.Bool(bucketKey),
+	}
+	if keyID != "" {
+		rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID = aws.String(keyID)
+	}
+
+	return rule
+}
+
+// GetBucketEncryption returns the default encryption rules of the bucket. It returns nil if the bucket has none.
+func (b *Bucket) GetBucketEncryption() ([]*s3.ServerSideEncryptionRule, error) {
+	return b.GetBucketEncryptionWithContext(aws.BackgroundContext())
+}
+
+// GetBucketEncryptionWithContext is the same as GetBucketEncryption with the context ctx.
+func (b *Bucket) GetBucketEncryptionWithContext(ctx aws.Context) ([]*s3.ServerSideEncryptionRule, error) {
+	resp, err := b.S3.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "ServerSideEncryptionConfigurationNotFoundError" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ServerSideEncryptionConfiguration == nil {
+		return nil, nil
+	}
+
+	return resp.ServerSideEncryptionConfiguration.Rules, nil
+}
+
+// PutBucketEncryption sets the default encryption of the bucket to rule, e.g. SSES3Rule or SSEKMSRule.
+func (b *Bucket) PutBucketEncryption(rule *s3.ServerSideEncryptionRule) (*s3.PutBucketEncryptionOutput, error) {
+	return b.PutBucketEncryptionWithContext(aws.BackgroundContext(), rule)
+}
+
+// PutBucketEncryptionWithContext is the same as PutBucketEncryption with the context ctx.
+func (b *Bucket) PutBucketEncryptionWithContext(ctx aws.Context, rule *s3.ServerSideEncryptionRule) (*s3.PutBucketEncryptionOutput, error) {
+	return b.S3.PutBucketEncryptionWithContext(ctx, &s3.PutBucketEncryptionInput{
+		Bucket: b.Name,
+		ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+			Rules: []*s3.ServerSideEncryptionRule{rule},
+		},
+	}, b.reqOpts...)
+}
+
+// DeleteBucketEncryption deletes the default encryption configuration of the bucket.
+// New objects are still encrypted with SSE-S3 since S3 encrypts every object.
+func (b *Bucket) DeleteBucketEncryption() (*s3.DeleteBucketEncryptionOutput, error) {
+	return b.DeleteBucketEncryptionWithContext(aws.BackgroundContext())
+}
+
+// DeleteBucketEncryptionWithContext is the same as DeleteBucketEncryption with the context ctx.
+func (b *Bucket) DeleteBucketEncryptionWithContext(ctx aws.Context) (*s3.DeleteBucketEncryptionOutput, error) {
+	return b.S3.DeleteBucketEncryptionWithContext(ctx, &s3.DeleteBucketEncryptionInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+}
@@ -0,0 +1,50 @@
+package bucket
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketEncryption(t *testing.T) {
+	var stored []byte
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			stored, _ = io.ReadAll(r.Body)
+		case http.MethodDelete:
+			stored = nil
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			if stored == nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>ServerSideEncryptionConfigurationNotFoundError</Code></Error>`))
+				return
+			}
+			w.Write(stored)
+		}
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.PutBucketEncryption(SSEKMSRule("key", true))
+	require.NoError(t, err)
+
+	rules, err := b.GetBucketEncryption()
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, s3.ServerSideEncryptionAwsKms, aws.StringValue(rules[0].ApplyServerSideEncryptionByDefault.SSEAlgorithm))
+	assert.Equal(t, "key", aws.StringValue(rules[0].ApplyServerSideEncryptionByDefault.KMSMasterKeyID))
+	assert.True(t, aws.BoolValue(rules[0].BucketKeyEnabled))
+
+	_, err = b.DeleteBucketEncryption()
+	require.NoError(t, err)
+
+	rules, err = b.GetBucketEncryption()
+	require.NoError(t, err)
+	assert.Nil(t, rules)
+}
@@ -0,0 +1,105 @@
+package bucket
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const resolveEndpointHandlerName = "bucket.ResolveEndpointHandler"
+
+// WithFIPS returns an Option that sends every request made through the Bucket, including presigned ones,
+// to the FIPS endpoint of the region. It has no effect when the client is configured with a custom endpoint.
+func WithFIPS() Option {
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			r.Config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+			pushResolveEndpointHandler(r)
+		})
+	}
+}
+
+// WithDualStack returns an Option that sends every request made through the Bucket, including presigned ones,
+// to the dual-stack (IPv4 and IPv6) endpoint of the region. It has no effect when the client is configured with a custom endpoint.
+func WithDualStack() Option {
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			r.Config.UseDualStackEndpoint = endpoints.DualStackEndpointStateEnabled
+			pushResolveEndpointHandler(r)
+		})
+	}
+}
+
+// insecureHTTPClient is the HTTP client of the requests made through a Bucket with WithInsecureSkipVerify.
+var insecureHTTPClient = func() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	return &http.Client{Transport: t}
+}()
+
+// WithInsecureSkipVerify returns an Option that sends every request made through the Bucket without verifying the
+// TLS certificate of the endpoint, e.g. a MinIO server with a self-signed certificate in a test environment.
+// It replaces the HTTP client of the S3 client. It must not be used against AWS.
+func WithInsecureSkipVerify() Option {
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			r.Config.HTTPClient = insecureHTTPClient
+		})
+	}
+}
+
+// pushResolveEndpointHandler installs resolveEndpoint in front of the build handlers exactly once,
+// so it runs before the S3 customizations move the bucket name into the host.
+func pushResolveEndpointHandler(r *request.Request) {
+	r.Handlers.Build.RemoveByName(resolveEndpointHandlerName)
+	r.Handlers.Build.PushFrontNamed(request.NamedHandler{
+		Name: resolveEndpointHandlerName,
+		Fn:   resolveEndpoint,
+	})
+}
+
+// resolveEndpoint resolves the S3 endpoint again with the FIPS and dual-stack settings of the request
+// since the client resolves its endpoint only once when it is created.
+func resolveEndpoint(r *request.Request) {
+	if aws.StringValue(r.Config.Endpoint) != "" {
+		return
+	}
+
+	resolver := r.Config.EndpointResolver
+	if resolver == nil {
+		resolver = endpoints.DefaultResolver()
+	}
+
+	ep, err := resolver.EndpointFor(s3.EndpointsID, aws.StringValue(r.Config.Region), func(o *endpoints.Options) {
+		o.DisableSSL = aws.BoolValue(r.Config.DisableSSL)
+		o.UseFIPSEndpoint = r.Config.UseFIPSEndpoint
+		o.UseDualStackEndpoint = r.Config.UseDualStackEndpoint
+		o.S3UsEast1RegionalEndpoint = r.Config.S3UsEast1RegionalEndpoint
+	})
+	if err != nil {
+		r.Error = awserr.New(request.ErrCodeRequestError, "failed to resolve endpoint", err)
+		return
+	}
+
+	u, err := url.Parse(ep.URL)
+	if err != nil {
+		r.Error = awserr.New(request.ErrCodeRequestError, "failed to parse resolved endpoint", err)
+		return
+	}
+
+	r.ClientInfo.Endpoint = ep.URL
+	if ep.SigningRegion != "" {
+		r.ClientInfo.SigningRegion = ep.SigningRegion
+	}
+
+	r.HTTPRequest.URL.Scheme = u.Scheme
+	r.HTTPRequest.URL.Host = u.Host
+	r.HTTPRequest.Host = ""
+}
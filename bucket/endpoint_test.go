@@ -0,0 +1,73 @@
+package bucket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveEndpoint(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		opts      []Option
+		pathStyle bool
+		endpoint  string
+		want      string
+	}{
+		{"FIPS", []Option{WithFIPS()}, false, "", "https://bucket.s3-fips.us-west-2.amazonaws.com/key"},
+		{"FIPSPathStyle", []Option{WithFIPS()}, true, "", "https://s3-fips.us-west-2.amazonaws.com/bucket/key"},
+		{"DualStack", []Option{WithDualStack()}, false, "", "https://bucket.s3.dualstack.us-west-2.amazonaws.com/key"},
+		{"DualStackPathStyle", []Option{WithDualStack()}, true, "", "https://s3.dualstack.us-west-2.amazonaws.com/bucket/key"},
+		{"FIPSDualStack", []Option{WithFIPS(), WithDualStack()}, false, "", "https://bucket.s3-fips.dualstack.us-west-2.amazonaws.com/key"},
+		{"FIPSDualStackPathStyle", []Option{WithFIPS(), WithDualStack()}, true, "", "https://s3-fips.dualstack.us-west-2.amazonaws.com/bucket/key"},
+		{"None", nil, false, "", "https://bucket.s3.us-west-2.amazonaws.com/key"},
+		{"CustomEndpoint", []Option{WithFIPS(), WithDualStack()}, true, "http://localhost:9000", "http://localhost:9000/bucket/key"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &aws.Config{
+				Region:           aws.String("us-west-2"),
+				S3ForcePathStyle: aws.Bool(tc.pathStyle),
+				Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+			}
+			if tc.endpoint != "" {
+				cfg.Endpoint = aws.String(tc.endpoint)
+			}
+			b := New(s3.New(session.Must(session.NewSession(cfg))), "bucket", tc.opts...)
+
+			req, _ := b.HeadObjectRequest("key")
+			require.NoError(t, req.Build())
+			assert.Equal(t, tc.want, req.HTTPRequest.URL.String())
+
+			req, _ = b.GetObjectRequest("key")
+			presigned, err := req.Presign(time.Minute)
+			require.NoError(t, err)
+			assert.Contains(t, presigned, tc.want+"?")
+		})
+	}
+}
+
+func TestPresignPostPolicyFIPS(t *testing.T) {
+	for _, tc := range []struct {
+		pathStyle bool
+		want      string
+	}{
+		{false, "https://bucket.s3-fips.dualstack.us-west-2.amazonaws.com/"},
+		{true, "https://s3-fips.dualstack.us-west-2.amazonaws.com/bucket"},
+	} {
+		svc := s3.New(session.Must(session.NewSession(&aws.Config{
+			Region:           aws.String("us-west-2"),
+			S3ForcePathStyle: aws.Bool(tc.pathStyle),
+			Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		})))
+
+		p, err := New(svc, "bucket", WithFIPS(), WithDualStack()).PresignPostPolicy("key", PostPolicyOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, p.URL)
+	}
+}
@@ -0,0 +1,215 @@
+package bucket
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// The error helpers classify errors returned by the Bucket as follows.
+// An error is checked against the rows from top to bottom and the first row that matches wins.
+//
+//	Condition                                                    IsNotFound  IsThrottle  IsRetryable
+//	nil, or canceled by the context                              false       false       false
+//	code NoSuchKey, NoSuchBucket, NoSuchVersion, NoSuchUpload,
+//	  or NotFound, or HTTP 404                                   true        false       false
+//	code SlowDown, Throttling, ThrottlingException,
+//	  RequestLimitExceeded, TooManyRequestsException,
+//	  RequestThrottled, or HTTP 429                              false       true        true
+//	code InternalError, RequestTimeout, RequestTimeTooSkewed,
+//	  ExpiredToken, or HTTP 5xx                                  false       false       true
+//	connection errors the SDK considers retryable                false       false       true
+//	anything else                                                false       false       false
+//
+// IsPreconditionFailed is independent of the table. It matches code PreconditionFailed or HTTP 412, and
+// code ConditionalRequestConflict which S3 returns when conditional writes to the same key race.
+
+// The sentinel errors match, with errors.Is, the errors S3 returns to the requests made through a Bucket.
+// The matching error is still awserr.RequestFailure so that the code, the status code and the request ID are available
+// with a type assertion or errors.As.
+var (
+	// ErrNoSuchKey matches code NoSuchKey, and HTTP 404 to HeadObject.
+	ErrNoSuchKey = errors.New("bucket: no such key")
+
+	// ErrNoSuchBucket matches code NoSuchBucket, and HTTP 404 to HeadBucket.
+	ErrNoSuchBucket = errors.New("bucket: no such bucket")
+
+	// ErrAccessDenied matches code AccessDenied or HTTP 403.
+	ErrAccessDenied = errors.New("bucket: access denied")
+
+	// ErrNotModified matches HTTP 304 to a conditional request, e.g. GetObject with option.GetIfNoneMatch.
+	ErrNotModified = errors.New("bucket: object is not modified")
+
+	// ErrPreconditionFailed matches code PreconditionFailed or HTTP 412, e.g. to GetObject with option.GetIfMatch.
+	ErrPreconditionFailed = errors.New("bucket: precondition failed")
+
+	// ErrSlowDown matches code SlowDown or HTTP 503, which S3 returns when it throttles the requests.
+	ErrSlowDown = errors.New("bucket: slow down")
+)
+
+// A sentinelError is an error from S3 that matches a sentinel error.
+type sentinelError struct {
+	awserr.RequestFailure
+	sentinel error
+}
+
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.RequestFailure
+}
+
+// sentinelRequestOption wraps the error of the request so that it matches the sentinel error.
+// It runs on every attempt so that the retry handlers see the same error as the caller.
+func sentinelRequestOption(r *request.Request) {
+	r.Handlers.UnmarshalError.PushBack(func(r *request.Request) {
+		r.Error = withSentinel(r.Operation.Name, r.Error)
+	})
+}
+
+// withSentinel returns err of the operation op as sentinelError if it matches a sentinel error.
+func withSentinel(op string, err error) error {
+	rerr, ok := err.(awserr.RequestFailure)
+	if !ok {
+		return err
+	}
+
+	var sentinel error
+	switch {
+	case rerr.Code() == "NoSuchKey":
+		sentinel = ErrNoSuchKey
+	case rerr.Code() == "NoSuchBucket":
+		sentinel = ErrNoSuchBucket
+	case rerr.StatusCode() == http.StatusNotFound && op == "HeadObject":
+		sentinel = ErrNoSuchKey
+	case rerr.StatusCode() == http.StatusNotFound && op == "HeadBucket":
+		sentinel = ErrNoSuchBucket
+	case rerr.Code() == "AccessDenied", rerr.StatusCode() == http.StatusForbidden:
+		sentinel = ErrAccessDenied
+	case rerr.StatusCode() == http.StatusNotModified:
+		sentinel = ErrNotModified
+	case rerr.Code() == "PreconditionFailed", rerr.StatusCode() == http.StatusPreconditionFailed:
+		sentinel = ErrPreconditionFailed
+	case rerr.Code() == "SlowDown", rerr.StatusCode() == http.StatusServiceUnavailable:
+		sentinel = ErrSlowDown
+	default:
+		return err
+	}
+
+	return &sentinelError{RequestFailure: rerr, sentinel: sentinel}
+}
+
+var notFoundCodes = map[string]struct{}{
+	"NoSuchKey":     {},
+	"NoSuchBucket":  {},
+	"NoSuchVersion": {},
+	"NoSuchUpload":  {},
+	"NotFound":      {},
+}
+
+var throttleCodes = map[string]struct{}{
+	"SlowDown":                 {},
+	"Throttling":               {},
+	"ThrottlingException":      {},
+	"RequestLimitExceeded":     {},
+	"TooManyRequestsException": {},
+	"RequestThrottled":         {},
+}
+
+var retryableCodes = map[string]struct{}{
+	"InternalError":        {},
+	"RequestTimeout":       {},
+	"RequestTimeTooSkewed": {},
+	"ExpiredToken":         {},
+}
+
+// IsNotFound reports whether err means the bucket, the object, the version or the upload does not exist.
+func IsNotFound(err error) bool {
+	if err == nil || isCanceled(err) {
+		return false
+	}
+
+	if hasCode(err, notFoundCodes) {
+		return true
+	}
+
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsThrottle reports whether err means S3 throttled the request.
+func IsThrottle(err error) bool {
+	if err == nil || isCanceled(err) || IsNotFound(err) {
+		return false
+	}
+
+	if hasCode(err, throttleCodes) {
+		return true
+	}
+
+	return statusCode(err) == http.StatusTooManyRequests
+}
+
+// IsRetryable reports whether the request that failed with err may succeed when it is retried.
+func IsRetryable(err error) bool {
+	if err == nil || isCanceled(err) || IsNotFound(err) {
+		return false
+	}
+
+	if IsThrottle(err) || hasCode(err, retryableCodes) || statusCode(err) >= http.StatusInternalServerError {
+		return true
+	}
+
+	// The SDK treats unknown errors as retryable so only the errors from the SDK are passed.
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+
+	return request.IsErrorRetryable(aerr)
+}
+
+// IsPreconditionFailed reports whether err means the condition of a conditional request does not hold.
+func IsPreconditionFailed(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+
+	switch aerr.Code() {
+	case "PreconditionFailed", "ConditionalRequestConflict":
+		return true
+	}
+
+	return statusCode(err) == http.StatusPreconditionFailed
+}
+
+func isCanceled(err error) bool {
+	var aerr awserr.Error
+
+	return errors.As(err, &aerr) && aerr.Code() == request.CanceledErrorCode
+}
+
+// hasCode reports whether err or an error it wraps, e.g. with fmt.Errorf("%w"), has one of codes.
+func hasCode(err error, codes map[string]struct{}) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+
+	_, found := codes[aerr.Code()]
+
+	return found
+}
+
+func statusCode(err error) int {
+	var rerr awserr.RequestFailure
+	if errors.As(err, &rerr) {
+		return rerr.StatusCode()
+	}
+
+	return 0
+}
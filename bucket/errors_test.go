@@ -0,0 +1,91 @@
+package bucket
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorClassification(t *testing.T) {
+	failure := func(code string, status int) error {
+		return awserr.NewRequestFailure(awserr.New(code, "", nil), status, "")
+	}
+
+	for _, tc := range []struct {
+		name      string
+		err       error
+		notFound  bool
+		throttle  bool
+		retryable bool
+	}{
+		{"nil", nil, false, false, false},
+		{"canceled", awserr.New(request.CanceledErrorCode, "", nil), false, false, false},
+		{"NoSuchKey", failure("NoSuchKey", http.StatusNotFound), true, false, false},
+		{"HEAD 404", failure("NotFound", http.StatusNotFound), true, false, false},
+		{"SlowDown", failure("SlowDown", http.StatusServiceUnavailable), false, true, true},
+		{"429", failure("Unknown", http.StatusTooManyRequests), false, true, true},
+		{"InternalError", failure("InternalError", http.StatusInternalServerError), false, false, true},
+		{"503", failure("ServiceUnavailable", http.StatusServiceUnavailable), false, false, true},
+		{"AccessDenied", failure("AccessDenied", http.StatusForbidden), false, false, false},
+		{"plain error", errors.New("boom"), false, false, false},
+		{"wrapped NoSuchKey", fmt.Errorf("get: %w", failure("NoSuchKey", http.StatusNotFound)), true, false, false},
+		{"wrapped SlowDown", fmt.Errorf("put: %w", failure("SlowDown", http.StatusServiceUnavailable)), false, true, true},
+		{"wrapped 503", fmt.Errorf("put: %w", failure("ServiceUnavailable", http.StatusServiceUnavailable)), false, false, true},
+		{"wrapped canceled", fmt.Errorf("get: %w", awserr.New(request.CanceledErrorCode, "", nil)), false, false, false},
+		{"wrapped connection reset", fmt.Errorf("get: %w", awserr.New(request.ErrCodeSerialization, "", errors.New("connection reset"))), false, false, true},
+	} {
+		assert.Equal(t, tc.notFound, IsNotFound(tc.err), "IsNotFound: "+tc.name)
+		assert.Equal(t, tc.throttle, IsThrottle(tc.err), "IsThrottle: "+tc.name)
+		assert.Equal(t, tc.retryable, IsRetryable(tc.err), "IsRetryable: "+tc.name)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bucket/missing":
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`))
+			}
+		case "/bucket/denied":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>denied</Message></Error>`))
+		case "/bucket/busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<Error><Code>SlowDown</Code><Message>slow down</Message></Error>`))
+		case "/other":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.GetObject("missing")
+	assert.ErrorIs(t, err, ErrNoSuchKey)
+	assert.True(t, IsNotFound(err))
+
+	var rerr awserr.RequestFailure
+	require.ErrorAs(t, err, &rerr)
+	assert.Equal(t, "NoSuchKey", rerr.Code())
+
+	_, err = b.HeadObject("missing")
+	assert.ErrorIs(t, err, ErrNoSuchKey)
+	assert.NotErrorIs(t, err, ErrNoSuchBucket)
+
+	_, err = New(svc, "other").HeadBucket()
+	assert.ErrorIs(t, err, ErrNoSuchBucket)
+
+	_, err = b.PutObject("denied", strings.NewReader("hello"))
+	assert.ErrorIs(t, err, ErrAccessDenied)
+
+	_, err = b.DeleteObject("busy")
+	assert.ErrorIs(t, err, ErrSlowDown)
+	assert.True(t, IsThrottle(err))
+}
@@ -0,0 +1,47 @@
+package bucket
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// ErrInvalidExpiration is returned by ParseExpiration when the value is not in the format of x-amz-expiration.
+var ErrInvalidExpiration = errors.New("bucket: invalid x-amz-expiration value")
+
+var expirationPattern = regexp.MustCompile(`^expiry-date="([^"]+)",\s*rule-id="([^"]*)"$`)
+
+// An Expiration is the time an object is deleted by a lifecycle rule.
+type Expiration struct {
+	Time   time.Time
+	RuleID string
+}
+
+// ParseExpiration parses the Expiration field of s3.PutObjectOutput, s3.HeadObjectOutput or s3.GetObjectOutput,
+// e.g. `expiry-date="Fri, 23 Dec 2012 00:00:00 GMT", rule-id="picture-deletion-rule"`.
+// The rule ID is URL-encoded by S3 and is decoded.
+// It returns nil if expiration is nil or empty, i.e. the object does not expire.
+func ParseExpiration(expiration *string) (*Expiration, error) {
+	if expiration == nil || *expiration == "" {
+		return nil, nil
+	}
+
+	m := expirationPattern.FindStringSubmatch(*expiration)
+	if m == nil {
+		return nil, ErrInvalidExpiration
+	}
+
+	t, err := http.ParseTime(m[1])
+	if err != nil {
+		return nil, ErrInvalidExpiration
+	}
+
+	ruleID, err := url.QueryUnescape(m[2])
+	if err != nil {
+		return nil, ErrInvalidExpiration
+	}
+
+	return &Expiration{Time: t, RuleID: ruleID}, nil
+}
@@ -0,0 +1,30 @@
+package bucket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiration(t *testing.T) {
+	exp, err := ParseExpiration(aws.String(`expiry-date="Fri, 23 Dec 2012 00:00:00 GMT", rule-id="picture-deletion-rule"`))
+	require.NoError(t, err)
+	assert.Equal(t, &Expiration{Time: time.Date(2012, 12, 23, 0, 0, 0, 0, time.UTC), RuleID: "picture-deletion-rule"}, exp)
+
+	exp, err = ParseExpiration(aws.String(`expiry-date="Fri, 23 Dec 2012 00:00:00 GMT", rule-id="my%20rule"`))
+	require.NoError(t, err)
+	assert.Equal(t, "my rule", exp.RuleID)
+
+	exp, err = ParseExpiration(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, exp)
+
+	_, err = ParseExpiration(aws.String(`expiry-date="tomorrow", rule-id="x"`))
+	assert.Equal(t, ErrInvalidExpiration, err)
+
+	_, err = ParseExpiration(aws.String(`expiry-date="Fri, 23 Dec 2012 00:00:00 GMT", rule-id="bad%zz"`))
+	assert.Equal(t, ErrInvalidExpiration, err)
+}
@@ -0,0 +1,329 @@
+package bucket
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+const (
+	// minPartSize is the minimum size of a part of a multipart upload except the last one.
+	minPartSize = 8 << 20
+
+	// maxParts is the maximum number of parts of a multipart upload.
+	maxParts = 10000
+
+	// fileUploadConcurrency is the number of parts PutObjectFromFile uploads at the same time.
+	fileUploadConcurrency = 5
+)
+
+// PutObjectFromFile puts an object with reading data from the file at path. Files larger than 8 MiB are uploaded
+// with a multipart upload whose parts are read from the file directly and uploaded concurrently.
+// Content-Type is set from the extension of path unless opts set it, and Content-Length from the size of the file.
+//
+// For a multipart upload, the output has the fields of s3.CompleteMultipartUploadOutput and opts are applied to
+// s3.CreateMultipartUploadInput through the fields with the same name. The upload is aborted if it fails.
+func (b *Bucket) PutObjectFromFile(ctx aws.Context, key, path string, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := fi.Size()
+	if b.maxObjectSize > 0 && size > b.maxObjectSize {
+		return nil, fmt.Errorf("%w: %s is %d bytes, the limit is %d bytes", ErrObjectTooLarge, path, size, b.maxObjectSize)
+	}
+
+	if ct := mime.TypeByExtension(filepath.Ext(path)); ct != "" {
+		opts = append([]option.PutObjectInput{option.ContentType(ct)}, opts...)
+	}
+
+	if size <= minPartSize {
+		return b.PutObjectWithContext(ctx, key, f, append([]option.PutObjectInput{option.ContentLength(size)}, opts...)...)
+	}
+
+	return b.putObjectMultipart(ctx, key, f, size, opts...)
+}
+
+// GetObjectToFile writes the object for key to the file at path and returns the number of bytes written.
+// The object is written to a temporary file in the directory of path first, which is renamed to path when the object
+// is written completely, so path is either unchanged or has the whole object. The modification time of the file is set
+// to Last-Modified of the object.
+func (b *Bucket) GetObjectToFile(ctx aws.Context, key, path string, opts ...option.GetObjectInput) (int64, error) {
+	resp, err := b.GetObjectWithContext(ctx, key, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := writeFile(f, resp.Body)
+	if err != nil {
+		os.Remove(f.Name())
+		return n, err
+	}
+
+	if resp.LastModified != nil {
+		if err := os.Chtimes(f.Name(), *resp.LastModified, *resp.LastModified); err != nil {
+			os.Remove(f.Name())
+			return n, err
+		}
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return n, err
+	}
+
+	return n, nil
+}
+
+// writeFile copies r to f, syncs and closes f.
+func writeFile(f *os.File, r io.Reader) (int64, error) {
+	n, err := io.Copy(f, r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return n, err
+}
+
+// putObjectMultipart uploads size bytes of r to key with a multipart upload.
+func (b *Bucket) putObjectMultipart(ctx aws.Context, key string, r io.ReaderAt, size int64, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	return b.multipartUpload(ctx, key, opts, func(upload *s3.CreateMultipartUploadOutput, put *s3.PutObjectInput) ([]*s3.CompletedPart, int64, error) {
+		parts, err := b.uploadParts(ctx, upload, put, r, size)
+		return parts, size, err
+	})
+}
+
+// multipartUpload creates a multipart upload for key, uploads the parts with upload and completes it.
+// upload returns the completed parts and the size of the object.
+// opts are applied to s3.CreateMultipartUploadInput through the fields with the same name.
+// The upload is aborted if it fails. If only the callback of WithUploadCallback fails, the output is returned
+// along with *CallbackError.
+func (b *Bucket) multipartUpload(
+	ctx aws.Context,
+	key string,
+	opts []option.PutObjectInput,
+	upload func(*s3.CreateMultipartUploadOutput, *s3.PutObjectInput) ([]*s3.CompletedPart, int64, error),
+) (*s3.PutObjectOutput, error) {
+	put := &s3.PutObjectInput{
+		Bucket: b.Name,
+	}
+
+	b.applyPutOptions(put, key, opts)
+
+	create := &s3.CreateMultipartUploadInput{}
+	awsutil.Copy(create, put)
+
+	created, err := b.S3.CreateMultipartUploadWithContext(ctx, create, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, size, err := upload(created, put)
+	if err != nil {
+		b.abortMultipartUpload(created)
+		return nil, err
+	}
+
+	resp, err := b.S3.CompleteMultipartUploadWithContext(withUploadSize(ctx, size), &s3.CompleteMultipartUploadInput{
+		Bucket:          created.Bucket,
+		Key:             created.Key,
+		UploadId:        created.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	}, b.reqOpts...)
+
+	// the object exists if only the callback failed
+	var cerr *CallbackError
+	if err != nil && !errors.As(err, &cerr) {
+		b.abortMultipartUpload(created)
+		return nil, err
+	}
+
+	out := &s3.PutObjectOutput{}
+	awsutil.Copy(out, resp)
+
+	return out, err
+}
+
+// abortMultipartUpload aborts upload so that its parts are not kept.
+// It is made without the context of the upload, which may be done.
+func (b *Bucket) abortMultipartUpload(upload *s3.CreateMultipartUploadOutput) {
+	b.S3.AbortMultipartUploadWithContext(aws.BackgroundContext(), &s3.AbortMultipartUploadInput{
+		Bucket:   upload.Bucket,
+		Key:      upload.Key,
+		UploadId: upload.UploadId,
+	}, b.reqOpts...)
+}
+
+// uploadParts uploads r in parts concurrently and returns the completed parts in order.
+func (b *Bucket) uploadParts(ctx aws.Context, upload *s3.CreateMultipartUploadOutput, put *s3.PutObjectInput, r io.ReaderAt, size int64) ([]*s3.CompletedPart, error) {
+	partSize := int64(minPartSize)
+	if size/maxParts >= partSize {
+		partSize = size/maxParts + 1
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		parts []*s3.CompletedPart
+		perr  error
+		sem   = make(chan struct{}, fileUploadConcurrency)
+	)
+
+	for num, off := int64(1), int64(0); off < size; num, off = num+1, off+partSize {
+		n := partSize
+		if off+n > size {
+			n = size - off
+		}
+
+		mu.Lock()
+		failed := perr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(num int64, body *io.SectionReader) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			part, err := b.uploadPart(ctx, upload, put, num, body)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if perr == nil {
+					perr = err
+				}
+				return
+			}
+
+			parts = append(parts, part)
+		}(num, io.NewSectionReader(r, off, n))
+	}
+
+	wg.Wait()
+
+	if perr != nil {
+		return nil, perr
+	}
+
+	sortParts(parts)
+
+	return parts, nil
+}
+
+// uploadPart uploads body as the part num of upload.
+// The SSE-C key, the request payer and the checksum algorithm are taken from put.
+func (b *Bucket) uploadPart(ctx aws.Context, upload *s3.CreateMultipartUploadOutput, put *s3.PutObjectInput, num int64, body io.ReadSeeker) (*s3.CompletedPart, error) {
+	req := &s3.UploadPartInput{
+		Bucket:               upload.Bucket,
+		Key:                  upload.Key,
+		UploadId:             upload.UploadId,
+		PartNumber:           aws.Int64(num),
+		Body:                 body,
+		ChecksumAlgorithm:    put.ChecksumAlgorithm,
+		SSECustomerAlgorithm: put.SSECustomerAlgorithm,
+		SSECustomerKey:       put.SSECustomerKey,
+		SSECustomerKeyMD5:    put.SSECustomerKeyMD5,
+		RequestPayer:         put.RequestPayer,
+		ExpectedBucketOwner:  put.ExpectedBucketOwner,
+	}
+
+	if err := setPartChecksum(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := b.S3.UploadPartWithContext(ctx, req, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// CompleteMultipartUpload requires the checksums of the parts of an upload created with a checksum algorithm
+	return &s3.CompletedPart{
+		ETag:           resp.ETag,
+		PartNumber:     aws.Int64(num),
+		ChecksumCRC32:  resp.ChecksumCRC32,
+		ChecksumCRC32C: resp.ChecksumCRC32C,
+		ChecksumSHA1:   resp.ChecksumSHA1,
+		ChecksumSHA256: resp.ChecksumSHA256,
+	}, nil
+}
+
+// setPartChecksum sets the checksum of the body of req computed with its ChecksumAlgorithm so that S3 verifies the part.
+// The body is rewound to where it was.
+func setPartChecksum(req *s3.UploadPartInput) error {
+	var (
+		h     hash.Hash
+		field **string
+	)
+	switch aws.StringValue(req.ChecksumAlgorithm) {
+	case "":
+		return nil
+	case s3.ChecksumAlgorithmCrc32:
+		h, field = crc32.NewIEEE(), &req.ChecksumCRC32
+	case s3.ChecksumAlgorithmCrc32c:
+		h, field = crc32.New(crc32.MakeTable(crc32.Castagnoli)), &req.ChecksumCRC32C
+	case s3.ChecksumAlgorithmSha1:
+		h, field = sha1.New(), &req.ChecksumSHA1
+	case s3.ChecksumAlgorithmSha256:
+		h, field = sha256.New(), &req.ChecksumSHA256
+	default:
+		return fmt.Errorf("bucket: unknown checksum algorithm %q", aws.StringValue(req.ChecksumAlgorithm))
+	}
+
+	pos, err := req.Body.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(h, req.Body); err != nil {
+		return err
+	}
+	if _, err := req.Body.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+
+	*field = aws.String(base64.StdEncoding.EncodeToString(h.Sum(nil)))
+
+	return nil
+}
+
+// sortParts sorts parts by the part number as CompleteMultipartUpload requires.
+func sortParts(parts []*s3.CompletedPart) {
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.Int64Value(parts[i].PartNumber) < aws.Int64Value(parts[j].PartNumber)
+	})
+}
@@ -0,0 +1,206 @@
+package bucket
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutObjectFromFileMultipart(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), (minPartSize+minPartSize/2)/10)
+	path := filepath.Join(t.TempDir(), "data.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	var (
+		mu          sync.Mutex
+		contentType string
+		received    = map[string]int{}
+	)
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && q.Has("uploads"):
+			contentType = r.Header.Get("Content-Type")
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+		case r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			received[q.Get("partNumber")] = len(body)
+			w.Header().Set("ETag", `"part"`)
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"etag-2"</ETag></CompleteMultipartUploadResult>`))
+		}
+	})
+
+	resp, err := New(svc, "bucket").PutObjectFromFile(aws.BackgroundContext(), "key", path)
+	require.NoError(t, err)
+
+	assert.Equal(t, `"etag-2"`, aws.StringValue(resp.ETag))
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, map[string]int{"1": minPartSize, "2": len(data) - minPartSize}, received)
+}
+
+func TestPutObjectFromFileMultipartCompleteFails(t *testing.T) {
+	data := make([]byte, minPartSize+1)
+	path := filepath.Join(t.TempDir(), "data.bin")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	newBucket := func(t *testing.T, completeStatus int, aborted *bool, opts ...Option) *Bucket {
+		var mu sync.Mutex
+		svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			q := r.URL.Query()
+			switch {
+			case r.Method == http.MethodPost && q.Has("uploads"):
+				w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+			case r.Method == http.MethodPut:
+				ioutil.ReadAll(r.Body)
+				w.Header().Set("ETag", `"part"`)
+			case r.Method == http.MethodPost:
+				w.WriteHeader(completeStatus)
+				if completeStatus != http.StatusOK {
+					w.Write([]byte(`<Error><Code>InvalidPart</Code><Message>One or more of the specified parts could not be found.</Message></Error>`))
+					return
+				}
+				w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"etag-2"</ETag></CompleteMultipartUploadResult>`))
+			case r.Method == http.MethodDelete && q.Get("uploadId") == "id":
+				*aborted = true
+				w.WriteHeader(http.StatusNoContent)
+			}
+		})
+
+		return New(svc, "bucket", opts...)
+	}
+
+	t.Run("Complete", func(t *testing.T) {
+		var aborted bool
+		_, err := newBucket(t, http.StatusBadRequest, &aborted).PutObjectFromFile(aws.BackgroundContext(), "key", path)
+		require.Error(t, err)
+		assert.True(t, aborted, "the parts are not kept")
+	})
+
+	t.Run("Callback", func(t *testing.T) {
+		var aborted bool
+		b := newBucket(t, http.StatusOK, &aborted, WithUploadCallback(func(aws.Context, UploadInfo) error {
+			return assert.AnError
+		}))
+
+		_, err := b.PutObjectFromFile(aws.BackgroundContext(), "key", path)
+		var cerr *CallbackError
+		require.ErrorAs(t, err, &cerr)
+		assert.False(t, aborted, "the object is uploaded")
+	})
+}
+
+func TestPutObjectFromFileMultipartChecksum(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), (minPartSize+minPartSize/2)/10)
+	path := filepath.Join(t.TempDir(), "data.bin")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	sum := func(b []byte) string {
+		h := sha256.Sum256(b)
+		return base64.StdEncoding.EncodeToString(h[:])
+	}
+
+	var (
+		mu        sync.Mutex
+		algorithm string
+		headers   = map[string]http.Header{}
+		complete  []byte
+	)
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && q.Has("uploads"):
+			algorithm = r.Header.Get("X-Amz-Checksum-Algorithm")
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+		case r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			headers[q.Get("partNumber")] = r.Header.Clone()
+			w.Header().Set("ETag", `"part"`)
+			w.Header().Set("X-Amz-Checksum-Sha256", sum(body))
+		case r.Method == http.MethodPost:
+			complete, _ = ioutil.ReadAll(r.Body)
+			w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"etag-2"</ETag></CompleteMultipartUploadResult>`))
+		}
+	})
+
+	_, err := New(svc, "bucket").PutObjectFromFile(aws.BackgroundContext(), "key", path, option.ChecksumAlgorithm(s3.ChecksumAlgorithmSha256))
+	require.NoError(t, err)
+
+	assert.Equal(t, s3.ChecksumAlgorithmSha256, algorithm)
+	require.Len(t, headers, 2)
+	for num, part := range map[string][]byte{"1": data[:minPartSize], "2": data[minPartSize:]} {
+		assert.Equal(t, s3.ChecksumAlgorithmSha256, headers[num].Get("X-Amz-Sdk-Checksum-Algorithm"), "part %s", num)
+		assert.Equal(t, sum(part), headers[num].Get("X-Amz-Checksum-Sha256"), "part %s", num)
+	}
+
+	var completed struct {
+		Parts []struct {
+			PartNumber     string
+			ChecksumSHA256 string
+		} `xml:"Part"`
+	}
+	require.NoError(t, xml.Unmarshal(complete, &completed))
+	require.Len(t, completed.Parts, 2)
+	assert.Equal(t, "1", completed.Parts[0].PartNumber)
+	assert.Equal(t, sum(data[:minPartSize]), completed.Parts[0].ChecksumSHA256)
+	assert.Equal(t, "2", completed.Parts[1].PartNumber)
+	assert.Equal(t, sum(data[minPartSize:]), completed.Parts[1].ChecksumSHA256)
+}
+
+func TestGetObjectToFile(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bucket/key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Write([]byte("hello"))
+	})
+	b := New(svc, "bucket")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+
+	n, err := b.GetObjectToFile(aws.BackgroundContext(), "key", path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, fi.ModTime().Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)))
+
+	_, err = b.GetObjectToFile(aws.BackgroundContext(), "missing", filepath.Join(dir, "missing"))
+	assert.True(t, IsNotFound(err))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
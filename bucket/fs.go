@@ -0,0 +1,274 @@
+package bucket
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// FS returns a read-only fs.FS of the objects under prefix. Names are the keys relative to prefix, with "/" separating
+// directories, which exist as long as there are objects under them. It implements fs.ReadDirFS, fs.StatFS and
+// fs.ReadFileFS. prefix should be empty or end with "/".
+func (b *Bucket) FS(prefix string) fs.FS {
+	return &bucketFS{b: b.WithPrefix(prefix)}
+}
+
+type bucketFS struct {
+	b *Bucket
+}
+
+var (
+	_ fs.ReadDirFS  = (*bucketFS)(nil)
+	_ fs.StatFS     = (*bucketFS)(nil)
+	_ fs.ReadFileFS = (*bucketFS)(nil)
+)
+
+func (fsys *bucketFS) Open(name string) (fs.File, error) {
+	info, err := fsys.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return &dirFile{fsys: fsys, name: name, info: info}, nil
+	}
+
+	return &objectFile{fsys: fsys, name: name, info: info}, nil
+}
+
+func (fsys *bucketFS) Stat(name string) (fs.FileInfo, error) {
+	return fsys.stat("stat", name)
+}
+
+func (fsys *bucketFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	resp, err := fsys.b.GetObject(name)
+	if err != nil {
+		return nil, pathError("readfile", name, err)
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+func (fsys *bucketFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entries, err := fsys.readDir(name, 0)
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+
+	if len(entries) == 0 && name != "." {
+		info, err := fsys.stat("readdir", name)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+		}
+	}
+
+	return entries, nil
+}
+
+// stat returns the info of the object or the directory name.
+func (fsys *bucketFS) stat(op, name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return &fileInfo{name: ".", dir: true}, nil
+	}
+
+	head, err := fsys.b.HeadObject(name)
+	if err == nil {
+		return &fileInfo{
+			name:    path.Base(name),
+			size:    aws.Int64Value(head.ContentLength),
+			modTime: aws.TimeValue(head.LastModified),
+			sys:     head,
+		}, nil
+	}
+	if !IsNotFound(err) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	entries, err := fsys.readDir(name, 1)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	if len(entries) == 0 {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &fileInfo{name: path.Base(name), dir: true}, nil
+}
+
+// readDir returns the entries of the directory name sorted by name. It stops after limit entries if limit is positive.
+func (fsys *bucketFS) readDir(name string, limit int) ([]fs.DirEntry, error) {
+	prefix := ""
+	if name != "." {
+		prefix = name + "/"
+	}
+
+	var entries []fs.DirEntry
+	err := fsys.b.ListObjectsV2PagesWithContext(aws.BackgroundContext(), prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			base := strings.TrimPrefix(aws.StringValue(o.Key), prefix)
+			if base == "" {
+				// a marker object of the directory itself
+				continue
+			}
+
+			entries = append(entries, fs.FileInfoToDirEntry(&fileInfo{
+				name:    base,
+				size:    aws.Int64Value(o.Size),
+				modTime: aws.TimeValue(o.LastModified),
+				sys:     o,
+			}))
+		}
+
+		for _, cp := range page.CommonPrefixes {
+			base := strings.TrimSuffix(strings.TrimPrefix(aws.StringValue(cp.Prefix), prefix), "/")
+			if base == "" {
+				continue
+			}
+
+			entries = append(entries, fs.FileInfoToDirEntry(&fileInfo{name: base, dir: true}))
+		}
+
+		return limit <= 0 || len(entries) < limit
+	}, func(req *s3.ListObjectsV2Input) {
+		req.Delimiter = aws.String("/")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries, nil
+}
+
+// pathError returns err as *fs.PathError, translating a missing key into fs.ErrNotExist.
+func pathError(op, name string, err error) error {
+	if IsNotFound(err) {
+		err = fs.ErrNotExist
+	}
+
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+	sys     interface{}
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.dir }
+func (fi *fileInfo) Sys() interface{}   { return fi.sys }
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0555
+	}
+
+	return 0444
+}
+
+// objectFile is an object opened by bucketFS. The object is downloaded on the first Read.
+type objectFile struct {
+	fsys *bucketFS
+	name string
+	info fs.FileInfo
+	body io.ReadCloser
+}
+
+func (f *objectFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *objectFile) Read(p []byte) (int, error) {
+	if f.body == nil {
+		resp, err := f.fsys.b.GetObject(f.name)
+		if err != nil {
+			return 0, pathError("read", f.name, err)
+		}
+
+		f.body = resp.Body
+	}
+
+	return f.body.Read(p)
+}
+
+func (f *objectFile) Close() error {
+	if f.body == nil {
+		return nil
+	}
+
+	return f.body.Close()
+}
+
+// dirFile is a directory opened by bucketFS. The entries are listed on the first ReadDir.
+type dirFile struct {
+	fsys    *bucketFS
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	listed  bool
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *dirFile) Close() error { return nil }
+
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.listed {
+		entries, err := d.fsys.readDir(d.name, 0)
+		if err != nil {
+			return nil, pathError("readdir", d.name, err)
+		}
+
+		d.entries = entries
+		d.listed = true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+
+	return entries, nil
+}
@@ -0,0 +1,118 @@
+package bucket
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fsStub serves objects from memory, listing them with the delimiter.
+type fsStub struct {
+	s3iface.S3API
+
+	objects map[string]string
+}
+
+var fsStubTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (s *fsStub) notFound() error {
+	return awserr.NewRequestFailure(awserr.New("NotFound", "not found", nil), http.StatusNotFound, "")
+}
+
+func (s *fsStub) HeadObjectWithContext(_ aws.Context, in *s3.HeadObjectInput, _ ...request.Option) (*s3.HeadObjectOutput, error) {
+	body, ok := s.objects[aws.StringValue(in.Key)]
+	if !ok {
+		return nil, s.notFound()
+	}
+
+	return &s3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(body))), LastModified: aws.Time(fsStubTime)}, nil
+}
+
+func (s *fsStub) GetObjectWithContext(_ aws.Context, in *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	body, ok := s.objects[aws.StringValue(in.Key)]
+	if !ok {
+		return nil, s.notFound()
+	}
+
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func (s *fsStub) ListObjectsV2PagesWithContext(_ aws.Context, in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	prefix, delim := aws.StringValue(in.Prefix), aws.StringValue(in.Delimiter)
+
+	var keys []string
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := &s3.ListObjectsV2Output{}
+	seen := map[string]bool{}
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		if i := strings.Index(k[len(prefix):], delim); delim != "" && i >= 0 {
+			cp := k[:len(prefix)+i+1]
+			if !seen[cp] {
+				seen[cp] = true
+				out.CommonPrefixes = append(out.CommonPrefixes, &s3.CommonPrefix{Prefix: aws.String(cp)})
+			}
+			continue
+		}
+
+		out.Contents = append(out.Contents, &s3.Object{
+			Key:          aws.String(k),
+			Size:         aws.Int64(int64(len(s.objects[k]))),
+			LastModified: aws.Time(fsStubTime),
+		})
+	}
+
+	fn(out, true)
+	return nil
+}
+
+func TestFS(t *testing.T) {
+	b := New(&fsStub{objects: map[string]string{
+		"site/index.html":      "<html>",
+		"site/css/main.css":    "body {}",
+		"site/img/":            "",
+		"site/img/logo.png":    "png",
+		"site/img/icons/a.svg": "<svg>",
+		"other/not-in-the-fs":  "x",
+	}}, "bucket")
+
+	fsys := b.FS("site/")
+	require.NoError(t, fstest.TestFS(fsys, "index.html", "css/main.css", "img/logo.png", "img/icons/a.svg"))
+
+	data, err := fs.ReadFile(fsys, "css/main.css")
+	require.NoError(t, err)
+	assert.Equal(t, "body {}", string(data))
+
+	entries, err := fs.ReadDir(fsys, "img")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "icons", entries[0].Name())
+	assert.True(t, entries[0].IsDir())
+	assert.Equal(t, "logo.png", entries[1].Name())
+
+	_, err = fs.Stat(fsys, "missing.html")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = fs.ReadFile(fsys, "missing.html")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -0,0 +1,84 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// A WriteEvent is an object written or deleted through a Bucket. It is passed to the hook of WithWriteHook.
+type WriteEvent struct {
+	// Key is relative to the Bucket given to New, i.e. it includes the prefix of views.
+	Key string
+
+	// VersionID is the version written, or the version or the delete marker of a deletion, if any.
+	VersionID string
+
+	// Deleted reports whether the object is deleted rather than written.
+	Deleted bool
+}
+
+// WithWriteHook returns an Option that calls fn after each successful PutObject, CompleteMultipartUpload, CopyObject,
+// DeleteObject and DeleteObjects made through the Bucket with each object they write or delete, i.e. after every
+// write including the ones made by the helpers such as PutObjectFromFile, CopyObjectMultipart and DeletePrefix.
+// It is meant to keep something in sync with the bucket, e.g. an index of the objects.
+//
+// The write has succeeded when fn is called, so an error of fn is returned as *CallbackError along with the output.
+// fn is not called for the rest of the objects of a DeleteObjects once it fails.
+func WithWriteHook(fn func(ctx aws.Context, ev WriteEvent) error) Option {
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			if len(modifiedKeys(r.Params)) == 0 {
+				return
+			}
+
+			// The error of a Complete handler is not returned by the request, so fn is called once the output is unmarshaled.
+			r.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+				if r.Error != nil {
+					return
+				}
+
+				for _, ev := range writeEvents(r.Params, r.Data) {
+					ev.Key = b.userKey(ev.Key)
+					if err := fn(r.Context(), ev); err != nil {
+						// the write must not be retried for the hook
+						r.Error = &CallbackError{Key: ev.Key, Err: err}
+						r.Retryable = aws.Bool(false)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+// writeEvents returns the events of the request with params and the output data with the keys stored in S3.
+func writeEvents(params, data interface{}) []WriteEvent {
+	switch out := data.(type) {
+	case *s3.PutObjectOutput:
+		return []WriteEvent{{Key: aws.StringValue(params.(*s3.PutObjectInput).Key), VersionID: aws.StringValue(out.VersionId)}}
+	case *s3.CompleteMultipartUploadOutput:
+		return []WriteEvent{{Key: aws.StringValue(params.(*s3.CompleteMultipartUploadInput).Key), VersionID: aws.StringValue(out.VersionId)}}
+	case *s3.CopyObjectOutput:
+		return []WriteEvent{{Key: aws.StringValue(params.(*s3.CopyObjectInput).Key), VersionID: aws.StringValue(out.VersionId)}}
+	case *s3.DeleteObjectOutput:
+		in := params.(*s3.DeleteObjectInput)
+		versionID := aws.StringValue(in.VersionId)
+		if versionID == "" {
+			versionID = aws.StringValue(out.VersionId)
+		}
+		return []WriteEvent{{Key: aws.StringValue(in.Key), VersionID: versionID, Deleted: true}}
+	case *s3.DeleteObjectsOutput:
+		var events []WriteEvent
+		for _, d := range out.Deleted {
+			versionID := aws.StringValue(d.VersionId)
+			if versionID == "" {
+				versionID = aws.StringValue(d.DeleteMarkerVersionId)
+			}
+			events = append(events, WriteEvent{Key: aws.StringValue(d.Key), VersionID: versionID, Deleted: true})
+		}
+		return events
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package bucket
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWriteHook(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+			w.Header().Set("x-amz-version-id", "v2")
+			w.Write([]byte(`<CopyObjectResult><ETag>"copy"</ETag></CopyObjectResult>`))
+		case r.Method == http.MethodPut:
+			w.Header().Set("x-amz-version-id", "v1")
+		case r.Method == http.MethodPost && r.URL.Query().Has("delete"):
+			w.Write([]byte(`<DeleteResult>` +
+				`<Deleted><Key>dir/a</Key><DeleteMarker>true</DeleteMarker><DeleteMarkerVersionId>m1</DeleteMarkerVersionId></Deleted>` +
+				`<Error><Key>dir/b</Key><Code>AccessDenied</Code></Error>` +
+				`</DeleteResult>`))
+		case r.Method == http.MethodDelete:
+			w.Header().Set("x-amz-version-id", "m2")
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet:
+			w.Write([]byte("data"))
+		}
+	})
+
+	var events []WriteEvent
+	fail := false
+	b := New(svc, "bucket", WithWriteHook(func(_ aws.Context, ev WriteEvent) error {
+		events = append(events, ev)
+		if fail {
+			return assert.AnError
+		}
+		return nil
+	})).WithPrefix("dir/")
+
+	_, err := b.PutObject("key", strings.NewReader("data"))
+	require.NoError(t, err)
+	_, err = b.CopyObject("copy", "key")
+	require.NoError(t, err)
+	_, err = b.DeleteObject("key")
+	require.NoError(t, err)
+	_, err = b.DeleteObjects([]*s3.ObjectIdentifier{{Key: aws.String("a")}, {Key: aws.String("b")}})
+	require.NoError(t, err)
+	_, err = b.GetObject("key")
+	require.NoError(t, err)
+
+	assert.Equal(t, []WriteEvent{
+		{Key: "dir/key", VersionID: "v1"},
+		{Key: "dir/copy", VersionID: "v2"},
+		{Key: "dir/key", VersionID: "m2", Deleted: true},
+		{Key: "dir/a", VersionID: "m1", Deleted: true},
+	}, events, "only the objects written or deleted are passed")
+
+	fail = true
+
+	resp, err := b.PutObject("key", strings.NewReader("data"))
+	var cerr *CallbackError
+	require.ErrorAs(t, err, &cerr)
+	assert.Equal(t, "dir/key", cerr.Key)
+	assert.Equal(t, "v1", aws.StringValue(resp.VersionId), "the output is returned along with the error")
+
+	deleted, err := b.DeleteObjects([]*s3.ObjectIdentifier{{Key: aws.String("a")}})
+	require.ErrorAs(t, err, &cerr)
+	require.NotNil(t, deleted)
+	assert.Equal(t, "a", aws.StringValue(deleted.Deleted[0].Key))
+}
@@ -0,0 +1,150 @@
+package bucket
+
+import (
+	"iter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// A VersionEntry is either an object version or a delete marker returned by ListObjectVersions.
+type VersionEntry struct {
+	Key            string
+	VersionID      string
+	IsLatest       bool
+	IsDeleteMarker bool
+	LastModified   time.Time
+	Owner          *s3.Owner
+
+	// ETag, Size and StorageClass are empty for delete markers.
+	ETag         string
+	Size         int64
+	StorageClass string
+}
+
+// Objects returns an iterator over the objects with the given prefix in the order of the keys.
+// An error ends the iteration after it is yielded.
+func (b *Bucket) Objects(ctx aws.Context, prefix string, opts ...option.ListObjectsV2Input) iter.Seq2[*s3.Object, error] {
+	return func(yield func(*s3.Object, error) bool) {
+		err := b.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+			for _, o := range page.Contents {
+				if !yield(o, nil) {
+					return false
+				}
+			}
+
+			return true
+		}, opts...)
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// ObjectVersions returns an iterator over all versions and delete markers of the objects with the given prefix.
+// The entries are ordered by key and then from the newest to the oldest as S3 returns them.
+// An error ends the iteration after it is yielded.
+func (b *Bucket) ObjectVersions(ctx aws.Context, prefix string, opts ...option.ListObjectVersionsInput) iter.Seq2[VersionEntry, error] {
+	return func(yield func(VersionEntry, error) bool) {
+		err := b.ListObjectVersionsPagesWithContext(ctx, prefix, func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+			for _, e := range mergeVersions(page.Versions, page.DeleteMarkers) {
+				if !yield(e, nil) {
+					return false
+				}
+			}
+
+			return true
+		}, opts...)
+		if err != nil {
+			yield(VersionEntry{}, err)
+		}
+	}
+}
+
+// mergeVersions merges versions and delete markers of a page into the order of S3.
+func mergeVersions(versions []*s3.ObjectVersion, markers []*s3.DeleteMarkerEntry) []VersionEntry {
+	entries := make([]VersionEntry, 0, len(versions)+len(markers))
+
+	i, j := 0, 0
+	for i < len(versions) || j < len(markers) {
+		if j == len(markers) || (i < len(versions) && versionBefore(versions[i], markers[j])) {
+			v := versions[i]
+			entries = append(entries, VersionEntry{
+				Key:          aws.StringValue(v.Key),
+				VersionID:    aws.StringValue(v.VersionId),
+				IsLatest:     aws.BoolValue(v.IsLatest),
+				LastModified: aws.TimeValue(v.LastModified),
+				Owner:        v.Owner,
+				ETag:         aws.StringValue(v.ETag),
+				Size:         aws.Int64Value(v.Size),
+				StorageClass: aws.StringValue(v.StorageClass),
+			})
+			i++
+
+			continue
+		}
+
+		m := markers[j]
+		entries = append(entries, VersionEntry{
+			Key:            aws.StringValue(m.Key),
+			VersionID:      aws.StringValue(m.VersionId),
+			IsLatest:       aws.BoolValue(m.IsLatest),
+			IsDeleteMarker: true,
+			LastModified:   aws.TimeValue(m.LastModified),
+			Owner:          m.Owner,
+		})
+		j++
+	}
+
+	return entries
+}
+
+// versionBefore reports whether v comes before m, i.e. it has a smaller key or is newer for the same key.
+// LastModified has a resolution of a second, so the latest one comes first when they are modified in the same second.
+func versionBefore(v *s3.ObjectVersion, m *s3.DeleteMarkerEntry) bool {
+	vk, mk := aws.StringValue(v.Key), aws.StringValue(m.Key)
+	if vk != mk {
+		return vk < mk
+	}
+
+	vt, mt := aws.TimeValue(v.LastModified), aws.TimeValue(m.LastModified)
+	if !vt.Equal(mt) {
+		return vt.After(mt)
+	}
+
+	return !aws.BoolValue(m.IsLatest)
+}
+
+// ListLatestVersions returns an iterator that yields the newest version that is not a delete marker for each key
+// with the given prefix. IsLatest of the entry is false when the key is currently deleted by a delete marker.
+// An error ends the iteration after it is yielded.
+func (b *Bucket) ListLatestVersions(ctx aws.Context, prefix string, opts ...option.ListObjectVersionsInput) iter.Seq2[VersionEntry, error] {
+	return func(yield func(VersionEntry, error) bool) {
+		var (
+			key   string
+			found bool
+		)
+
+		for e, err := range b.ObjectVersions(ctx, prefix, opts...) {
+			if err != nil {
+				yield(VersionEntry{}, err)
+				return
+			}
+
+			if e.Key != key {
+				key, found = e.Key, false
+			}
+
+			if found || e.IsDeleteMarker {
+				continue
+			}
+
+			found = true
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
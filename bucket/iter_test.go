@@ -0,0 +1,159 @@
+package bucket
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingListStub struct {
+	objectsStub
+
+	err error
+}
+
+func (s *failingListStub) ListObjectsV2PagesWithContext(ctx aws.Context, in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, opts ...request.Option) error {
+	if fn(&s3.ListObjectsV2Output{Contents: s.objects}, false) {
+		return s.err
+	}
+
+	return nil
+}
+
+func TestObjects(t *testing.T) {
+	objects := []*s3.Object{{Key: aws.String("a")}, {Key: aws.String("b")}, {Key: aws.String("c")}}
+
+	var keys []string
+	for o, err := range New(&objectsStub{objects: objects}, "bucket").Objects(aws.BackgroundContext(), "") {
+		assert.NoError(t, err)
+
+		keys = append(keys, aws.StringValue(o.Key))
+		if len(keys) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	listErr := errors.New("list failed")
+
+	var errs []error
+	for _, err := range New(&failingListStub{objectsStub: objectsStub{objects: objects}, err: listErr}, "bucket").Objects(aws.BackgroundContext(), "") {
+		errs = append(errs, err)
+	}
+	assert.Equal(t, []error{nil, nil, nil, listErr}, errs)
+}
+
+func TestMergeVersions(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	version := func(key, id string, modified time.Time, latest bool) *s3.ObjectVersion {
+		return &s3.ObjectVersion{Key: aws.String(key), VersionId: aws.String(id), LastModified: aws.Time(modified), IsLatest: aws.Bool(latest)}
+	}
+	marker := func(key, id string, modified time.Time, latest bool) *s3.DeleteMarkerEntry {
+		return &s3.DeleteMarkerEntry{Key: aws.String(key), VersionId: aws.String(id), LastModified: aws.Time(modified), IsLatest: aws.Bool(latest)}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		versions []*s3.ObjectVersion
+		markers  []*s3.DeleteMarkerEntry
+		want     []string
+	}{
+		{
+			name:     "by key",
+			versions: []*s3.ObjectVersion{version("a", "a1", t0, true), version("c", "c1", t0, true)},
+			markers:  []*s3.DeleteMarkerEntry{marker("b", "b1", t0, true)},
+			want:     []string{"a1", "b1", "c1"},
+		},
+		{
+			name:     "newest first",
+			versions: []*s3.ObjectVersion{version("a", "a3", t0.Add(2*time.Second), true), version("a", "a1", t0, false)},
+			markers:  []*s3.DeleteMarkerEntry{marker("a", "a2", t0.Add(time.Second), false)},
+			want:     []string{"a3", "a2", "a1"},
+		},
+		{
+			name:     "latest marker in the same second",
+			versions: []*s3.ObjectVersion{version("a", "a1", t0, false)},
+			markers:  []*s3.DeleteMarkerEntry{marker("a", "a2", t0, true)},
+			want:     []string{"a2", "a1"},
+		},
+		{
+			name:     "latest version in the same second",
+			versions: []*s3.ObjectVersion{version("a", "a2", t0, true)},
+			markers:  []*s3.DeleteMarkerEntry{marker("a", "a1", t0, false)},
+			want:     []string{"a2", "a1"},
+		},
+		{
+			name: "markers only",
+			markers: []*s3.DeleteMarkerEntry{
+				marker("a", "a2", t0.Add(time.Second), true),
+				marker("a", "a1", t0, false),
+			},
+			want: []string{"a2", "a1"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ids []string
+			for _, e := range mergeVersions(tc.versions, tc.markers) {
+				ids = append(ids, e.VersionID)
+			}
+			assert.Equal(t, tc.want, ids)
+		})
+	}
+
+	entries := mergeVersions([]*s3.ObjectVersion{{
+		Key: aws.String("a"), VersionId: aws.String("v"), IsLatest: aws.Bool(false), LastModified: aws.Time(t0),
+		ETag: aws.String(`"etag"`), Size: aws.Int64(3), StorageClass: aws.String(s3.ObjectStorageClassStandard),
+	}}, []*s3.DeleteMarkerEntry{marker("a", "m", t0.Add(time.Second), true)})
+	assert.Equal(t, []VersionEntry{
+		{Key: "a", VersionID: "m", IsLatest: true, IsDeleteMarker: true, LastModified: t0.Add(time.Second)},
+		{Key: "a", VersionID: "v", LastModified: t0, ETag: `"etag"`, Size: 3, StorageClass: s3.ObjectStorageClassStandard},
+	}, entries)
+}
+
+func TestListLatestVersions(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	version := func(key, id string, modified time.Time, latest bool) *s3.ObjectVersion {
+		return &s3.ObjectVersion{Key: aws.String(key), VersionId: aws.String(id), LastModified: aws.Time(modified), IsLatest: aws.Bool(latest)}
+	}
+	marker := func(key, id string, modified time.Time, latest bool) *s3.DeleteMarkerEntry {
+		return &s3.DeleteMarkerEntry{Key: aws.String(key), VersionId: aws.String(id), LastModified: aws.Time(modified), IsLatest: aws.Bool(latest)}
+	}
+
+	b := New(&versionsStub{page: &s3.ListObjectVersionsOutput{
+		Versions: []*s3.ObjectVersion{
+			version("live", "live2", t0.Add(time.Second), true),
+			version("live", "live1", t0, false),
+			version("marked", "marked1", t0, false),
+		},
+		DeleteMarkers: []*s3.DeleteMarkerEntry{
+			// every version of deleted is gone but the marker
+			marker("deleted", "deleted1", t0, true),
+			marker("marked", "marked3", t0.Add(2*time.Second), true),
+			marker("marked", "marked2", t0.Add(time.Second), false),
+		},
+	}}, "bucket")
+
+	var entries []VersionEntry
+	for e, err := range b.ListLatestVersions(aws.BackgroundContext(), "") {
+		assert.NoError(t, err)
+		entries = append(entries, e)
+	}
+	assert.Equal(t, []VersionEntry{
+		{Key: "live", VersionID: "live2", IsLatest: true, LastModified: t0.Add(time.Second)},
+		{Key: "marked", VersionID: "marked1", LastModified: t0},
+	}, entries)
+
+	var keys []string
+	for e, err := range b.ListLatestVersions(aws.BackgroundContext(), "") {
+		assert.NoError(t, err)
+
+		keys = append(keys, e.Key)
+		break
+	}
+	assert.Equal(t, []string{"live"}, keys)
+}
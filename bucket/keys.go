@@ -0,0 +1,255 @@
+package bucket
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ErrKeyOutsidePrefix is returned when a key passed to a view returned by WithPrefix may escape the prefix of the view.
+var ErrKeyOutsidePrefix = errors.New("bucket: key is outside the prefix of the view")
+
+// WithPrefix returns a view of the bucket where every key is relative to prefix.
+// Keys passed to the view are joined with prefix and keys in listings are returned without it.
+// The view shares the S3 client and the options with b.
+//
+// Requests for a key or a listing prefix that starts with "/" or has a "." or ".." segment fail with an error
+// matching ErrKeyOutsidePrefix before they are sent, since such keys may resolve outside prefix when they are
+// interpreted as paths, e.g. by FS or a file system mounting the bucket. The keys are checked by a request handler
+// so they are not checked if the S3 client is not *s3.S3.
+func (b *Bucket) WithPrefix(prefix string) *Bucket {
+	view := *b
+	view.prefix = b.prefix + prefix
+
+	if view.prefix != "" {
+		view.reqOpts = append(append([]request.Option(nil), b.reqOpts...), view.checkKeysRequestOption)
+	}
+
+	return &view
+}
+
+// checkKeysRequestOption fails the request if any key of the input, including the source of a copy in the bucket,
+// is not under the prefix of b.
+func (b *Bucket) checkKeysRequestOption(r *request.Request) {
+	r.Handlers.Validate.PushFront(func(r *request.Request) {
+		var (
+			keys   []string
+			source string
+		)
+		switch in := r.Params.(type) {
+		case *s3.ListObjectsInput:
+			keys = append(keys, aws.StringValue(in.Prefix))
+		case *s3.ListObjectsV2Input:
+			keys = append(keys, aws.StringValue(in.Prefix))
+		case *s3.ListObjectVersionsInput:
+			keys = append(keys, aws.StringValue(in.Prefix))
+		case *s3.DeleteObjectsInput:
+			if in.Delete != nil {
+				for _, o := range in.Delete.Objects {
+					keys = append(keys, aws.StringValue(o.Key))
+				}
+			}
+		case *s3.CopyObjectInput:
+			keys = append(keys, aws.StringValue(in.Key))
+			source = aws.StringValue(in.CopySource)
+		case *s3.UploadPartCopyInput:
+			keys = append(keys, aws.StringValue(in.Key))
+			source = aws.StringValue(in.CopySource)
+		default:
+			keys = append(keys, paramsKey(r.Params))
+		}
+
+		for _, key := range keys {
+			// an empty key is not set by the view, e.g. in a request for the bucket
+			if key != "" && !b.underPrefix(key) {
+				r.Error = fmt.Errorf("bucket: %s %q: %w", r.Operation.Name, key, ErrKeyOutsidePrefix)
+				return
+			}
+		}
+
+		// the objects in the other buckets are not in the namespace of the view
+		if source != "" {
+			name, key, err := parseCopySource(source)
+			if err == nil && name != aws.StringValue(b.Name) {
+				return
+			}
+			if err != nil || !b.underPrefix(key) {
+				r.Error = fmt.Errorf("bucket: %s copy source %q: %w", r.Operation.Name, source, ErrKeyOutsidePrefix)
+			}
+		}
+	})
+}
+
+// underPrefix reports whether the key stored in S3 is under the prefix of b
+// and the rest of the key cannot be resolved outside of it as a path.
+//
+// The stored key is compared with the encoded forms of the rejected segments rather than decoded
+// so that the keys encoded by an irreversible KeyCodec are checked as well.
+// The first segment of the key is encoded together with the last segment of the prefix.
+func (b *Bucket) underPrefix(stored string) bool {
+	i := strings.LastIndex(b.prefix, "/") + 1
+	dir, last := b.encodeKey(b.prefix[:i]), b.prefix[i:]
+	if !strings.HasPrefix(stored, dir) {
+		return false
+	}
+
+	segments := strings.Split(stored[len(dir):], "/")
+	for j, seg := range segments {
+		joined := ""
+		if j == 0 {
+			joined = last
+		}
+
+		if seg == b.encodeKey(joined+".") || seg == b.encodeKey(joined+"..") {
+			return false
+		}
+	}
+
+	// the key starts with "/"
+	return len(segments) == 1 || segments[0] != b.encodeKey(last)
+}
+
+// Prefix returns the prefix of the view. It is empty unless the Bucket is returned by WithPrefix.
+func (b *Bucket) Prefix() string {
+	return b.prefix
+}
+
+// ObjectKey returns the key stored in S3 for key, i.e. key joined with the prefix of the view and encoded by the
+// KeyCodec, e.g. to pass it as the source key to CopyObjectFrom of another Bucket.
+func (b *Bucket) ObjectKey(key string) string {
+	return b.objectKey(key)
+}
+
+// A KeyCodec transforms keys before they are stored in S3, e.g. to hide sensitive identifiers in key names.
+// Encode must be deterministic so that the same key always maps to the same stored key.
+// Decode returns an error if the stored key cannot be reversed.
+type KeyCodec interface {
+	Encode(key string) string
+	Decode(stored string) (string, error)
+}
+
+// WithKeyCodec returns an Option that stores every key, including the prefix of views, encoded by c.
+// Keys in listings are decoded, or left as stored if c cannot decode them.
+//
+// The segments of a key separated by "/" are encoded separately so that listing a prefix that ends with "/" works.
+// A prefix that ends in the middle of a segment does not match any key.
+func WithKeyCodec(c KeyCodec) Option {
+	return func(b *Bucket) {
+		b.codec = c
+	}
+}
+
+// objectKey returns the key stored in S3 for key.
+func (b *Bucket) objectKey(key string) string {
+	return b.encodeKey(b.prefix + key)
+}
+
+// encodeKey returns key encoded by the codec of b segment by segment.
+func (b *Bucket) encodeKey(key string) string {
+	if b.codec == nil {
+		return key
+	}
+
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		if seg != "" {
+			segments[i] = b.codec.Encode(seg)
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// userKey returns the key seen by the caller for the key stored in S3.
+func (b *Bucket) userKey(stored string) string {
+	key := stored
+	if b.codec != nil {
+		segments := strings.Split(stored, "/")
+		for i, seg := range segments {
+			if seg == "" {
+				continue
+			}
+
+			decoded, err := b.codec.Decode(seg)
+			if err != nil {
+				return stored
+			}
+
+			segments[i] = decoded
+		}
+
+		key = strings.Join(segments, "/")
+	}
+
+	return strings.TrimPrefix(key, b.prefix)
+}
+
+// key returns the key stored in S3 for key as the SDK input.
+func (b *Bucket) key(key string) *string {
+	return aws.String(b.objectKey(key))
+}
+
+// mapKey replaces the key in *p with the key stored in S3 if it is set.
+func (b *Bucket) mapKey(p **string) {
+	if *p != nil {
+		*p = b.key(**p)
+	}
+}
+
+// unmapKey replaces the key stored in S3 in *p with the key seen by the caller if it is set.
+func (b *Bucket) unmapKey(p **string) {
+	if *p != nil && (b.prefix != "" || b.codec != nil) {
+		*p = aws.String(b.userKey(**p))
+	}
+}
+
+func (b *Bucket) unmapListObjectsOutput(out *s3.ListObjectsOutput) {
+	b.unmapKey(&out.Prefix)
+	b.unmapKey(&out.Marker)
+	b.unmapKey(&out.NextMarker)
+	for _, o := range out.Contents {
+		b.unmapKey(&o.Key)
+	}
+	for _, cp := range out.CommonPrefixes {
+		b.unmapKey(&cp.Prefix)
+	}
+}
+
+func (b *Bucket) unmapListObjectsV2Output(out *s3.ListObjectsV2Output) {
+	b.unmapKey(&out.Prefix)
+	b.unmapKey(&out.StartAfter)
+	for _, o := range out.Contents {
+		b.unmapKey(&o.Key)
+	}
+	for _, cp := range out.CommonPrefixes {
+		b.unmapKey(&cp.Prefix)
+	}
+}
+
+func (b *Bucket) unmapListObjectVersionsOutput(out *s3.ListObjectVersionsOutput) {
+	b.unmapKey(&out.Prefix)
+	b.unmapKey(&out.KeyMarker)
+	b.unmapKey(&out.NextKeyMarker)
+	for _, v := range out.Versions {
+		b.unmapKey(&v.Key)
+	}
+	for _, m := range out.DeleteMarkers {
+		b.unmapKey(&m.Key)
+	}
+	for _, cp := range out.CommonPrefixes {
+		b.unmapKey(&cp.Prefix)
+	}
+}
+
+func (b *Bucket) unmapDeleteObjectsOutput(out *s3.DeleteObjectsOutput) {
+	for _, d := range out.Deleted {
+		b.unmapKey(&d.Key)
+	}
+	for _, e := range out.Errors {
+		b.unmapKey(&e.Key)
+	}
+}
@@ -0,0 +1,169 @@
+package bucket
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/nabeken/aws-go-s3/keycodec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listStub struct {
+	s3iface.S3API
+
+	input *s3.ListObjectsV2Input
+}
+
+func (s *listStub) ListObjectsV2PagesWithContext(_ aws.Context, in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	s.input = in
+	fn(&s3.ListObjectsV2Output{
+		Prefix:   in.Prefix,
+		Contents: []*s3.Object{{Key: aws.String(aws.StringValue(in.Prefix) + "a.txt")}},
+	}, true)
+
+	return nil
+}
+
+func TestWithPrefix(t *testing.T) {
+	stub := &listStub{}
+	b := New(stub, "bucket").WithPrefix("tenant/").WithPrefix("sub/")
+
+	assert.Equal(t, "tenant/sub/", b.Prefix())
+	assert.Equal(t, "tenant/sub/key", aws.StringValue(b.key("key")))
+
+	var keys []string
+	err := b.ListObjectsV2PagesWithContext(aws.BackgroundContext(), "dir/", func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			keys = append(keys, aws.StringValue(o.Key))
+		}
+		return true
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "tenant/sub/dir/", aws.StringValue(stub.input.Prefix))
+	assert.Equal(t, []string{"dir/a.txt"}, keys)
+}
+
+func TestWithPrefixRejectsEscapingKeys(t *testing.T) {
+	var paths []string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	})
+	b := New(svc, "bucket").WithPrefix("tenant/")
+
+	_, err := b.PutObject("dir/key", strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	for _, key := range []string{"../other/key", "dir/../../other/key", "./key", "/key"} {
+		_, err = b.GetObject(key)
+		assert.ErrorIs(t, err, ErrKeyOutsidePrefix, key)
+	}
+
+	_, err = b.ListObjects("../")
+	assert.ErrorIs(t, err, ErrKeyOutsidePrefix)
+	_, err = b.DeleteObjects([]*s3.ObjectIdentifier{{Key: aws.String("key")}, {Key: aws.String("../key")}})
+	assert.ErrorIs(t, err, ErrKeyOutsidePrefix)
+
+	assert.Equal(t, []string{"/bucket/tenant/dir/key"}, paths, "the invalid requests are not sent")
+}
+
+func TestWithPrefixCopySource(t *testing.T) {
+	var paths []string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch {
+		case r.Method == http.MethodHead:
+			w.Header().Set("Content-Length", "5")
+			w.Header().Set("ETag", `"etag"`)
+		case r.URL.Query().Has("partNumber"):
+			w.Write([]byte(`<CopyPartResult><ETag>"part"</ETag></CopyPartResult>`))
+		default:
+			w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+		}
+	})
+	b := New(svc, "bucket").WithPrefix("tenant/")
+	ctx := aws.BackgroundContext()
+
+	_, err := b.CopyObject("dst", "src")
+	require.NoError(t, err)
+	_, err = b.CopyObjectFrom("dst", "other", "any/key")
+	require.NoError(t, err, "the objects in the other buckets are not checked")
+
+	for _, source := range []string{"bucket/other/key", "/bucket/other/key", "bucket/tenant%2F..%2Fother/key", "bucket/tenant/%zz", "bucket/"} {
+		_, err = b.CopyObject("dst", "src", func(req *s3.CopyObjectInput) {
+			req.CopySource = aws.String(source)
+		})
+		assert.ErrorIs(t, err, ErrKeyOutsidePrefix, source)
+	}
+
+	_, err = b.CopyObjectFrom("dst", "bucket", "other/key")
+	assert.ErrorIs(t, err, ErrKeyOutsidePrefix)
+
+	_, err = b.S3.UploadPartCopyWithContext(ctx, &s3.UploadPartCopyInput{
+		Bucket:     b.Name,
+		Key:        b.key("dst"),
+		CopySource: aws.String("bucket/other/key"),
+		PartNumber: aws.Int64(1),
+		UploadId:   aws.String("id"),
+	}, b.reqOpts...)
+	assert.ErrorIs(t, err, ErrKeyOutsidePrefix)
+
+	assert.Equal(t, []string{"/bucket/tenant/dst", "/bucket/tenant/dst"}, paths, "the invalid requests are not sent")
+}
+
+func TestParseCopySource(t *testing.T) {
+	for _, tc := range []struct {
+		source string
+		bucket string
+		key    string
+		err    bool
+	}{
+		{source: "bucket/dir/key", bucket: "bucket", key: "dir/key"},
+		{source: "/bucket/dir/key", bucket: "bucket", key: "dir/key"},
+		{source: "bucket/dir/a%2Bb%20c?versionId=v1", bucket: "bucket", key: "dir/a+b c"},
+		{source: "bucket/%zz", err: true},
+		{source: "bucket/", err: true},
+		{source: "bucket", err: true},
+		{source: "/key", err: true},
+	} {
+		bucket, key, err := parseCopySource(tc.source)
+		if tc.err {
+			assert.Error(t, err, tc.source)
+			continue
+		}
+		require.NoError(t, err, tc.source)
+		assert.Equal(t, tc.bucket, bucket, tc.source)
+		assert.Equal(t, tc.key, key, tc.source)
+	}
+}
+
+func TestWithPrefixKeyCodec(t *testing.T) {
+	var paths []string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	})
+	codec := keycodec.NewHMAC([]byte("secret"))
+
+	for _, prefix := range []string{"tenant/", "tenant/p"} {
+		paths = nil
+		b := New(svc, "bucket", WithKeyCodec(codec)).WithPrefix(prefix)
+
+		_, err := b.PutObject("dir/a.txt", strings.NewReader("hello"))
+		require.NoError(t, err, prefix)
+		_, err = b.ListObjects("dir/")
+		require.NoError(t, err, prefix)
+
+		for _, key := range []string{"../other/key", "dir/../../other/key", "./key", "/key"} {
+			_, err = b.GetObject(key)
+			assert.ErrorIs(t, err, ErrKeyOutsidePrefix, prefix+key)
+		}
+
+		assert.Len(t, paths, 2, prefix)
+	}
+}
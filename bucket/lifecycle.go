@@ -0,0 +1,149 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// A LifecycleRule builds a s3.LifecycleRule for PutBucketLifecycleConfiguration.
+// The rule is enabled unless Disable is called.
+type LifecycleRule struct {
+	rule   *s3.LifecycleRule
+	prefix string
+	tags   map[string]string
+}
+
+// NewLifecycleRule returns a LifecycleRule named id that applies to the objects under keyPrefix in the bucket.
+func (b *Bucket) NewLifecycleRule(id, keyPrefix string) *LifecycleRule {
+	return &LifecycleRule{
+		rule: &s3.LifecycleRule{
+			ID:     aws.String(id),
+			Status: aws.String(s3.ExpirationStatusEnabled),
+		},
+		prefix: b.objectKey(keyPrefix),
+	}
+}
+
+// Tag restricts the rule to the objects with the tag key=value.
+func (r *LifecycleRule) Tag(key, value string) *LifecycleRule {
+	if r.tags == nil {
+		r.tags = map[string]string{}
+	}
+	r.tags[key] = value
+
+	return r
+}
+
+// Expire deletes the current version of an object days after it is created.
+func (r *LifecycleRule) Expire(days int) *LifecycleRule {
+	r.rule.Expiration = &s3.LifecycleExpiration{Days: aws.Int64(int64(days))}
+
+	return r
+}
+
+// Transition moves an object to storageClass, e.g. s3.TransitionStorageClassGlacier, days after it is created.
+func (r *LifecycleRule) Transition(days int, storageClass string) *LifecycleRule {
+	r.rule.Transitions = append(r.rule.Transitions, &s3.Transition{
+		Days:         aws.Int64(int64(days)),
+		StorageClass: aws.String(storageClass),
+	})
+
+	return r
+}
+
+// ExpireNoncurrent deletes a noncurrent version days after it becomes noncurrent.
+func (r *LifecycleRule) ExpireNoncurrent(days int) *LifecycleRule {
+	r.rule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{NoncurrentDays: aws.Int64(int64(days))}
+
+	return r
+}
+
+// TransitionNoncurrent moves a noncurrent version to storageClass days after it becomes noncurrent.
+func (r *LifecycleRule) TransitionNoncurrent(days int, storageClass string) *LifecycleRule {
+	r.rule.NoncurrentVersionTransitions = append(r.rule.NoncurrentVersionTransitions, &s3.NoncurrentVersionTransition{
+		NoncurrentDays: aws.Int64(int64(days)),
+		StorageClass:   aws.String(storageClass),
+	})
+
+	return r
+}
+
+// AbortIncompleteMultipartUpload aborts a multipart upload not completed within days after it is initiated.
+func (r *LifecycleRule) AbortIncompleteMultipartUpload(days int) *LifecycleRule {
+	r.rule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{DaysAfterInitiation: aws.Int64(int64(days))}
+
+	return r
+}
+
+// Disable disables the rule.
+func (r *LifecycleRule) Disable() *LifecycleRule {
+	r.rule.Status = aws.String(s3.ExpirationStatusDisabled)
+
+	return r
+}
+
+// Rule returns the s3.LifecycleRule built by r.
+func (r *LifecycleRule) Rule() *s3.LifecycleRule {
+	rule := *r.rule
+
+	switch {
+	case len(r.tags) == 0:
+		rule.Filter = &s3.LifecycleRuleFilter{Prefix: aws.String(r.prefix)}
+	case len(r.tags) == 1 && r.prefix == "":
+		rule.Filter = &s3.LifecycleRuleFilter{Tag: TagSet(r.tags)[0]}
+	default:
+		rule.Filter = &s3.LifecycleRuleFilter{And: &s3.LifecycleRuleAndOperator{
+			Prefix: aws.String(r.prefix),
+			Tags:   TagSet(r.tags),
+		}}
+	}
+
+	return &rule
+}
+
+// GetBucketLifecycleConfiguration returns the lifecycle rules of the bucket. It returns nil if the bucket has none.
+func (b *Bucket) GetBucketLifecycleConfiguration() ([]*s3.LifecycleRule, error) {
+	return b.GetBucketLifecycleConfigurationWithContext(aws.BackgroundContext())
+}
+
+// GetBucketLifecycleConfigurationWithContext is the same as GetBucketLifecycleConfiguration with the context ctx.
+func (b *Bucket) GetBucketLifecycleConfigurationWithContext(ctx aws.Context) ([]*s3.LifecycleRule, error) {
+	resp, err := b.S3.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchLifecycleConfiguration" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Rules, nil
+}
+
+// PutBucketLifecycleConfiguration replaces the lifecycle rules of the bucket with rules,
+// e.g. the ones built by NewLifecycleRule.
+func (b *Bucket) PutBucketLifecycleConfiguration(rules ...*s3.LifecycleRule) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+	return b.PutBucketLifecycleConfigurationWithContext(aws.BackgroundContext(), rules...)
+}
+
+// PutBucketLifecycleConfigurationWithContext is the same as PutBucketLifecycleConfiguration with the context ctx.
+func (b *Bucket) PutBucketLifecycleConfigurationWithContext(ctx aws.Context, rules ...*s3.LifecycleRule) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+	return b.S3.PutBucketLifecycleConfigurationWithContext(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 b.Name,
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: rules},
+	}, b.reqOpts...)
+}
+
+// DeleteBucketLifecycle deletes the lifecycle rules of the bucket.
+func (b *Bucket) DeleteBucketLifecycle() (*s3.DeleteBucketLifecycleOutput, error) {
+	return b.DeleteBucketLifecycleWithContext(aws.BackgroundContext())
+}
+
+// DeleteBucketLifecycleWithContext is the same as DeleteBucketLifecycle with the context ctx.
+func (b *Bucket) DeleteBucketLifecycleWithContext(ctx aws.Context) (*s3.DeleteBucketLifecycleOutput, error) {
+	return b.S3.DeleteBucketLifecycleWithContext(ctx, &s3.DeleteBucketLifecycleInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+}
@@ -0,0 +1,70 @@
+package bucket
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycleRule(t *testing.T) {
+	b := New(nil, "bucket").WithPrefix("tenant/")
+
+	rule := b.NewLifecycleRule("logs", "logs/").
+		Transition(30, s3.TransitionStorageClassGlacier).
+		Expire(365).
+		ExpireNoncurrent(7).
+		AbortIncompleteMultipartUpload(1).
+		Rule()
+
+	assert.Equal(t, "tenant/logs/", aws.StringValue(rule.Filter.Prefix))
+	assert.Equal(t, s3.ExpirationStatusEnabled, aws.StringValue(rule.Status))
+	assert.Equal(t, int64(365), aws.Int64Value(rule.Expiration.Days))
+	assert.Equal(t, int64(30), aws.Int64Value(rule.Transitions[0].Days))
+	assert.Equal(t, int64(7), aws.Int64Value(rule.NoncurrentVersionExpiration.NoncurrentDays))
+	assert.Equal(t, int64(1), aws.Int64Value(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation))
+	require.NoError(t, rule.Validate())
+
+	rule = b.NewLifecycleRule("tmp", "").Tag("temporary", "true").Expire(1).Disable().Rule()
+	assert.Equal(t, "tenant/", aws.StringValue(rule.Filter.And.Prefix))
+	assert.Equal(t, map[string]string{"temporary": "true"}, TagMap(rule.Filter.And.Tags))
+	assert.Equal(t, s3.ExpirationStatusDisabled, aws.StringValue(rule.Status))
+
+	rule = New(nil, "bucket").NewLifecycleRule("tmp", "").Tag("temporary", "true").Expire(1).Rule()
+	assert.Equal(t, "temporary", aws.StringValue(rule.Filter.Tag.Key))
+}
+
+func TestBucketLifecycleConfiguration(t *testing.T) {
+	var ids []string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			var conf struct {
+				Rules []struct{ ID string } `xml:"Rule"`
+			}
+			require.NoError(t, xml.NewDecoder(r.Body).Decode(&conf))
+			for _, rule := range conf.Rules {
+				ids = append(ids, rule.ID)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<Error><Code>NoSuchLifecycleConfiguration</Code></Error>`))
+		}
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.PutBucketLifecycleConfiguration(
+		b.NewLifecycleRule("a", "a/").Expire(1).Rule(),
+		b.NewLifecycleRule("b", "b/").Expire(2).Rule(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, ids)
+
+	rules, err := b.GetBucketLifecycleConfiguration()
+	require.NoError(t, err)
+	assert.Nil(t, rules)
+}
@@ -0,0 +1,48 @@
+package bucket
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// ErrTooManyObjects is returned by ListAllObjects when the listing exceeds the limit set by WithMaxListObjects.
+var ErrTooManyObjects = errors.New("bucket: too many objects to list")
+
+// WithMaxListObjects returns an Option that makes ListAllObjects fail with ErrTooManyObjects
+// instead of returning more than n objects.
+func WithMaxListObjects(n int) Option {
+	return func(b *Bucket) {
+		b.maxListObjects = n
+	}
+}
+
+// ListAllObjects returns all the objects with the given prefix across all pages in the order of the keys.
+// The listing stops as soon as it exceeds the limit set by WithMaxListObjects.
+func (b *Bucket) ListAllObjects(ctx aws.Context, prefix string, opts ...option.ListObjectsV2Input) ([]*s3.Object, error) {
+	var (
+		objects  []*s3.Object
+		exceeded bool
+	)
+
+	err := b.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		objects = append(objects, page.Contents...)
+		if b.maxListObjects > 0 && len(objects) > b.maxListObjects {
+			exceeded = true
+			return false
+		}
+
+		return true
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if exceeded {
+		return nil, fmt.Errorf("%w: %s has more than %d objects", ErrTooManyObjects, prefix, b.maxListObjects)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,44 @@
+package bucket
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAllObjects(t *testing.T) {
+	var requests int
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		// 3 pages of 2 objects
+		page, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+		fmt.Fprint(w, `<ListBucketResult>`)
+		if page < 2 {
+			fmt.Fprintf(w, `<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>`, page+1)
+		}
+		for i := 0; i < 2; i++ {
+			fmt.Fprintf(w, `<Contents><Key>key%d</Key></Contents>`, page*2+i)
+		}
+		fmt.Fprint(w, `</ListBucketResult>`)
+	})
+
+	objects, err := New(svc, "bucket").ListAllObjects(aws.BackgroundContext(), "")
+	require.NoError(t, err)
+	require.Len(t, objects, 6)
+	assert.Equal(t, "key5", aws.StringValue(objects[5].Key))
+
+	requests = 0
+	_, err = New(svc, "bucket", WithMaxListObjects(3)).ListAllObjects(aws.BackgroundContext(), "")
+	assert.ErrorIs(t, err, ErrTooManyObjects)
+	assert.Equal(t, 2, requests, "the listing stops at the limit")
+
+	objects, err = New(svc, "bucket", WithMaxListObjects(6)).ListAllObjects(aws.BackgroundContext(), "")
+	require.NoError(t, err)
+	assert.Len(t, objects, 6)
+}
@@ -0,0 +1,101 @@
+package bucket
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// A LogEntry describes an S3 call made through a Bucket with WithLogger.
+type LogEntry struct {
+	Bucket    string
+	Operation string
+
+	// Key is the object key of the call or "" if the operation has none.
+	Key string
+
+	// Duration is the time from the creation of the request to its completion, including the retries.
+	Duration time.Duration
+
+	// StatusCode is the HTTP status code of the last response. It is zero if no response is received.
+	StatusCode int
+
+	RequestID string
+	Retries   int
+
+	// Err is the error of the call or nil if it succeeds.
+	Err error
+}
+
+// A Logger receives a LogEntry for every S3 call. It must be safe for concurrent use.
+type Logger interface {
+	LogRequest(ctx aws.Context, e LogEntry)
+}
+
+// WithLogger returns an Option that logs every S3 call made through the Bucket to l when it completes.
+// Presigned requests are not logged.
+func WithLogger(l Logger) Option {
+	return func(b *Bucket) {
+		name := aws.StringValue(b.Name)
+
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			start := time.Now()
+
+			r.Handlers.Complete.PushBack(func(r *request.Request) {
+				e := LogEntry{
+					Bucket:    name,
+					Operation: r.Operation.Name,
+					Key:       paramsKey(r.Params),
+					Duration:  time.Since(start),
+					RequestID: r.RequestID,
+					Retries:   r.RetryCount,
+					Err:       r.Error,
+				}
+
+				if r.HTTPResponse != nil {
+					e.StatusCode = r.HTTPResponse.StatusCode
+				}
+
+				l.LogRequest(r.Context(), e)
+			})
+		})
+	}
+}
+
+// SlogLogger returns a Logger that writes the entries to l, or to slog.Default() if l is nil.
+// The calls that succeed are logged at the info level and the others at the error level.
+func SlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) LogRequest(ctx aws.Context, e LogEntry) {
+	l := s.l
+	if l == nil {
+		l = slog.Default()
+	}
+
+	attrs := []slog.Attr{
+		slog.String("bucket", e.Bucket),
+		slog.String("operation", e.Operation),
+		slog.Duration("duration", e.Duration),
+		slog.Int("status", e.StatusCode),
+		slog.String("request_id", e.RequestID),
+		slog.Int("retries", e.Retries),
+	}
+	if e.Key != "" {
+		attrs = append(attrs, slog.String("key", e.Key))
+	}
+
+	if e.Err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "s3 request failed", append(attrs, slog.Any("error", e.Err))...)
+		return
+	}
+
+	l.LogAttrs(ctx, slog.LevelInfo, "s3 request", attrs...)
+}
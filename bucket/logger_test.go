@@ -0,0 +1,49 @@
+package bucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogger(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-amz-request-id", "req-1")
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`))
+		}
+	})
+
+	var buf bytes.Buffer
+	b := New(svc, "bucket", WithLogger(SlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))))
+
+	_, err := b.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	_, err = b.GetObject("missing")
+	require.Error(t, err)
+
+	dec := json.NewDecoder(&buf)
+
+	var put, get map[string]interface{}
+	require.NoError(t, dec.Decode(&put))
+	require.NoError(t, dec.Decode(&get))
+
+	assert.Equal(t, "INFO", put["level"])
+	assert.Equal(t, "PutObject", put["operation"])
+	assert.Equal(t, "key", put["key"])
+	assert.Equal(t, "req-1", put["request_id"])
+	assert.Equal(t, float64(200), put["status"])
+	assert.Equal(t, float64(0), put["retries"])
+
+	assert.Equal(t, "ERROR", get["level"])
+	assert.Equal(t, "GetObject", get["operation"])
+	assert.Equal(t, float64(404), get["status"])
+	assert.Contains(t, get["error"], "NoSuchKey")
+}
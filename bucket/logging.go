@@ -0,0 +1,58 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// EnableAccessLogging enables server access logging on the bucket. The logs are delivered to the bucket targetBucket
+// under targetPrefix, e.g. "logs/bucket/", and grants give others access to the delivered log objects.
+// targetBucket must allow the logging service to write to it.
+func (b *Bucket) EnableAccessLogging(targetBucket, targetPrefix string, grants ...*s3.TargetGrant) (*s3.PutBucketLoggingOutput, error) {
+	return b.EnableAccessLoggingWithContext(aws.BackgroundContext(), targetBucket, targetPrefix, grants...)
+}
+
+// EnableAccessLoggingWithContext is the same as EnableAccessLogging with the context ctx.
+func (b *Bucket) EnableAccessLoggingWithContext(ctx aws.Context, targetBucket, targetPrefix string, grants ...*s3.TargetGrant) (*s3.PutBucketLoggingOutput, error) {
+	return b.putLogging(ctx, &s3.LoggingEnabled{
+		TargetBucket: aws.String(targetBucket),
+		TargetPrefix: aws.String(targetPrefix),
+		TargetGrants: grants,
+	})
+}
+
+// DisableAccessLogging disables server access logging on the bucket.
+func (b *Bucket) DisableAccessLogging() (*s3.PutBucketLoggingOutput, error) {
+	return b.DisableAccessLoggingWithContext(aws.BackgroundContext())
+}
+
+// DisableAccessLoggingWithContext is the same as DisableAccessLogging with the context ctx.
+func (b *Bucket) DisableAccessLoggingWithContext(ctx aws.Context) (*s3.PutBucketLoggingOutput, error) {
+	return b.putLogging(ctx, nil)
+}
+
+func (b *Bucket) putLogging(ctx aws.Context, logging *s3.LoggingEnabled) (*s3.PutBucketLoggingOutput, error) {
+	return b.S3.PutBucketLoggingWithContext(ctx, &s3.PutBucketLoggingInput{
+		Bucket: b.Name,
+		BucketLoggingStatus: &s3.BucketLoggingStatus{
+			LoggingEnabled: logging,
+		},
+	}, b.reqOpts...)
+}
+
+// GetAccessLogging returns the server access logging configuration of the bucket. It returns nil if logging is disabled.
+func (b *Bucket) GetAccessLogging() (*s3.LoggingEnabled, error) {
+	return b.GetAccessLoggingWithContext(aws.BackgroundContext())
+}
+
+// GetAccessLoggingWithContext is the same as GetAccessLogging with the context ctx.
+func (b *Bucket) GetAccessLoggingWithContext(ctx aws.Context) (*s3.LoggingEnabled, error) {
+	resp, err := b.S3.GetBucketLoggingWithContext(ctx, &s3.GetBucketLoggingInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.LoggingEnabled, nil
+}
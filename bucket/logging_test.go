@@ -0,0 +1,47 @@
+package bucket
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogging(t *testing.T) {
+	var stored []byte
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			stored, _ = io.ReadAll(r.Body)
+			return
+		}
+		w.Write(stored)
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.EnableAccessLogging("logs", "bucket/", &s3.TargetGrant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeCanonicalUser), ID: aws.String("auditor")},
+		Permission: aws.String(s3.BucketLogsPermissionRead),
+	})
+	require.NoError(t, err)
+
+	logging, err := b.GetAccessLogging()
+	require.NoError(t, err)
+	require.NotNil(t, logging)
+	assert.Equal(t, "logs", aws.StringValue(logging.TargetBucket))
+	assert.Equal(t, "bucket/", aws.StringValue(logging.TargetPrefix))
+	require.Len(t, logging.TargetGrants, 1)
+	assert.Equal(t, "auditor", aws.StringValue(logging.TargetGrants[0].Grantee.ID))
+
+	_, err = b.DisableAccessLogging()
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(stored, []byte("LoggingEnabled")))
+
+	logging, err = b.GetAccessLogging()
+	require.NoError(t, err)
+	assert.Nil(t, logging)
+}
@@ -0,0 +1,77 @@
+package bucket
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// UpdateObjectMetadata replaces the user-defined metadata of key with the result of mutate by copying the object onto itself.
+// mutate receives a copy of the current metadata. Content-Type and the other system metadata, tags, server-side encryption
+// and the storage class are carried over since they are otherwise reset by a copy with MetadataDirective=REPLACE.
+// The copy fails if the object is modified after its metadata is read. The ACL of the object is not carried over.
+// The metadata is left as it is if mutate is nil. opts are applied after the metadata is carried over, e.g. to change
+// Content-Type with option.CopyContentType.
+func (b *Bucket) UpdateObjectMetadata(ctx aws.Context, key string, mutate func(map[string]string) map[string]string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	head, err := b.S3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := b.selfCopyInput(key, head)
+	if mutate != nil {
+		md := make(map[string]string, len(head.Metadata))
+		for k, v := range head.Metadata {
+			md[k] = aws.StringValue(v)
+		}
+
+		req.Metadata = aws.StringMap(mutate(md))
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.CopyObjectWithContext(ctx, req, b.reqOpts...)
+}
+
+// selfCopyInput returns the input of CopyObject copying key onto itself with MetadataDirective=REPLACE.
+// The metadata, the system metadata, server-side encryption and the storage class in head are carried over.
+// The copy fails if the ETag of the object is no longer the one in head.
+func (b *Bucket) selfCopyInput(key string, head *s3.HeadObjectOutput) *s3.CopyObjectInput {
+	req := &s3.CopyObjectInput{
+		Bucket:                  b.Name,
+		Key:                     b.key(key),
+		CopySource:              aws.String(copySource(aws.StringValue(b.Name), b.objectKey(key))),
+		CopySourceIfMatch:       head.ETag,
+		MetadataDirective:       aws.String(s3.MetadataDirectiveReplace),
+		TaggingDirective:        aws.String(s3.TaggingDirectiveCopy),
+		Metadata:                head.Metadata,
+		ContentType:             head.ContentType,
+		ContentEncoding:         head.ContentEncoding,
+		ContentDisposition:      head.ContentDisposition,
+		ContentLanguage:         head.ContentLanguage,
+		CacheControl:            head.CacheControl,
+		WebsiteRedirectLocation: head.WebsiteRedirectLocation,
+		ServerSideEncryption:    head.ServerSideEncryption,
+		SSEKMSKeyId:             head.SSEKMSKeyId,
+		BucketKeyEnabled:        head.BucketKeyEnabled,
+		StorageClass:            head.StorageClass,
+	}
+
+	if expires, err := http.ParseTime(aws.StringValue(head.Expires)); err == nil {
+		req.Expires = aws.Time(expires)
+	}
+
+	return req
+}
+
+// ReplaceObjectMetadata replaces the user-defined metadata of key with md as UpdateObjectMetadata.
+func (b *Bucket) ReplaceObjectMetadata(ctx aws.Context, key string, md map[string]string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	return b.UpdateObjectMetadata(ctx, key, func(map[string]string) map[string]string { return md }, opts...)
+}
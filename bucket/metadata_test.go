@@ -0,0 +1,44 @@
+package bucket
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceObjectMetadata(t *testing.T) {
+	var copied http.Header
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("ETag", `"etag"`)
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("x-amz-meta-owner", "alice")
+			return
+		}
+
+		copied = r.Header.Clone()
+		w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.ReplaceObjectMetadata(aws.BackgroundContext(), "a b", map[string]string{"team": "infra"}, option.CopyContentType("text/plain"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "bucket/a%20b", copied.Get("X-Amz-Copy-Source"))
+	assert.Equal(t, `"etag"`, copied.Get("X-Amz-Copy-Source-If-Match"))
+	assert.Equal(t, "REPLACE", copied.Get("X-Amz-Metadata-Directive"))
+	assert.Equal(t, "text/plain", copied.Get("Content-Type"))
+	assert.Equal(t, "no-cache", copied.Get("Cache-Control"))
+	assert.Equal(t, "infra", copied.Get("X-Amz-Meta-Team"))
+	assert.Empty(t, copied.Get("X-Amz-Meta-Owner"))
+
+	_, err = b.UpdateObjectMetadata(aws.BackgroundContext(), "a b", nil, option.CopyContentType("text/csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", copied.Get("Content-Type"))
+	assert.Equal(t, "alice", copied.Get("X-Amz-Meta-Owner"))
+}
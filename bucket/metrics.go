@@ -0,0 +1,78 @@
+package bucket
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// A Metric describes an S3 call made through a Bucket with WithMetrics.
+type Metric struct {
+	Bucket    string
+	Operation string
+
+	// Duration is the time from the creation of the request to its completion, including the retries.
+	Duration time.Duration
+
+	// Attempts is the number of HTTP requests sent, including the retries.
+	Attempts int
+
+	// BytesSent is the size of the request body and BytesReceived is Content-Length of the response.
+	BytesSent     int64
+	BytesReceived int64
+
+	// StatusCode is the HTTP status code of the last response. It is zero if no response is received.
+	StatusCode int
+
+	// ErrorCode is the S3 error code, e.g. "NoSuchKey", or the SDK error code, e.g. "RequestCanceled".
+	// It is empty if the call succeeds.
+	ErrorCode string
+}
+
+// A MetricsSink receives a Metric for every S3 call. It must be safe for concurrent use.
+// The prommetrics package provides one for Prometheus.
+type MetricsSink interface {
+	ObserveRequest(m Metric)
+}
+
+// WithMetrics returns an Option that reports every S3 call made through the Bucket to sink when it completes.
+// Presigned requests are not reported.
+func WithMetrics(sink MetricsSink) Option {
+	return func(b *Bucket) {
+		name := aws.StringValue(b.Name)
+
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			start := time.Now()
+
+			r.Handlers.Complete.PushBack(func(r *request.Request) {
+				m := Metric{
+					Bucket:    name,
+					Operation: r.Operation.Name,
+					Duration:  time.Since(start),
+					Attempts:  r.RetryCount + 1,
+				}
+
+				if r.HTTPRequest != nil && r.HTTPRequest.ContentLength > 0 {
+					m.BytesSent = r.HTTPRequest.ContentLength
+				}
+
+				if r.HTTPResponse != nil {
+					m.StatusCode = r.HTTPResponse.StatusCode
+					if r.HTTPResponse.ContentLength > 0 {
+						m.BytesReceived = r.HTTPResponse.ContentLength
+					}
+				}
+
+				if aerr, ok := r.Error.(awserr.Error); ok {
+					m.ErrorCode = aerr.Code()
+				} else if r.Error != nil {
+					m.ErrorCode = "Unknown"
+				}
+
+				sink.ObserveRequest(m)
+			})
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package bucket
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type metricsRecorder []Metric
+
+func (r *metricsRecorder) ObserveRequest(m Metric) {
+	*r = append(*r, m)
+}
+
+func TestWithMetrics(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`))
+			return
+		}
+	})
+
+	var rec metricsRecorder
+	b := New(svc, "bucket", WithMetrics(&rec))
+
+	_, err := b.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	_, err = b.GetObject("missing")
+	require.Error(t, err)
+
+	require.Len(t, rec, 2)
+
+	assert.Equal(t, "bucket", rec[0].Bucket)
+	assert.Equal(t, "PutObject", rec[0].Operation)
+	assert.Equal(t, 1, rec[0].Attempts)
+	assert.Equal(t, int64(5), rec[0].BytesSent)
+	assert.Equal(t, http.StatusOK, rec[0].StatusCode)
+	assert.Empty(t, rec[0].ErrorCode)
+
+	assert.Equal(t, "GetObject", rec[1].Operation)
+	assert.Equal(t, http.StatusNotFound, rec[1].StatusCode)
+	assert.Equal(t, "NoSuchKey", rec[1].ErrorCode)
+}
@@ -0,0 +1,172 @@
+package bucket
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// ErrMoveNotVerified is returned by MoveObject when the copied object does not have the size of the source object.
+// The source object is not deleted in that case.
+var ErrMoveNotVerified = errors.New("bucket: the copied object does not match the source object")
+
+// ErrMoveToSelf is returned by MoveObject when dest and src are the same object. Nothing is copied or deleted.
+var ErrMoveToSelf = errors.New("bucket: the source and the destination of the move are the same object")
+
+// MoveObject moves src to dest by copying src to dest, verifying the size of dest and deleting src.
+// The copy is made with CopyObjectMultipart so an object larger than 5 GiB is moved as well, and it fails if src
+// is modified after it is read. src is kept if the copy or the verification fails.
+func (b *Bucket) MoveObject(dest, src string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	return b.MoveObjectWithContext(aws.BackgroundContext(), dest, src, opts...)
+}
+
+// MoveObjectWithContext is the same as MoveObject with the context ctx.
+func (b *Bucket) MoveObjectWithContext(ctx aws.Context, dest, src string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	// A multipart copy onto itself succeeds and src would be deleted.
+	if aws.StringValue(b.key(dest)) == aws.StringValue(b.key(src)) {
+		return nil, ErrMoveToSelf
+	}
+
+	head, err := b.HeadObjectWithContext(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]option.CopyObjectInput{func(req *s3.CopyObjectInput) {
+		req.CopySourceIfMatch = head.ETag
+	}}, opts...)
+
+	resp, err := b.CopyObjectMultipart(ctx, dest, src, MultipartCopyOptions{}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	copied, err := b.HeadObjectWithContext(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+	if aws.Int64Value(copied.ContentLength) != aws.Int64Value(head.ContentLength) {
+		return nil, ErrMoveNotVerified
+	}
+
+	if _, err := b.DeleteObjectWithContext(ctx, src); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// A RenameResult is the result of RenamePrefix.
+type RenameResult struct {
+	// Moved is the new keys of the moved objects in the order of the keys.
+	Moved []string
+
+	// Errors is the objects that failed to be moved in the order of the source keys.
+	Errors []*MoveError
+}
+
+// Err returns *RenamePrefixError if any object failed to be moved, and nil otherwise.
+func (r *RenameResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+
+	return &RenamePrefixError{Errors: r.Errors}
+}
+
+// A MoveError reports an object that RenamePrefix failed to move.
+type MoveError struct {
+	Src  string
+	Dest string
+	Err  error
+}
+
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("bucket: failed to move %s to %s: %v", e.Src, e.Dest, e.Err)
+}
+
+func (e *MoveError) Unwrap() error {
+	return e.Err
+}
+
+// A RenamePrefixError reports the objects that RenamePrefix failed to move.
+type RenamePrefixError struct {
+	Errors []*MoveError
+}
+
+func (e *RenamePrefixError) Error() string {
+	return fmt.Sprintf("bucket: failed to move %d objects: %v", len(e.Errors), e.Errors[0])
+}
+
+func (e *RenamePrefixError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// RenamePrefix moves every object under oldPrefix to the same key under newPrefix with MoveObject,
+// moving concurrency objects at the same time. The objects that fail to be moved are collected in the result
+// and the others are still moved. An error is returned only if the listing fails, in which case the result holds
+// the objects done so far.
+//
+// newPrefix must not be under oldPrefix since the moved objects would be listed again.
+func (b *Bucket) RenamePrefix(ctx aws.Context, oldPrefix, newPrefix string, concurrency int) (*RenameResult, error) {
+	if strings.HasPrefix(newPrefix, oldPrefix) {
+		return nil, fmt.Errorf("bucket: cannot rename %q to %q under it", oldPrefix, newPrefix)
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		result = &RenameResult{}
+		sem    = make(chan struct{}, concurrency)
+		lerr   error
+	)
+
+	for o, err := range b.Objects(ctx, oldPrefix) {
+		if err != nil {
+			lerr = err
+			break
+		}
+
+		src := aws.StringValue(o.Key)
+		dest := newPrefix + strings.TrimPrefix(src, oldPrefix)
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := b.MoveObjectWithContext(ctx, dest, src)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors = append(result.Errors, &MoveError{Src: src, Dest: dest, Err: err})
+				return
+			}
+			result.Moved = append(result.Moved, dest)
+		}()
+	}
+
+	wg.Wait()
+
+	sort.Strings(result.Moved)
+	sort.Slice(result.Errors, func(i, j int) bool { return result.Errors[i].Src < result.Errors[j].Src })
+
+	return result, lerr
+}
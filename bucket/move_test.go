@@ -0,0 +1,116 @@
+package bucket
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMoveTestBucket returns a Bucket backed by an in-memory bucket with objects where copying to a key with "denied" fails.
+func newMoveTestBucket(t *testing.T, objects map[string]string) *Bucket {
+	var mu sync.Mutex
+
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+			keys := make([]string, 0, len(objects))
+			for k := range objects {
+				if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			fmt.Fprint(w, `<ListBucketResult>`)
+			for _, k := range keys {
+				fmt.Fprintf(w, `<Contents><Key>%s</Key><Size>%d</Size></Contents>`, k, len(objects[k]))
+			}
+			fmt.Fprint(w, `</ListBucketResult>`)
+		case r.Method == http.MethodHead:
+			v, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Length", fmt.Sprint(len(v)))
+			w.Header().Set("ETag", `"`+v+`"`)
+		case r.Method == http.MethodPut:
+			if strings.Contains(key, "denied") {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+				return
+			}
+
+			src, err := url.PathUnescape(strings.TrimPrefix(r.Header.Get("X-Amz-Copy-Source"), "bucket/"))
+			require.NoError(t, err)
+			assert.Equal(t, `"`+objects[src]+`"`, r.Header.Get("X-Amz-Copy-Source-If-Match"))
+
+			objects[key] = objects[src]
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+		case r.Method == http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	return New(svc, "bucket")
+}
+
+func TestMoveObject(t *testing.T) {
+	objects := map[string]string{"src": "data"}
+	b := newMoveTestBucket(t, objects)
+
+	_, err := b.MoveObject("dest", "src")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"dest": "data"}, objects)
+
+	_, err = b.MoveObject("denied", "dest")
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	assert.Equal(t, map[string]string{"dest": "data"}, objects, "the source is kept")
+
+	_, err = b.MoveObject("dest", "dest")
+	assert.ErrorIs(t, err, ErrMoveToSelf)
+	_, err = b.WithPrefix("de").MoveObject("st", "st")
+	assert.ErrorIs(t, err, ErrMoveToSelf)
+	assert.Equal(t, map[string]string{"dest": "data"}, objects, "the source is kept")
+}
+
+func TestRenamePrefix(t *testing.T) {
+	objects := map[string]string{
+		"old/a":        "a",
+		"old/sub/b":    "b",
+		"old/denied/c": "c",
+		"other":        "other",
+	}
+	b := newMoveTestBucket(t, objects)
+
+	result, err := b.RenamePrefix(aws.BackgroundContext(), "old/", "new/", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new/a", "new/sub/b"}, result.Moved)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "old/denied/c", result.Errors[0].Src)
+	assert.Equal(t, "new/denied/c", result.Errors[0].Dest)
+	assert.ErrorIs(t, result.Err(), ErrAccessDenied)
+
+	assert.Equal(t, map[string]string{
+		"new/a":        "a",
+		"new/sub/b":    "b",
+		"old/denied/c": "c",
+		"other":        "other",
+	}, objects)
+
+	_, err = b.RenamePrefix(aws.BackgroundContext(), "new/", "new/moved/", 2)
+	assert.Error(t, err)
+}
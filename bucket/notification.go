@@ -0,0 +1,159 @@
+package bucket
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// A Notification builds an event notification for PutNotificationConfiguration.
+// Exactly one of Topic, Queue and Lambda must be called to set the destination.
+type Notification struct {
+	b      *Bucket
+	id     string
+	events []*string
+	rules  []*s3.FilterRule
+
+	topic, queue, lambda string
+}
+
+// NewNotification returns a Notification named id that is sent for events, e.g. s3.EventS3ObjectCreated.
+// It is sent for every key in the bucket, or under the prefix of the view returned by WithPrefix, unless Prefix or Suffix is called.
+func (b *Bucket) NewNotification(id string, events ...string) *Notification {
+	n := &Notification{
+		b:      b,
+		id:     id,
+		events: aws.StringSlice(events),
+	}
+
+	if b.prefix != "" {
+		n.rules = append(n.rules, &s3.FilterRule{
+			Name:  aws.String(s3.FilterRuleNamePrefix),
+			Value: aws.String(b.objectKey("")),
+		})
+	}
+
+	return n
+}
+
+// Prefix restricts the notification to the keys under keyPrefix in the bucket.
+func (n *Notification) Prefix(keyPrefix string) *Notification {
+	n.setRule(s3.FilterRuleNamePrefix, n.b.objectKey(keyPrefix))
+
+	return n
+}
+
+// Suffix restricts the notification to the keys ending with suffix, e.g. ".jpg".
+func (n *Notification) Suffix(suffix string) *Notification {
+	n.setRule(s3.FilterRuleNameSuffix, suffix)
+
+	return n
+}
+
+func (n *Notification) setRule(name, value string) {
+	for _, r := range n.rules {
+		if aws.StringValue(r.Name) == name {
+			r.Value = aws.String(value)
+			return
+		}
+	}
+
+	n.rules = append(n.rules, &s3.FilterRule{Name: aws.String(name), Value: aws.String(value)})
+}
+
+// Topic sends the notification to the SNS topic topicARN.
+func (n *Notification) Topic(topicARN string) *Notification {
+	n.topic, n.queue, n.lambda = topicARN, "", ""
+
+	return n
+}
+
+// Queue sends the notification to the SQS queue queueARN.
+func (n *Notification) Queue(queueARN string) *Notification {
+	n.topic, n.queue, n.lambda = "", queueARN, ""
+
+	return n
+}
+
+// Lambda invokes the Lambda function functionARN with the notification.
+func (n *Notification) Lambda(functionARN string) *Notification {
+	n.topic, n.queue, n.lambda = "", "", functionARN
+
+	return n
+}
+
+// filter returns the filter of the notification or nil if it has no rules.
+func (n *Notification) filter() *s3.NotificationConfigurationFilter {
+	if len(n.rules) == 0 {
+		return nil
+	}
+
+	return &s3.NotificationConfigurationFilter{Key: &s3.KeyFilter{FilterRules: n.rules}}
+}
+
+// NotificationConfiguration returns the s3.NotificationConfiguration with notifications.
+func NotificationConfiguration(notifications ...*Notification) (*s3.NotificationConfiguration, error) {
+	conf := &s3.NotificationConfiguration{}
+
+	for _, n := range notifications {
+		switch {
+		case n.topic != "":
+			conf.TopicConfigurations = append(conf.TopicConfigurations, &s3.TopicConfiguration{
+				Id:       aws.String(n.id),
+				Events:   n.events,
+				Filter:   n.filter(),
+				TopicArn: aws.String(n.topic),
+			})
+		case n.queue != "":
+			conf.QueueConfigurations = append(conf.QueueConfigurations, &s3.QueueConfiguration{
+				Id:       aws.String(n.id),
+				Events:   n.events,
+				Filter:   n.filter(),
+				QueueArn: aws.String(n.queue),
+			})
+		case n.lambda != "":
+			conf.LambdaFunctionConfigurations = append(conf.LambdaFunctionConfigurations, &s3.LambdaFunctionConfiguration{
+				Id:                aws.String(n.id),
+				Events:            n.events,
+				Filter:            n.filter(),
+				LambdaFunctionArn: aws.String(n.lambda),
+			})
+		default:
+			return nil, fmt.Errorf("bucket: notification %q has no destination", n.id)
+		}
+	}
+
+	return conf, nil
+}
+
+// PutNotificationConfiguration replaces the event notifications of the bucket with notifications.
+// The bucket has no notifications if notifications is empty.
+func (b *Bucket) PutNotificationConfiguration(notifications ...*Notification) (*s3.PutBucketNotificationConfigurationOutput, error) {
+	return b.PutNotificationConfigurationWithContext(aws.BackgroundContext(), notifications...)
+}
+
+// PutNotificationConfigurationWithContext is the same as PutNotificationConfiguration with the context ctx.
+func (b *Bucket) PutNotificationConfigurationWithContext(ctx aws.Context, notifications ...*Notification) (*s3.PutBucketNotificationConfigurationOutput, error) {
+	conf, err := NotificationConfiguration(notifications...)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.S3.PutBucketNotificationConfigurationWithContext(ctx, &s3.PutBucketNotificationConfigurationInput{
+		Bucket:                    b.Name,
+		NotificationConfiguration: conf,
+	}, b.reqOpts...)
+}
+
+// GetNotificationConfiguration returns the event notifications of the bucket.
+func (b *Bucket) GetNotificationConfiguration() (*s3.NotificationConfiguration, error) {
+	return b.GetNotificationConfigurationWithContext(aws.BackgroundContext())
+}
+
+// GetNotificationConfigurationWithContext is the same as GetNotificationConfiguration with the context ctx.
+func (b *Bucket) GetNotificationConfigurationWithContext(ctx aws.Context) (*s3.NotificationConfiguration, error) {
+	return b.S3.GetBucketNotificationConfigurationWithContext(ctx, &s3.GetBucketNotificationConfigurationRequest{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+}
@@ -0,0 +1,34 @@
+package bucket
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationConfiguration(t *testing.T) {
+	b := New(nil, "bucket").WithPrefix("tenant/")
+
+	conf, err := NotificationConfiguration(
+		b.NewNotification("images", s3.EventS3ObjectCreated).Prefix("images/").Suffix(".jpg").Lambda("arn:aws:lambda:us-east-1:123456789012:function:thumb"),
+		b.NewNotification("deletes", s3.EventS3ObjectRemoved).Queue("arn:aws:sqs:us-east-1:123456789012:deletes"),
+	)
+	require.NoError(t, err)
+	require.NoError(t, conf.Validate())
+
+	require.Len(t, conf.LambdaFunctionConfigurations, 1)
+	rules := conf.LambdaFunctionConfigurations[0].Filter.Key.FilterRules
+	require.Len(t, rules, 2)
+	assert.Equal(t, "tenant/images/", aws.StringValue(rules[0].Value))
+	assert.Equal(t, ".jpg", aws.StringValue(rules[1].Value))
+
+	require.Len(t, conf.QueueConfigurations, 1)
+	assert.Equal(t, "tenant/", aws.StringValue(conf.QueueConfigurations[0].Filter.Key.FilterRules[0].Value))
+	assert.Empty(t, conf.TopicConfigurations)
+
+	_, err = NotificationConfiguration(b.NewNotification("none", s3.EventS3ObjectCreated))
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package bucket
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// GetObjectRetention returns the Object Lock retention of the object for key.
+func (b *Bucket) GetObjectRetention(key string, opts ...option.GetObjectRetentionInput) (*s3.ObjectLockRetention, error) {
+	return b.GetObjectRetentionWithContext(aws.BackgroundContext(), key, opts...)
+}
+
+// GetObjectRetentionWithContext is the same as GetObjectRetention with the context ctx.
+func (b *Bucket) GetObjectRetentionWithContext(ctx aws.Context, key string, opts ...option.GetObjectRetentionInput) (*s3.ObjectLockRetention, error) {
+	req := &s3.GetObjectRetentionInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	resp, err := b.S3.GetObjectRetentionWithContext(ctx, req, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Retention, nil
+}
+
+// PutObjectRetention locks the object for key in mode, s3.ObjectLockRetentionModeGovernance or
+// s3.ObjectLockRetentionModeCompliance, until until.
+func (b *Bucket) PutObjectRetention(key, mode string, until time.Time, opts ...option.PutObjectRetentionInput) (*s3.PutObjectRetentionOutput, error) {
+	return b.PutObjectRetentionWithContext(aws.BackgroundContext(), key, mode, until, opts...)
+}
+
+// PutObjectRetentionWithContext is the same as PutObjectRetention with the context ctx.
+func (b *Bucket) PutObjectRetentionWithContext(ctx aws.Context, key, mode string, until time.Time, opts ...option.PutObjectRetentionInput) (*s3.PutObjectRetentionOutput, error) {
+	req := &s3.PutObjectRetentionInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+		Retention: &s3.ObjectLockRetention{
+			Mode:            aws.String(mode),
+			RetainUntilDate: aws.Time(until),
+		},
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.PutObjectRetentionWithContext(ctx, req, b.reqOpts...)
+}
+
+// GetObjectLegalHold reports whether a legal hold is placed on the object for key.
+func (b *Bucket) GetObjectLegalHold(key string, opts ...option.GetObjectLegalHoldInput) (bool, error) {
+	return b.GetObjectLegalHoldWithContext(aws.BackgroundContext(), key, opts...)
+}
+
+// GetObjectLegalHoldWithContext is the same as GetObjectLegalHold with the context ctx.
+func (b *Bucket) GetObjectLegalHoldWithContext(ctx aws.Context, key string, opts ...option.GetObjectLegalHoldInput) (bool, error) {
+	req := &s3.GetObjectLegalHoldInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	resp, err := b.S3.GetObjectLegalHoldWithContext(ctx, req, b.reqOpts...)
+	if err != nil {
+		return false, err
+	}
+
+	return resp.LegalHold != nil && aws.StringValue(resp.LegalHold.Status) == s3.ObjectLockLegalHoldStatusOn, nil
+}
+
+// PutObjectLegalHold places a legal hold on the object for key if on is true, or removes it otherwise.
+func (b *Bucket) PutObjectLegalHold(key string, on bool, opts ...option.PutObjectLegalHoldInput) (*s3.PutObjectLegalHoldOutput, error) {
+	return b.PutObjectLegalHoldWithContext(aws.BackgroundContext(), key, on, opts...)
+}
+
+// PutObjectLegalHoldWithContext is the same as PutObjectLegalHold with the context ctx.
+func (b *Bucket) PutObjectLegalHoldWithContext(ctx aws.Context, key string, on bool, opts ...option.PutObjectLegalHoldInput) (*s3.PutObjectLegalHoldOutput, error) {
+	status := s3.ObjectLockLegalHoldStatusOff
+	if on {
+		status = s3.ObjectLockLegalHoldStatusOn
+	}
+
+	req := &s3.PutObjectLegalHoldInput{
+		Bucket:    b.Name,
+		Key:       b.key(key),
+		LegalHold: &s3.ObjectLockLegalHold{Status: aws.String(status)},
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.PutObjectLegalHoldWithContext(ctx, req, b.reqOpts...)
+}
+
+// GetObjectLockConfiguration returns the Object Lock configuration of the bucket.
+func (b *Bucket) GetObjectLockConfiguration() (*s3.ObjectLockConfiguration, error) {
+	return b.GetObjectLockConfigurationWithContext(aws.BackgroundContext())
+}
+
+// GetObjectLockConfigurationWithContext is the same as GetObjectLockConfiguration with the context ctx.
+func (b *Bucket) GetObjectLockConfigurationWithContext(ctx aws.Context) (*s3.ObjectLockConfiguration, error) {
+	resp, err := b.S3.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.ObjectLockConfiguration, nil
+}
@@ -0,0 +1,59 @@
+package bucket
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectLock(t *testing.T) {
+	var (
+		body   string
+		header http.Header
+	)
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPut:
+			data, _ := ioutil.ReadAll(r.Body)
+			body, header = string(data), r.Header
+		case q.Has("retention"):
+			w.Write([]byte(`<Retention><Mode>COMPLIANCE</Mode><RetainUntilDate>2030-01-01T00:00:00Z</RetainUntilDate></Retention>`))
+		case q.Has("legal-hold"):
+			w.Write([]byte(`<LegalHold><Status>ON</Status></LegalHold>`))
+		}
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.PutObjectLegalHold("key", true, option.PutLegalHoldVersionID("v1"))
+	require.NoError(t, err)
+	assert.Contains(t, body, "<Status>ON</Status>")
+	assert.NotEmpty(t, header.Get("Content-MD5"))
+
+	on, err := b.GetObjectLegalHold("key")
+	require.NoError(t, err)
+	assert.True(t, on)
+
+	retention, err := b.GetObjectRetention("key")
+	require.NoError(t, err)
+	assert.Equal(t, s3.ObjectLockRetentionModeCompliance, aws.StringValue(retention.Mode))
+	assert.Equal(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), aws.TimeValue(retention.RetainUntilDate))
+
+	_, err = b.PutObject("key", strings.NewReader("hello"),
+		option.ObjectLockMode(s3.ObjectLockModeGovernance),
+		option.ObjectLockRetainUntilDate(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+		option.ContentMD5(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", body)
+	assert.Equal(t, "XUFAKrxLKna5cZ2REBfFkg==", header.Get("Content-MD5"))
+	assert.Equal(t, "GOVERNANCE", header.Get("X-Amz-Object-Lock-Mode"))
+}
@@ -0,0 +1,38 @@
+package bucket
+
+import "strings"
+
+// An OperationClass is a set of S3 operations that an Option can be scoped to.
+type OperationClass int
+
+// Operation classes. They can be combined with bitwise OR.
+const (
+	// ReadOperations are operations that read objects or their metadata, e.g. GetObject and HeadObject.
+	ReadOperations OperationClass = 1 << iota
+
+	// WriteOperations are operations that modify the bucket, e.g. PutObject, CopyObject and DeleteObject.
+	WriteOperations
+
+	// ListOperations are operations that list the bucket, e.g. ListObjectsV2 and ListObjectVersions.
+	ListOperations
+
+	// AllOperations matches every operation.
+	AllOperations = ReadOperations | WriteOperations | ListOperations
+)
+
+// Contains reports whether the operation named op belongs to c.
+func (c OperationClass) Contains(op string) bool {
+	return c&classify(op) != 0
+}
+
+// classify returns the OperationClass for the S3 operation named op.
+func classify(op string) OperationClass {
+	switch {
+	case strings.HasPrefix(op, "List"):
+		return ListOperations
+	case strings.HasPrefix(op, "Get"), strings.HasPrefix(op, "Head"), strings.HasPrefix(op, "Select"):
+		return ReadOperations
+	default:
+		return WriteOperations
+	}
+}
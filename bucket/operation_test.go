@@ -0,0 +1,37 @@
+package bucket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	for op, want := range map[string]OperationClass{
+		"GetObject":               ReadOperations,
+		"GetObjectTagging":        ReadOperations,
+		"HeadObject":              ReadOperations,
+		"HeadBucket":              ReadOperations,
+		"SelectObjectContent":     ReadOperations,
+		"ListObjectsV2":           ListOperations,
+		"ListObjectVersions":      ListOperations,
+		"ListParts":               ListOperations,
+		"ListMultipartUploads":    ListOperations,
+		"PutObject":               WriteOperations,
+		"CopyObject":              WriteOperations,
+		"DeleteObjects":           WriteOperations,
+		"CreateMultipartUpload":   WriteOperations,
+		"UploadPart":              WriteOperations,
+		"UploadPartCopy":          WriteOperations,
+		"CompleteMultipartUpload": WriteOperations,
+		"AbortMultipartUpload":    WriteOperations,
+		"RestoreObject":           WriteOperations,
+	} {
+		t.Run(op, func(t *testing.T) {
+			assert.Equal(t, want, classify(op))
+			assert.True(t, want.Contains(op))
+			assert.True(t, AllOperations.Contains(op))
+			assert.False(t, (AllOperations &^ want).Contains(op))
+		})
+	}
+}
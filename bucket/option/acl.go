@@ -0,0 +1,74 @@
+package option
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// The GetObjectACLInput type is an adapter to change a parameter in
+// s3.GetObjectAclInput.
+type GetObjectACLInput func(req *s3.GetObjectAclInput)
+
+// The PutObjectACLInput type is an adapter to change a parameter in
+// s3.PutObjectAclInput.
+type PutObjectACLInput func(req *s3.PutObjectAclInput)
+
+// AllUsers is the URI of the group of anyone on the internet for GranteeURI.
+const AllUsers = "http://acs.amazonaws.com/groups/global/AllUsers"
+
+// AuthenticatedUsers is the URI of the group of any AWS account for GranteeURI.
+const AuthenticatedUsers = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+
+// GranteeID returns the grantee for the grant options by the canonical user ID of an AWS account.
+func GranteeID(canonicalID string) string {
+	return `id="` + canonicalID + `"`
+}
+
+// GranteeURI returns the grantee for the grant options by the URI of a group, e.g. AllUsers.
+func GranteeURI(uri string) string {
+	return `uri="` + uri + `"`
+}
+
+// ACLVersionID returns a GetObjectACLInput that gets the ACL of the version versionID.
+func ACLVersionID(versionID string) GetObjectACLInput {
+	return func(req *s3.GetObjectAclInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
+
+// PutACLVersionID returns a PutObjectACLInput that sets the ACL of the version versionID.
+func PutACLVersionID(versionID string) PutObjectACLInput {
+	return func(req *s3.PutObjectAclInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
+
+// GrantRead returns a PutObjectACLInput that allows grantees, e.g. GranteeID("..."), to read the object.
+func GrantRead(grantees ...string) PutObjectACLInput {
+	return func(req *s3.PutObjectAclInput) {
+		req.GrantRead = aws.String(strings.Join(grantees, ", "))
+	}
+}
+
+// GrantReadACP returns a PutObjectACLInput that allows grantees to read the ACL of the object.
+func GrantReadACP(grantees ...string) PutObjectACLInput {
+	return func(req *s3.PutObjectAclInput) {
+		req.GrantReadACP = aws.String(strings.Join(grantees, ", "))
+	}
+}
+
+// GrantWriteACP returns a PutObjectACLInput that allows grantees to change the ACL of the object.
+func GrantWriteACP(grantees ...string) PutObjectACLInput {
+	return func(req *s3.PutObjectAclInput) {
+		req.GrantWriteACP = aws.String(strings.Join(grantees, ", "))
+	}
+}
+
+// GrantFullControl returns a PutObjectACLInput that gives grantees every permission on the object.
+func GrantFullControl(grantees ...string) PutObjectACLInput {
+	return func(req *s3.PutObjectAclInput) {
+		req.GrantFullControl = aws.String(strings.Join(grantees, ", "))
+	}
+}
@@ -0,0 +1,35 @@
+package option
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// The GetObjectAttributesInput type is an adapter to change a parameter in
+// s3.GetObjectAttributesInput.
+type GetObjectAttributesInput func(req *s3.GetObjectAttributesInput)
+
+// Attributes returns a GetObjectAttributesInput that requests only attrs, e.g. s3.ObjectAttributesChecksum,
+// instead of all the attributes.
+func Attributes(attrs ...string) GetObjectAttributesInput {
+	return func(req *s3.GetObjectAttributesInput) {
+		req.ObjectAttributes = aws.StringSlice(attrs)
+	}
+}
+
+// AttributesVersionID returns a GetObjectAttributesInput that gets the attributes of the version versionID
+// instead of the current version.
+func AttributesVersionID(versionID string) GetObjectAttributesInput {
+	return func(req *s3.GetObjectAttributesInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
+
+// AttributesParts returns a GetObjectAttributesInput that returns up to maxParts parts after the part number marker
+// in ObjectParts.
+func AttributesParts(marker, maxParts int64) GetObjectAttributesInput {
+	return func(req *s3.GetObjectAttributesInput) {
+		req.PartNumberMarker = aws.Int64(marker)
+		req.MaxParts = aws.Int64(maxParts)
+	}
+}
@@ -0,0 +1,41 @@
+package option
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// The CreateBucketInput type is an adapter to change a parameter in
+// s3.CreateBucketInput.
+type CreateBucketInput func(req *s3.CreateBucketInput)
+
+// LocationConstraint returns a CreateBucketInput that creates the bucket in region.
+func LocationConstraint(region string) CreateBucketInput {
+	return func(req *s3.CreateBucketInput) {
+		req.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(region),
+		}
+	}
+}
+
+// ObjectLockEnabledForBucket returns a CreateBucketInput that enables Object Lock on the bucket.
+func ObjectLockEnabledForBucket() CreateBucketInput {
+	return func(req *s3.CreateBucketInput) {
+		req.ObjectLockEnabledForBucket = aws.Bool(true)
+	}
+}
+
+// ObjectOwnership returns a CreateBucketInput that sets the object ownership of the bucket,
+// e.g. s3.ObjectOwnershipBucketOwnerEnforced.
+func ObjectOwnership(ownership string) CreateBucketInput {
+	return func(req *s3.CreateBucketInput) {
+		req.ObjectOwnership = aws.String(ownership)
+	}
+}
+
+// CreateBucketACL returns a CreateBucketInput that sets the canned ACL of the bucket.
+func CreateBucketACL(acl string) CreateBucketInput {
+	return func(req *s3.CreateBucketInput) {
+		req.ACL = aws.String(acl)
+	}
+}
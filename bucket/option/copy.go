@@ -1,6 +1,8 @@
 package option
 
 import (
+	"net/url"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
@@ -16,3 +18,36 @@ func CopySSEKMSKeyID(keyID string) CopyObjectInput {
 		req.ServerSideEncryption = aws.String("aws:kms")
 	}
 }
+
+// CopySourceVersionID returns a CopyObjectInput that copies the version versionID of the source object
+// instead of the current version.
+func CopySourceVersionID(versionID string) CopyObjectInput {
+	return func(req *s3.CopyObjectInput) {
+		req.CopySource = aws.String(aws.StringValue(req.CopySource) + "?versionId=" + url.QueryEscape(versionID))
+	}
+}
+
+// CopyMetadataDirective returns a CopyObjectInput that sets MetadataDirective to directive,
+// s3.MetadataDirectiveCopy or s3.MetadataDirectiveReplace.
+func CopyMetadataDirective(directive string) CopyObjectInput {
+	return func(req *s3.CopyObjectInput) {
+		req.MetadataDirective = aws.String(directive)
+	}
+}
+
+// CopyMetadata returns a CopyObjectInput that replaces the user-defined metadata of the destination object with md.
+// It sets MetadataDirective to REPLACE, which also resets the system metadata such as Content-Type unless they are set.
+func CopyMetadata(md map[string]string) CopyObjectInput {
+	return func(req *s3.CopyObjectInput) {
+		req.Metadata = aws.StringMap(md)
+		req.MetadataDirective = aws.String(s3.MetadataDirectiveReplace)
+	}
+}
+
+// CopyContentType returns a CopyObjectInput that sets Content-Type of the destination object.
+// It takes effect only if MetadataDirective is REPLACE.
+func CopyContentType(ct string) CopyObjectInput {
+	return func(req *s3.CopyObjectInput) {
+		req.ContentType = aws.String(ct)
+	}
+}
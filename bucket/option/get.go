@@ -1,7 +1,79 @@
 package option
 
-import "github.com/aws/aws-sdk-go/service/s3"
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
 
 // The GetObjectInput type is an adapter to change a parameter in
 // s3.GetObjectInput.
 type GetObjectInput func(req *s3.GetObjectInput)
+
+// GetVersionID returns a GetObjectInput that gets the version versionID instead of the current version.
+func GetVersionID(versionID string) GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
+
+// ChecksumMode returns a GetObjectInput that makes S3 return the additional checksum stored with the object.
+func ChecksumMode() GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		req.ChecksumMode = aws.String(s3.ChecksumModeEnabled)
+	}
+}
+
+// GetIfMatch returns a GetObjectInput that gets the object only if its ETag is etag.
+// Otherwise GetObject fails with an error matching bucket.ErrPreconditionFailed.
+func GetIfMatch(etag string) GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		req.IfMatch = aws.String(etag)
+	}
+}
+
+// GetIfNoneMatch returns a GetObjectInput that gets the object only if its ETag is not etag.
+// Otherwise GetObject fails with an error matching bucket.ErrNotModified.
+func GetIfNoneMatch(etag string) GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		req.IfNoneMatch = aws.String(etag)
+	}
+}
+
+// GetIfModifiedSince returns a GetObjectInput that gets the object only if it has been modified after t.
+// Otherwise GetObject fails with an error matching bucket.ErrNotModified.
+func GetIfModifiedSince(t time.Time) GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		req.IfModifiedSince = aws.Time(t)
+	}
+}
+
+// GetIfUnmodifiedSince returns a GetObjectInput that gets the object only if it has not been modified after t.
+// Otherwise GetObject fails with an error matching bucket.ErrPreconditionFailed.
+func GetIfUnmodifiedSince(t time.Time) GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		req.IfUnmodifiedSince = aws.Time(t)
+	}
+}
+
+// Range returns a GetObjectInput that gets the bytes from start to end inclusive.
+// A negative end gets the bytes from start to the end of the object.
+func Range(start, end int64) GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		if end < 0 {
+			req.Range = aws.String(fmt.Sprintf("bytes=%d-", start))
+			return
+		}
+
+		req.Range = aws.String(fmt.Sprintf("bytes=%d-%d", start, end))
+	}
+}
+
+// GetExpectedBucketOwner returns a GetObjectInput that fails GetObject unless the bucket is owned by the account accountID.
+func GetExpectedBucketOwner(accountID string) GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		req.ExpectedBucketOwner = aws.String(accountID)
+	}
+}
@@ -1,7 +1,51 @@
 package option
 
-import "github.com/aws/aws-sdk-go/service/s3"
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
 
 // The HeadObjectInput type is an adapter to change a parameter in
 // s3.HeadObjectInput.
 type HeadObjectInput func(req *s3.HeadObjectInput)
+
+// HeadChecksumMode returns a HeadObjectInput that makes S3 return the additional checksum stored with the object.
+func HeadChecksumMode() HeadObjectInput {
+	return func(req *s3.HeadObjectInput) {
+		req.ChecksumMode = aws.String(s3.ChecksumModeEnabled)
+	}
+}
+
+// HeadIfMatch returns a HeadObjectInput that succeeds only if the ETag of the object is etag.
+// Otherwise HeadObject fails with an error matching bucket.ErrPreconditionFailed.
+func HeadIfMatch(etag string) HeadObjectInput {
+	return func(req *s3.HeadObjectInput) {
+		req.IfMatch = aws.String(etag)
+	}
+}
+
+// HeadIfNoneMatch returns a HeadObjectInput that succeeds only if the ETag of the object is not etag.
+// Otherwise HeadObject fails with an error matching bucket.ErrNotModified.
+func HeadIfNoneMatch(etag string) HeadObjectInput {
+	return func(req *s3.HeadObjectInput) {
+		req.IfNoneMatch = aws.String(etag)
+	}
+}
+
+// HeadIfModifiedSince returns a HeadObjectInput that succeeds only if the object has been modified after t.
+// Otherwise HeadObject fails with an error matching bucket.ErrNotModified.
+func HeadIfModifiedSince(t time.Time) HeadObjectInput {
+	return func(req *s3.HeadObjectInput) {
+		req.IfModifiedSince = aws.Time(t)
+	}
+}
+
+// HeadIfUnmodifiedSince returns a HeadObjectInput that succeeds only if the object has not been modified after t.
+// Otherwise HeadObject fails with an error matching bucket.ErrPreconditionFailed.
+func HeadIfUnmodifiedSince(t time.Time) HeadObjectInput {
+	return func(req *s3.HeadObjectInput) {
+		req.IfUnmodifiedSince = aws.Time(t)
+	}
+}
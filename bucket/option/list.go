@@ -40,3 +40,11 @@ func ListMarker(marker string) ListObjectsInput {
 		req.Marker = aws.String(marker)
 	}
 }
+
+// ListStartAfter returns a ListObjectsV2Input that changes a StartAfter in
+// s3.ListObjectsV2Input.
+func ListStartAfter(key string) ListObjectsV2Input {
+	return func(req *s3.ListObjectsV2Input) {
+		req.StartAfter = aws.String(key)
+	}
+}
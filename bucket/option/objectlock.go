@@ -0,0 +1,91 @@
+package option
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// The GetObjectRetentionInput type is an adapter to change a parameter in
+// s3.GetObjectRetentionInput.
+type GetObjectRetentionInput func(req *s3.GetObjectRetentionInput)
+
+// The PutObjectRetentionInput type is an adapter to change a parameter in
+// s3.PutObjectRetentionInput.
+type PutObjectRetentionInput func(req *s3.PutObjectRetentionInput)
+
+// The GetObjectLegalHoldInput type is an adapter to change a parameter in
+// s3.GetObjectLegalHoldInput.
+type GetObjectLegalHoldInput func(req *s3.GetObjectLegalHoldInput)
+
+// The PutObjectLegalHoldInput type is an adapter to change a parameter in
+// s3.PutObjectLegalHoldInput.
+type PutObjectLegalHoldInput func(req *s3.PutObjectLegalHoldInput)
+
+// ObjectLockMode returns a PutObjectInput that sets the Object Lock mode, s3.ObjectLockModeGovernance or
+// s3.ObjectLockModeCompliance. It must be used with ObjectLockRetainUntilDate.
+// S3 requires Content-MD5 for a PutObject with the Object Lock parameters. See ContentMD5.
+func ObjectLockMode(mode string) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ObjectLockMode = aws.String(mode)
+	}
+}
+
+// ObjectLockRetainUntilDate returns a PutObjectInput that sets the date until which the object is locked.
+func ObjectLockRetainUntilDate(until time.Time) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ObjectLockRetainUntilDate = aws.Time(until)
+	}
+}
+
+// ObjectLockLegalHold returns a PutObjectInput that places a legal hold on the object if on is true.
+func ObjectLockLegalHold(on bool) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ObjectLockLegalHoldStatus = aws.String(legalHoldStatus(on))
+	}
+}
+
+// RetentionVersionID returns a GetObjectRetentionInput that gets the retention of the version versionID.
+func RetentionVersionID(versionID string) GetObjectRetentionInput {
+	return func(req *s3.GetObjectRetentionInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
+
+// PutRetentionVersionID returns a PutObjectRetentionInput that sets the retention of the version versionID.
+func PutRetentionVersionID(versionID string) PutObjectRetentionInput {
+	return func(req *s3.PutObjectRetentionInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
+
+// BypassGovernanceRetention returns a PutObjectRetentionInput that allows shortening or removing a retention
+// in the governance mode. It requires s3:BypassGovernanceRetention.
+func BypassGovernanceRetention() PutObjectRetentionInput {
+	return func(req *s3.PutObjectRetentionInput) {
+		req.BypassGovernanceRetention = aws.Bool(true)
+	}
+}
+
+// LegalHoldVersionID returns a GetObjectLegalHoldInput that gets the legal hold of the version versionID.
+func LegalHoldVersionID(versionID string) GetObjectLegalHoldInput {
+	return func(req *s3.GetObjectLegalHoldInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
+
+// PutLegalHoldVersionID returns a PutObjectLegalHoldInput that sets the legal hold of the version versionID.
+func PutLegalHoldVersionID(versionID string) PutObjectLegalHoldInput {
+	return func(req *s3.PutObjectLegalHoldInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
+
+func legalHoldStatus(on bool) string {
+	if on {
+		return s3.ObjectLockLegalHoldStatusOn
+	}
+
+	return s3.ObjectLockLegalHoldStatusOff
+}
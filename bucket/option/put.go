@@ -1,8 +1,16 @@
 package option
 
 import (
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/ioutils"
 )
 
 // The PutObjectInput type is an adapter to change a parameter in
@@ -51,3 +59,76 @@ func ContentLength(length int64) PutObjectInput {
 		req.ContentLength = aws.Int64(length)
 	}
 }
+
+// WebsiteRedirectLocation returns a PutObjectInput that set the location a website endpoint redirects requests
+// for the object to, e.g. "/new/page.html" or "https://example.com/".
+func WebsiteRedirectLocation(location string) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.WebsiteRedirectLocation = aws.String(location)
+	}
+}
+
+// ContentTypeAuto returns a PutObjectInput that set Content-Type detected from the extension of the key or,
+// if the extension is unknown, from the first 512 bytes of the body. Content-Type that is already set is kept
+// so ContentTypeAuto should be placed after ContentType.
+func ContentTypeAuto() PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		if req.ContentType != nil {
+			return
+		}
+
+		if ct := mime.TypeByExtension(path.Ext(aws.StringValue(req.Key))); ct != "" {
+			req.ContentType = aws.String(ct)
+			return
+		}
+
+		if req.Body == nil {
+			return
+		}
+
+		head, err := ioutils.Peek(req.Body, 512)
+		if err != nil {
+			return
+		}
+
+		req.ContentType = aws.String(http.DetectContentType(head))
+	}
+}
+
+// ChecksumAlgorithm returns a PutObjectInput that makes the SDK compute the checksum of the body with algorithm,
+// e.g. s3.ChecksumAlgorithmSha256, and S3 store it with the object.
+func ChecksumAlgorithm(algorithm string) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ChecksumAlgorithm = aws.String(algorithm)
+	}
+}
+
+// ContentMD5 returns a PutObjectInput that sets Content-MD5 computed from the body.
+// The body is read to the end and rewound to where it was. Content-MD5 that is already set is kept.
+func ContentMD5() PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		if req.Body == nil || req.ContentMD5 != nil {
+			return
+		}
+
+		pos, err := req.Body.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return
+		}
+
+		h := md5.New()
+		_, err = io.Copy(h, req.Body)
+		if _, serr := req.Body.Seek(pos, io.SeekStart); err != nil || serr != nil {
+			return
+		}
+
+		req.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	}
+}
+
+// ExpectedBucketOwner returns a PutObjectInput that fails the upload unless the bucket is owned by the account accountID.
+func ExpectedBucketOwner(accountID string) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ExpectedBucketOwner = aws.String(accountID)
+	}
+}
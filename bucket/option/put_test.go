@@ -0,0 +1,40 @@
+package option
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentTypeAuto(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		key         string
+		body        io.ReadSeeker
+		contentType *string
+		want        *string
+	}{
+		{name: "extension", key: "dir/a.json", body: strings.NewReader("{}"), want: aws.String("application/json")},
+		{name: "sniffed", key: "dir/a", body: strings.NewReader("%PDF-1.7"), want: aws.String("application/pdf")},
+		{name: "kept", key: "dir/a.json", body: strings.NewReader("{}"), contentType: aws.String("text/plain"), want: aws.String("text/plain")},
+		{name: "no body", key: "dir/a"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &s3.PutObjectInput{Key: aws.String(tc.key), Body: tc.body, ContentType: tc.contentType}
+			ContentTypeAuto()(req)
+			assert.Equal(t, tc.want, req.ContentType)
+
+			if tc.body != nil {
+				// the sniffed bytes are not consumed
+				pos, err := tc.body.Seek(0, io.SeekCurrent)
+				require.NoError(t, err)
+				assert.Zero(t, pos)
+			}
+		})
+	}
+}
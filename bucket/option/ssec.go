@@ -0,0 +1,60 @@
+package option
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// sseCustomerKey returns the algorithm, the key and the MD5 of the key for the SSE-C parameters.
+// The SDK encodes the key in base64 when it sends it.
+func sseCustomerKey(key []byte) (algorithm, k, keyMD5 *string) {
+	sum := md5.Sum(key)
+
+	return aws.String(s3.ServerSideEncryptionAes256), aws.String(string(key)), aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// SSECustomerKey returns a PutObjectInput that encrypts the object with SSE-C using the 256-bit key.
+// The same key must be given to read the object. SSE-C requests must be sent over HTTPS.
+func SSECustomerKey(key []byte) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.SSECustomerAlgorithm, req.SSECustomerKey, req.SSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+// GetSSECustomerKey returns a GetObjectInput that decrypts the object encrypted with SSE-C using key.
+func GetSSECustomerKey(key []byte) GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		req.SSECustomerAlgorithm, req.SSECustomerKey, req.SSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+// HeadSSECustomerKey returns a HeadObjectInput for the object encrypted with SSE-C using key.
+func HeadSSECustomerKey(key []byte) HeadObjectInput {
+	return func(req *s3.HeadObjectInput) {
+		req.SSECustomerAlgorithm, req.SSECustomerKey, req.SSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+// CopySSECustomerKey returns a CopyObjectInput that encrypts the copy with SSE-C using key.
+func CopySSECustomerKey(key []byte) CopyObjectInput {
+	return func(req *s3.CopyObjectInput) {
+		req.SSECustomerAlgorithm, req.SSECustomerKey, req.SSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+// CopySourceSSECustomerKey returns a CopyObjectInput that decrypts the source object encrypted with SSE-C using key.
+func CopySourceSSECustomerKey(key []byte) CopyObjectInput {
+	return func(req *s3.CopyObjectInput) {
+		req.CopySourceSSECustomerAlgorithm, req.CopySourceSSECustomerKey, req.CopySourceSSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+// AttributesSSECustomerKey returns a GetObjectAttributesInput for the object encrypted with SSE-C using key.
+func AttributesSSECustomerKey(key []byte) GetObjectAttributesInput {
+	return func(req *s3.GetObjectAttributesInput) {
+		req.SSECustomerAlgorithm, req.SSECustomerKey, req.SSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
@@ -0,0 +1,44 @@
+package option
+
+import (
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// The GetObjectTaggingInput type is an adapter to change a parameter in
+// s3.GetObjectTaggingInput.
+type GetObjectTaggingInput func(req *s3.GetObjectTaggingInput)
+
+// The PutObjectTaggingInput type is an adapter to change a parameter in
+// s3.PutObjectTaggingInput.
+type PutObjectTaggingInput func(req *s3.PutObjectTaggingInput)
+
+// Tagging returns a PutObjectInput that sets the tags of the object.
+func Tagging(tags map[string]string) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		v := url.Values{}
+		for k, val := range tags {
+			v.Set(k, val)
+		}
+
+		req.Tagging = aws.String(v.Encode())
+	}
+}
+
+// TaggingVersionID returns a GetObjectTaggingInput that gets the tags of the version versionID
+// instead of the current version.
+func TaggingVersionID(versionID string) GetObjectTaggingInput {
+	return func(req *s3.GetObjectTaggingInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
+
+// PutTaggingVersionID returns a PutObjectTaggingInput that sets the tags of the version versionID
+// instead of the current version.
+func PutTaggingVersionID(versionID string) PutObjectTaggingInput {
+	return func(req *s3.PutObjectTaggingInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
@@ -0,0 +1,24 @@
+package option
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// The PutBucketVersioningInput type is an adapter to change a parameter in
+// s3.PutBucketVersioningInput.
+type PutBucketVersioningInput func(req *s3.PutBucketVersioningInput)
+
+// MFADelete returns a PutBucketVersioningInput that enables or disables MFA delete.
+// serial is the serial number of the MFA device of the root account and code is the current code displayed on it.
+func MFADelete(enabled bool, serial, code string) PutBucketVersioningInput {
+	return func(req *s3.PutBucketVersioningInput) {
+		status := s3.MFADeleteDisabled
+		if enabled {
+			status = s3.MFADeleteEnabled
+		}
+
+		req.MFA = aws.String(serial + " " + code)
+		req.VersioningConfiguration.MFADelete = aws.String(status)
+	}
+}
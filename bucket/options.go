@@ -0,0 +1,160 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// An Option configures a Bucket in New.
+type Option func(b *Bucket)
+
+// WithAppName returns an Option that appends "name/version" to the User-Agent of every request made through the Bucket.
+func WithAppName(name, version string) Option {
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, request.WithAppendUserAgent(name+"/"+version))
+	}
+}
+
+// WithHeaders returns an Option that sets the HTTP headers h on every request made through the Bucket.
+func WithHeaders(h map[string]string) Option {
+	return WithOperationHeaders(AllOperations, h)
+}
+
+// WithOperationHeaders returns an Option that sets the HTTP headers h on requests for the operations in class.
+// The headers are set after the request is built so they are covered by the signature.
+func WithOperationHeaders(class OperationClass, h map[string]string) Option {
+	headers := make(map[string]string, len(h))
+	for k, v := range h {
+		headers[k] = v
+	}
+
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			if !class.Contains(r.Operation.Name) {
+				return
+			}
+
+			r.Handlers.Build.PushBack(func(r *request.Request) {
+				for k, v := range headers {
+					r.HTTPRequest.Header.Set(k, v)
+				}
+			})
+		})
+	}
+}
+
+// WithContentTypeAuto returns an Option that applies option.ContentTypeAuto to every object written by PutObject, its
+// variants and the multipart uploads of the Bucket that does not set Content-Type. The type is detected from the key
+// passed by the caller rather than the key encoded by WithKeyCodec.
+func WithContentTypeAuto() Option {
+	return func(b *Bucket) {
+		b.contentTypeAuto = true
+	}
+}
+
+// WithContentMD5 returns an Option that applies option.ContentMD5 to every PutObject and UploadPart made through the Bucket
+// that does not set Content-MD5, e.g. for a bucket policy that requires it. The SDK computes Content-MD5 by itself only
+// if S3DisableContentMD5Validation is not set on the client and the request is not presigned.
+func WithContentMD5() Option {
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			switch in := r.Params.(type) {
+			case *s3.PutObjectInput:
+				r.Handlers.Validate.PushFront(func(r *request.Request) {
+					option.ContentMD5()(in)
+				})
+			case *s3.UploadPartInput:
+				r.Handlers.Validate.PushFront(func(r *request.Request) {
+					put := &s3.PutObjectInput{Body: in.Body, ContentMD5: in.ContentMD5}
+					option.ContentMD5()(put)
+					in.ContentMD5 = put.ContentMD5
+				})
+			}
+		})
+	}
+}
+
+// WithDefaultPutOptions returns an Option that applies opts to every object uploaded through the Bucket,
+// including multipart and presigned uploads, before the options given to each call.
+// PresignPostPolicy applies the ones that have a form field. See PresignPostPolicy.
+// It is useful for organization-wide defaults such as SSE, the storage class or the expected bucket owner.
+func WithDefaultPutOptions(opts ...option.PutObjectInput) Option {
+	return func(b *Bucket) {
+		b.defaultPutOpts = append(b.defaultPutOpts, opts...)
+	}
+}
+
+// WithDefaultGetOptions returns an Option that applies opts to every GetObject made through the Bucket,
+// including presigned ones, before the options given to each call.
+func WithDefaultGetOptions(opts ...option.GetObjectInput) Option {
+	return func(b *Bucket) {
+		b.defaultGetOpts = append(b.defaultGetOpts, opts...)
+	}
+}
+
+// WithDefaultCopyOptions returns an Option that applies opts to every object copied through the Bucket,
+// including multipart copies, before the options given to each call.
+func WithDefaultCopyOptions(opts ...option.CopyObjectInput) Option {
+	return func(b *Bucket) {
+		b.defaultCopyOpts = append(b.defaultCopyOpts, opts...)
+	}
+}
+
+// WithOptions returns a view of the bucket with opts applied in addition to the options of b, e.g. to add default
+// options to a view returned by WithPrefix. The request options of opts are applied after those of b, and the retry
+// budget and its statistics stay shared with b.
+func (b *Bucket) WithOptions(opts ...Option) *Bucket {
+	view := *b
+	view.reqOpts = append([]request.Option(nil), b.reqOpts...)
+	view.defaultPutOpts = append([]option.PutObjectInput(nil), b.defaultPutOpts...)
+	view.defaultGetOpts = append([]option.GetObjectInput(nil), b.defaultGetOpts...)
+	view.defaultCopyOpts = append([]option.CopyObjectInput(nil), b.defaultCopyOpts...)
+
+	for _, f := range opts {
+		f(&view)
+	}
+
+	return &view
+}
+
+// putOptions returns opts following the default options set by WithDefaultPutOptions.
+func (b *Bucket) putOptions(opts []option.PutObjectInput) []option.PutObjectInput {
+	if len(b.defaultPutOpts) == 0 {
+		return opts
+	}
+
+	return append(append([]option.PutObjectInput(nil), b.defaultPutOpts...), opts...)
+}
+
+// applyPutOptions applies opts following the default options to req for key. The options see key as the caller
+// passed it, e.g. option.ContentTypeAuto under WithKeyCodec, and req.Key is set to the key stored in S3 afterwards.
+func (b *Bucket) applyPutOptions(req *s3.PutObjectInput, key string, opts []option.PutObjectInput) {
+	req.Key = aws.String(key)
+	for _, f := range b.putOptions(opts) {
+		f(req)
+	}
+	if b.contentTypeAuto {
+		option.ContentTypeAuto()(req)
+	}
+	req.Key = b.key(key)
+}
+
+// getOptions returns opts following the default options set by WithDefaultGetOptions.
+func (b *Bucket) getOptions(opts []option.GetObjectInput) []option.GetObjectInput {
+	if len(b.defaultGetOpts) == 0 {
+		return opts
+	}
+
+	return append(append([]option.GetObjectInput(nil), b.defaultGetOpts...), opts...)
+}
+
+// copyOptions returns opts following the default options set by WithDefaultCopyOptions.
+func (b *Bucket) copyOptions(opts []option.CopyObjectInput) []option.CopyObjectInput {
+	if len(b.defaultCopyOpts) == 0 {
+		return opts
+	}
+
+	return append(append([]option.CopyObjectInput(nil), b.defaultCopyOpts...), opts...)
+}
@@ -0,0 +1,179 @@
+package bucket
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/nabeken/aws-go-s3/keycodec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAppName(t *testing.T) {
+	var userAgent string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+	})
+
+	_, err := New(svc, "bucket", WithAppName("myapp", "1.2.3")).HeadObject("key")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(userAgent, "aws-sdk-go/"), userAgent)
+	assert.True(t, strings.HasSuffix(userAgent, " myapp/1.2.3"), userAgent)
+
+	_, err = New(svc, "bucket").HeadObject("key")
+	require.NoError(t, err)
+	assert.NotContains(t, userAgent, "myapp")
+}
+
+func TestWithOperationHeaders(t *testing.T) {
+	headers := map[string]http.Header{}
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/bucket":
+			headers["list"] = r.Header
+			w.Write([]byte(`<ListBucketResult></ListBucketResult>`))
+		case r.Method == http.MethodGet:
+			headers["get"] = r.Header
+		case r.Method == http.MethodPut:
+			headers["put"] = r.Header
+		}
+	})
+
+	h := map[string]string{"X-Trace": "abc"}
+	b := New(svc, "bucket",
+		WithOperationHeaders(WriteOperations|ListOperations, h),
+		WithHeaders(map[string]string{"X-Tenant": "t1"}),
+	)
+	// the map of the caller is copied
+	h["X-Trace"] = "changed"
+
+	_, err := b.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	_, err = b.GetObject("key")
+	require.NoError(t, err)
+	_, err = b.ListObjects("")
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc", headers["put"].Get("X-Trace"))
+	assert.Equal(t, "abc", headers["list"].Get("X-Trace"))
+	assert.Empty(t, headers["get"].Get("X-Trace"), "GetObject is not a write or a list")
+
+	for _, name := range []string{"put", "get", "list"} {
+		assert.Equal(t, "t1", headers[name].Get("X-Tenant"), name)
+		assert.Contains(t, headers[name].Get("Authorization"), "x-tenant", "the header is signed")
+	}
+}
+
+func TestWithContentMD5(t *testing.T) {
+	var md5Header string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		md5Header = r.Header.Get("Content-Md5")
+	})
+	// the SDK sets Content-MD5 by itself unless this is set
+	svc.Config.S3DisableContentMD5Validation = aws.Bool(true)
+
+	_, err := New(svc, "bucket").PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	require.Empty(t, md5Header)
+
+	b := New(svc, "bucket", WithContentMD5())
+
+	_, err = b.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	sum := md5.Sum([]byte("hello"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), md5Header)
+}
+
+func TestWithDefaultOptions(t *testing.T) {
+	var header http.Header
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	})
+
+	b := New(svc, "bucket",
+		WithDefaultPutOptions(option.SSES3(), option.ACLPrivate(), option.ExpectedBucketOwner("123456789012")),
+		WithDefaultGetOptions(option.GetExpectedBucketOwner("123456789012")),
+	)
+
+	_, err := b.PutObject("key", strings.NewReader("hello"), option.ACLPublicRead())
+	require.NoError(t, err)
+	assert.Equal(t, "AES256", header.Get("X-Amz-Server-Side-Encryption"))
+	assert.Equal(t, "public-read", header.Get("X-Amz-Acl"), "the options of the call override the defaults")
+	assert.Equal(t, "123456789012", header.Get("X-Amz-Expected-Bucket-Owner"))
+
+	_, err = b.GetObject("key")
+	require.NoError(t, err)
+	assert.Equal(t, "123456789012", header.Get("X-Amz-Expected-Bucket-Owner"))
+
+	_, headers, err := b.PresignPutObject("key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "AES256", headers.Get("X-Amz-Server-Side-Encryption"))
+}
+
+func TestWithContentTypeAuto(t *testing.T) {
+	var contentType string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+	})
+	codec := keycodec.NewHMAC([]byte("secret"))
+
+	for _, tc := range []struct {
+		name string
+		b    *Bucket
+		key  string
+		body string
+		opts []option.PutObjectInput
+		want string
+	}{
+		{
+			name: "extension",
+			b:    New(svc, "bucket", WithContentTypeAuto()),
+			key:  "dir/a.json",
+			body: "{}",
+			want: "application/json",
+		},
+		{
+			name: "extension under codec",
+			b:    New(svc, "bucket", WithKeyCodec(codec), WithContentTypeAuto()),
+			key:  "dir/a.json",
+			body: "{}",
+			want: "application/json",
+		},
+		{
+			name: "sniffed under codec",
+			b:    New(svc, "bucket", WithKeyCodec(codec), WithContentTypeAuto()),
+			key:  "dir/a",
+			body: "\x89PNG\r\n\x1a\n",
+			want: "image/png",
+		},
+		{
+			name: "set by the caller",
+			b:    New(svc, "bucket", WithKeyCodec(codec), WithContentTypeAuto()),
+			key:  "dir/a.json",
+			body: "{}",
+			opts: []option.PutObjectInput{option.ContentType("text/plain")},
+			want: "text/plain",
+		},
+		{
+			name: "option under codec",
+			b:    New(svc, "bucket", WithKeyCodec(codec)),
+			key:  "dir/a.css",
+			body: "a {}",
+			opts: []option.PutObjectInput{option.ContentTypeAuto()},
+			want: "text/css; charset=utf-8",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.b.PutObject(tc.key, strings.NewReader(tc.body), tc.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, contentType)
+		})
+	}
+}
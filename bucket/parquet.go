@@ -0,0 +1,27 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/selectquery"
+)
+
+// SelectParquet runs S3 Select on the Parquet object key and returns the records decoded into T.
+// Only the columns of the fields of T are selected. See selectquery.Projection.
+// where is the WHERE clause of the query, e.g. `s."status" = 'failed'`, or empty to read all records.
+// A caller of this MUST close the returned Rows.
+func SelectParquet[T any](ctx aws.Context, b *Bucket, key, where string) (*selectquery.Rows[T], error) {
+	resp, err := b.S3.SelectObjectContentWithContext(ctx, &s3.SelectObjectContentInput{
+		Bucket:              b.Name,
+		Key:                 b.key(key),
+		Expression:          aws.String(selectquery.Expression[T](where)),
+		ExpressionType:      aws.String(s3.ExpressionTypeSql),
+		InputSerialization:  selectquery.ParquetInput(),
+		OutputSerialization: selectquery.JSONOutput(),
+	}, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectquery.NewRows[T](resp.EventStream, selectquery.JSON), nil
+}
@@ -0,0 +1,113 @@
+package bucket
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// A PolicyDocument is an IAM policy, e.g. a bucket policy built with NewPolicy.
+type PolicyDocument struct {
+	Version   string
+	Statement []PolicyStatement
+}
+
+// A PolicyStatement is a statement in PolicyDocument.
+type PolicyStatement struct {
+	Sid       string `json:",omitempty"`
+	Effect    string
+	Principal map[string]string `json:",omitempty"`
+	Action    []string
+	Resource  []string
+	Condition map[string]map[string]string `json:",omitempty"`
+}
+
+// NewPolicy returns a PolicyDocument with stmts.
+func NewPolicy(stmts ...PolicyStatement) *PolicyDocument {
+	return &PolicyDocument{
+		Version:   "2012-10-17",
+		Statement: stmts,
+	}
+}
+
+// Add appends stmts to the policy and returns it.
+func (p *PolicyDocument) Add(stmts ...PolicyStatement) *PolicyDocument {
+	p.Statement = append(p.Statement, stmts...)
+
+	return p
+}
+
+// String returns the policy as JSON for PutPolicy.
+func (p *PolicyDocument) String() string {
+	data, _ := json.Marshal(p)
+
+	return string(data)
+}
+
+// PublicReadStatement returns a statement that allows anyone to get the objects under keyPrefix in the bucket.
+func (b *Bucket) PublicReadStatement(keyPrefix string) PolicyStatement {
+	return PolicyStatement{
+		Sid:       "PublicRead",
+		Effect:    "Allow",
+		Principal: map[string]string{"AWS": "*"},
+		Action:    []string{"s3:GetObject"},
+		Resource:  []string{b.arn() + "/" + b.objectKey(keyPrefix) + "*"},
+	}
+}
+
+// CrossAccountStatements returns statements that allow the AWS account accountID perms on the objects under keyPrefix in the bucket.
+func (b *Bucket) CrossAccountStatements(accountID, keyPrefix string, perms Permission) []PolicyStatement {
+	stmts := b.accessPolicy(b.objectKey(keyPrefix), perms).Statement
+	for i := range stmts {
+		stmts[i].Principal = map[string]string{"AWS": "arn:" + b.partition() + ":iam::" + accountID + ":root"}
+	}
+
+	return stmts
+}
+
+// GetPolicy returns the bucket policy as JSON or empty if the bucket has no policy.
+func (b *Bucket) GetPolicy() (string, error) {
+	return b.GetPolicyWithContext(aws.BackgroundContext())
+}
+
+// GetPolicyWithContext is the same as GetPolicy with the context ctx.
+func (b *Bucket) GetPolicyWithContext(ctx aws.Context) (string, error) {
+	resp, err := b.S3.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchBucketPolicy" {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(resp.Policy), nil
+}
+
+// PutPolicy replaces the bucket policy with policy, e.g. the String of a PolicyDocument.
+func (b *Bucket) PutPolicy(policy string) (*s3.PutBucketPolicyOutput, error) {
+	return b.PutPolicyWithContext(aws.BackgroundContext(), policy)
+}
+
+// PutPolicyWithContext is the same as PutPolicy with the context ctx.
+func (b *Bucket) PutPolicyWithContext(ctx aws.Context, policy string) (*s3.PutBucketPolicyOutput, error) {
+	return b.S3.PutBucketPolicyWithContext(ctx, &s3.PutBucketPolicyInput{
+		Bucket: b.Name,
+		Policy: aws.String(policy),
+	}, b.reqOpts...)
+}
+
+// DeletePolicy deletes the bucket policy.
+func (b *Bucket) DeletePolicy() (*s3.DeleteBucketPolicyOutput, error) {
+	return b.DeletePolicyWithContext(aws.BackgroundContext())
+}
+
+// DeletePolicyWithContext is the same as DeletePolicy with the context ctx.
+func (b *Bucket) DeletePolicyWithContext(ctx aws.Context) (*s3.DeleteBucketPolicyOutput, error) {
+	return b.S3.DeleteBucketPolicyWithContext(ctx, &s3.DeleteBucketPolicyInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+}
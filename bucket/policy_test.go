@@ -0,0 +1,56 @@
+package bucket
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	var stored string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			stored = string(data)
+		case http.MethodDelete:
+			stored = ""
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			if stored == "" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>NoSuchBucketPolicy</Code></Error>`))
+				return
+			}
+			w.Write([]byte(stored))
+		}
+	})
+	b := New(svc, "bucket").WithPrefix("tenant/")
+
+	policy := NewPolicy(b.PublicReadStatement("public/")).Add(b.CrossAccountStatements("123456789012", "shared/", PermissionRead|PermissionList)...)
+	_, err := b.PutPolicy(policy.String())
+	require.NoError(t, err)
+
+	got, err := b.GetPolicy()
+	require.NoError(t, err)
+
+	var doc PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(got), &doc))
+	require.Len(t, doc.Statement, 3)
+	assert.Equal(t, map[string]string{"AWS": "*"}, doc.Statement[0].Principal)
+	assert.Equal(t, []string{"arn:aws:s3:::bucket/tenant/public/*"}, doc.Statement[0].Resource)
+	assert.Equal(t, map[string]string{"AWS": "arn:aws:iam::123456789012:root"}, doc.Statement[1].Principal)
+	assert.Equal(t, []string{"s3:ListBucket"}, doc.Statement[2].Action)
+	assert.Equal(t, "tenant/shared/*", doc.Statement[2].Condition["StringLike"]["s3:prefix"])
+
+	_, err = b.DeletePolicy()
+	require.NoError(t, err)
+
+	got, err = b.GetPolicy()
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,244 @@
+package bucket
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ErrNoCredentials is returned by PresignPostPolicy when the S3 client of the Bucket is not *s3.S3 with credentials.
+var ErrNoCredentials = errors.New("bucket: S3 client has no credentials to sign with")
+
+// PostPolicyOptions restricts the uploads allowed by the policy of PresignPostPolicy.
+type PostPolicyOptions struct {
+	// Expires is how long the policy is valid. It defaults to 15 minutes.
+	Expires time.Duration
+
+	// ContentType is the Content-Type the form must send if not empty.
+	ContentType string
+
+	// MinContentLength and MaxContentLength restrict the size of the upload if MaxContentLength is positive.
+	MinContentLength int64
+	MaxContentLength int64
+
+	// Fields are other form fields that must be sent as is, e.g. "acl" or "x-amz-meta-owner".
+	Fields map[string]string
+}
+
+// A PostPolicy is a signed policy for a browser form upload.
+type PostPolicy struct {
+	// URL is the action of the form.
+	URL string
+
+	// Fields are the form fields to send along with the file, which must be the last field.
+	Fields map[string]string
+}
+
+// PresignPostPolicy returns the signed policy for a form to upload the object for key with POST.
+// The policy is signed with Signature Version 4 by the credentials of the S3 client.
+//
+// The default put options of WithDefaultPutOptions are added to the fields and the conditions, and opts takes
+// precedence over them. The options that have no form field, i.e. the expected bucket owner, the checksums and
+// SSE-C whose key must not be handed to the browser, are not applied.
+func (b *Bucket) PresignPostPolicy(key string, opts PostPolicyOptions) (*PostPolicy, error) {
+	svc, ok := b.S3.(*s3.S3)
+	if !ok || svc.Config.Credentials == nil {
+		return nil, ErrNoCredentials
+	}
+
+	creds, err := svc.Config.Credentials.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := b.postURL(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	expires := opts.Expires
+	if expires <= 0 {
+		expires = 15 * time.Minute
+	}
+
+	now := time.Now().UTC()
+	region := aws.StringValue(svc.Config.Region)
+	scope := now.Format("20060102") + "/" + region + "/s3/aws4_request"
+
+	fields, err := b.defaultPostFields(svc, key)
+	if err != nil {
+		return nil, err
+	}
+
+	fields["key"] = b.objectKey(key)
+	fields["x-amz-algorithm"] = "AWS4-HMAC-SHA256"
+	fields["x-amz-credential"] = creds.AccessKeyID + "/" + scope
+	fields["x-amz-date"] = now.Format("20060102T150405Z")
+	if creds.SessionToken != "" {
+		fields["x-amz-security-token"] = creds.SessionToken
+	}
+	if opts.ContentType != "" {
+		fields["Content-Type"] = opts.ContentType
+	}
+	for k, v := range opts.Fields {
+		fields[k] = v
+	}
+
+	conditions := []interface{}{map[string]string{"bucket": aws.StringValue(b.Name)}}
+	for k, v := range fields {
+		conditions = append(conditions, map[string]string{k: v})
+	}
+	if opts.MaxContentLength > 0 {
+		conditions = append(conditions, []interface{}{"content-length-range", opts.MinContentLength, opts.MaxContentLength})
+	}
+
+	policy, err := json.Marshal(map[string]interface{}{
+		"expiration": now.Add(expires).Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(policy)
+
+	key4 := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), now.Format("20060102"))
+	for _, s := range []string{region, "s3", "aws4_request"} {
+		key4 = hmacSHA256(key4, s)
+	}
+
+	fields["policy"] = encoded
+	fields["x-amz-signature"] = hex.EncodeToString(hmacSHA256(key4, encoded))
+
+	return &PostPolicy{URL: u, Fields: fields}, nil
+}
+
+// postFieldHeaders are the headers of PutObject whose form field of POST has the same name.
+var postFieldHeaders = map[string]bool{
+	"Cache-Control":       true,
+	"Content-Disposition": true,
+	"Content-Encoding":    true,
+	"Content-Language":    true,
+	"Content-Type":        true,
+	"Expires":             true,
+}
+
+// postFieldPrefixes are the prefixes of the headers of PutObject that are form fields of POST in lower case.
+var postFieldPrefixes = []string{
+	"X-Amz-Meta-",
+	"X-Amz-Object-Lock-",
+	"X-Amz-Server-Side-Encryption",
+	"X-Amz-Storage-Class",
+	"X-Amz-Website-Redirect-Location",
+}
+
+// defaultPostFields returns the form fields of the default put options of b for key.
+// The headers the options set on PutObject are translated to the fields.
+func (b *Bucket) defaultPostFields(svc *s3.S3, key string) (map[string]string, error) {
+	fields := map[string]string{}
+	if len(b.defaultPutOpts) == 0 {
+		return fields, nil
+	}
+
+	in := &s3.PutObjectInput{Bucket: b.Name}
+	b.applyPutOptions(in, key, nil)
+
+	req, _ := svc.PutObjectRequest(in)
+	if err := req.Build(); err != nil {
+		return nil, err
+	}
+
+	for name := range req.HTTPRequest.Header {
+		value := req.HTTPRequest.Header.Get(name)
+
+		switch {
+		case postFieldHeaders[name]:
+			fields[name] = value
+		case name == "X-Amz-Acl":
+			fields["acl"] = value
+		case name == "X-Amz-Tagging":
+			tagging, err := postTagging(value)
+			if err != nil {
+				return nil, err
+			}
+			fields["tagging"] = tagging
+		case strings.HasPrefix(name, "X-Amz-Server-Side-Encryption-Customer-"):
+			// the key of SSE-C is not handed out
+		default:
+			for _, prefix := range postFieldPrefixes {
+				if strings.HasPrefix(name, prefix) {
+					fields[strings.ToLower(name)] = value
+					break
+				}
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// postTagging converts the tags in the format of x-amz-tagging to the XML of the tagging field of POST.
+func postTagging(header string) (string, error) {
+	values, err := url.ParseQuery(header)
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type tag struct {
+		Key   string
+		Value string
+	}
+	tagging := struct {
+		XMLName xml.Name `xml:"Tagging"`
+		Tags    []tag    `xml:"TagSet>Tag"`
+	}{}
+	for _, k := range keys {
+		tagging.Tags = append(tagging.Tags, tag{Key: k, Value: values.Get(k)})
+	}
+
+	data, err := xml.Marshal(tagging)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// postURL returns the URL of the bucket as the endpoint of svc addresses it with the request options of b,
+// e.g. the endpoint of WithFIPS.
+func (b *Bucket) postURL(svc *s3.S3) (string, error) {
+	req, _ := svc.HeadBucketRequest(&s3.HeadBucketInput{Bucket: b.Name})
+	req.ApplyOptions(b.reqOpts...)
+	if err := req.Build(); err != nil {
+		return "", err
+	}
+
+	u := *req.HTTPRequest.URL
+	u.RawQuery = ""
+
+	return u.String(), nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
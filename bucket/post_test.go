@@ -0,0 +1,109 @@
+package bucket
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresignPostPolicy(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("presigning must not send a request")
+	})
+	b := New(svc, "bucket").WithPrefix("uploads/")
+
+	p, err := b.PresignPostPolicy("a.png", PostPolicyOptions{
+		ContentType:      "image/png",
+		MaxContentLength: 1 << 20,
+		Fields:           map[string]string{"acl": "private"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasSuffix(p.URL, "/bucket"), p.URL)
+	assert.Equal(t, "uploads/a.png", p.Fields["key"])
+	assert.Equal(t, "image/png", p.Fields["Content-Type"])
+	assert.Equal(t, "private", p.Fields["acl"])
+	assert.Equal(t, "AWS4-HMAC-SHA256", p.Fields["x-amz-algorithm"])
+
+	date := p.Fields["x-amz-date"][:8]
+	assert.Equal(t, "AKID/"+date+"/us-east-1/s3/aws4_request", p.Fields["x-amz-credential"])
+
+	raw, err := base64.StdEncoding.DecodeString(p.Fields["policy"])
+	require.NoError(t, err)
+
+	var policy struct {
+		Expiration time.Time
+		Conditions []interface{}
+	}
+	require.NoError(t, json.Unmarshal(raw, &policy))
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), policy.Expiration, time.Minute)
+	assert.Contains(t, policy.Conditions, map[string]interface{}{"bucket": "bucket"})
+	assert.Contains(t, policy.Conditions, map[string]interface{}{"key": "uploads/a.png"})
+	assert.Contains(t, policy.Conditions, map[string]interface{}{"Content-Type": "image/png"})
+	assert.Contains(t, policy.Conditions, []interface{}{"content-length-range", 0.0, float64(1 << 20)})
+
+	key := hmacSHA256([]byte("AWS4SECRET"), date)
+	for _, s := range []string{"us-east-1", "s3", "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	assert.Equal(t, hex.EncodeToString(hmacSHA256(key, p.Fields["policy"])), p.Fields["x-amz-signature"])
+
+	_, err = New(&listStub{}, "bucket").PresignPostPolicy("a.png", PostPolicyOptions{})
+	assert.Equal(t, ErrNoCredentials, err)
+}
+
+func TestPresignPostPolicyDefaultPutOptions(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("presigning must not send a request")
+	})
+	b := New(svc, "bucket", WithDefaultPutOptions(
+		option.SSEKMSKeyID("key-id"),
+		option.ACLPrivate(),
+		option.ContentType("application/octet-stream"),
+		option.Tagging(map[string]string{"team": "a b", "env": "prod"}),
+		option.ExpectedBucketOwner("123456789012"),
+		func(req *s3.PutObjectInput) {
+			req.Metadata = map[string]*string{"Owner": aws.String("alice")}
+			req.StorageClass = aws.String(s3.StorageClassStandardIa)
+		},
+	))
+
+	p, err := b.PresignPostPolicy("a.png", PostPolicyOptions{ContentType: "image/png"})
+	require.NoError(t, err)
+
+	want := map[string]string{
+		"acl":                          "private",
+		"Content-Type":                 "image/png",
+		"tagging":                      "<Tagging><TagSet><Tag><Key>env</Key><Value>prod</Value></Tag><Tag><Key>team</Key><Value>a b</Value></Tag></TagSet></Tagging>",
+		"x-amz-meta-owner":             "alice",
+		"x-amz-server-side-encryption": "aws:kms",
+		"x-amz-server-side-encryption-aws-kms-key-id": "key-id",
+		"x-amz-storage-class":                         "STANDARD_IA",
+	}
+	for k, v := range want {
+		assert.Equal(t, v, p.Fields[k], k)
+	}
+	assert.NotContains(t, p.Fields, "x-amz-expected-bucket-owner")
+	assert.NotContains(t, p.Fields, "X-Amz-Expected-Bucket-Owner")
+
+	raw, err := base64.StdEncoding.DecodeString(p.Fields["policy"])
+	require.NoError(t, err)
+
+	var policy struct {
+		Conditions []interface{}
+	}
+	require.NoError(t, json.Unmarshal(raw, &policy))
+	for k, v := range want {
+		assert.Contains(t, policy.Conditions, map[string]interface{}{k: v})
+	}
+}
@@ -0,0 +1,36 @@
+package bucket
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// PresignGetObject returns a URL to get the object for key that expires after expires.
+func (b *Bucket) PresignGetObject(key string, expires time.Duration, opts ...option.GetObjectInput) (string, error) {
+	req, _ := b.GetObjectRequest(key, opts...)
+
+	return req.Presign(expires)
+}
+
+// PresignPutObject returns a URL to put the object for key that expires after expires, and the headers
+// set by opts, e.g. Content-Type and the SSE headers, that the client must send with the same values.
+func (b *Bucket) PresignPutObject(key string, expires time.Duration, opts ...option.PutObjectInput) (string, http.Header, error) {
+	req, _ := b.PutObjectRequest(key, nil, opts...)
+
+	u, signed, err := req.PresignRequest(expires)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the signer returns the names in lower case
+	header := http.Header{}
+	for k, vs := range signed {
+		for _, v := range vs {
+			header.Add(k, v)
+		}
+	}
+
+	return u, header, nil
+}
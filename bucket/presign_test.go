@@ -0,0 +1,48 @@
+package bucket
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresignGetObject(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("presigning must not send a request")
+	})
+	b := New(svc, "bucket").WithPrefix("tenant/")
+
+	s, err := b.PresignGetObject("a b.txt", 10*time.Minute, option.GetVersionID("v1"))
+	require.NoError(t, err)
+
+	u, err := url.Parse(s)
+	require.NoError(t, err)
+	assert.Equal(t, "/bucket/tenant/a b.txt", u.Path)
+	assert.Equal(t, "600", u.Query().Get("X-Amz-Expires"))
+	assert.Equal(t, "v1", u.Query().Get("versionId"))
+	assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+}
+
+func TestPresignPutObject(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("presigning must not send a request")
+	})
+	b := New(svc, "bucket")
+
+	s, header, err := b.PresignPutObject("a.png", time.Hour, option.ContentType("image/png"), option.SSES3())
+	require.NoError(t, err)
+
+	u, err := url.Parse(s)
+	require.NoError(t, err)
+	assert.Equal(t, "/bucket/a.png", u.Path)
+	assert.Equal(t, "3600", u.Query().Get("X-Amz-Expires"))
+	assert.Contains(t, u.Query().Get("X-Amz-SignedHeaders"), "content-type")
+
+	assert.Equal(t, "image/png", header.Get("Content-Type"))
+	assert.Equal(t, "AES256", header.Get("X-Amz-Server-Side-Encryption"))
+}
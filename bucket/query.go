@@ -0,0 +1,247 @@
+package bucket
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/selectquery"
+)
+
+// defaultQueryConcurrency is the number of objects queried at the same time unless QueryOptions.Concurrency is set.
+const defaultQueryConcurrency = 4
+
+// QueryOptions controls how Query reads the objects.
+type QueryOptions struct {
+	// Expression is the S3 Select expression run on each object, e.g. "SELECT * FROM S3Object s WHERE s.status = 'failed'".
+	// It must return whole records that decode into T. S3 Select is not used if it is empty.
+	Expression string
+
+	// Input describes the objects. Only CSV and JSON Lines can be read without S3 Select.
+	Input *s3.InputSerialization
+
+	// Columns are the names of the CSV columns passed to selectquery.NewRows. If empty, the header line of the
+	// object is used when the object is downloaded and Input says to use it.
+	Columns []string
+
+	// Concurrency is the number of objects queried at the same time. It defaults to 4.
+	Concurrency int
+}
+
+// Query reads the records of every object with the given prefix and returns the ones filter accepts.
+// Go methods cannot have type parameters, so this is a function instead of a method.
+//
+// Each object is queried with S3 Select if opts.Expression is set and the object supports it. Otherwise, or if
+// S3 Select fails, the object is downloaded and decoded. filter is applied in both cases, so it must select
+// the same records as the expression. The records are returned in the order of the keys.
+func Query[T any](ctx aws.Context, b *Bucket, prefix string, filter func(T) bool, opts QueryOptions) ([]T, error) {
+	if opts.Input == nil {
+		return nil, errors.New("bucket: QueryOptions.Input must be set")
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultQueryConcurrency
+	}
+
+	var objects []*s3.Object
+	if err := b.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		objects = append(objects, page.Contents...)
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results = make([][]T, len(objects))
+		sem     = make(chan struct{}, concurrency)
+		wg      sync.WaitGroup
+		once    sync.Once
+		qerr    error
+	)
+
+	for i, obj := range objects {
+		i, obj := i, obj
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			records, err := queryObject(ctx, b, obj, filter, opts)
+			if err != nil {
+				once.Do(func() {
+					qerr = err
+					cancel()
+				})
+				return
+			}
+
+			results[i] = records
+		}()
+	}
+
+	wg.Wait()
+
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var merged []T
+	for _, records := range results {
+		merged = append(merged, records...)
+	}
+
+	return merged, nil
+}
+
+// queryObject returns the records of obj accepted by filter.
+func queryObject[T any](ctx aws.Context, b *Bucket, obj *s3.Object, filter func(T) bool, opts QueryOptions) ([]T, error) {
+	key := aws.StringValue(obj.Key)
+
+	if opts.Expression != "" && selectable(obj) {
+		records, err := selectObject(ctx, b, key, filter, opts)
+		if err == nil || ctx.Err() != nil || opts.Input.Parquet != nil {
+			return records, err
+		}
+	}
+
+	return downloadObject(ctx, b, key, filter, opts)
+}
+
+// selectable reports whether S3 Select can be used on obj.
+func selectable(obj *s3.Object) bool {
+	switch aws.StringValue(obj.StorageClass) {
+	case s3.ObjectStorageClassGlacier, s3.ObjectStorageClassDeepArchive:
+		return false
+	}
+
+	return true
+}
+
+func selectObject[T any](ctx aws.Context, b *Bucket, key string, filter func(T) bool, opts QueryOptions) ([]T, error) {
+	format := selectquery.JSON
+	output := &s3.OutputSerialization{JSON: &s3.JSONOutput{}}
+	if opts.Input.CSV != nil {
+		format = selectquery.CSV
+		output = &s3.OutputSerialization{CSV: &s3.CSVOutput{}}
+	}
+
+	resp, err := b.S3.SelectObjectContentWithContext(ctx, &s3.SelectObjectContentInput{
+		Bucket:              b.Name,
+		Key:                 b.key(key),
+		Expression:          aws.String(opts.Expression),
+		ExpressionType:      aws.String(s3.ExpressionTypeSql),
+		InputSerialization:  opts.Input,
+		OutputSerialization: output,
+	}, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return collect(selectquery.NewRows[T](resp.EventStream, format, opts.Columns...), filter)
+}
+
+func downloadObject[T any](ctx aws.Context, b *Bucket, key string, filter func(T) bool, opts QueryOptions) ([]T, error) {
+	if opts.Input.CSV == nil && opts.Input.JSON == nil {
+		return nil, errors.New("bucket: only CSV and JSON objects can be queried without S3 Select")
+	}
+
+	resp, err := b.GetObjectWithContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := decompress(resp.Body, aws.StringValue(opts.Input.CompressionType))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if opts.Input.JSON != nil {
+		return collect(selectquery.NewReaderRows[T](body, selectquery.JSON), filter)
+	}
+
+	columns := opts.Columns
+	switch aws.StringValue(opts.Input.CSV.FileHeaderInfo) {
+	case s3.FileHeaderInfoUse, s3.FileHeaderInfoIgnore:
+		br := bufio.NewReader(body)
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			body.Close()
+			return nil, err
+		}
+
+		if aws.StringValue(opts.Input.CSV.FileHeaderInfo) == s3.FileHeaderInfoUse && len(columns) == 0 {
+			header, err := csv.NewReader(strings.NewReader(line)).Read()
+			if err != nil && err != io.EOF {
+				body.Close()
+				return nil, err
+			}
+			columns = header
+		}
+
+		body = readCloser{Reader: br, Closer: body}
+	}
+
+	return collect(selectquery.NewReaderRows[T](body, selectquery.CSV, columns...), filter)
+}
+
+// collect drains rows into a slice of the records accepted by filter and closes rows.
+func collect[T any](rows *selectquery.Rows[T], filter func(T) bool) ([]T, error) {
+	defer rows.Close()
+
+	var records []T
+	for rows.Next() {
+		if v := rows.Row(); filter == nil || filter(v) {
+			records = append(records, v)
+		}
+	}
+
+	return records, rows.Err()
+}
+
+// decompress wraps body to decompress it by the S3 Select compression type.
+func decompress(body io.ReadCloser, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case s3.CompressionTypeGzip:
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+
+		return readCloser{Reader: zr, Closer: body}, nil
+	case s3.CompressionTypeBzip2:
+		return readCloser{Reader: bzip2.NewReader(body), Closer: body}, nil
+	}
+
+	return body, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,64 @@
+package bucket
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type queryStub struct {
+	s3iface.S3API
+
+	objects map[string]string
+}
+
+func (s *queryStub) ListObjectsV2PagesWithContext(_ aws.Context, in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	out := &s3.ListObjectsV2Output{Prefix: in.Prefix}
+	for _, k := range []string{"logs/1.csv", "logs/2.csv"} {
+		out.Contents = append(out.Contents, &s3.Object{Key: aws.String(k)})
+	}
+	fn(out, true)
+
+	return nil
+}
+
+func (s *queryStub) SelectObjectContentWithContext(aws.Context, *s3.SelectObjectContentInput, ...request.Option) (*s3.SelectObjectContentOutput, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *queryStub) GetObjectWithContext(_ aws.Context, in *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(s.objects[aws.StringValue(in.Key)]))}, nil
+}
+
+func TestQueryFallback(t *testing.T) {
+	type record struct {
+		ID     string `csv:"id"`
+		Status string `csv:"status"`
+	}
+
+	stub := &queryStub{objects: map[string]string{
+		"logs/1.csv": "id,status\n1,ok\n2,failed\n",
+		"logs/2.csv": "id,status\n3,failed\n",
+	}}
+
+	records, err := Query(aws.BackgroundContext(), New(stub, "bucket"), "logs/", func(r record) bool {
+		return r.Status == "failed"
+	}, QueryOptions{
+		Expression: "SELECT * FROM S3Object s WHERE s.status = 'failed'",
+		Input: &s3.InputSerialization{
+			CSV: &s3.CSVInput{FileHeaderInfo: aws.String(s3.FileHeaderInfoUse)},
+		},
+		Concurrency: 1,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []record{{"2", "failed"}, {"3", "failed"}}, records)
+}
@@ -0,0 +1,71 @@
+package bucket
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// GetObjectRange returns a reader of length bytes of the object from start. Fewer bytes are returned if the object
+// ends before start+length. A caller of this MUST close the reader when it finishes reading.
+func (b *Bucket) GetObjectRange(key string, start, length int64, opts ...option.GetObjectInput) (io.ReadCloser, error) {
+	return b.GetObjectRangeWithContext(aws.BackgroundContext(), key, start, length, opts...)
+}
+
+// GetObjectRangeWithContext is the same as GetObjectRange with the context ctx.
+// The context also applies to reading the body.
+func (b *Bucket) GetObjectRangeWithContext(ctx aws.Context, key string, start, length int64, opts ...option.GetObjectInput) (io.ReadCloser, error) {
+	if start < 0 || length <= 0 {
+		return nil, fmt.Errorf("bucket: invalid range of %d bytes from %d", length, start)
+	}
+
+	opts = append(opts[:len(opts):len(opts)], option.Range(start, start+length-1))
+
+	resp, err := b.GetObjectWithContext(ctx, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := aws.StringValue(resp.ContentRange)
+	first, last, _, err := parseContentRange(cr)
+	if err == nil && (first != start || last > start+length-1) {
+		err = fmt.Errorf("bucket: Content-Range %q does not match the range of %d bytes from %d", cr, length, start)
+	}
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// parseContentRange parses Content-Range of a ranged GetObject, e.g. "bytes 0-99/1234".
+func parseContentRange(cr string) (first, last, size int64, err error) {
+	invalid := fmt.Errorf("bucket: invalid Content-Range %q", cr)
+
+	rng, total, ok := strings.Cut(strings.TrimPrefix(cr, "bytes "), "/")
+	if !ok || !strings.HasPrefix(cr, "bytes ") {
+		return 0, 0, 0, invalid
+	}
+
+	f, l, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, 0, invalid
+	}
+
+	if first, err = strconv.ParseInt(f, 10, 64); err != nil {
+		return 0, 0, 0, invalid
+	}
+	if last, err = strconv.ParseInt(l, 10, 64); err != nil {
+		return 0, 0, 0, invalid
+	}
+	if size, err = strconv.ParseInt(total, 10, 64); err != nil {
+		return 0, 0, 0, invalid
+	}
+
+	return first, last, size, nil
+}
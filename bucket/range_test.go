@@ -0,0 +1,52 @@
+package bucket
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetObjectRange(t *testing.T) {
+	const content = "0123456789"
+
+	contentRange := ""
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bytes=2-5", r.Header.Get("Range"))
+		if contentRange == "" {
+			w.Header().Set("Content-Range", "bytes 2-5/10")
+		} else {
+			w.Header().Set("Content-Range", contentRange)
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(content[2:6]))
+	})
+	b := New(svc, "bucket")
+
+	rc, err := b.GetObjectRange("key", 2, 4)
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "2345", string(data))
+	rc.Close()
+
+	contentRange = "bytes 0-9/10"
+	_, err = b.GetObjectRange("key", 2, 4)
+	assert.Error(t, err)
+
+	_, err = b.GetObjectRange("key", 2, 0)
+	assert.Error(t, err)
+}
+
+func TestParseContentRange(t *testing.T) {
+	first, last, size, err := parseContentRange("bytes 0-99/1234")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 99, 1234}, []int64{first, last, size})
+
+	for _, cr := range []string{"", "0-99/1234", "bytes 0-99", "bytes */1234", "bytes a-99/1234"} {
+		_, _, _, err := parseContentRange(cr)
+		assert.Error(t, err, cr)
+	}
+}
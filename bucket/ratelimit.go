@@ -0,0 +1,97 @@
+package bucket
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// WithRateLimit returns an Option that limits the requests of the operations in class made through the Bucket to
+// rps per second with bursts of up to burst requests. Each attempt, including retries, takes a token and waits until
+// one is available or the context of the request is done. Presigned requests are not limited.
+//
+// The limit is shared by every goroutine using the Bucket and its views. Classes given to separate WithRateLimit
+// calls are limited separately, e.g. WithRateLimit(ReadOperations, 5000, 100) and WithRateLimit(WriteOperations, 3000, 100)
+// to stay under the S3 request rates per prefix. A non-positive rps disables the limit.
+func WithRateLimit(class OperationClass, rps float64, burst int) Option {
+	if rps <= 0 {
+		return func(*Bucket) {}
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	tb := &tokenBucket{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			if !class.Contains(r.Operation.Name) {
+				return
+			}
+
+			r.Handlers.Sign.PushFront(func(r *request.Request) {
+				if r.IsPresigned() {
+					return
+				}
+
+				if err := tb.wait(r.Context()); err != nil {
+					r.Error = awserr.New(request.CanceledErrorCode, "request context canceled while waiting for the rate limit", err)
+				}
+			})
+		})
+	}
+}
+
+// A tokenBucket allows rate events per second with bursts of burst events.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// wait takes a token, waiting until it is available or ctx is done.
+func (tb *tokenBucket) wait(ctx aws.Context) error {
+	tb.mu.Lock()
+
+	now := time.Now()
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+
+	// The token is reserved now so that the waiters are served in order.
+	tb.tokens--
+	if tb.tokens >= 0 {
+		tb.mu.Unlock()
+		return nil
+	}
+
+	delay := time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+	tb.mu.Unlock()
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		tb.mu.Lock()
+		tb.tokens++
+		tb.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
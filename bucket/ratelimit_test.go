@@ -0,0 +1,50 @@
+package bucket
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {})
+	b := New(svc, "bucket", WithRateLimit(ReadOperations, 50, 1))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.WithPrefix("view/").HeadObject("key")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// the first request takes the burst and the others wait 20ms each
+	assert.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+
+	b = New(svc, "bucket", WithRateLimit(ReadOperations, 1, 1))
+
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := b.PutObject("key", strings.NewReader("data"))
+		require.NoError(t, err)
+	}
+	assert.Less(t, time.Since(start), time.Second, "writes are not limited")
+
+	_, err := b.HeadObject("key")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err = b.HeadObjectWithContext(ctx, "key")
+	assert.True(t, isCanceled(err), "%v", err)
+}
@@ -0,0 +1,157 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// A ReplicationRule builds a s3.ReplicationRule for PutBucketReplication.
+// The rule is enabled and does not replicate delete markers unless changed.
+type ReplicationRule struct {
+	rule   *s3.ReplicationRule
+	prefix string
+	tags   map[string]string
+}
+
+// NewReplicationRule returns a ReplicationRule named id that replicates the objects under keyPrefix in the bucket
+// to the bucket destBucketARN, e.g. "arn:aws:s3:::dr-bucket".
+func (b *Bucket) NewReplicationRule(id, keyPrefix, destBucketARN string) *ReplicationRule {
+	return &ReplicationRule{
+		rule: &s3.ReplicationRule{
+			ID:     aws.String(id),
+			Status: aws.String(s3.ReplicationRuleStatusEnabled),
+			Destination: &s3.Destination{
+				Bucket: aws.String(destBucketARN),
+			},
+			DeleteMarkerReplication: &s3.DeleteMarkerReplication{
+				Status: aws.String(s3.DeleteMarkerReplicationStatusDisabled),
+			},
+		},
+		prefix: b.objectKey(keyPrefix),
+	}
+}
+
+// Tag restricts the rule to the objects with the tag key=value.
+func (r *ReplicationRule) Tag(key, value string) *ReplicationRule {
+	if r.tags == nil {
+		r.tags = map[string]string{}
+	}
+	r.tags[key] = value
+
+	return r
+}
+
+// StorageClass stores the replicas in storageClass, e.g. s3.StorageClassStandardIa, instead of the class of the source.
+func (r *ReplicationRule) StorageClass(storageClass string) *ReplicationRule {
+	r.rule.Destination.StorageClass = aws.String(storageClass)
+
+	return r
+}
+
+// ReplicaKMSKeyID replicates the objects encrypted with SSE-KMS and encrypts the replicas with the KMS key keyID.
+func (r *ReplicationRule) ReplicaKMSKeyID(keyID string) *ReplicationRule {
+	r.rule.Destination.EncryptionConfiguration = &s3.EncryptionConfiguration{ReplicaKmsKeyID: aws.String(keyID)}
+	r.rule.SourceSelectionCriteria = &s3.SourceSelectionCriteria{
+		SseKmsEncryptedObjects: &s3.SseKmsEncryptedObjects{Status: aws.String(s3.SseKmsEncryptedObjectsStatusEnabled)},
+	}
+
+	return r
+}
+
+// Account makes the destination account accountID the owner of the replicas.
+func (r *ReplicationRule) Account(accountID string) *ReplicationRule {
+	r.rule.Destination.Account = aws.String(accountID)
+	r.rule.Destination.AccessControlTranslation = &s3.AccessControlTranslation{Owner: aws.String(s3.OwnerOverrideDestination)}
+
+	return r
+}
+
+// Priority sets the priority of the rule. S3 applies the rule with the highest priority when rules overlap.
+func (r *ReplicationRule) Priority(priority int) *ReplicationRule {
+	r.rule.Priority = aws.Int64(int64(priority))
+
+	return r
+}
+
+// ReplicateDeleteMarkers replicates delete markers too.
+func (r *ReplicationRule) ReplicateDeleteMarkers() *ReplicationRule {
+	r.rule.DeleteMarkerReplication.Status = aws.String(s3.DeleteMarkerReplicationStatusEnabled)
+
+	return r
+}
+
+// Disable disables the rule.
+func (r *ReplicationRule) Disable() *ReplicationRule {
+	r.rule.Status = aws.String(s3.ReplicationRuleStatusDisabled)
+
+	return r
+}
+
+// Rule returns the s3.ReplicationRule built by r.
+func (r *ReplicationRule) Rule() *s3.ReplicationRule {
+	rule := *r.rule
+
+	switch {
+	case len(r.tags) == 0:
+		rule.Filter = &s3.ReplicationRuleFilter{Prefix: aws.String(r.prefix)}
+	case len(r.tags) == 1 && r.prefix == "":
+		rule.Filter = &s3.ReplicationRuleFilter{Tag: TagSet(r.tags)[0]}
+	default:
+		rule.Filter = &s3.ReplicationRuleFilter{And: &s3.ReplicationRuleAndOperator{
+			Prefix: aws.String(r.prefix),
+			Tags:   TagSet(r.tags),
+		}}
+	}
+
+	return &rule
+}
+
+// GetBucketReplication returns the replication configuration of the bucket. It returns nil if the bucket has none.
+func (b *Bucket) GetBucketReplication() (*s3.ReplicationConfiguration, error) {
+	return b.GetBucketReplicationWithContext(aws.BackgroundContext())
+}
+
+// GetBucketReplicationWithContext is the same as GetBucketReplication with the context ctx.
+func (b *Bucket) GetBucketReplicationWithContext(ctx aws.Context) (*s3.ReplicationConfiguration, error) {
+	resp, err := b.S3.GetBucketReplicationWithContext(ctx, &s3.GetBucketReplicationInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "ReplicationConfigurationNotFoundError" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.ReplicationConfiguration, nil
+}
+
+// PutBucketReplication replaces the replication configuration of the bucket with rules, e.g. the ones built by
+// NewReplicationRule. S3 assumes the IAM role roleARN to replicate the objects. Versioning must be enabled on the bucket.
+func (b *Bucket) PutBucketReplication(roleARN string, rules ...*s3.ReplicationRule) (*s3.PutBucketReplicationOutput, error) {
+	return b.PutBucketReplicationWithContext(aws.BackgroundContext(), roleARN, rules...)
+}
+
+// PutBucketReplicationWithContext is the same as PutBucketReplication with the context ctx.
+func (b *Bucket) PutBucketReplicationWithContext(ctx aws.Context, roleARN string, rules ...*s3.ReplicationRule) (*s3.PutBucketReplicationOutput, error) {
+	return b.S3.PutBucketReplicationWithContext(ctx, &s3.PutBucketReplicationInput{
+		Bucket: b.Name,
+		ReplicationConfiguration: &s3.ReplicationConfiguration{
+			Role:  aws.String(roleARN),
+			Rules: rules,
+		},
+	}, b.reqOpts...)
+}
+
+// DeleteBucketReplication deletes the replication configuration of the bucket.
+func (b *Bucket) DeleteBucketReplication() (*s3.DeleteBucketReplicationOutput, error) {
+	return b.DeleteBucketReplicationWithContext(aws.BackgroundContext())
+}
+
+// DeleteBucketReplicationWithContext is the same as DeleteBucketReplication with the context ctx.
+func (b *Bucket) DeleteBucketReplicationWithContext(ctx aws.Context) (*s3.DeleteBucketReplicationOutput, error) {
+	return b.S3.DeleteBucketReplicationWithContext(ctx, &s3.DeleteBucketReplicationInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+}
@@ -0,0 +1,33 @@
+package bucket
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicationRule(t *testing.T) {
+	b := New(nil, "bucket").WithPrefix("tenant/")
+
+	rule := b.NewReplicationRule("dr", "data/", "arn:aws:s3:::dr-bucket").
+		StorageClass(s3.StorageClassStandardIa).
+		ReplicaKMSKeyID("key").
+		Priority(1).
+		Rule()
+
+	assert.Equal(t, "tenant/data/", aws.StringValue(rule.Filter.Prefix))
+	assert.Equal(t, "arn:aws:s3:::dr-bucket", aws.StringValue(rule.Destination.Bucket))
+	assert.Equal(t, s3.StorageClassStandardIa, aws.StringValue(rule.Destination.StorageClass))
+	assert.Equal(t, "key", aws.StringValue(rule.Destination.EncryptionConfiguration.ReplicaKmsKeyID))
+	assert.Equal(t, s3.SseKmsEncryptedObjectsStatusEnabled, aws.StringValue(rule.SourceSelectionCriteria.SseKmsEncryptedObjects.Status))
+	assert.Equal(t, s3.DeleteMarkerReplicationStatusDisabled, aws.StringValue(rule.DeleteMarkerReplication.Status))
+	require.NoError(t, rule.Validate())
+
+	rule = b.NewReplicationRule("tagged", "", "arn:aws:s3:::dr-bucket").Tag("replicate", "true").ReplicateDeleteMarkers().Rule()
+	assert.Equal(t, "tenant/", aws.StringValue(rule.Filter.And.Prefix))
+	assert.Equal(t, map[string]string{"replicate": "true"}, TagMap(rule.Filter.And.Tags))
+	assert.Equal(t, s3.DeleteMarkerReplicationStatusEnabled, aws.StringValue(rule.DeleteMarkerReplication.Status))
+}
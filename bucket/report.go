@@ -0,0 +1,114 @@
+package bucket
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// A ClassUsage is the number and the total size of objects in a storage class.
+type ClassUsage struct {
+	Objects int64
+	Bytes   int64
+}
+
+// A StorageClassReport is the usage per storage class of the objects under a prefix.
+type StorageClassReport struct {
+	Prefix string
+
+	// Classes is the usage by storage class of all objects.
+	Classes map[string]ClassUsage
+
+	// SubPrefixes is the usage by storage class of each sub-prefix up to the first "/" after Prefix,
+	// e.g. "logs/" for "logs/2024/01.gz". The objects directly under Prefix are under "".
+	SubPrefixes map[string]map[string]ClassUsage
+}
+
+// NewStorageClassReport returns an empty report for prefix to be filled with Add, e.g. from an S3 Inventory report.
+func NewStorageClassReport(prefix string) *StorageClassReport {
+	return &StorageClassReport{
+		Prefix:      prefix,
+		Classes:     map[string]ClassUsage{},
+		SubPrefixes: map[string]map[string]ClassUsage{},
+	}
+}
+
+// Add counts an object. An empty storageClass is counted as STANDARD. Keys without Prefix are ignored.
+func (r *StorageClassReport) Add(key, storageClass string, size int64) {
+	if !strings.HasPrefix(key, r.Prefix) {
+		return
+	}
+
+	if storageClass == "" {
+		storageClass = s3.ObjectStorageClassStandard
+	}
+
+	sub := ""
+	if i := strings.Index(key[len(r.Prefix):], "/"); i >= 0 {
+		sub = key[len(r.Prefix) : len(r.Prefix)+i+1]
+	}
+
+	addUsage(r.Classes, storageClass, size)
+
+	classes, ok := r.SubPrefixes[sub]
+	if !ok {
+		classes = map[string]ClassUsage{}
+		r.SubPrefixes[sub] = classes
+	}
+	addUsage(classes, storageClass, size)
+}
+
+func addUsage(m map[string]ClassUsage, class string, size int64) {
+	u := m[class]
+	u.Objects++
+	u.Bytes += size
+	m[class] = u
+}
+
+// StorageClassReport lists the objects with the given prefix and returns their usage per storage class.
+// Only the current versions are counted.
+func (b *Bucket) StorageClassReport(ctx aws.Context, prefix string) (*StorageClassReport, error) {
+	r := NewStorageClassReport(prefix)
+
+	err := b.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			r.Add(aws.StringValue(o.Key), aws.StringValue(o.StorageClass), aws.Int64Value(o.Size))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Total returns the usage of all objects in the report.
+func (r *StorageClassReport) Total() ClassUsage {
+	var total ClassUsage
+	for _, u := range r.Classes {
+		total.Objects += u.Objects
+		total.Bytes += u.Bytes
+	}
+
+	return total
+}
+
+// SummarizePrefix lists the objects with the given prefix and returns their number, their total size
+// and their total size by storage class as StorageClassReport does.
+func (b *Bucket) SummarizePrefix(ctx aws.Context, prefix string) (count int64, totalBytes int64, byStorageClass map[string]int64, err error) {
+	r, err := b.StorageClassReport(ctx, prefix)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	byStorageClass = make(map[string]int64, len(r.Classes))
+	for class, u := range r.Classes {
+		byStorageClass[class] = u.Bytes
+	}
+
+	total := r.Total()
+
+	return total.Objects, total.Bytes, byStorageClass, nil
+}
@@ -0,0 +1,46 @@
+package bucket
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageClassReport(t *testing.T) {
+	r := NewStorageClassReport("data/")
+	r.Add("data/a.txt", "", 1)
+	r.Add("data/logs/1.gz", "GLACIER", 10)
+	r.Add("data/logs/2024/2.gz", "GLACIER", 20)
+	r.Add("data/logs/3.gz", "STANDARD", 5)
+	r.Add("other/b.txt", "", 100)
+
+	assert.Equal(t, map[string]ClassUsage{
+		"STANDARD": {Objects: 2, Bytes: 6},
+		"GLACIER":  {Objects: 2, Bytes: 30},
+	}, r.Classes)
+	assert.Equal(t, map[string]map[string]ClassUsage{
+		"":      {"STANDARD": {Objects: 1, Bytes: 1}},
+		"logs/": {"STANDARD": {Objects: 1, Bytes: 5}, "GLACIER": {Objects: 2, Bytes: 30}},
+	}, r.SubPrefixes)
+	assert.Equal(t, ClassUsage{Objects: 4, Bytes: 36}, r.Total())
+}
+
+func TestSummarizePrefix(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "data/", r.URL.Query().Get("prefix"))
+		w.Write([]byte(`<ListBucketResult>` +
+			`<Contents><Key>data/a.txt</Key><Size>1</Size></Contents>` +
+			`<Contents><Key>data/logs/1.gz</Key><Size>10</Size><StorageClass>GLACIER</StorageClass></Contents>` +
+			`<Contents><Key>data/logs/2.gz</Key><Size>20</Size><StorageClass>GLACIER</StorageClass></Contents>` +
+			`</ListBucketResult>`))
+	})
+
+	count, total, byClass, err := New(svc, "bucket").SummarizePrefix(aws.BackgroundContext(), "data/")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Equal(t, int64(31), total)
+	assert.Equal(t, map[string]int64{"STANDARD": 1, "GLACIER": 30}, byClass)
+}
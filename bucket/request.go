@@ -0,0 +1,96 @@
+package bucket
+
+import (
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// PutObjectRequest generates a "aws/request.Request" representing the client's request for the PutObject operation.
+func (b *Bucket) PutObjectRequest(key string, rs io.ReadSeeker, opts ...option.PutObjectInput) (*request.Request, *s3.PutObjectOutput) {
+	req := &s3.PutObjectInput{
+		Bucket: b.Name,
+		Body:   rs,
+	}
+
+	b.applyPutOptions(req, key, opts)
+
+	r, resp := b.S3.PutObjectRequest(req)
+	r.ApplyOptions(b.reqOpts...)
+
+	return r, resp
+}
+
+// HeadObjectRequest generates a "aws/request.Request" representing the client's request for the HeadObject operation.
+func (b *Bucket) HeadObjectRequest(key string, opts ...option.HeadObjectInput) (*request.Request, *s3.HeadObjectOutput) {
+	req := &s3.HeadObjectInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	r, resp := b.S3.HeadObjectRequest(req)
+	r.ApplyOptions(b.reqOpts...)
+
+	return r, resp
+}
+
+// DeleteObjectRequest generates a "aws/request.Request" representing the client's request for the DeleteObject operation.
+func (b *Bucket) DeleteObjectRequest(key string) (*request.Request, *s3.DeleteObjectOutput) {
+	r, resp := b.S3.DeleteObjectRequest(&s3.DeleteObjectInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	})
+	r.ApplyOptions(b.reqOpts...)
+
+	return r, resp
+}
+
+// CopyObjectRequest generates a "aws/request.Request" representing the client's request for the CopyObject operation.
+func (b *Bucket) CopyObjectRequest(dest, src string, opts ...option.CopyObjectInput) (*request.Request, *s3.CopyObjectOutput) {
+	req := &s3.CopyObjectInput{
+		Bucket:     b.Name,
+		Key:        b.key(dest),
+		CopySource: aws.String(copySource(aws.StringValue(b.Name), b.objectKey(src))),
+	}
+
+	for _, f := range b.copyOptions(opts) {
+		f(req)
+	}
+
+	r, resp := b.S3.CopyObjectRequest(req)
+	r.ApplyOptions(b.reqOpts...)
+
+	return r, resp
+}
+
+// ListObjectsV2Request generates a "aws/request.Request" representing the client's request for the ListObjectsV2 operation.
+// The keys in the output are relative to the prefix of the Bucket as in ListObjectsV2PagesWithContext.
+func (b *Bucket) ListObjectsV2Request(prefix string, opts ...option.ListObjectsV2Input) (*request.Request, *s3.ListObjectsV2Output) {
+	req := &s3.ListObjectsV2Input{
+		Bucket: b.Name,
+		Prefix: b.key(prefix),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	b.mapKey(&req.StartAfter)
+
+	r, resp := b.S3.ListObjectsV2Request(req)
+	r.ApplyOptions(b.reqOpts...)
+	r.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		if r.Error == nil {
+			b.unmapListObjectsV2Output(resp)
+		}
+	})
+
+	return r, resp
+}
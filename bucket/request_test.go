@@ -0,0 +1,24 @@
+package bucket
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListObjectsV2Request(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tenant/dir/", r.URL.Query().Get("prefix"))
+		w.Write([]byte(`<ListBucketResult><Prefix>tenant/dir/</Prefix><Contents><Key>tenant/dir/a.txt</Key></Contents></ListBucketResult>`))
+	})
+
+	r, resp := New(svc, "bucket").WithPrefix("tenant/").ListObjectsV2Request("dir/")
+	require.NoError(t, r.Send())
+
+	require.Len(t, resp.Contents, 1)
+	assert.Equal(t, "dir/a.txt", aws.StringValue(resp.Contents[0].Key))
+	assert.Equal(t, "dir/", aws.StringValue(resp.Prefix))
+}
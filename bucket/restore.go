@@ -0,0 +1,102 @@
+package bucket
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ErrInvalidRestore is returned by ParseRestoreStatus when the value is not in the format of x-amz-restore.
+var ErrInvalidRestore = errors.New("bucket: invalid x-amz-restore value")
+
+// ErrNotRestoring is returned by WaitForRestore when no restore of the object is requested.
+var ErrNotRestoring = errors.New("bucket: object is not being restored")
+
+// restorePollInterval is how often WaitForRestore checks the restore. Restores take minutes to hours.
+var restorePollInterval = time.Minute
+
+var restorePattern = regexp.MustCompile(`^ongoing-request="(true|false)"(?:,\s*expiry-date="([^"]+)")?$`)
+
+// A RestoreStatus is the status of the restore of an archived object.
+type RestoreStatus struct {
+	// InProgress is true while the object is being restored.
+	InProgress bool
+
+	// Expiry is the time the restored copy is removed. It is zero while the restore is in progress.
+	Expiry time.Time
+}
+
+// ParseRestoreStatus parses the Restore field of head, e.g. `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`.
+// It returns nil if no restore has been requested or the restored copy has been removed.
+func ParseRestoreStatus(head *s3.HeadObjectOutput) (*RestoreStatus, error) {
+	if head == nil || head.Restore == nil || *head.Restore == "" {
+		return nil, nil
+	}
+
+	m := restorePattern.FindStringSubmatch(*head.Restore)
+	if m == nil {
+		return nil, ErrInvalidRestore
+	}
+
+	status := &RestoreStatus{InProgress: m[1] == "true"}
+	if m[2] != "" {
+		t, err := http.ParseTime(m[2])
+		if err != nil {
+			return nil, ErrInvalidRestore
+		}
+
+		status.Expiry = t
+	}
+
+	return status, nil
+}
+
+// RestoreObject requests a temporary copy of the archived object for key to be restored for days days.
+// tier is one of s3.Tier*, e.g. s3.TierStandard.
+func (b *Bucket) RestoreObject(key string, days int64, tier string) (*s3.RestoreObjectOutput, error) {
+	return b.RestoreObjectWithContext(aws.BackgroundContext(), key, days, tier)
+}
+
+// RestoreObjectWithContext is the same as RestoreObject with the context ctx.
+func (b *Bucket) RestoreObjectWithContext(ctx aws.Context, key string, days int64, tier string) (*s3.RestoreObjectOutput, error) {
+	req := &s3.RestoreObjectInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+		RestoreRequest: &s3.RestoreRequest{
+			Days:                 aws.Int64(days),
+			GlacierJobParameters: &s3.GlacierJobParameters{Tier: aws.String(tier)},
+		},
+	}
+
+	return b.S3.RestoreObjectWithContext(ctx, req, b.reqOpts...)
+}
+
+// WaitForRestore polls HeadObject every minute until the restore of the object for key completes and returns its status.
+// It fails with ErrNotRestoring if no restore is requested.
+func (b *Bucket) WaitForRestore(ctx aws.Context, key string) (*RestoreStatus, error) {
+	for {
+		head, err := b.HeadObjectWithContext(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		status, err := ParseRestoreStatus(head)
+		if err != nil {
+			return nil, err
+		}
+		if status == nil {
+			return nil, ErrNotRestoring
+		}
+		if !status.InProgress {
+			return status, nil
+		}
+
+		if err := aws.SleepWithContext(ctx, restorePollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
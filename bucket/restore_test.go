@@ -0,0 +1,63 @@
+package bucket
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRestoreStatus(t *testing.T) {
+	status, err := ParseRestoreStatus(&s3.HeadObjectOutput{Restore: aws.String(`ongoing-request="true"`)})
+	require.NoError(t, err)
+	assert.Equal(t, &RestoreStatus{InProgress: true}, status)
+
+	status, err = ParseRestoreStatus(&s3.HeadObjectOutput{Restore: aws.String(`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`)})
+	require.NoError(t, err)
+	assert.Equal(t, &RestoreStatus{Expiry: time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC)}, status)
+
+	status, err = ParseRestoreStatus(&s3.HeadObjectOutput{})
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+
+	_, err = ParseRestoreStatus(&s3.HeadObjectOutput{Restore: aws.String(`restoring`)})
+	assert.Equal(t, ErrInvalidRestore, err)
+}
+
+func TestWaitForRestore(t *testing.T) {
+	defer func(d time.Duration) { restorePollInterval = d }(restorePollInterval)
+	restorePollInterval = time.Millisecond
+
+	heads := 0
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			assert.Contains(t, r.URL.RawQuery, "restore")
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodHead:
+			heads++
+			if heads < 3 {
+				w.Header().Set("X-Amz-Restore", `ongoing-request="true"`)
+				return
+			}
+			w.Header().Set("X-Amz-Restore", `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`)
+		}
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.RestoreObject("key", 1, s3.TierBulk)
+	require.NoError(t, err)
+
+	status, err := b.WaitForRestore(aws.BackgroundContext(), "key")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC), status.Expiry)
+	assert.Equal(t, 3, heads)
+
+	svc = newTestS3(t, func(w http.ResponseWriter, r *http.Request) {})
+	_, err = New(svc, "bucket").WaitForRestore(aws.BackgroundContext(), "key")
+	assert.Equal(t, ErrNotRestoring, err)
+}
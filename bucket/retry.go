@@ -0,0 +1,218 @@
+package bucket
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// retryBudgetCap is the maximum number of retries a retry budget can accumulate.
+const retryBudgetCap = 100
+
+// RetryStats holds the counters of requests made through a Bucket.
+type RetryStats struct {
+	// Attempts is the number of HTTP requests sent, including retries.
+	Attempts int64
+
+	// Retries is the number of attempts that were retries.
+	Retries int64
+
+	// Throttles is the number of attempts that failed with a throttling error.
+	Throttles int64
+
+	// Shed is the number of retries that were not made because the retry budget was exhausted.
+	Shed int64
+}
+
+type retryState struct {
+	attempts  int64
+	retries   int64
+	throttles int64
+	shed      int64
+
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+}
+
+// RetryStats returns a snapshot of the retry counters of the Bucket.
+func (b *Bucket) RetryStats() RetryStats {
+	if b.retry == nil {
+		return RetryStats{}
+	}
+
+	return RetryStats{
+		Attempts:  atomic.LoadInt64(&b.retry.attempts),
+		Retries:   atomic.LoadInt64(&b.retry.retries),
+		Throttles: atomic.LoadInt64(&b.retry.throttles),
+		Shed:      atomic.LoadInt64(&b.retry.shed),
+	}
+}
+
+// WithRetryBudget returns an Option that limits retries to roughly ratio of the requests made through the Bucket.
+// Each request adds ratio to the budget and each retry takes one from it. The budget starts with and is capped at
+// 100 retries. A retry is not made when the budget is exhausted so that retries do not amplify an S3 incident.
+func WithRetryBudget(ratio float64) Option {
+	return func(b *Bucket) {
+		b.retry.mu.Lock()
+		defer b.retry.mu.Unlock()
+
+		b.retry.ratio = ratio
+		b.retry.tokens = retryBudgetCap
+	}
+}
+
+// A RetryPolicy controls how requests made through a Bucket with WithRetryPolicy are retried.
+// Zero fields take the values of DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a request.
+	MaxRetries int
+
+	// BaseDelay is the base of the exponential backoff for errors other than throttling.
+	BaseDelay time.Duration
+
+	// ThrottleBaseDelay is the base of the exponential backoff for throttling errors, e.g. 503 SlowDown.
+	ThrottleBaseDelay time.Duration
+
+	// MaxDelay caps the delay before a retry.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by WithRetryPolicy for the zero fields.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:        5,
+		BaseDelay:         50 * time.Millisecond,
+		ThrottleBaseDelay: 500 * time.Millisecond,
+		MaxDelay:          20 * time.Second,
+	}
+}
+
+// WithRetryPolicy returns an Option that retries every request made through the Bucket by p instead of the retryer of
+// the S3 client. A request is retried if IsRetryable reports its error, e.g. 503 SlowDown, RequestTimeout or a reset
+// connection. The delay before the nth retry is chosen at random up to the base delay times 2^n ("full jitter").
+func WithRetryPolicy(p RetryPolicy) Option {
+	def := DefaultRetryPolicy()
+	if p.MaxRetries == 0 {
+		p.MaxRetries = def.MaxRetries
+	}
+	if p.BaseDelay == 0 {
+		p.BaseDelay = def.BaseDelay
+	}
+	if p.ThrottleBaseDelay == 0 {
+		p.ThrottleBaseDelay = def.ThrottleBaseDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			r.Retryer = retryer{p}
+		})
+	}
+}
+
+// retryer is request.Retryer by RetryPolicy.
+type retryer struct {
+	p RetryPolicy
+}
+
+// RetryRules returns the delay before retrying r.
+func (rt retryer) RetryRules(r *request.Request) time.Duration {
+	base := rt.p.BaseDelay
+	// S3 responds to HEAD with 503 and no error code when it slows down.
+	if IsThrottle(r.Error) || statusCode(r.Error) == http.StatusServiceUnavailable {
+		base = rt.p.ThrottleBaseDelay
+	}
+
+	ceiling := rt.p.MaxDelay
+	if n := r.RetryCount; n < 32 {
+		if d := base << uint(n); d > 0 && d < ceiling {
+			ceiling = d
+		}
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// ShouldRetry reports whether r should be retried.
+func (rt retryer) ShouldRetry(r *request.Request) bool {
+	return IsRetryable(r.Error)
+}
+
+// MaxRetries returns the maximum number of retries.
+func (rt retryer) MaxRetries() int {
+	return rt.p.MaxRetries
+}
+
+// requestOption installs the handlers that maintain s on a request.
+func (s *retryState) requestOption(r *request.Request) {
+	r.Handlers.Send.PushFront(func(r *request.Request) {
+		atomic.AddInt64(&s.attempts, 1)
+		if r.RetryCount > 0 {
+			atomic.AddInt64(&s.retries, 1)
+			return
+		}
+
+		s.deposit()
+	})
+
+	// This runs ahead of the core AfterRetryHandler which would schedule the retry.
+	r.Handlers.AfterRetry.PushFront(func(r *request.Request) {
+		if IsThrottle(r.Error) {
+			atomic.AddInt64(&s.throttles, 1)
+		}
+
+		retryable := r.Retryable
+		if retryable == nil {
+			retryable = aws.Bool(r.ShouldRetry(r))
+		}
+
+		if !aws.BoolValue(retryable) || r.RetryCount >= r.MaxRetries() {
+			return
+		}
+
+		if !s.withdraw() {
+			atomic.AddInt64(&s.shed, 1)
+			r.Retryable = aws.Bool(false)
+		}
+	})
+}
+
+// deposit adds the ratio to the budget.
+func (s *retryState) deposit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ratio == 0 {
+		return
+	}
+
+	if s.tokens += s.ratio; s.tokens > retryBudgetCap {
+		s.tokens = retryBudgetCap
+	}
+}
+
+// withdraw takes a retry from the budget. It reports whether the retry is allowed.
+func (s *retryState) withdraw() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ratio == 0 {
+		return true
+	}
+
+	if s.tokens < 1 {
+		return false
+	}
+
+	s.tokens--
+
+	return true
+}
@@ -0,0 +1,83 @@
+package bucket
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetryPolicy(t *testing.T) {
+	var (
+		attempts int32
+		failures int32 = 2
+		status         = http.StatusServiceUnavailable
+	)
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			w.WriteHeader(status)
+			w.Write([]byte(`<Error><Code>SlowDown</Code></Error>`))
+		}
+	})
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, ThrottleBaseDelay: time.Millisecond}
+	b := New(svc, "bucket", WithRetryPolicy(policy))
+
+	// the client itself does not retry
+	_, err := b.HeadObject("key")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), attempts)
+	assert.Equal(t, int64(2), b.RetryStats().Retries)
+
+	attempts, failures, status = 0, 10, http.StatusNotFound
+	_, err = b.HeadObject("key")
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, int32(1), attempts)
+
+	attempts, failures, status = 0, 10, http.StatusServiceUnavailable
+	_, err = b.HeadObject("key")
+	assert.Error(t, err)
+	assert.Equal(t, int32(4), attempts)
+}
+
+func TestWithRetryBudget(t *testing.T) {
+	code := "SlowDown"
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`<Error><Code>` + code + `</Code></Error>`))
+	})
+	policy := RetryPolicy{MaxRetries: 1, BaseDelay: time.Nanosecond, ThrottleBaseDelay: time.Nanosecond}
+
+	const requests = 150
+	b := New(svc, "bucket", WithRetryPolicy(policy), WithRetryBudget(0.1))
+	for i := 0; i < requests; i++ {
+		_, err := b.GetObject("key")
+		require.True(t, IsThrottle(err), "%v", err)
+	}
+
+	// the budget of 100 retries, refilled by 0.1 per request, runs out after about 110 requests
+	stats := b.RetryStats()
+	assert.Greater(t, stats.Retries, int64(100))
+	assert.Less(t, stats.Retries, int64(requests))
+	assert.Equal(t, int64(requests), stats.Retries+stats.Shed, "every request is retried or shed once")
+	assert.Equal(t, requests+stats.Retries, stats.Attempts)
+	assert.Equal(t, stats.Attempts, stats.Throttles, "503 SlowDown is a throttle")
+
+	// no retry is shed without a budget
+	b = New(svc, "bucket", WithRetryPolicy(policy))
+	for i := 0; i < requests; i++ {
+		_, err := b.GetObject("key")
+		require.Error(t, err)
+	}
+	assert.Equal(t, RetryStats{Attempts: 2 * requests, Retries: requests, Throttles: 2 * requests}, b.RetryStats())
+
+	// the other errors are retried but are not throttles
+	code = "InternalError"
+	b = New(svc, "bucket", WithRetryPolicy(policy), WithRetryBudget(0.1))
+	_, err := b.GetObject("key")
+	require.Error(t, err)
+	assert.Equal(t, RetryStats{Attempts: 2, Retries: 1}, b.RetryStats())
+}
@@ -0,0 +1,160 @@
+package bucket
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// grantFields are the fields of the inputs that grant a permission to the grantees in the header format.
+var grantFields = []string{"GrantFullControl", "GrantRead", "GrantReadACP", "GrantWrite", "GrantWriteACP"}
+
+// publicGroups are the URIs of the groups that make a grant public.
+var publicGroups = []string{option.AllUsers, option.AuthenticatedUsers}
+
+// A SecurityProfile is a set of client-side guardrails that are enforced on every request made through the Bucket.
+// Requests that violate the profile fail with *SecurityViolationError before they are sent.
+type SecurityProfile struct {
+	// RequireSSE rejects object writes that do not request server-side encryption.
+	RequireSSE bool
+
+	// DenyPublicACL rejects requests that set the public-read, public-read-write or authenticated-read canned ACL,
+	// or grant a permission to the AllUsers or AuthenticatedUsers group in a Grant field or the AccessControlPolicy.
+	// Bucket policies are not checked.
+	DenyPublicACL bool
+
+	// RequireTLS rejects requests whose endpoint is not HTTPS.
+	RequireTLS bool
+
+	// ExpectedBucketOwner is set as ExpectedBucketOwner on every request that supports it and does not set it already.
+	ExpectedBucketOwner string
+
+	// RequireExpectedBucketOwner rejects requests that support ExpectedBucketOwner but do not set it.
+	RequireExpectedBucketOwner bool
+}
+
+// StrictSecurityProfile returns a SecurityProfile that enables every guardrail.
+// owner is the account ID of the bucket owner and is set as ExpectedBucketOwner on every request.
+func StrictSecurityProfile(owner string) SecurityProfile {
+	return SecurityProfile{
+		RequireSSE:                 true,
+		DenyPublicACL:              true,
+		RequireTLS:                 true,
+		ExpectedBucketOwner:        owner,
+		RequireExpectedBucketOwner: true,
+	}
+}
+
+// A SecurityViolationError is returned when a request violates the SecurityProfile of the Bucket.
+type SecurityViolationError struct {
+	Operation string
+	Reason    string
+}
+
+func (e *SecurityViolationError) Error() string {
+	return fmt.Sprintf("bucket: %s rejected by security profile: %s", e.Operation, e.Reason)
+}
+
+// WithSecurityProfile returns an Option that enforces p on every request made through the Bucket.
+func WithSecurityProfile(p SecurityProfile) Option {
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			r.Handlers.Validate.PushFront(func(r *request.Request) {
+				if reason := p.check(r); reason != "" {
+					r.Error = &SecurityViolationError{
+						Operation: r.Operation.Name,
+						Reason:    reason,
+					}
+				}
+			})
+		})
+	}
+}
+
+// check applies p to r and returns the reason of the violation if any.
+func (p SecurityProfile) check(r *request.Request) string {
+	if p.RequireTLS && r.HTTPRequest.URL.Scheme != "https" {
+		return "endpoint must use TLS"
+	}
+
+	params := reflect.ValueOf(r.Params)
+	if params.Kind() != reflect.Ptr || params.IsNil() {
+		return ""
+	}
+
+	if owner := params.Elem().FieldByName("ExpectedBucketOwner"); owner.IsValid() && owner.IsNil() {
+		if p.ExpectedBucketOwner != "" {
+			owner.Set(reflect.ValueOf(aws.String(p.ExpectedBucketOwner)))
+		} else if p.RequireExpectedBucketOwner {
+			return "ExpectedBucketOwner must be set"
+		}
+	}
+
+	if p.DenyPublicACL && publicACL(params.Elem()) {
+		return "public ACL is not allowed"
+	}
+
+	if p.RequireSSE {
+		var sse, ssec *string
+		switch in := r.Params.(type) {
+		case *s3.PutObjectInput:
+			sse, ssec = in.ServerSideEncryption, in.SSECustomerAlgorithm
+		case *s3.CopyObjectInput:
+			sse, ssec = in.ServerSideEncryption, in.SSECustomerAlgorithm
+		case *s3.CreateMultipartUploadInput:
+			sse, ssec = in.ServerSideEncryption, in.SSECustomerAlgorithm
+		default:
+			return ""
+		}
+
+		if sse == nil && ssec == nil {
+			return "server-side encryption must be requested"
+		}
+	}
+
+	return ""
+}
+
+// publicACL reports whether the input in sets a canned ACL or a grant that gives access to everyone or any AWS account.
+func publicACL(in reflect.Value) bool {
+	if acl := in.FieldByName("ACL"); acl.IsValid() && !acl.IsNil() {
+		switch acl.Elem().String() {
+		case s3.ObjectCannedACLPublicRead, s3.ObjectCannedACLPublicReadWrite, s3.ObjectCannedACLAuthenticatedRead:
+			return true
+		}
+	}
+
+	for _, name := range grantFields {
+		grant := in.FieldByName(name)
+		if !grant.IsValid() || grant.IsNil() {
+			continue
+		}
+
+		for _, uri := range publicGroups {
+			if strings.Contains(grant.Elem().String(), uri) {
+				return true
+			}
+		}
+	}
+
+	if policy := in.FieldByName("AccessControlPolicy"); policy.IsValid() && !policy.IsNil() {
+		for _, g := range policy.Interface().(*s3.AccessControlPolicy).Grants {
+			if g.Grantee == nil {
+				continue
+			}
+
+			for _, uri := range publicGroups {
+				if aws.StringValue(g.Grantee.URI) == uri {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
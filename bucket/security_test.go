@@ -0,0 +1,171 @@
+package bucket
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSecurityProfileExpectedBucketOwner(t *testing.T) {
+	var headers []http.Header
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		if r.Method == http.MethodGet && r.URL.Path == "/bucket" {
+			w.Write([]byte(`<ListBucketResult></ListBucketResult>`))
+		}
+	})
+	b := New(svc, "bucket", WithSecurityProfile(SecurityProfile{ExpectedBucketOwner: "123456789012"}))
+
+	_, err := b.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	_, err = b.GetObject("key")
+	require.NoError(t, err)
+	_, err = b.HeadObject("key")
+	require.NoError(t, err)
+	_, err = b.ListObjects("")
+	require.NoError(t, err)
+	_, err = b.DeleteObject("key")
+	require.NoError(t, err)
+
+	require.Len(t, headers, 5)
+	for _, h := range headers {
+		assert.Equal(t, "123456789012", h.Get("X-Amz-Expected-Bucket-Owner"))
+	}
+
+	// the owner set on the request is kept
+	_, err = b.PutObject("key", strings.NewReader("hello"), option.ExpectedBucketOwner("210987654321"))
+	require.NoError(t, err)
+	assert.Equal(t, "210987654321", headers[5].Get("X-Amz-Expected-Bucket-Owner"))
+
+	// CreateBucket has no ExpectedBucketOwner
+	_, err = b.CreateBucket()
+	require.NoError(t, err)
+	assert.Empty(t, headers[6].Get("X-Amz-Expected-Bucket-Owner"))
+}
+
+func TestWithSecurityProfileRequireExpectedBucketOwner(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<ListAllMyBucketsResult></ListAllMyBucketsResult>`))
+	})
+	b := New(svc, "bucket", WithSecurityProfile(SecurityProfile{RequireExpectedBucketOwner: true}))
+
+	_, err := b.GetObject("key")
+	var verr *SecurityViolationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "GetObject", verr.Operation)
+
+	_, err = b.GetObject("key", option.GetExpectedBucketOwner("123456789012"))
+	assert.NoError(t, err)
+
+	// the requests without ExpectedBucketOwner are left alone
+	_, err = b.S3.ListBucketsWithContext(aws.BackgroundContext(), &s3.ListBucketsInput{}, b.reqOpts...)
+	assert.NoError(t, err)
+}
+
+func TestWithSecurityProfileACL(t *testing.T) {
+	var header http.Header
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	})
+	b := New(svc, "bucket", WithSecurityProfile(SecurityProfile{DenyPublicACL: true}))
+
+	_, err := b.PutObject("key", strings.NewReader("hello"), option.ACLPublicRead())
+	var verr *SecurityViolationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "PutObject", verr.Operation)
+
+	_, err = b.PutObjectACL("key", s3.ObjectCannedACLPublicReadWrite)
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "PutObjectAcl", verr.Operation)
+
+	_, err = b.PutObject("key", strings.NewReader("hello"), func(req *s3.PutObjectInput) {
+		req.ACL = aws.String(s3.ObjectCannedACLAuthenticatedRead)
+	})
+	require.ErrorAs(t, err, &verr)
+
+	for _, grant := range []option.PutObjectACLInput{
+		option.GrantRead(option.GranteeURI(option.AllUsers)),
+		option.GrantReadACP(option.GranteeID("abc"), option.GranteeURI(option.AuthenticatedUsers)),
+		option.GrantWriteACP(option.GranteeURI(option.AllUsers)),
+		option.GrantFullControl(option.GranteeURI(option.AuthenticatedUsers)),
+	} {
+		_, err = b.PutObjectACL("key", "", grant)
+		require.ErrorAs(t, err, &verr)
+	}
+
+	_, err = b.PutObject("key", strings.NewReader("hello"), func(req *s3.PutObjectInput) {
+		req.GrantRead = aws.String(option.GranteeURI(option.AllUsers))
+	})
+	require.ErrorAs(t, err, &verr)
+
+	_, err = b.PutObjectACL("key", "", func(req *s3.PutObjectAclInput) {
+		req.AccessControlPolicy = &s3.AccessControlPolicy{
+			Owner: &s3.Owner{ID: aws.String("abc")},
+			Grants: []*s3.Grant{{
+				Grantee:    &s3.Grantee{Type: aws.String(s3.TypeGroup), URI: aws.String(option.AllUsers)},
+				Permission: aws.String(s3.PermissionRead),
+			}},
+		}
+	})
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "PutObjectAcl", verr.Operation)
+
+	// the grants to accounts are allowed
+	_, err = b.PutObjectACL("key", "", option.GrantRead(option.GranteeID("abc")))
+	require.NoError(t, err)
+	assert.Equal(t, `id="abc"`, header.Get("X-Amz-Grant-Read"))
+
+	_, err = b.PutObjectACL("key", "", func(req *s3.PutObjectAclInput) {
+		req.AccessControlPolicy = &s3.AccessControlPolicy{
+			Owner: &s3.Owner{ID: aws.String("abc")},
+			Grants: []*s3.Grant{{
+				Grantee:    &s3.Grantee{Type: aws.String(s3.TypeCanonicalUser), ID: aws.String("def")},
+				Permission: aws.String(s3.PermissionRead),
+			}},
+		}
+	})
+	require.NoError(t, err)
+
+	_, err = b.PutObject("key", strings.NewReader("hello"), option.ACLPrivate())
+	require.NoError(t, err)
+	assert.Equal(t, "private", header.Get("X-Amz-Acl"))
+
+	_, err = b.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Empty(t, header.Get("X-Amz-Acl"), "no ACL is added")
+}
+
+func TestWithSecurityProfileRequireSSE(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {})
+	b := New(svc, "bucket", WithSecurityProfile(SecurityProfile{RequireSSE: true}))
+
+	_, err := b.PutObject("key", strings.NewReader("hello"))
+	var verr *SecurityViolationError
+	require.ErrorAs(t, err, &verr)
+
+	_, err = b.PutObject("key", strings.NewReader("hello"), option.SSES3())
+	assert.NoError(t, err)
+
+	// reads do not request encryption
+	_, err = b.GetObject("key")
+	assert.NoError(t, err)
+}
+
+func TestWithSecurityProfileRequireTLS(t *testing.T) {
+	called := false
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	_, err := New(svc, "bucket", WithSecurityProfile(StrictSecurityProfile("123456789012"))).GetObject("key")
+	var verr *SecurityViolationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "endpoint must use TLS", verr.Reason)
+	assert.False(t, called)
+}
@@ -0,0 +1,26 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/selectquery"
+)
+
+// SelectObjectContent runs the S3 Select SQL expression on the object key and returns a reader of the result records
+// serialized as out. See the selectquery package for the serializations and for decoding the records into Go values.
+// A caller of this MUST close the reader.
+func (b *Bucket) SelectObjectContent(ctx aws.Context, key, expression string, in *s3.InputSerialization, out *s3.OutputSerialization) (*selectquery.Reader, error) {
+	resp, err := b.S3.SelectObjectContentWithContext(ctx, &s3.SelectObjectContentInput{
+		Bucket:              b.Name,
+		Key:                 b.key(key),
+		Expression:          aws.String(expression),
+		ExpressionType:      aws.String(s3.ExpressionTypeSql),
+		InputSerialization:  in,
+		OutputSerialization: out,
+	}, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectquery.NewReader(resp.EventStream), nil
+}
@@ -0,0 +1,60 @@
+package bucket
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/ioutils"
+)
+
+// ErrObjectTooLarge is returned when an upload exceeds the limit set by WithMaxObjectSize.
+var ErrObjectTooLarge = errors.New("bucket: object exceeds the maximum size")
+
+// WithMaxObjectSize returns an Option that rejects uploads through the Bucket larger than n bytes with ErrObjectTooLarge.
+// The size of PutObject is taken from Content-Length or the remaining length of the body.
+// n of zero or less means no limit.
+func WithMaxObjectSize(n int64) Option {
+	return func(b *Bucket) {
+		if n <= 0 {
+			b.maxObjectSize = 0
+			return
+		}
+
+		b.maxObjectSize = n
+
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			in, ok := r.Params.(*s3.PutObjectInput)
+			if !ok {
+				return
+			}
+
+			r.Handlers.Validate.PushBack(func(r *request.Request) {
+				size, err := putObjectSize(in)
+				if err != nil {
+					r.Error = err
+					return
+				}
+
+				if size > n {
+					r.Error = fmt.Errorf("%w: %s is %d bytes, the limit is %d bytes", ErrObjectTooLarge, aws.StringValue(in.Key), size, n)
+				}
+			})
+		})
+	}
+}
+
+// putObjectSize returns the number of bytes in.Body will upload.
+func putObjectSize(in *s3.PutObjectInput) (int64, error) {
+	if in.ContentLength != nil {
+		return *in.ContentLength, nil
+	}
+
+	if in.Body == nil {
+		return 0, nil
+	}
+
+	return ioutils.Remaining(in.Body)
+}
@@ -0,0 +1,112 @@
+package bucket
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMaxObjectSize(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && q.Has("uploads"):
+			requests = append(requests, "create")
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+		case r.Method == http.MethodPut && q.Has("partNumber"):
+			requests = append(requests, "part")
+			w.Header().Set("ETag", `"part"`)
+		case r.Method == http.MethodPut:
+			requests = append(requests, "put")
+		case r.Method == http.MethodDelete:
+			requests = append(requests, "abort")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	reset := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		sent := requests
+		requests = nil
+		return sent
+	}
+
+	// io.MultiReader hides Seek
+	stream := func(data []byte) io.Reader { return io.MultiReader(bytes.NewReader(data)) }
+
+	t.Run("PutObject", func(t *testing.T) {
+		b := New(svc, "bucket", WithMaxObjectSize(4))
+
+		_, err := b.PutObject("key", strings.NewReader("hello"))
+		assert.ErrorIs(t, err, ErrObjectTooLarge)
+		assert.Empty(t, reset())
+
+		_, err = b.PutObject("key", strings.NewReader("four"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"put"}, reset())
+	})
+
+	t.Run("NoLimit", func(t *testing.T) {
+		for _, n := range []int64{0, -1} {
+			b := New(svc, "bucket", WithMaxObjectSize(n))
+
+			_, err := b.PutObject("key", strings.NewReader("hello"))
+			require.NoError(t, err, n)
+			_, err = b.PutObjectFromReader(aws.BackgroundContext(), "key", stream([]byte("hello")))
+			require.NoError(t, err, n)
+			assert.Equal(t, []string{"put", "put"}, reset(), n)
+		}
+	})
+
+	t.Run("File", func(t *testing.T) {
+		b := New(svc, "bucket", WithMaxObjectSize(4))
+
+		path := filepath.Join(t.TempDir(), "data.txt")
+		require.NoError(t, os.WriteFile(path, []byte("hello"), 0o600))
+
+		_, err := b.PutObjectFromFile(aws.BackgroundContext(), "key", path)
+		assert.ErrorIs(t, err, ErrObjectTooLarge)
+		assert.Empty(t, reset())
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		b := New(svc, "bucket", WithMaxObjectSize(minPartSize+1))
+
+		_, err := b.PutObjectStream("key", stream([]byte("hello")))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"put"}, reset())
+
+		_, err = b.PutObjectStream("key", stream(make([]byte, 2*minPartSize)))
+		assert.ErrorIs(t, err, ErrObjectTooLarge)
+		assert.Equal(t, []string{"create", "part", "abort"}, reset(), "the second part is not uploaded")
+	})
+
+	t.Run("Spool", func(t *testing.T) {
+		b := New(svc, "bucket", WithMaxObjectSize(4))
+
+		_, err := b.PutObjectFromReader(aws.BackgroundContext(), "key", stream([]byte("hello")))
+		assert.ErrorIs(t, err, ErrObjectTooLarge)
+		assert.Empty(t, reset())
+
+		_, err = b.PutObjectFromReader(aws.BackgroundContext(), "key", stream([]byte("four")))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"put"}, reset())
+	})
+}
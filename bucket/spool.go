@@ -0,0 +1,57 @@
+package bucket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/nabeken/aws-go-s3/ioutils"
+)
+
+// defaultSpoolMemLimit is the size up to which PutObjectFromReader holds the body in memory.
+const defaultSpoolMemLimit = 8 << 20
+
+type spoolConfig struct {
+	dir      string
+	memLimit int64
+	limit    int64
+}
+
+// WithSpool returns an Option that configures how PutObjectFromReader spools a body. A body up to memLimit bytes is held
+// in memory and a larger one is written to a temporary file in dir. A body larger than limit is rejected unless limit is zero.
+// By default, a body up to 8 MiB is held in memory and the default directory for temporary files is used.
+func WithSpool(dir string, memLimit, limit int64) Option {
+	return func(b *Bucket) {
+		b.spool = spoolConfig{dir: dir, memLimit: memLimit, limit: limit}
+	}
+}
+
+// PutObjectFromReader puts an object with reading data from r that may not be seekable, e.g. an HTTP request body.
+// r is spooled as configured by WithSpool so that the SDK can rewind the body to retry the upload.
+// It fails with ioutils.ErrSpoolLimit if r exceeds the limit, or ErrObjectTooLarge if it exceeds WithMaxObjectSize.
+func (b *Bucket) PutObjectFromReader(ctx aws.Context, key string, r io.Reader, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		return b.PutObjectWithContext(ctx, key, rs, opts...)
+	}
+
+	limit := b.spool.limit
+	tooLarge := false
+	if b.maxObjectSize > 0 && (limit == 0 || b.maxObjectSize < limit) {
+		limit = b.maxObjectSize
+		tooLarge = true
+	}
+
+	body, err := ioutils.Spool(r, b.spool.dir, b.spool.memLimit, limit)
+	if tooLarge && errors.Is(err, ioutils.ErrSpoolLimit) {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrObjectTooLarge, key, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return b.PutObjectWithContext(ctx, key, body, opts...)
+}
@@ -0,0 +1,60 @@
+package bucket
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSECustomerKey(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	sum := md5.Sum(key)
+	encodedKey := base64.StdEncoding.EncodeToString(key)
+	encodedMD5 := base64.StdEncoding.EncodeToString(sum[:])
+
+	var header http.Header
+	// the SDK refuses to send SSE-C keys over HTTP
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		if r.Header.Get("X-Amz-Copy-Source") != "" {
+			w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	// a custom CA bundle would replace the TLS config of the test client
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	svc := s3.New(session.Must(session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(srv.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		MaxRetries:       aws.Int(0),
+		HTTPClient:       srv.Client(),
+	})))
+	b := New(svc, "bucket")
+
+	_, err := b.PutObject("key", strings.NewReader("data"), option.SSECustomerKey(key))
+	require.NoError(t, err)
+	assert.Equal(t, "AES256", header.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm"))
+	assert.Equal(t, encodedKey, header.Get("X-Amz-Server-Side-Encryption-Customer-Key"))
+	assert.Equal(t, encodedMD5, header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5"))
+
+	_, err = b.CopyObject("dst", "key", option.CopySourceSSECustomerKey(key), option.CopySSECustomerKey(key))
+	require.NoError(t, err)
+	assert.Equal(t, encodedKey, header.Get("X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key"))
+	assert.Equal(t, encodedMD5, header.Get("X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key-Md5"))
+	assert.Equal(t, encodedKey, header.Get("X-Amz-Server-Side-Encryption-Customer-Key"))
+}
@@ -0,0 +1,143 @@
+package bucket
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// PutObjectStream puts an object with reading data from r that may not be seekable without holding the whole body.
+// A body up to 8 MiB is put with PutObject. A larger one is uploaded with a multipart upload whose 8 MiB parts are
+// read from r one by one and uploaded concurrently, so at most 6 parts are held in memory. A multipart upload is
+// limited to 10000 parts, i.e. about 78 GiB.
+//
+// For a multipart upload, the output has the fields of s3.CompleteMultipartUploadOutput and opts are applied to
+// s3.CreateMultipartUploadInput through the fields with the same name. The upload is aborted if it fails.
+func (b *Bucket) PutObjectStream(key string, r io.Reader, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	return b.PutObjectStreamWithContext(aws.BackgroundContext(), key, r, opts...)
+}
+
+// PutObjectStreamWithContext is the same as PutObjectStream with the context ctx.
+func (b *Bucket) PutObjectStreamWithContext(ctx aws.Context, key string, r io.Reader, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		return b.PutObjectWithContext(ctx, key, rs, opts...)
+	}
+
+	first, eof, err := readPart(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if eof {
+		return b.PutObjectWithContext(ctx, key, bytes.NewReader(first), opts...)
+	}
+
+	return b.multipartUpload(ctx, key, opts, func(upload *s3.CreateMultipartUploadOutput, put *s3.PutObjectInput) ([]*s3.CompletedPart, int64, error) {
+		return b.streamParts(ctx, key, upload, put, first, r)
+	})
+}
+
+// streamParts uploads first and then the rest of r in parts concurrently and returns the completed parts in order
+// and the size of the object.
+func (b *Bucket) streamParts(ctx aws.Context, key string, upload *s3.CreateMultipartUploadOutput, put *s3.PutObjectInput, first []byte, r io.Reader) ([]*s3.CompletedPart, int64, error) {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		parts []*s3.CompletedPart
+		perr  error
+		sem   = make(chan struct{}, fileUploadConcurrency)
+		size  int64
+	)
+
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if perr == nil {
+			perr = err
+		}
+	}
+
+	part, eof := first, false
+	for num := int64(1); ; num++ {
+		mu.Lock()
+		failed := perr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		if num > maxParts {
+			setErr(fmt.Errorf("bucket: %s exceeds %d parts of %d bytes", key, maxParts, minPartSize))
+			break
+		}
+
+		size += int64(len(part))
+		if b.maxObjectSize > 0 && size > b.maxObjectSize {
+			setErr(fmt.Errorf("%w: %s is larger than %d bytes", ErrObjectTooLarge, key, b.maxObjectSize))
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(num int64, body []byte) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			completed, err := b.uploadPart(ctx, upload, put, num, bytes.NewReader(body))
+			if err != nil {
+				setErr(err)
+				return
+			}
+
+			mu.Lock()
+			parts = append(parts, completed)
+			mu.Unlock()
+		}(num, part)
+
+		if eof {
+			break
+		}
+
+		var err error
+		part, eof, err = readPart(r)
+		if err != nil {
+			setErr(err)
+			break
+		}
+
+		if len(part) == 0 {
+			break
+		}
+	}
+
+	wg.Wait()
+
+	if perr != nil {
+		return nil, 0, perr
+	}
+
+	sortParts(parts)
+
+	return parts, size, nil
+}
+
+// readPart reads a part of a multipart upload from r. eof reports whether r has no more data after the part.
+func readPart(r io.Reader) (part []byte, eof bool, err error) {
+	buf := make([]byte, minPartSize)
+
+	n, err := io.ReadFull(r, buf)
+	switch err {
+	case nil:
+		return buf, false, nil
+	case io.EOF, io.ErrUnexpectedEOF:
+		return buf[:n], true, nil
+	}
+
+	return nil, false, err
+}
@@ -0,0 +1,72 @@
+package bucket
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutObjectStream(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received map[string]int
+		aborted  bool
+	)
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && q.Has("uploads"):
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+		case r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			received[q.Get("partNumber")] = len(body)
+			w.Header().Set("ETag", `"part"`)
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"etag-2"</ETag></CompleteMultipartUploadResult>`))
+		case r.Method == http.MethodDelete:
+			aborted = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	b := New(svc, "bucket")
+
+	// io.MultiReader hides Seek from PutObjectStream
+	stream := func(data []byte) io.Reader { return io.MultiReader(bytes.NewReader(data)) }
+
+	t.Run("Small", func(t *testing.T) {
+		received = map[string]int{}
+
+		_, err := b.PutObjectStream("key", stream([]byte("hello")))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"": 5}, received)
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		received = map[string]int{}
+		data := bytes.Repeat([]byte("0123456789"), (2*minPartSize+minPartSize/2)/10)
+
+		resp, err := b.PutObjectStream("key", stream(data))
+		require.NoError(t, err)
+		assert.Equal(t, `"etag-2"`, aws.StringValue(resp.ETag))
+		assert.Equal(t, map[string]int{"1": minPartSize, "2": minPartSize, "3": len(data) - 2*minPartSize}, received)
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		received = map[string]int{}
+		data := make([]byte, minPartSize+1)
+
+		_, err := New(svc, "bucket", WithMaxObjectSize(minPartSize)).PutObjectStream("key", stream(data))
+		assert.ErrorIs(t, err, ErrObjectTooLarge)
+		assert.True(t, aborted)
+	})
+}
@@ -0,0 +1,414 @@
+package bucket
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// defaultSyncConcurrency is the number of files SyncUp and SyncDown transfer at the same time by default.
+const defaultSyncConcurrency = 5
+
+// A SyncOption changes the behavior of SyncUp and SyncDown.
+type SyncOption func(c *syncConfig)
+
+type syncConfig struct {
+	delete      bool
+	dryRun      bool
+	concurrency int
+	putOpts     []option.PutObjectInput
+}
+
+// SyncDelete deletes the objects or the files in the destination that do not exist in the source.
+func SyncDelete() SyncOption {
+	return func(c *syncConfig) {
+		c.delete = true
+	}
+}
+
+// SyncDryRun reports what would be transferred and deleted without changing anything.
+func SyncDryRun() SyncOption {
+	return func(c *syncConfig) {
+		c.dryRun = true
+	}
+}
+
+// SyncConcurrency transfers up to n files at the same time. The default is 5.
+func SyncConcurrency(n int) SyncOption {
+	return func(c *syncConfig) {
+		c.concurrency = n
+	}
+}
+
+// SyncPutObjectOptions applies opts to the objects uploaded by SyncUp.
+func SyncPutObjectOptions(opts ...option.PutObjectInput) SyncOption {
+	return func(c *syncConfig) {
+		c.putOpts = append(c.putOpts, opts...)
+	}
+}
+
+// A SyncSummary reports the result of SyncUp and SyncDown. The keys are sorted.
+type SyncSummary struct {
+	// Transferred is the keys of the objects uploaded or downloaded.
+	Transferred []string
+
+	// Deleted is the keys of the objects deleted by SyncUp, or the slash-separated paths relative to localDir of the
+	// files deleted by SyncDown, with SyncDelete.
+	Deleted []string
+
+	// Skipped is the number of the unchanged files.
+	Skipped int
+
+	// Bytes is the total size of the transferred files.
+	Bytes int64
+}
+
+// SyncUp uploads the regular files under localDir to the keys under prefix like "aws s3 sync" does.
+// The key of a file is prefix followed by "/", unless prefix is empty or ends with it, and the slash-separated path of
+// the file relative to localDir.
+//
+// A file is uploaded unless an object with the same size exists and either its ETag is the MD5 of the file or,
+// for the objects uploaded with a multipart upload, it is modified after the file.
+// The summary holds the changes done so far if an error occurs.
+func (b *Bucket) SyncUp(ctx aws.Context, localDir, prefix string, opts ...SyncOption) (*SyncSummary, error) {
+	c := newSyncConfig(opts)
+	prefix = syncPrefix(prefix)
+
+	files, err := localFiles(localDir)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := b.syncObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &syncSummary{}
+	err = syncEach(ctx, c.concurrency, sortedKeys(files), func(ctx aws.Context, rel string) error {
+		fi, path := files[rel], filepath.Join(localDir, filepath.FromSlash(rel))
+
+		changed, err := fileChanged(path, fi, objects[rel], true)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			s.skip()
+			return nil
+		}
+
+		if !c.dryRun {
+			if _, err := b.PutObjectFromFile(ctx, prefix+rel, path, c.putOpts...); err != nil {
+				return err
+			}
+		}
+
+		s.transfer(prefix+rel, fi.Size())
+
+		return nil
+	})
+	if err != nil || !c.delete {
+		return s.result(), err
+	}
+
+	var extraneous []*s3.ObjectIdentifier
+	for _, rel := range sortedKeys(objects) {
+		if _, ok := files[rel]; !ok {
+			extraneous = append(extraneous, &s3.ObjectIdentifier{Key: aws.String(prefix + rel)})
+		}
+	}
+
+	if len(extraneous) > 0 && !c.dryRun {
+		if _, err := b.deleteAll(ctx, extraneous); err != nil {
+			return s.result(), err
+		}
+	}
+
+	for _, id := range extraneous {
+		s.delete(aws.StringValue(id.Key))
+	}
+
+	return s.result(), nil
+}
+
+// SyncDown downloads the objects under prefix to the files under localDir like "aws s3 sync" does.
+// It is the reverse of SyncUp. The objects whose keys end with "/" or do not map to a path under localDir are ignored.
+//
+// An object is downloaded unless a file with the same size exists and either the ETag of the object is the MD5 of the
+// file or, for the objects uploaded with a multipart upload, the file is not modified before the object.
+// The modification time of a downloaded file is set to Last-Modified of the object.
+// The summary holds the changes done so far if an error occurs.
+func (b *Bucket) SyncDown(ctx aws.Context, localDir, prefix string, opts ...SyncOption) (*SyncSummary, error) {
+	c := newSyncConfig(opts)
+	prefix = syncPrefix(prefix)
+
+	objects, err := b.syncObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := localFiles(localDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	s := &syncSummary{}
+	err = syncEach(ctx, c.concurrency, sortedKeys(objects), func(ctx aws.Context, rel string) error {
+		o, path := objects[rel], filepath.Join(localDir, filepath.FromSlash(rel))
+
+		if fi, ok := files[rel]; ok {
+			changed, err := fileChanged(path, fi, o, false)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				s.skip()
+				return nil
+			}
+		}
+
+		if !c.dryRun {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+
+			if _, err := b.GetObjectToFile(ctx, prefix+rel, path, option.GetIfMatch(aws.StringValue(o.ETag))); err != nil {
+				return err
+			}
+		}
+
+		s.transfer(prefix+rel, aws.Int64Value(o.Size))
+
+		return nil
+	})
+	if err != nil || !c.delete {
+		return s.result(), err
+	}
+
+	for _, rel := range sortedKeys(files) {
+		if _, ok := objects[rel]; ok {
+			continue
+		}
+
+		if !c.dryRun {
+			if err := os.Remove(filepath.Join(localDir, filepath.FromSlash(rel))); err != nil {
+				return s.result(), err
+			}
+		}
+
+		s.delete(rel)
+	}
+
+	return s.result(), nil
+}
+
+func newSyncConfig(opts []SyncOption) *syncConfig {
+	c := &syncConfig{concurrency: defaultSyncConcurrency}
+	for _, f := range opts {
+		f(c)
+	}
+
+	if c.concurrency <= 0 {
+		c.concurrency = 1
+	}
+
+	return c
+}
+
+func syncPrefix(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+
+	return prefix + "/"
+}
+
+// syncObjects returns the objects under prefix by the slash-separated paths relative to prefix.
+func (b *Bucket) syncObjects(ctx aws.Context, prefix string) (map[string]*s3.Object, error) {
+	objects := map[string]*s3.Object{}
+	for o, err := range b.Objects(ctx, prefix) {
+		if err != nil {
+			return nil, err
+		}
+
+		rel := strings.TrimPrefix(aws.StringValue(o.Key), prefix)
+		if rel == "" || strings.HasSuffix(rel, "/") || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			continue
+		}
+
+		objects[rel] = o
+	}
+
+	return objects, nil
+}
+
+// localFiles returns the regular files under dir by the slash-separated paths relative to dir.
+func localFiles(dir string) (map[string]fs.FileInfo, error) {
+	files := map[string]fs.FileInfo{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = fi
+
+		return nil
+	})
+
+	return files, err
+}
+
+// fileChanged reports whether the file at path and the object o differ. For the multipart ETags, the file is changed
+// if it is modified after the object when up is true, or if the object is modified after the file otherwise.
+func fileChanged(path string, fi fs.FileInfo, o *s3.Object, up bool) (bool, error) {
+	if o == nil || fi.Size() != aws.Int64Value(o.Size) {
+		return true, nil
+	}
+
+	etag := strings.Trim(aws.StringValue(o.ETag), `"`)
+	if len(etag) == md5.Size*2 && !strings.Contains(etag, "-") {
+		sum, err := fileMD5(path)
+		if err != nil {
+			return false, err
+		}
+
+		return sum != etag, nil
+	}
+
+	modified := aws.TimeValue(o.LastModified).Truncate(time.Second)
+	if up {
+		return fi.ModTime().Truncate(time.Second).After(modified), nil
+	}
+
+	return modified.After(fi.ModTime().Truncate(time.Second)), nil
+}
+
+func fileMD5(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// syncEach calls fn with each key with up to concurrency calls at the same time.
+// It stops at the first error and returns it.
+func syncEach(ctx aws.Context, concurrency int, keys []string, fn func(ctx aws.Context, key string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		serr error
+		sem  = make(chan struct{}, concurrency)
+	)
+
+	for _, key := range keys {
+		mu.Lock()
+		failed := serr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(ctx, key); err != nil {
+				mu.Lock()
+				if serr == nil {
+					serr = fmt.Errorf("bucket: failed to sync %s: %w", key, err)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(key)
+	}
+
+	wg.Wait()
+
+	return serr
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// syncSummary collects SyncSummary from concurrent transfers.
+type syncSummary struct {
+	mu sync.Mutex
+	s  SyncSummary
+}
+
+func (s *syncSummary) transfer(key string, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.s.Transferred = append(s.s.Transferred, key)
+	s.s.Bytes += size
+}
+
+func (s *syncSummary) skip() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.s.Skipped++
+}
+
+func (s *syncSummary) delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.s.Deleted = append(s.s.Deleted, key)
+}
+
+func (s *syncSummary) result() *SyncSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.s
+	sort.Strings(r.Transferred)
+	sort.Strings(r.Deleted)
+
+	return &r
+}
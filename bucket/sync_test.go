@@ -0,0 +1,142 @@
+package bucket
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSyncTestBucket returns a Bucket backed by an in-memory bucket with objects.
+func newSyncTestBucket(t *testing.T, objects map[string]string) *Bucket {
+	var mu sync.Mutex
+
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+			fmt.Fprint(w, `<ListBucketResult>`)
+			for k, v := range objects {
+				if !strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+					continue
+				}
+				sum := md5.Sum([]byte(v))
+				fmt.Fprintf(w, `<Contents><Key>%s</Key><Size>%d</Size><ETag>"%s"</ETag><LastModified>2006-01-02T15:04:05Z</LastModified></Contents>`,
+					k, len(v), hex.EncodeToString(sum[:]))
+			}
+			fmt.Fprint(w, `</ListBucketResult>`)
+		case r.Method == http.MethodGet:
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			fmt.Fprint(w, objects[key])
+		case r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			objects[key] = string(body)
+		case r.Method == http.MethodPost:
+			var req struct {
+				Objects []struct{ Key string } `xml:"Object"`
+			}
+			require.NoError(t, xml.NewDecoder(r.Body).Decode(&req))
+
+			fmt.Fprint(w, `<DeleteResult>`)
+			for _, o := range req.Objects {
+				delete(objects, o.Key)
+				fmt.Fprintf(w, `<Deleted><Key>%s</Key></Deleted>`, o.Key)
+			}
+			fmt.Fprint(w, `</DeleteResult>`)
+		}
+	})
+
+	return New(svc, "bucket")
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	}
+}
+
+func TestSyncUp(t *testing.T) {
+	objects := map[string]string{
+		"p/same.txt":    "same",
+		"p/changed.txt": "old!",
+		"p/extra.txt":   "extra",
+		"other.txt":     "other",
+	}
+	b := newSyncTestBucket(t, objects)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"same.txt":    "same",
+		"changed.txt": "new!",
+		"sub/new.txt": "new",
+	})
+
+	summary, err := b.SyncUp(aws.BackgroundContext(), dir, "p", SyncDelete(), SyncDryRun())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p/changed.txt", "p/sub/new.txt"}, summary.Transferred)
+	assert.Equal(t, []string{"p/extra.txt"}, summary.Deleted)
+	assert.Len(t, objects, 4)
+
+	summary, err = b.SyncUp(aws.BackgroundContext(), dir, "p", SyncDelete())
+	require.NoError(t, err)
+	assert.Equal(t, &SyncSummary{
+		Transferred: []string{"p/changed.txt", "p/sub/new.txt"},
+		Deleted:     []string{"p/extra.txt"},
+		Skipped:     1,
+		Bytes:       7,
+	}, summary)
+	assert.Equal(t, map[string]string{
+		"p/same.txt":    "same",
+		"p/changed.txt": "new!",
+		"p/sub/new.txt": "new",
+		"other.txt":     "other",
+	}, objects)
+}
+
+func TestSyncDown(t *testing.T) {
+	b := newSyncTestBucket(t, map[string]string{
+		"p/same.txt":    "same",
+		"p/sub/new.txt": "new",
+		"p/dir/":        "",
+		"p/../escape":   "escape",
+	})
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"same.txt":  "same",
+		"extra.txt": "extra",
+	})
+
+	summary, err := b.SyncDown(aws.BackgroundContext(), dir, "p/", SyncDelete())
+	require.NoError(t, err)
+	assert.Equal(t, &SyncSummary{
+		Transferred: []string{"p/sub/new.txt"},
+		Deleted:     []string{"extra.txt"},
+		Skipped:     1,
+		Bytes:       3,
+	}, summary)
+
+	files, err := localFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"same.txt", "sub/new.txt"}, sortedKeys(files))
+
+	data, err := os.ReadFile(filepath.Join(dir, "sub", "new.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+}
@@ -0,0 +1,136 @@
+package bucket
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// GetObjectTagging returns the tags of the object for key.
+func (b *Bucket) GetObjectTagging(key string, opts ...option.GetObjectTaggingInput) (map[string]string, error) {
+	return b.GetObjectTaggingWithContext(aws.BackgroundContext(), key, opts...)
+}
+
+// GetObjectTaggingWithContext is the same as GetObjectTagging with the context ctx.
+func (b *Bucket) GetObjectTaggingWithContext(ctx aws.Context, key string, opts ...option.GetObjectTaggingInput) (map[string]string, error) {
+	req := &s3.GetObjectTaggingInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	resp, err := b.S3.GetObjectTaggingWithContext(ctx, req, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return TagMap(resp.TagSet), nil
+}
+
+// PutObjectTagging replaces the tags of the object for key with tags.
+func (b *Bucket) PutObjectTagging(key string, tags map[string]string, opts ...option.PutObjectTaggingInput) (*s3.PutObjectTaggingOutput, error) {
+	return b.PutObjectTaggingWithContext(aws.BackgroundContext(), key, tags, opts...)
+}
+
+// PutObjectTaggingWithContext is the same as PutObjectTagging with the context ctx.
+func (b *Bucket) PutObjectTaggingWithContext(ctx aws.Context, key string, tags map[string]string, opts ...option.PutObjectTaggingInput) (*s3.PutObjectTaggingOutput, error) {
+	req := &s3.PutObjectTaggingInput{
+		Bucket:  b.Name,
+		Key:     b.key(key),
+		Tagging: &s3.Tagging{TagSet: TagSet(tags)},
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.PutObjectTaggingWithContext(ctx, req, b.reqOpts...)
+}
+
+// DeleteObjectTagging removes all tags of the object for key.
+func (b *Bucket) DeleteObjectTagging(key string) (*s3.DeleteObjectTaggingOutput, error) {
+	return b.DeleteObjectTaggingWithContext(aws.BackgroundContext(), key)
+}
+
+// DeleteObjectTaggingWithContext is the same as DeleteObjectTagging with the context ctx.
+func (b *Bucket) DeleteObjectTaggingWithContext(ctx aws.Context, key string) (*s3.DeleteObjectTaggingOutput, error) {
+	req := &s3.DeleteObjectTaggingInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+
+	return b.S3.DeleteObjectTaggingWithContext(ctx, req, b.reqOpts...)
+}
+
+// GetTags returns the tags of the bucket. It returns an empty map if the bucket has no tags.
+func (b *Bucket) GetTags() (map[string]string, error) {
+	return b.GetTagsWithContext(aws.BackgroundContext())
+}
+
+// GetTagsWithContext is the same as GetTags with the context ctx.
+func (b *Bucket) GetTagsWithContext(ctx aws.Context) (map[string]string, error) {
+	resp, err := b.S3.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchTagSet" {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return TagMap(resp.TagSet), nil
+}
+
+// PutTags replaces the tags of the bucket with tags.
+func (b *Bucket) PutTags(tags map[string]string) (*s3.PutBucketTaggingOutput, error) {
+	return b.PutTagsWithContext(aws.BackgroundContext(), tags)
+}
+
+// PutTagsWithContext is the same as PutTags with the context ctx.
+func (b *Bucket) PutTagsWithContext(ctx aws.Context, tags map[string]string) (*s3.PutBucketTaggingOutput, error) {
+	return b.S3.PutBucketTaggingWithContext(ctx, &s3.PutBucketTaggingInput{
+		Bucket:  b.Name,
+		Tagging: &s3.Tagging{TagSet: TagSet(tags)},
+	}, b.reqOpts...)
+}
+
+// DeleteTags removes all tags of the bucket.
+func (b *Bucket) DeleteTags() (*s3.DeleteBucketTaggingOutput, error) {
+	return b.DeleteTagsWithContext(aws.BackgroundContext())
+}
+
+// DeleteTagsWithContext is the same as DeleteTags with the context ctx.
+func (b *Bucket) DeleteTagsWithContext(ctx aws.Context) (*s3.DeleteBucketTaggingOutput, error) {
+	return b.S3.DeleteBucketTaggingWithContext(ctx, &s3.DeleteBucketTaggingInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+}
+
+// TagSet returns tags as the tag set of the SDK ordered by key.
+func TagSet(tags map[string]string) []*s3.Tag {
+	set := make([]*s3.Tag, 0, len(tags))
+	for k, v := range tags {
+		set = append(set, &s3.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+
+	sort.Slice(set, func(i, j int) bool { return aws.StringValue(set[i].Key) < aws.StringValue(set[j].Key) })
+
+	return set
+}
+
+// TagMap returns the tag set of the SDK as a map.
+func TagMap(set []*s3.Tag) map[string]string {
+	tags := make(map[string]string, len(set))
+	for _, t := range set {
+		tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+	}
+
+	return tags
+}
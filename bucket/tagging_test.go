@@ -0,0 +1,82 @@
+package bucket
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectTagging(t *testing.T) {
+	var body string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := ioutil.ReadAll(r.Body)
+			body = string(data)
+		case http.MethodGet:
+			w.Write([]byte(`<Tagging><TagSet><Tag><Key>env</Key><Value>prod</Value></Tag><Tag><Key>team</Key><Value>core</Value></Tag></TagSet></Tagging>`))
+		}
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.PutObjectTagging("key", map[string]string{"team": "core", "env": "prod"})
+	require.NoError(t, err)
+
+	// the SDK does not keep the order of the elements in a tag
+	var sent struct {
+		Tags []struct{ Key, Value string } `xml:"TagSet>Tag"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(body), &sent))
+	assert.Equal(t, []struct{ Key, Value string }{{"env", "prod"}, {"team", "core"}}, sent.Tags)
+
+	tags, err := b.GetObjectTagging("key")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "core", "env": "prod"}, tags)
+
+	req := &s3.PutObjectInput{}
+	option.Tagging(map[string]string{"team": "core", "cost center": "a&b"})(req)
+	assert.Equal(t, "cost+center=a%26b&team=core", *req.Tagging)
+}
+
+func TestBucketTagging(t *testing.T) {
+	var body []byte
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/bucket", r.URL.Path)
+
+		switch r.Method {
+		case http.MethodPut:
+			body, _ = ioutil.ReadAll(r.Body)
+		case http.MethodDelete:
+			body = nil
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodGet:
+			if body == nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>NoSuchTagSet</Code></Error>`))
+				return
+			}
+			w.Write(body)
+		}
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.PutTags(map[string]string{"cost-center": "42"})
+	require.NoError(t, err)
+
+	tags, err := b.GetTags()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cost-center": "42"}, tags)
+
+	_, err = b.DeleteTags()
+	require.NoError(t, err)
+
+	tags, err = b.GetTags()
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+}
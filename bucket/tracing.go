@@ -0,0 +1,102 @@
+package bucket
+
+import (
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/nabeken/aws-go-s3/bucket"
+
+// WithTracing returns an Option that creates an OpenTelemetry span named "S3.<operation>" for every S3 call made through
+// the Bucket. The span is a child of the span in the context of the call, e.g. the one given to GetObjectWithContext,
+// and has the bucket, key, operation, bytes, HTTP status code and AWS request ID as attributes.
+// The spans are created by tp or by the global TracerProvider if tp is nil. Presigned requests are not traced.
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(b *Bucket) {
+		name := aws.StringValue(b.Name)
+
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			var span trace.Span
+
+			r.Handlers.Sign.PushFront(func(r *request.Request) {
+				if span != nil || r.IsPresigned() {
+					return
+				}
+
+				provider := tp
+				if provider == nil {
+					provider = otel.GetTracerProvider()
+				}
+
+				attrs := []attribute.KeyValue{
+					semconv.RPCSystemKey.String("aws-api"),
+					semconv.RPCService("S3"),
+					semconv.RPCMethod(r.Operation.Name),
+					semconv.AWSS3Bucket(name),
+				}
+				if key := paramsKey(r.Params); key != "" {
+					attrs = append(attrs, semconv.AWSS3Key(key))
+				}
+
+				var ctx aws.Context
+				ctx, span = provider.Tracer(tracerName).Start(r.Context(), "S3."+r.Operation.Name,
+					trace.WithSpanKind(trace.SpanKindClient),
+					trace.WithAttributes(attrs...),
+				)
+				r.SetContext(ctx)
+			})
+
+			r.Handlers.Complete.PushBack(func(r *request.Request) {
+				if span == nil {
+					return
+				}
+				defer span.End()
+
+				if r.RequestID != "" {
+					span.SetAttributes(semconv.AWSRequestID(r.RequestID))
+				}
+				if r.HTTPRequest != nil && r.HTTPRequest.ContentLength > 0 {
+					span.SetAttributes(semconv.HTTPRequestBodySize(int(r.HTTPRequest.ContentLength)))
+				}
+				if r.HTTPResponse != nil {
+					span.SetAttributes(semconv.HTTPResponseStatusCode(r.HTTPResponse.StatusCode))
+					if r.HTTPResponse.ContentLength > 0 {
+						span.SetAttributes(semconv.HTTPResponseBodySize(int(r.HTTPResponse.ContentLength)))
+					}
+				}
+
+				if r.Error != nil {
+					span.RecordError(r.Error)
+					if aerr, ok := r.Error.(awserr.Error); ok {
+						span.SetStatus(codes.Error, aerr.Code())
+					} else {
+						span.SetStatus(codes.Error, r.Error.Error())
+					}
+				}
+			})
+		})
+	}
+}
+
+// paramsKey returns the object key in the input params of an operation or "" if it has none.
+func paramsKey(params interface{}) string {
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ""
+	}
+
+	key := v.Elem().FieldByName("Key")
+	if !key.IsValid() || key.Type() != reflect.TypeOf((*string)(nil)) {
+		return ""
+	}
+
+	return aws.StringValue(key.Interface().(*string))
+}
@@ -0,0 +1,60 @@
+package bucket
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTracing(t *testing.T) {
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-amz-request-id", "req-1")
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`))
+		}
+	})
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	b := New(svc, "bucket", WithTracing(tp)).WithPrefix("p/")
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, err := b.PutObjectWithContext(ctx, "key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	_, err = b.GetObjectWithContext(ctx, "missing")
+	require.Error(t, err)
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 3)
+
+	put, get := spans[0], spans[1]
+	assert.Equal(t, "S3.PutObject", put.Name())
+	assert.Equal(t, trace.SpanKindClient, put.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), put.Parent().SpanID())
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range put.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "bucket", attrs["aws.s3.bucket"].AsString())
+	assert.Equal(t, "p/key", attrs["aws.s3.key"].AsString())
+	assert.Equal(t, "req-1", attrs["aws.request_id"].AsString())
+	assert.Equal(t, int64(5), attrs["http.request.body.size"].AsInt64())
+	assert.Equal(t, int64(200), attrs["http.response.status_code"].AsInt64())
+	assert.Equal(t, codes.Unset, put.Status().Code)
+
+	assert.Equal(t, "S3.GetObject", get.Name())
+	assert.Equal(t, codes.Error, get.Status().Code)
+	assert.Equal(t, "NoSuchKey", get.Status().Description)
+}
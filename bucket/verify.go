@@ -0,0 +1,214 @@
+package bucket
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// A ChecksumMismatch is an object whose content does not match its stored checksum.
+type ChecksumMismatch struct {
+	Key string
+
+	// Algorithm is the checksum algorithm, e.g. s3.ChecksumAlgorithmSha256, or "MD5" for the ETag.
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+// A ChecksumMismatchError is returned when reading the body of GetObject made through a Bucket with
+// WithChecksumVerification if the content does not match its checksum. Key is the key stored in S3.
+type ChecksumMismatchError struct {
+	ChecksumMismatch
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("bucket: %s: %s checksum mismatch: expected %s, got %s", e.Key, e.Algorithm, e.Expected, e.Actual)
+}
+
+// WithChecksumVerification returns an Option that makes every GetObject made through the Bucket request the additional
+// checksum and verifies the body against it, or the ETag if it is the MD5 of the content, while the body is read.
+// Reading the body returns *ChecksumMismatchError instead of io.EOF if the content does not match.
+// Range requests and objects without a checksum of the whole content are not verified.
+func WithChecksumVerification() Option {
+	return func(b *Bucket) {
+		b.reqOpts = append(b.reqOpts, func(r *request.Request) {
+			in, ok := r.Params.(*s3.GetObjectInput)
+			if !ok {
+				return
+			}
+
+			r.Handlers.Validate.PushFront(func(r *request.Request) {
+				if in.ChecksumMode == nil {
+					in.ChecksumMode = aws.String(s3.ChecksumModeEnabled)
+				}
+			})
+
+			r.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+				out, ok := r.Data.(*s3.GetObjectOutput)
+				if r.Error != nil || !ok || in.Range != nil || out.ContentRange != nil || out.Body == nil {
+					return
+				}
+
+				algorithm, expected, h, encode := objectChecksum(out)
+				if h == nil {
+					return
+				}
+
+				out.Body = &verifyingReader{
+					ReadCloser: out.Body,
+					mismatch:   ChecksumMismatch{Key: aws.StringValue(in.Key), Algorithm: algorithm, Expected: expected},
+					h:          h,
+					encode:     encode,
+				}
+			})
+		})
+	}
+}
+
+// verifyingReader computes the checksum of the body while it is read and compares it at the end of the body.
+type verifyingReader struct {
+	io.ReadCloser
+
+	mismatch ChecksumMismatch
+	h        hash.Hash
+	encode   func([]byte) string
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.h.Write(p[:n])
+
+	if err == io.EOF {
+		if actual := r.encode(r.h.Sum(nil)); actual != r.mismatch.Expected {
+			r.mismatch.Actual = actual
+			return n, &ChecksumMismatchError{ChecksumMismatch: r.mismatch}
+		}
+	}
+
+	return n, err
+}
+
+// A VerifyReport is the result of VerifyChecksums.
+type VerifyReport struct {
+	// Checked is the number of objects whose content is verified.
+	Checked int
+
+	// Unverifiable are the sampled objects without a checksum of the whole content, e.g. multipart uploads
+	// without additional checksums or objects encrypted with SSE-KMS.
+	Unverifiable []string
+
+	Mismatches []ChecksumMismatch
+}
+
+// VerifyChecksums downloads a sample of the objects with the given prefix and compares the checksum of the content
+// with the additional checksum stored by S3, or the ETag if the object has no additional checksum and the ETag is its MD5.
+// sampleRate is the probability in [0, 1] for each object to be sampled.
+// Mismatches are reported in VerifyReport. An error is returned only when listing or downloading fails.
+func (b *Bucket) VerifyChecksums(ctx aws.Context, prefix string, sampleRate float64) (*VerifyReport, error) {
+	var sampled []*s3.Object
+	err := b.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			if rand.Float64() < sampleRate {
+				sampled = append(sampled, o)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{}
+	for _, o := range sampled {
+		key := aws.StringValue(o.Key)
+
+		mismatch, ok, err := b.verifyChecksum(ctx, key, o.ETag)
+		if err != nil {
+			return report, err
+		}
+
+		if !ok {
+			report.Unverifiable = append(report.Unverifiable, key)
+			continue
+		}
+
+		report.Checked++
+		if mismatch != nil {
+			report.Mismatches = append(report.Mismatches, *mismatch)
+		}
+	}
+
+	return report, nil
+}
+
+// verifyChecksum downloads key and returns the mismatch if the content does not match its checksum.
+// ok is false if the object has no checksum of the whole content.
+func (b *Bucket) verifyChecksum(ctx aws.Context, key string, etag *string) (mismatch *ChecksumMismatch, ok bool, err error) {
+	resp, err := b.S3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket:       b.Name,
+		Key:          b.key(key),
+		IfMatch:      etag,
+		ChecksumMode: aws.String(s3.ChecksumModeEnabled),
+	}, b.reqOpts...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	algorithm, expected, h, encode := objectChecksum(resp)
+	if h == nil {
+		return nil, false, nil
+	}
+
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return nil, false, err
+	}
+
+	if actual := encode(h.Sum(nil)); actual != expected {
+		return &ChecksumMismatch{Key: key, Algorithm: algorithm, Expected: expected, Actual: actual}, true, nil
+	}
+
+	return nil, true, nil
+}
+
+// objectChecksum returns the checksum of the whole content of the object in resp and the hash to compute it.
+// The hash is nil if there is no such checksum.
+func objectChecksum(resp *s3.GetObjectOutput) (algorithm, expected string, h hash.Hash, encode func([]byte) string) {
+	for _, c := range []struct {
+		algorithm string
+		value     *string
+		new       func() hash.Hash
+	}{
+		{s3.ChecksumAlgorithmSha256, resp.ChecksumSHA256, sha256.New},
+		{s3.ChecksumAlgorithmSha1, resp.ChecksumSHA1, sha1.New},
+		{s3.ChecksumAlgorithmCrc32c, resp.ChecksumCRC32C, func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) }},
+		{s3.ChecksumAlgorithmCrc32, resp.ChecksumCRC32, func() hash.Hash { return crc32.NewIEEE() }},
+	} {
+		// a checksum of a multipart upload is the checksum of the checksums of the parts, e.g. "...-3"
+		if v := aws.StringValue(c.value); v != "" && !strings.Contains(v, "-") {
+			return c.algorithm, v, c.new(), base64.StdEncoding.EncodeToString
+		}
+	}
+
+	// the ETag is the MD5 of the content unless it is a multipart upload or encrypted with SSE-KMS or SSE-C
+	etag := strings.Trim(aws.StringValue(resp.ETag), `"`)
+	if len(etag) == md5.Size*2 && aws.StringValue(resp.ServerSideEncryption) != s3.ServerSideEncryptionAwsKms &&
+		aws.StringValue(resp.ServerSideEncryption) != "aws:kms:dsse" && resp.SSECustomerAlgorithm == nil {
+		return "MD5", etag, md5.New(), hex.EncodeToString
+	}
+
+	return "", "", nil, nil
+}
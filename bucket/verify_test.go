@@ -0,0 +1,86 @@
+package bucket
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type checksumStub struct {
+	objectsStub
+
+	outputs map[string]s3.GetObjectOutput
+}
+
+func (s *checksumStub) GetObjectWithContext(_ aws.Context, in *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	out := s.outputs[aws.StringValue(in.Key)]
+	out.Body = io.NopCloser(strings.NewReader("hello"))
+
+	return &out, nil
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	sum := sha256.Sum256([]byte("hello"))
+
+	stub := &checksumStub{
+		objectsStub: objectsStub{objects: []*s3.Object{
+			{Key: aws.String("md5")},
+			{Key: aws.String("multipart")},
+			{Key: aws.String("sha256")},
+			{Key: aws.String("corrupted")},
+		}},
+		outputs: map[string]s3.GetObjectOutput{
+			"md5":       {ETag: aws.String(`"5d41402abc4b2a76b9719d911017c592"`)},
+			"multipart": {ETag: aws.String(`"5d41402abc4b2a76b9719d911017c592-2"`)},
+			"sha256":    {ChecksumSHA256: aws.String(base64.StdEncoding.EncodeToString(sum[:]))},
+			"corrupted": {ChecksumCRC32: aws.String("AAAAAA==")},
+		},
+	}
+
+	report, err := New(stub, "bucket").VerifyChecksums(aws.BackgroundContext(), "", 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Checked)
+	assert.Equal(t, []string{"multipart"}, report.Unverifiable)
+	assert.Equal(t, []ChecksumMismatch{{Key: "corrupted", Algorithm: "CRC32", Expected: "AAAAAA==", Actual: "NhCmhg=="}}, report.Mismatches)
+}
+
+func TestWithChecksumVerification(t *testing.T) {
+	sum := sha256.Sum256([]byte("hello"))
+	checksum := base64.StdEncoding.EncodeToString(sum[:])
+
+	var body string
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, s3.ChecksumModeEnabled, r.Header.Get("X-Amz-Checksum-Mode"))
+		w.Header().Set("X-Amz-Checksum-Sha256", checksum)
+		w.Write([]byte(body))
+	})
+	b := New(svc, "bucket", WithChecksumVerification())
+
+	body = "hello"
+	rc, err := b.GetObjectReader("key")
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	body = "hellO"
+	rc, err = b.GetObjectReader("key")
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+
+	var mismatch *ChecksumMismatchError
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "key", mismatch.Key)
+	assert.Equal(t, s3.ChecksumAlgorithmSha256, mismatch.Algorithm)
+	assert.Equal(t, checksum, mismatch.Expected)
+}
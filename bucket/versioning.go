@@ -0,0 +1,71 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// A VersioningStatus is the versioning state of a bucket returned by GetVersioningStatus.
+type VersioningStatus struct {
+	// Status is s3.BucketVersioningStatusEnabled, s3.BucketVersioningStatusSuspended or empty if versioning has never been enabled.
+	Status string
+
+	// MFADelete is true if deleting a version or changing the versioning state requires MFA.
+	MFADelete bool
+}
+
+// EnableVersioning enables versioning on the bucket.
+func (b *Bucket) EnableVersioning(opts ...option.PutBucketVersioningInput) (*s3.PutBucketVersioningOutput, error) {
+	return b.EnableVersioningWithContext(aws.BackgroundContext(), opts...)
+}
+
+// EnableVersioningWithContext is the same as EnableVersioning with the context ctx.
+func (b *Bucket) EnableVersioningWithContext(ctx aws.Context, opts ...option.PutBucketVersioningInput) (*s3.PutBucketVersioningOutput, error) {
+	return b.putVersioning(ctx, s3.BucketVersioningStatusEnabled, opts)
+}
+
+// SuspendVersioning suspends versioning on the bucket. The existing versions are kept.
+func (b *Bucket) SuspendVersioning(opts ...option.PutBucketVersioningInput) (*s3.PutBucketVersioningOutput, error) {
+	return b.SuspendVersioningWithContext(aws.BackgroundContext(), opts...)
+}
+
+// SuspendVersioningWithContext is the same as SuspendVersioning with the context ctx.
+func (b *Bucket) SuspendVersioningWithContext(ctx aws.Context, opts ...option.PutBucketVersioningInput) (*s3.PutBucketVersioningOutput, error) {
+	return b.putVersioning(ctx, s3.BucketVersioningStatusSuspended, opts)
+}
+
+func (b *Bucket) putVersioning(ctx aws.Context, status string, opts []option.PutBucketVersioningInput) (*s3.PutBucketVersioningOutput, error) {
+	req := &s3.PutBucketVersioningInput{
+		Bucket: b.Name,
+		VersioningConfiguration: &s3.VersioningConfiguration{
+			Status: aws.String(status),
+		},
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.PutBucketVersioningWithContext(ctx, req, b.reqOpts...)
+}
+
+// GetVersioningStatus returns the versioning state of the bucket.
+func (b *Bucket) GetVersioningStatus() (*VersioningStatus, error) {
+	return b.GetVersioningStatusWithContext(aws.BackgroundContext())
+}
+
+// GetVersioningStatusWithContext is the same as GetVersioningStatus with the context ctx.
+func (b *Bucket) GetVersioningStatusWithContext(ctx aws.Context) (*VersioningStatus, error) {
+	resp, err := b.S3.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VersioningStatus{
+		Status:    aws.StringValue(resp.Status),
+		MFADelete: aws.StringValue(resp.MFADelete) == s3.MFADeleteStatusEnabled,
+	}, nil
+}
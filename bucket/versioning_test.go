@@ -0,0 +1,50 @@
+package bucket
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersioning(t *testing.T) {
+	var (
+		conf struct {
+			Status    string
+			MfaDelete string
+		}
+		mfa string
+	)
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			conf.MfaDelete = ""
+			require.NoError(t, xml.NewDecoder(r.Body).Decode(&conf))
+			mfa = r.Header.Get("X-Amz-Mfa")
+			return
+		}
+
+		w.Write([]byte(`<VersioningConfiguration><Status>` + conf.Status + `</Status><MfaDelete>` + conf.MfaDelete + `</MfaDelete></VersioningConfiguration>`))
+	})
+	b := New(svc, "bucket")
+
+	_, err := b.EnableVersioning(option.MFADelete(true, "arn:aws:iam::123456789012:mfa/root", "123456"))
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::123456789012:mfa/root 123456", mfa)
+
+	status, err := b.GetVersioningStatus()
+	require.NoError(t, err)
+	assert.Equal(t, &VersioningStatus{Status: s3.BucketVersioningStatusEnabled, MFADelete: true}, status)
+
+	_, err = b.SuspendVersioning()
+	require.NoError(t, err)
+	assert.Empty(t, mfa)
+
+	status, err = b.GetVersioningStatus()
+	require.NoError(t, err)
+	assert.Equal(t, s3.BucketVersioningStatusSuspended, status.Status)
+	assert.False(t, status.MFADelete)
+}
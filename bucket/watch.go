@@ -0,0 +1,146 @@
+package bucket
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// A ChangeType is the kind of a Change.
+type ChangeType int
+
+// Change types sent by Watch.
+const (
+	// ChangeCreated is sent for a key that did not exist in the previous listing.
+	ChangeCreated ChangeType = iota + 1
+
+	// ChangeUpdated is sent for a key whose ETag or size differs from the previous listing.
+	ChangeUpdated
+
+	// ChangeDeleted is sent for a key that no longer exists.
+	ChangeDeleted
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeCreated:
+		return "created"
+	case ChangeUpdated:
+		return "updated"
+	case ChangeDeleted:
+		return "deleted"
+	}
+
+	return "unknown"
+}
+
+// A Change is a change under the prefix detected by Watch.
+type Change struct {
+	Type ChangeType
+	Key  string
+
+	// Object is the object in the latest listing, or in the previous listing for ChangeDeleted.
+	Object *s3.Object
+
+	// Err is set instead of the other fields if listing fails. Watch tries again at the next interval.
+	Err error
+}
+
+// Watch lists the prefix every interval and sends the changes from the previous listing to the returned channel
+// in the order of the keys. The first listing is the baseline and does not send any change.
+// The channel is closed when ctx is done.
+//
+// Watch is meant for buckets where event notifications cannot be configured. Changes between two listings
+// are merged, e.g. an object created and deleted within an interval is never seen.
+func (b *Bucket) Watch(ctx aws.Context, prefix string, interval time.Duration) <-chan Change {
+	ch := make(chan Change)
+
+	go func() {
+		defer close(ch)
+
+		send := func(c Change) bool {
+			select {
+			case ch <- c:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var prev map[string]*s3.Object
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			cur, err := b.snapshot(ctx, prefix)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				if !send(Change{Err: err}) {
+					return
+				}
+			case prev == nil:
+				prev = cur
+			default:
+				for _, c := range diffSnapshots(prev, cur) {
+					if !send(c) {
+						return
+					}
+				}
+				prev = cur
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// snapshot returns the objects under prefix by key.
+func (b *Bucket) snapshot(ctx aws.Context, prefix string) (map[string]*s3.Object, error) {
+	objects := map[string]*s3.Object{}
+	err := b.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			objects[aws.StringValue(o.Key)] = o
+		}
+		return true
+	})
+
+	return objects, err
+}
+
+// diffSnapshots returns the changes from prev to cur sorted by key.
+func diffSnapshots(prev, cur map[string]*s3.Object) []Change {
+	var changes []Change
+
+	for key, o := range cur {
+		p, ok := prev[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: ChangeCreated, Key: key, Object: o})
+		case aws.StringValue(p.ETag) != aws.StringValue(o.ETag) || aws.Int64Value(p.Size) != aws.Int64Value(o.Size):
+			changes = append(changes, Change{Type: ChangeUpdated, Key: key, Object: o})
+		}
+	}
+
+	for key, p := range prev {
+		if _, ok := cur[key]; !ok {
+			changes = append(changes, Change{Type: ChangeDeleted, Key: key, Object: p})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
@@ -0,0 +1,33 @@
+package bucket
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	obj := func(etag string, size int64) *s3.Object {
+		return &s3.Object{ETag: aws.String(etag), Size: aws.Int64(size)}
+	}
+
+	prev := map[string]*s3.Object{
+		"a": obj("1", 1),
+		"b": obj("1", 1),
+		"c": obj("1", 1),
+	}
+	cur := map[string]*s3.Object{
+		"a": obj("1", 1),
+		"b": obj("2", 1),
+		"d": obj("1", 1),
+	}
+
+	var got []string
+	for _, c := range diffSnapshots(prev, cur) {
+		got = append(got, c.Type.String()+" "+c.Key)
+	}
+
+	assert.Equal(t, []string{"updated b", "deleted c", "created d"}, got)
+}
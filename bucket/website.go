@@ -0,0 +1,71 @@
+package bucket
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// RedirectPrefixRule returns a routing rule that redirects requests for the keys under keyPrefix in the bucket
+// to the same keys under replacement.
+func (b *Bucket) RedirectPrefixRule(keyPrefix, replacement string) *s3.RoutingRule {
+	return &s3.RoutingRule{
+		Condition: &s3.Condition{KeyPrefixEquals: aws.String(b.objectKey(keyPrefix))},
+		Redirect:  &s3.Redirect{ReplaceKeyPrefixWith: aws.String(b.objectKey(replacement))},
+	}
+}
+
+// PutWebsiteConfiguration enables static website hosting on the bucket. indexDocument is the suffix appended to
+// requests for a directory, e.g. "index.html", and errorDocument is the key returned for 4XX errors if it is not empty.
+// rules, e.g. the ones built by RedirectPrefixRule, are applied in order.
+func (b *Bucket) PutWebsiteConfiguration(indexDocument, errorDocument string, rules ...*s3.RoutingRule) (*s3.PutBucketWebsiteOutput, error) {
+	return b.PutWebsiteConfigurationWithContext(aws.BackgroundContext(), indexDocument, errorDocument, rules...)
+}
+
+// PutWebsiteConfigurationWithContext is the same as PutWebsiteConfiguration with the context ctx.
+func (b *Bucket) PutWebsiteConfigurationWithContext(ctx aws.Context, indexDocument, errorDocument string, rules ...*s3.RoutingRule) (*s3.PutBucketWebsiteOutput, error) {
+	conf := &s3.WebsiteConfiguration{
+		IndexDocument: &s3.IndexDocument{Suffix: aws.String(indexDocument)},
+		RoutingRules:  rules,
+	}
+	if errorDocument != "" {
+		conf.ErrorDocument = &s3.ErrorDocument{Key: b.key(errorDocument)}
+	}
+
+	return b.S3.PutBucketWebsiteWithContext(ctx, &s3.PutBucketWebsiteInput{
+		Bucket:               b.Name,
+		WebsiteConfiguration: conf,
+	}, b.reqOpts...)
+}
+
+// GetWebsiteConfiguration returns the website configuration of the bucket. It returns nil if website hosting is not enabled.
+func (b *Bucket) GetWebsiteConfiguration() (*s3.GetBucketWebsiteOutput, error) {
+	return b.GetWebsiteConfigurationWithContext(aws.BackgroundContext())
+}
+
+// GetWebsiteConfigurationWithContext is the same as GetWebsiteConfiguration with the context ctx.
+func (b *Bucket) GetWebsiteConfigurationWithContext(ctx aws.Context) (*s3.GetBucketWebsiteOutput, error) {
+	resp, err := b.S3.GetBucketWebsiteWithContext(ctx, &s3.GetBucketWebsiteInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchWebsiteConfiguration" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// DeleteWebsiteConfiguration disables static website hosting on the bucket.
+func (b *Bucket) DeleteWebsiteConfiguration() (*s3.DeleteBucketWebsiteOutput, error) {
+	return b.DeleteWebsiteConfigurationWithContext(aws.BackgroundContext())
+}
+
+// DeleteWebsiteConfigurationWithContext is the same as DeleteWebsiteConfiguration with the context ctx.
+func (b *Bucket) DeleteWebsiteConfigurationWithContext(ctx aws.Context) (*s3.DeleteBucketWebsiteOutput, error) {
+	return b.S3.DeleteBucketWebsiteWithContext(ctx, &s3.DeleteBucketWebsiteInput{
+		Bucket: b.Name,
+	}, b.reqOpts...)
+}
@@ -0,0 +1,34 @@
+package bucket
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutWebsiteConfiguration(t *testing.T) {
+	var conf struct {
+		IndexDocument struct{ Suffix string }
+		ErrorDocument struct{ Key string }
+		RoutingRules  []struct {
+			Condition struct{ KeyPrefixEquals string }
+			Redirect  struct{ ReplaceKeyPrefixWith string }
+		} `xml:"RoutingRules>RoutingRule"`
+	}
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, xml.NewDecoder(r.Body).Decode(&conf))
+	})
+	b := New(svc, "bucket").WithPrefix("site/")
+
+	_, err := b.PutWebsiteConfiguration("index.html", "404.html", b.RedirectPrefixRule("docs/", "manual/"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "index.html", conf.IndexDocument.Suffix)
+	assert.Equal(t, "site/404.html", conf.ErrorDocument.Key)
+	require.Len(t, conf.RoutingRules, 1)
+	assert.Equal(t, "site/docs/", conf.RoutingRules[0].Condition.KeyPrefixEquals)
+	assert.Equal(t, "site/manual/", conf.RoutingRules[0].Redirect.ReplaceKeyPrefixWith)
+}
@@ -0,0 +1,308 @@
+// Package bucketv2 provides the Bucket of the bucket package backed by the AWS SDK for Go v2.
+//
+// Every operation takes a context since the SDK v2 does. The options in the option subpackage change
+// the SDK v2 input structs in the same way as the bucket/option package does for the SDK v1.
+package bucketv2
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/nabeken/aws-go-s3/bucketv2/option"
+)
+
+// S3API is the subset of *s3.Client used by the Bucket. The SDK v2 has no s3iface, so this allows stubbing the client.
+type S3API interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+	ListObjects(ctx context.Context, params *s3.ListObjectsInput, optFns ...func(*s3.Options)) (*s3.ListObjectsOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+}
+
+var _ S3API = (*s3.Client)(nil)
+
+// A Bucket is an S3 bucket which holds properties such as bucket name and SSE things for S3 Bucket.
+type Bucket struct {
+	S3   S3API
+	Name *string
+
+	// clientOpts are applied to every request made through the bucket.
+	clientOpts []func(*s3.Options)
+
+	// prefix is joined with every key. See WithPrefix.
+	prefix string
+}
+
+// An Option configures a Bucket in New.
+type Option func(b *Bucket)
+
+// WithClientOptions returns an Option that applies fns to the client options of every request.
+func WithClientOptions(fns ...func(*s3.Options)) Option {
+	return func(b *Bucket) {
+		b.clientOpts = append(b.clientOpts, fns...)
+	}
+}
+
+// New returns Bucket instance with bucket name name.
+func New(s S3API, name string, opts ...Option) *Bucket {
+	b := &Bucket{
+		S3:   s,
+		Name: aws.String(name),
+	}
+
+	for _, f := range opts {
+		f(b)
+	}
+
+	return b
+}
+
+// WithPrefix returns a view of the bucket where every key is relative to prefix.
+// Keys passed to the view are joined with prefix and keys in listings are returned without it.
+func (b *Bucket) WithPrefix(prefix string) *Bucket {
+	view := *b
+	view.prefix = b.prefix + prefix
+
+	return &view
+}
+
+// Prefix returns the prefix of the view. It is empty unless the Bucket is returned by WithPrefix.
+func (b *Bucket) Prefix() string {
+	return b.prefix
+}
+
+// GetObject returns the s3.GetObjectOutput.
+func (b *Bucket) GetObject(ctx context.Context, key string, opts ...option.GetObjectInput) (*s3.GetObjectOutput, error) {
+	req := &s3.GetObjectInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.GetObject(ctx, req, b.clientOpts...)
+}
+
+// GetObjectReader returns a reader assosiated with body. A caller of this MUST close the reader when it finishes reading.
+func (b *Bucket) GetObjectReader(ctx context.Context, key string, opts ...option.GetObjectInput) (io.ReadCloser, error) {
+	resp, err := b.GetObject(ctx, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// HeadObject retrieves an object metadata for key.
+func (b *Bucket) HeadObject(ctx context.Context, key string, opts ...option.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	req := &s3.HeadObjectInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.HeadObject(ctx, req, b.clientOpts...)
+}
+
+// ExistsObject returns true if key exists on bucket.
+func (b *Bucket) ExistsObject(ctx context.Context, key string, opts ...option.HeadObjectInput) (bool, error) {
+	_, err := b.HeadObject(ctx, key, opts...)
+	if err == nil {
+		return true, nil
+	}
+
+	if IsNotFound(err) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// PutObject puts an object with reading data from r.
+// The SDK v2 requires ContentLength or a seekable r to sign the payload.
+func (b *Bucket) PutObject(ctx context.Context, key string, r io.Reader, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	req := &s3.PutObjectInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+		Body:   r,
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.PutObject(ctx, req, b.clientOpts...)
+}
+
+// DeleteObject deletes an object for key.
+func (b *Bucket) DeleteObject(ctx context.Context, key string) (*s3.DeleteObjectOutput, error) {
+	req := &s3.DeleteObjectInput{
+		Bucket: b.Name,
+		Key:    b.key(key),
+	}
+
+	return b.S3.DeleteObject(ctx, req, b.clientOpts...)
+}
+
+// DeleteObjects deletes each object for the given identifiers.
+// A maximum of 1000 objects can be deleted at a time with this method.
+func (b *Bucket) DeleteObjects(ctx context.Context, identifiers []types.ObjectIdentifier) (*s3.DeleteObjectsOutput, error) {
+	req := &s3.DeleteObjectsInput{
+		Bucket: b.Name,
+		Delete: &types.Delete{
+			Objects: make([]types.ObjectIdentifier, 0, len(identifiers)),
+		},
+	}
+
+	for _, id := range identifiers {
+		b.mapKey(&id.Key)
+		req.Delete.Objects = append(req.Delete.Objects, id)
+	}
+
+	resp, err := b.S3.DeleteObjects(ctx, req, b.clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range resp.Deleted {
+		b.unmapKey(&resp.Deleted[i].Key)
+	}
+	for i := range resp.Errors {
+		b.unmapKey(&resp.Errors[i].Key)
+	}
+
+	return resp, nil
+}
+
+// ListObjects lists objects that has prefix.
+func (b *Bucket) ListObjects(ctx context.Context, prefix string, opts ...option.ListObjectsInput) (*s3.ListObjectsOutput, error) {
+	req := &s3.ListObjectsInput{
+		Bucket: b.Name,
+		Prefix: b.key(prefix),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	b.mapKey(&req.Marker)
+
+	resp, err := b.S3.ListObjects(ctx, req, b.clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	b.unmapKey(&resp.Prefix)
+	b.unmapKey(&resp.Marker)
+	b.unmapKey(&resp.NextMarker)
+	for i := range resp.Contents {
+		b.unmapKey(&resp.Contents[i].Key)
+	}
+	for i := range resp.CommonPrefixes {
+		b.unmapKey(&resp.CommonPrefixes[i].Prefix)
+	}
+
+	return resp, nil
+}
+
+// ListObjectsV2Pages will page through objects with the given prefix.
+// Paging stops when pageFunc returns false.
+func (b *Bucket) ListObjectsV2Pages(
+	ctx context.Context,
+	prefix string,
+	pageFunc func(*s3.ListObjectsV2Output, bool) bool,
+	opts ...option.ListObjectsV2Input,
+) error {
+	req := &s3.ListObjectsV2Input{
+		Bucket: b.Name,
+		Prefix: b.key(prefix),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	b.mapKey(&req.StartAfter)
+
+	p := s3.NewListObjectsV2Paginator(b.S3, req)
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx, b.clientOpts...)
+		if err != nil {
+			return err
+		}
+
+		b.unmapKey(&page.Prefix)
+		b.unmapKey(&page.StartAfter)
+		for i := range page.Contents {
+			b.unmapKey(&page.Contents[i].Key)
+		}
+		for i := range page.CommonPrefixes {
+			b.unmapKey(&page.CommonPrefixes[i].Prefix)
+		}
+
+		if !pageFunc(page, !p.HasMorePages()) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// CopyObject copies an object within the bucket.
+func (b *Bucket) CopyObject(ctx context.Context, dest, src string, opts ...option.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	req := &s3.CopyObjectInput{
+		Bucket:     b.Name,
+		Key:        b.key(dest),
+		CopySource: aws.String(copySource(aws.ToString(b.Name), b.prefix+src)),
+	}
+
+	for _, f := range opts {
+		f(req)
+	}
+
+	return b.S3.CopyObject(ctx, req, b.clientOpts...)
+}
+
+// key returns the key stored in S3 for key as the SDK input.
+func (b *Bucket) key(key string) *string {
+	return aws.String(b.prefix + key)
+}
+
+// mapKey replaces the key in *p with the key stored in S3 if it is set.
+func (b *Bucket) mapKey(p **string) {
+	if *p != nil {
+		*p = b.key(**p)
+	}
+}
+
+// unmapKey replaces the key stored in S3 in *p with the key seen by the caller if it is set.
+func (b *Bucket) unmapKey(p **string) {
+	if *p != nil && b.prefix != "" {
+		*p = aws.String(strings.TrimPrefix(**p, b.prefix))
+	}
+}
+
+// copySource returns the value of CopySource for key in bucket.
+// Each segment of key is path-escaped. "+" is escaped as well since S3 may decode it as a space.
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = strings.Replace(url.PathEscape(seg), "+", "%2B", -1)
+	}
+
+	return bucket + "/" + strings.Join(segments, "/")
+}
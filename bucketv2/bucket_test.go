@@ -0,0 +1,113 @@
+package bucketv2
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/nabeken/aws-go-s3/bucketv2/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestS3 returns an SDK v2 client that sends every request to h.
+func newTestS3(t *testing.T, h http.HandlerFunc) *s3.Client {
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+
+	return s3.New(s3.Options{
+		BaseEndpoint:     aws.String(ts.URL),
+		Region:           "us-east-1",
+		UsePathStyle:     true,
+		Credentials:      credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		RetryMaxAttempts: 1,
+	})
+}
+
+func TestBucket(t *testing.T) {
+	objects := map[string]string{}
+
+	svc := newTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/bucket"), "/")
+
+		switch {
+		case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+			objects[key] = objects[strings.TrimPrefix(r.Header.Get("X-Amz-Copy-Source"), "bucket/")]
+			io.WriteString(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[key] = string(body)
+			w.Header().Set("ETag", `"etag"`)
+		case r.Method == http.MethodHead || r.Method == http.MethodGet && key != "":
+			body, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				if r.Method == http.MethodGet {
+					io.WriteString(w, `<Error><Code>NoSuchKey</Code></Error>`)
+				}
+				return
+			}
+			io.WriteString(w, body)
+		case r.Method == http.MethodGet:
+			prefix := r.URL.Query().Get("prefix")
+			io.WriteString(w, `<ListBucketResult><Prefix>`+prefix+`</Prefix>`)
+			for k := range objects {
+				if strings.HasPrefix(k, prefix) {
+					io.WriteString(w, `<Contents><Key>`+k+`</Key></Contents>`)
+				}
+			}
+			io.WriteString(w, `<IsTruncated>false</IsTruncated></ListBucketResult>`)
+		case r.Method == http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	ctx := context.Background()
+	b := New(svc, "bucket").WithPrefix("tenant/")
+
+	_, err := b.PutObject(ctx, "a.txt", strings.NewReader("hello"), option.ContentTypeAuto())
+	require.NoError(t, err)
+	assert.Equal(t, "hello", objects["tenant/a.txt"])
+
+	_, err = b.CopyObject(ctx, "b.txt", "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", objects["tenant/b.txt"])
+
+	r, err := b.GetObjectReader(ctx, "b.txt")
+	require.NoError(t, err)
+	body, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+
+	var keys []string
+	require.NoError(t, b.ListObjectsV2Pages(ctx, "", func(page *s3.ListObjectsV2Output, last bool) bool {
+		assert.True(t, last)
+		for _, o := range page.Contents {
+			keys = append(keys, aws.ToString(o.Key))
+		}
+		return true
+	}))
+	assert.ElementsMatch(t, []string{"a.txt", "b.txt"}, keys)
+
+	_, err = b.DeleteObject(ctx, "a.txt")
+	require.NoError(t, err)
+
+	exists, err := b.ExistsObject(ctx, "a.txt")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = b.ExistsObject(ctx, "b.txt")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = b.GetObject(ctx, "a.txt")
+	assert.True(t, IsNotFound(err))
+}
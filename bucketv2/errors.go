@@ -0,0 +1,49 @@
+package bucketv2
+
+import (
+	"errors"
+	"net/http"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+)
+
+var notFoundCodes = map[string]struct{}{
+	"NoSuchKey":     {},
+	"NoSuchBucket":  {},
+	"NoSuchVersion": {},
+	"NoSuchUpload":  {},
+	"NotFound":      {},
+}
+
+// IsNotFound reports whether err means the bucket, the object, the version or the upload does not exist.
+func IsNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if _, ok := notFoundCodes[apiErr.ErrorCode()]; ok {
+			return true
+		}
+	}
+
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsPreconditionFailed reports whether err means the condition of a conditional request does not hold.
+func IsPreconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "PreconditionFailed" {
+		return true
+	}
+
+	return statusCode(err) == http.StatusPreconditionFailed
+}
+
+// statusCode returns the HTTP status code of err or 0 if err has no response.
+func statusCode(err error) int {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode()
+	}
+
+	return 0
+}
@@ -0,0 +1,2 @@
+// Package option provides adapters to change a parameter in S3 request of the SDK v2.
+package option
@@ -0,0 +1,17 @@
+package option
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// The GetObjectInput type is an adapter to change a parameter in
+// s3.GetObjectInput.
+type GetObjectInput func(req *s3.GetObjectInput)
+
+// GetVersionID returns a GetObjectInput that gets the version versionID instead of the current version.
+func GetVersionID(versionID string) GetObjectInput {
+	return func(req *s3.GetObjectInput) {
+		req.VersionId = aws.String(versionID)
+	}
+}
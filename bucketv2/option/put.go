@@ -0,0 +1,107 @@
+package option
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// The PutObjectInput type is an adapter to change a parameter in
+// s3.PutObjectInput.
+type PutObjectInput func(req *s3.PutObjectInput)
+
+// SSEKMSKeyID returns a PutObjectInput that changes a SSE-KMS Key ID.
+func SSEKMSKeyID(keyID string) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.SSEKMSKeyId = aws.String(keyID)
+		req.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+	}
+}
+
+// SSES3 returns a PutObjectInput that uses SSE-S3 (AES256) in S3.
+func SSES3() PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ServerSideEncryption = types.ServerSideEncryptionAes256
+	}
+}
+
+// ACLPrivate returns a PutObjectInput that set ACL private.
+func ACLPrivate() PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ACL = types.ObjectCannedACLPrivate
+	}
+}
+
+// ACLPublicRead returns a PutObjectInput that set ACL public-read.
+func ACLPublicRead() PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ACL = types.ObjectCannedACLPublicRead
+	}
+}
+
+// ContentType returns a PutObjectInput that set Content-Type.
+func ContentType(ct string) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ContentType = aws.String(ct)
+	}
+}
+
+// ContentLength returns a PutObjectInput that set Content-Length.
+func ContentLength(length int64) PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		req.ContentLength = aws.Int64(length)
+	}
+}
+
+// ContentTypeAuto returns a PutObjectInput that set Content-Type detected from the extension of the key or,
+// if the extension is unknown, from the first 512 bytes of the body. Content-Type that is already set is kept
+// so ContentTypeAuto should be placed after ContentType.
+//
+// A body that is not an io.Seeker is wrapped to keep the peeked bytes.
+func ContentTypeAuto() PutObjectInput {
+	return func(req *s3.PutObjectInput) {
+		if req.ContentType != nil {
+			return
+		}
+
+		if ct := mime.TypeByExtension(path.Ext(aws.ToString(req.Key))); ct != "" {
+			req.ContentType = aws.String(ct)
+			return
+		}
+
+		if req.Body == nil {
+			return
+		}
+
+		if rs, ok := req.Body.(io.ReadSeeker); ok {
+			pos, err := rs.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return
+			}
+
+			head := make([]byte, 512)
+			n, err := io.ReadFull(rs, head)
+			if _, serr := rs.Seek(pos, io.SeekStart); serr != nil || (err != nil && err != io.EOF && err != io.ErrUnexpectedEOF) {
+				return
+			}
+
+			req.ContentType = aws.String(http.DetectContentType(head[:n]))
+			return
+		}
+
+		br := bufio.NewReaderSize(req.Body, 512)
+		head, err := br.Peek(512)
+		if err != nil && err != io.EOF {
+			return
+		}
+
+		req.Body = br
+		req.ContentType = aws.String(http.DetectContentType(head))
+	}
+}
@@ -0,0 +1,604 @@
+// Command s3bucket runs the high-level operations of the bucket package from the command line.
+//
+// Usage:
+//
+//	s3bucket [-profile name] <command> [arguments]
+//
+// The commands are:
+//
+//	ls [-r] s3://bucket/prefix          list objects, or all objects under prefix with -r
+//	cp SRC DST                          copy between local files and s3://bucket/key
+//	sync [-delete] [-dryrun] [-concurrency n] SRC DST
+//	                                    sync a local directory up to or down from s3://bucket/prefix
+//	rm [-r] s3://bucket/key             delete an object, or all objects under the prefix with -r
+//	du s3://bucket/prefix               show usage per storage class
+//	presign [-expires d] s3://bucket/key
+//	                                    print a presigned GET URL
+//	restore [-days n] [-tier t] [-wait] s3://bucket/key
+//	                                    restore an archived object or show the status of the restore
+//	lock [-mode m -until t] [-legal-hold on|off] [-bypass] s3://bucket/key
+//	                                    set the Object Lock retention or legal hold of an object, or show them
+//	audit s3://bucket                   show the versioning, encryption, access logging, Object Lock and
+//	                                    policy settings of the bucket
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+var profile = flag.String("profile", "", "shared config profile")
+
+var commands = map[string]func(args []string) error{
+	"ls":      ls,
+	"cp":      cp,
+	"rm":      rm,
+	"du":      du,
+	"presign": presign,
+	"restore": restore,
+	"sync":    sync,
+	"lock":    lock,
+	"audit":   audit,
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: s3bucket [-profile name] <ls|cp|sync|rm|du|presign|restore|lock|audit> [arguments]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "s3bucket: unknown command %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := cmd(flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "s3bucket: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseURL splits s3://bucket/key into the bucket name and the key.
+func parseURL(s string) (name, key string, ok bool) {
+	if !strings.HasPrefix(s, "s3://") {
+		return "", "", false
+	}
+
+	name, key, _ = strings.Cut(strings.TrimPrefix(s, "s3://"), "/")
+
+	return name, key, name != ""
+}
+
+// open returns the Bucket and the key of the s3:// URL s.
+func open(s string) (*bucket.Bucket, string, error) {
+	name, key, ok := parseURL(s)
+	if !ok {
+		return nil, "", fmt.Errorf("%q is not an s3://bucket/key URL", s)
+	}
+
+	b, err := bucket.NewFromProfile(*profile, name)
+
+	return b, key, err
+}
+
+// parseArgs parses the flags of a command in fs and returns n positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() != n {
+		return nil, fmt.Errorf("%s takes %d arguments", fs.Name(), n)
+	}
+
+	return fs.Args(), nil
+}
+
+func ls(args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ExitOnError)
+	recursive := fs.Bool("r", false, "list all objects under the prefix")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	b, prefix, err := open(args[0])
+	if err != nil {
+		return err
+	}
+
+	var opts []option.ListObjectsV2Input
+	if !*recursive {
+		opts = append(opts, func(req *s3.ListObjectsV2Input) {
+			req.Delimiter = aws.String("/")
+		})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	defer w.Flush()
+
+	return b.ListObjectsV2PagesWithContext(aws.BackgroundContext(), prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, cp := range page.CommonPrefixes {
+			fmt.Fprintf(w, "PRE\t\t %s\n", aws.StringValue(cp.Prefix))
+		}
+		for _, o := range page.Contents {
+			fmt.Fprintf(w, "%s\t%d\t %s\n", aws.TimeValue(o.LastModified).Format(time.RFC3339), aws.Int64Value(o.Size), aws.StringValue(o.Key))
+		}
+		return true
+	}, opts...)
+}
+
+func cp(args []string) error {
+	fs := flag.NewFlagSet("cp", flag.ExitOnError)
+
+	args, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	ctx := aws.BackgroundContext()
+	src, dst := args[0], args[1]
+	_, _, srcRemote := parseURL(src)
+	_, _, dstRemote := parseURL(dst)
+
+	switch {
+	case !srcRemote && dstRemote:
+		b, key, err := open(dst)
+		if err != nil {
+			return err
+		}
+
+		_, err = b.PutObjectFromFile(ctx, key, src)
+		return err
+
+	case srcRemote && !dstRemote:
+		b, key, err := open(src)
+		if err != nil {
+			return err
+		}
+
+		resp, err := b.GetObjectWithContext(ctx, key)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		f, err := os.Create(dst)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(f, resp.Body); err != nil {
+			f.Close()
+			return err
+		}
+
+		return f.Close()
+
+	case srcRemote && dstRemote:
+		sb, skey, err := open(src)
+		if err != nil {
+			return err
+		}
+
+		db, dkey, err := open(dst)
+		if err != nil {
+			return err
+		}
+
+		if aws.StringValue(sb.Name) == aws.StringValue(db.Name) {
+			_, err = sb.CopyObjectWithContext(ctx, dkey, skey)
+			return err
+		}
+
+		resp, err := sb.GetObjectWithContext(ctx, skey)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		_, err = db.PutObjectFromReader(ctx, dkey, resp.Body, func(req *s3.PutObjectInput) {
+			req.ContentType = resp.ContentType
+			req.Metadata = resp.Metadata
+		})
+		return err
+	}
+
+	return fmt.Errorf("either %q or %q must be an s3:// URL", src, dst)
+}
+
+func rm(args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ExitOnError)
+	recursive := fs.Bool("r", false, "delete all objects under the prefix")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	b, key, err := open(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx := aws.BackgroundContext()
+	if !*recursive {
+		_, err := b.DeleteObjectWithContext(ctx, key)
+		return err
+	}
+
+	n, err := b.DeletePrefixConcurrently(ctx, key, 4)
+	fmt.Printf("deleted %d objects\n", n)
+
+	return err
+}
+
+func du(args []string) error {
+	fs := flag.NewFlagSet("du", flag.ExitOnError)
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	b, prefix, err := open(args[0])
+	if err != nil {
+		return err
+	}
+
+	report, err := b.StorageClassReport(aws.BackgroundContext(), prefix)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "PREFIX\tCLASS\tOBJECTS\tBYTES")
+	for _, sub := range sortedKeys(report.SubPrefixes) {
+		classes := report.SubPrefixes[sub]
+		for _, class := range sortedKeys(classes) {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", prefix+sub, class, classes[class].Objects, classes[class].Bytes)
+		}
+	}
+	for _, class := range sortedKeys(report.Classes) {
+		fmt.Fprintf(w, "TOTAL\t%s\t%d\t%d\n", class, report.Classes[class].Objects, report.Classes[class].Bytes)
+	}
+
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func presign(args []string) error {
+	fs := flag.NewFlagSet("presign", flag.ExitOnError)
+	expires := fs.Duration("expires", 15*time.Minute, "expiry of the URL")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	b, key, err := open(args[0])
+	if err != nil {
+		return err
+	}
+
+	url, err := b.PresignGetObject(key, *expires)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(url)
+
+	return nil
+}
+
+func restore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	days := fs.Int64("days", 1, "days to keep the restored copy")
+	tier := fs.String("tier", s3.TierStandard, "retrieval tier: Expedited, Standard or Bulk")
+	wait := fs.Bool("wait", false, "wait until the restore completes")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	b, key, err := open(args[0])
+	if err != nil {
+		return err
+	}
+
+	head, err := b.HeadObject(key)
+	if err != nil {
+		return err
+	}
+
+	status, err := bucket.ParseRestoreStatus(head)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status != nil && status.InProgress:
+		fmt.Println("restore in progress")
+	case status != nil:
+		fmt.Printf("restored until %s\n", status.Expiry.Format(time.RFC3339))
+		return nil
+	default:
+		if _, err := b.RestoreObject(key, *days, *tier); err != nil {
+			return err
+		}
+
+		fmt.Println("restore requested")
+	}
+
+	if !*wait {
+		return nil
+	}
+
+	status, err = b.WaitForRestore(aws.BackgroundContext(), key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("restored until %s\n", status.Expiry.Format(time.RFC3339))
+
+	return nil
+}
+
+func sync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	del := fs.Bool("delete", false, "delete the files or objects that do not exist in SRC")
+	dryRun := fs.Bool("dryrun", false, "show what would be transferred or deleted without doing it")
+	concurrency := fs.Int("concurrency", 4, "number of concurrent transfers")
+
+	args, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	opts := []bucket.SyncOption{bucket.SyncConcurrency(*concurrency)}
+	if *del {
+		opts = append(opts, bucket.SyncDelete())
+	}
+	if *dryRun {
+		opts = append(opts, bucket.SyncDryRun())
+	}
+
+	ctx := aws.BackgroundContext()
+	src, dst := args[0], args[1]
+	_, _, srcRemote := parseURL(src)
+	_, _, dstRemote := parseURL(dst)
+
+	var summary *bucket.SyncSummary
+	switch {
+	case !srcRemote && dstRemote:
+		b, prefix, err := open(dst)
+		if err != nil {
+			return err
+		}
+
+		summary, err = b.SyncUp(ctx, src, prefix, opts...)
+		if err != nil {
+			return err
+		}
+
+	case srcRemote && !dstRemote:
+		b, prefix, err := open(src)
+		if err != nil {
+			return err
+		}
+
+		summary, err = b.SyncDown(ctx, dst, prefix, opts...)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("exactly one of %q and %q must be an s3:// URL", src, dst)
+	}
+
+	for _, key := range summary.Transferred {
+		fmt.Printf("transfer: %s\n", key)
+	}
+	for _, key := range summary.Deleted {
+		fmt.Printf("delete: %s\n", key)
+	}
+	fmt.Printf("transferred %d files (%d bytes), deleted %d, skipped %d\n",
+		len(summary.Transferred), summary.Bytes, len(summary.Deleted), summary.Skipped)
+
+	return nil
+}
+
+func lock(args []string) error {
+	fs := flag.NewFlagSet("lock", flag.ExitOnError)
+	mode := fs.String("mode", "", "retention mode: GOVERNANCE or COMPLIANCE")
+	until := fs.String("until", "", "retain until date in RFC 3339")
+	legalHold := fs.String("legal-hold", "", "turn the legal hold on or off")
+	bypass := fs.Bool("bypass", false, "bypass the GOVERNANCE retention to shorten or remove it")
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if (*mode == "") != (*until == "") {
+		return errors.New("lock: -mode and -until must be given together")
+	}
+
+	var retainUntil time.Time
+	if *until != "" {
+		retainUntil, err = time.Parse(time.RFC3339, *until)
+		if err != nil {
+			return fmt.Errorf("lock: -until: %w", err)
+		}
+	}
+
+	if *legalHold != "" && *legalHold != "on" && *legalHold != "off" {
+		return fmt.Errorf("lock: -legal-hold must be on or off, not %q", *legalHold)
+	}
+
+	b, key, err := open(args[0])
+	if err != nil {
+		return err
+	}
+
+	if *mode == "" && *legalHold == "" {
+		retention, err := b.GetObjectRetention(key)
+		if err != nil && !isCode(err, "NoSuchObjectLockConfiguration") {
+			return err
+		}
+
+		if retention != nil {
+			fmt.Printf("retention: %s until %s\n", aws.StringValue(retention.Mode), aws.TimeValue(retention.RetainUntilDate).Format(time.RFC3339))
+		} else {
+			fmt.Println("retention: none")
+		}
+
+		on, err := b.GetObjectLegalHold(key)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("legal hold: %t\n", on)
+
+		return nil
+	}
+
+	if *mode != "" {
+		var opts []option.PutObjectRetentionInput
+		if *bypass {
+			opts = append(opts, option.BypassGovernanceRetention())
+		}
+
+		if _, err := b.PutObjectRetention(key, *mode, retainUntil, opts...); err != nil {
+			return err
+		}
+	}
+
+	if *legalHold != "" {
+		if _, err := b.PutObjectLegalHold(key, *legalHold == "on"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func audit(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	b, key, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		return fmt.Errorf("audit takes an s3://bucket URL without a key, not %q", args[0])
+	}
+
+	versioning, err := b.GetVersioningStatus()
+	if err != nil {
+		return err
+	}
+
+	encryption, err := b.GetBucketEncryption()
+	if err != nil {
+		return err
+	}
+
+	logging, err := b.GetAccessLogging()
+	if err != nil {
+		return err
+	}
+
+	lockConfig, err := b.GetObjectLockConfiguration()
+	if err != nil && !isCode(err, "ObjectLockConfigurationNotFoundError") {
+		return err
+	}
+
+	policy, err := b.GetPolicy()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "versioning\t%s\n", orNone(versioning.Status))
+	fmt.Fprintf(w, "mfa delete\t%t\n", versioning.MFADelete)
+
+	algorithms := []string{}
+	for _, rule := range encryption {
+		if rule.ApplyServerSideEncryptionByDefault != nil {
+			algorithms = append(algorithms, aws.StringValue(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm))
+		}
+	}
+	fmt.Fprintf(w, "default encryption\t%s\n", orNone(strings.Join(algorithms, ",")))
+
+	if logging != nil {
+		fmt.Fprintf(w, "access logging\ts3://%s/%s\n", aws.StringValue(logging.TargetBucket), aws.StringValue(logging.TargetPrefix))
+	} else {
+		fmt.Fprintln(w, "access logging\tnone")
+	}
+
+	if lockConfig != nil {
+		fmt.Fprintf(w, "object lock\t%s\n", orNone(aws.StringValue(lockConfig.ObjectLockEnabled)))
+	} else {
+		fmt.Fprintln(w, "object lock\tnone")
+	}
+
+	fmt.Fprintf(w, "bucket policy\t%t\n", policy != "")
+
+	return nil
+}
+
+// isCode reports whether err is an AWS error with the code.
+func isCode(err error, code string) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == code
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+
+	return s
+}
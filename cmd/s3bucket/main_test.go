@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		name string
+		key  string
+		ok   bool
+	}{
+		{in: "s3://bucket/dir/key", name: "bucket", key: "dir/key", ok: true},
+		{in: "s3://bucket/dir/", name: "bucket", key: "dir/", ok: true},
+		{in: "s3://bucket/", name: "bucket", ok: true},
+		{in: "s3://bucket", name: "bucket", ok: true},
+		{in: "s3://"},
+		{in: "s3:///key", key: "key"},
+		{in: "bucket/key"},
+		{in: "/tmp/file"},
+		{in: "S3://bucket/key"},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			name, key, ok := parseURL(tc.in)
+			assert.Equal(t, tc.name, name)
+			assert.Equal(t, tc.key, key)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		want []string
+		err  string
+	}{
+		{name: "positional", args: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "flags", args: []string{"-r", "a", "b"}, want: []string{"a", "b"}},
+		{name: "too few", args: []string{"-r", "a"}, err: "test takes 2 arguments"},
+		{name: "too many", args: []string{"a", "b", "c"}, err: "test takes 2 arguments"},
+		{name: "flag after positional", args: []string{"a", "b", "-r"}, err: "test takes 2 arguments"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.Bool("r", false, "")
+
+			got, err := parseArgs(fs, tc.args, 2)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// TestCommandArgs tests the argument errors that are returned before a command opens a bucket.
+func TestCommandArgs(t *testing.T) {
+	for _, tc := range []struct {
+		cmd  string
+		args []string
+		err  string
+	}{
+		{cmd: "ls", args: nil, err: "ls takes 1 arguments"},
+		{cmd: "ls", args: []string{"bucket/prefix"}, err: `"bucket/prefix" is not an s3://bucket/key URL`},
+		{cmd: "cp", args: []string{"a"}, err: "cp takes 2 arguments"},
+		{cmd: "cp", args: []string{"a", "b"}, err: `either "a" or "b" must be an s3:// URL`},
+		{cmd: "rm", args: []string{"-r", "s3://"}, err: `"s3://" is not an s3://bucket/key URL`},
+		{cmd: "du", args: []string{"a", "b"}, err: "du takes 1 arguments"},
+		{cmd: "presign", args: []string{"key"}, err: `"key" is not an s3://bucket/key URL`},
+		{cmd: "restore", args: nil, err: "restore takes 1 arguments"},
+		{cmd: "sync", args: []string{"dir"}, err: "sync takes 2 arguments"},
+		{cmd: "sync", args: []string{"a", "b"}, err: `exactly one of "a" and "b" must be an s3:// URL`},
+		{cmd: "sync", args: []string{"s3://a/x", "s3://b/y"}, err: `exactly one of "s3://a/x" and "s3://b/y" must be an s3:// URL`},
+		{cmd: "lock", args: []string{"-mode", "GOVERNANCE", "s3://bucket/key"}, err: "lock: -mode and -until must be given together"},
+		{cmd: "lock", args: []string{"-until", "2030-01-01T00:00:00Z", "s3://bucket/key"}, err: "lock: -mode and -until must be given together"},
+		{cmd: "lock", args: []string{"-mode", "GOVERNANCE", "-until", "tomorrow", "s3://bucket/key"}, err: `lock: -until: parsing time "tomorrow" as "2006-01-02T15:04:05Z07:00": cannot parse "tomorrow" as "2006"`},
+		{cmd: "lock", args: []string{"-legal-hold", "yes", "s3://bucket/key"}, err: `lock: -legal-hold must be on or off, not "yes"`},
+		{cmd: "lock", args: []string{"-legal-hold", "on", "key"}, err: `"key" is not an s3://bucket/key URL`},
+		{cmd: "audit", args: nil, err: "audit takes 1 arguments"},
+		{cmd: "audit", args: []string{"bucket"}, err: `"bucket" is not an s3://bucket/key URL`},
+	} {
+		t.Run(fmt.Sprint(tc.cmd, tc.args), func(t *testing.T) {
+			assert.EqualError(t, commands[tc.cmd](tc.args), tc.err)
+		})
+	}
+}
+
+func TestIsCode(t *testing.T) {
+	err := awserr.New("NoSuchObjectLockConfiguration", "", nil)
+	assert.True(t, isCode(err, "NoSuchObjectLockConfiguration"))
+	assert.True(t, isCode(fmt.Errorf("get: %w", err), "NoSuchObjectLockConfiguration"))
+	assert.False(t, isCode(err, "NoSuchKey"))
+	assert.False(t, isCode(errors.New("NoSuchObjectLockConfiguration"), "NoSuchObjectLockConfiguration"))
+}
@@ -0,0 +1,175 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+// An Option configures Consume.
+type Option func(c *consumer)
+
+// WithWaitTime returns an Option that sets the long polling wait time in seconds. The default is 20, the maximum.
+func WithWaitTime(seconds int64) Option {
+	return func(c *consumer) {
+		c.waitTime = seconds
+	}
+}
+
+// WithMaxMessages returns an Option that sets the maximum number of messages received at a time. The default is 10, the maximum.
+func WithMaxMessages(n int64) Option {
+	return func(c *consumer) {
+		c.maxMessages = n
+	}
+}
+
+// WithRetryDelay returns an Option that sets the visibility timeout in seconds of a message whose events are not
+// handled, i.e. the delay until it is received again. The default is 30. 0 makes it visible immediately, which
+// redelivers a message that keeps failing without a pause.
+func WithRetryDelay(seconds int64) Option {
+	return func(c *consumer) {
+		c.retryDelay = seconds
+	}
+}
+
+// A BatchError reports the entries that failed in a DeleteMessageBatch or ChangeMessageVisibilityBatch call.
+// The messages of the entries are redelivered after the visibility timeout of the queue. The Id of an entry is the
+// index of the message in the ReceiveMessage response.
+type BatchError struct {
+	// Operation is "DeleteMessageBatch" or "ChangeMessageVisibilityBatch".
+	Operation string
+	Failed    []*sqs.BatchResultErrorEntry
+}
+
+func (e *BatchError) Error() string {
+	first := e.Failed[0]
+	return fmt.Sprintf("events: %s failed for %d messages: %s: %s", e.Operation, len(e.Failed), aws.StringValue(first.Code), aws.StringValue(first.Message))
+}
+
+type consumer struct {
+	svc         sqsiface.SQSAPI
+	queueURL    *string
+	handler     func(Event) error
+	waitTime    int64
+	maxMessages int64
+	retryDelay  int64
+}
+
+// Consume long-polls the queue and calls handler for each event until ctx is done, and then returns ctx.Err().
+// Every event in the S3 notifications, including the ones delivered through SNS, is dispatched. The test events sent by S3
+// when the notification is configured are deleted without being dispatched.
+//
+// A message is deleted after handler returns nil for all of its events. Otherwise the message is made visible again
+// after the retry delay so that it is redelivered or moved to the dead-letter queue of the queue.
+// A message that cannot be parsed is not deleted either. Consume returns the error if an SQS call fails, or
+// *BatchError if some of the messages of a batch cannot be deleted or released.
+func Consume(ctx aws.Context, svc sqsiface.SQSAPI, queueURL string, handler func(Event) error, opts ...Option) error {
+	c := &consumer{
+		svc:         svc,
+		queueURL:    aws.String(queueURL),
+		handler:     handler,
+		waitTime:    20,
+		maxMessages: 10,
+		retryDelay:  30,
+	}
+
+	for _, f := range opts {
+		f(c)
+	}
+
+	for {
+		resp, err := svc.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            c.queueURL,
+			WaitTimeSeconds:     aws.Int64(c.waitTime),
+			MaxNumberOfMessages: aws.Int64(c.maxMessages),
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := c.process(ctx, resp.Messages); err != nil {
+			return err
+		}
+	}
+}
+
+// process handles the messages and then deletes or releases them in batches.
+func (c *consumer) process(ctx aws.Context, msgs []*sqs.Message) error {
+	var (
+		deletes  []*sqs.DeleteMessageBatchRequestEntry
+		releases []*sqs.ChangeMessageVisibilityBatchRequestEntry
+	)
+
+	for i, m := range msgs {
+		id := aws.String(strconv.Itoa(i))
+
+		if c.handle(m) {
+			deletes = append(deletes, &sqs.DeleteMessageBatchRequestEntry{
+				Id:            id,
+				ReceiptHandle: m.ReceiptHandle,
+			})
+		} else {
+			releases = append(releases, &sqs.ChangeMessageVisibilityBatchRequestEntry{
+				Id:                id,
+				ReceiptHandle:     m.ReceiptHandle,
+				VisibilityTimeout: aws.Int64(c.retryDelay),
+			})
+		}
+	}
+
+	var errs []error
+
+	if len(deletes) > 0 {
+		resp, err := c.svc.DeleteMessageBatchWithContext(ctx, &sqs.DeleteMessageBatchInput{
+			QueueUrl: c.queueURL,
+			Entries:  deletes,
+		})
+		if err != nil {
+			return err
+		}
+		if len(resp.Failed) > 0 {
+			errs = append(errs, &BatchError{Operation: "DeleteMessageBatch", Failed: resp.Failed})
+		}
+	}
+
+	if len(releases) > 0 {
+		resp, err := c.svc.ChangeMessageVisibilityBatchWithContext(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+			QueueUrl: c.queueURL,
+			Entries:  releases,
+		})
+		if err != nil {
+			return err
+		}
+		if len(resp.Failed) > 0 {
+			errs = append(errs, &BatchError{Operation: "ChangeMessageVisibilityBatch", Failed: resp.Failed})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// handle dispatches the events in m and reports whether m can be deleted.
+func (c *consumer) handle(m *sqs.Message) bool {
+	evs, err := parse([]byte(aws.StringValue(m.Body)))
+	if err == errTestEvent {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+
+	for _, ev := range evs {
+		if err := c.handler(ev); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,198 @@
+// Package events consumes S3 event notifications delivered to SQS, directly or through SNS.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// An EventType is the kind of an event. It is the part of the event name before ":".
+type EventType string
+
+// Event types dispatched by Consume.
+const (
+	ObjectCreated EventType = "ObjectCreated"
+	ObjectRemoved EventType = "ObjectRemoved"
+)
+
+// An Event is an S3 event notification for an object.
+type Event struct {
+	// Type is the kind of the event, e.g. ObjectCreated.
+	Type EventType
+
+	// Name is the full event name without the "s3:" prefix, e.g. "ObjectCreated:Put".
+	Name string
+
+	Time   time.Time
+	Region string
+	Bucket string
+
+	// Key is the URL-decoded object key.
+	Key string
+
+	VersionID string
+	Size      int64
+	ETag      string
+
+	// Sequencer orders the events for the same key. See Before.
+	Sequencer string
+
+	// Principal is the ID of the principal that caused the event.
+	Principal string
+}
+
+// Before reports whether e happened before other for the same key by comparing the sequencers.
+func (e Event) Before(other Event) bool {
+	a, b := e.Sequencer, other.Sequencer
+	if len(a) != len(b) {
+		// sequencers have to be compared after padding the shorter one with leading zeros
+		n := len(a)
+		if len(b) > n {
+			n = len(b)
+		}
+		a = strings.Repeat("0", n-len(a)) + a
+		b = strings.Repeat("0", n-len(b)) + b
+	}
+
+	return a < b
+}
+
+// errTestEvent is returned by parse for the s3:TestEvent sent when the notification is configured.
+var errTestEvent = errors.New("events: test event")
+
+// A Notification is the S3 event notification message.
+type Notification struct {
+	Records []Record `json:"Records"`
+
+	// Event is "s3:TestEvent" for the test message S3 sends when the notification is configured. See IsTestEvent.
+	Event  string `json:"Event,omitempty"`
+	Bucket string `json:"Bucket,omitempty"`
+}
+
+// IsTestEvent reports whether n is the test message S3 sends when the notification is configured.
+func (n *Notification) IsTestEvent() bool {
+	return n.Event == "s3:TestEvent"
+}
+
+// A Record is an event in Notification.
+type Record struct {
+	EventVersion      string            `json:"eventVersion"`
+	EventSource       string            `json:"eventSource"`
+	AWSRegion         string            `json:"awsRegion"`
+	EventTime         time.Time         `json:"eventTime"`
+	EventName         string            `json:"eventName"`
+	UserIdentity      Identity          `json:"userIdentity"`
+	RequestParameters RequestParameters `json:"requestParameters"`
+	ResponseElements  map[string]string `json:"responseElements"`
+	S3                S3Entity          `json:"s3"`
+}
+
+// An Identity is the principal in Record.
+type Identity struct {
+	PrincipalID string `json:"principalId"`
+}
+
+// RequestParameters are the parameters of the request that caused Record.
+type RequestParameters struct {
+	SourceIPAddress string `json:"sourceIPAddress"`
+}
+
+// An S3Entity is the bucket and the object of Record.
+type S3Entity struct {
+	SchemaVersion   string       `json:"s3SchemaVersion"`
+	ConfigurationID string       `json:"configurationId"`
+	Bucket          BucketEntity `json:"bucket"`
+	Object          ObjectEntity `json:"object"`
+}
+
+// A BucketEntity is the bucket in S3Entity.
+type BucketEntity struct {
+	Name          string   `json:"name"`
+	OwnerIdentity Identity `json:"ownerIdentity"`
+	ARN           string   `json:"arn"`
+}
+
+// An ObjectEntity is the object in S3Entity.
+type ObjectEntity struct {
+	// Key is URL-decoded by Parse.
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+	ETag      string `json:"eTag"`
+	VersionID string `json:"versionId"`
+	Sequencer string `json:"sequencer"`
+}
+
+type snsEnvelope struct {
+	Type    string
+	Message string
+}
+
+// Parse parses the S3 event notification in data and URL-decodes the object keys.
+// data can also be an SNS notification wrapping it, i.e. the body of an SQS message subscribed to the SNS topic.
+func Parse(data []byte) (*Notification, error) {
+	var env snsEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+
+	if env.Type == "Notification" {
+		data = []byte(env.Message)
+	}
+
+	n := &Notification{}
+	if err := json.Unmarshal(data, n); err != nil {
+		return nil, err
+	}
+
+	for i := range n.Records {
+		obj := &n.Records[i].S3.Object
+
+		// keys are form-encoded, i.e. a space is "+"
+		key, err := url.QueryUnescape(obj.Key)
+		if err != nil {
+			return nil, fmt.Errorf("events: invalid object key %q: %w", obj.Key, err)
+		}
+
+		obj.Key = key
+	}
+
+	return n, nil
+}
+
+// parse returns the events in the body of an SQS message.
+func parse(body []byte) ([]Event, error) {
+	n, err := Parse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.IsTestEvent() {
+		return nil, errTestEvent
+	}
+
+	evs := make([]Event, 0, len(n.Records))
+	for _, r := range n.Records {
+		name := strings.TrimPrefix(r.EventName, "s3:")
+		typ, _, _ := strings.Cut(name, ":")
+
+		evs = append(evs, Event{
+			Type:      EventType(typ),
+			Name:      name,
+			Time:      r.EventTime,
+			Region:    r.AWSRegion,
+			Bucket:    r.S3.Bucket.Name,
+			Key:       r.S3.Object.Key,
+			VersionID: r.S3.Object.VersionID,
+			Size:      r.S3.Object.Size,
+			ETag:      r.S3.Object.ETag,
+			Sequencer: r.S3.Object.Sequencer,
+			Principal: r.UserIdentity.PrincipalID,
+		})
+	}
+
+	return evs, nil
+}
@@ -0,0 +1,190 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const notificationJSON = `{"Records":[{"eventVersion":"2.1","eventSource":"aws:s3","awsRegion":"us-east-1",
+"eventTime":"2024-01-02T03:04:05.000Z","eventName":"ObjectCreated:Put","userIdentity":{"principalId":"AWS:AIDAEXAMPLE"},
+"requestParameters":{"sourceIPAddress":"192.0.2.1"},
+"s3":{"bucket":{"name":"bucket"},"object":{"key":"dir/hello+world%2B1.txt","size":5,"eTag":"abc","versionId":"v1","sequencer":"0055AED6DCD90281E5"}}}]}`
+
+func TestParse(t *testing.T) {
+	sns, err := json.Marshal(map[string]string{"Type": "Notification", "Message": notificationJSON})
+	require.NoError(t, err)
+
+	for _, body := range []string{notificationJSON, string(sns)} {
+		evs, err := parse([]byte(body))
+		require.NoError(t, err)
+		require.Len(t, evs, 1)
+
+		assert.Equal(t, ObjectCreated, evs[0].Type)
+		assert.Equal(t, "ObjectCreated:Put", evs[0].Name)
+		assert.Equal(t, "dir/hello world+1.txt", evs[0].Key)
+		assert.Equal(t, "v1", evs[0].VersionID)
+		assert.Equal(t, int64(5), evs[0].Size)
+		assert.Equal(t, "AWS:AIDAEXAMPLE", evs[0].Principal)
+	}
+
+	_, err = parse([]byte(`{"Service":"Amazon S3","Event":"s3:TestEvent"}`))
+	assert.Equal(t, errTestEvent, err)
+}
+
+func TestParseNotification(t *testing.T) {
+	n, err := Parse([]byte(notificationJSON))
+	require.NoError(t, err)
+	require.Len(t, n.Records, 1)
+
+	r := n.Records[0]
+	assert.False(t, n.IsTestEvent())
+	assert.Equal(t, "aws:s3", r.EventSource)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), r.EventTime)
+	assert.Equal(t, "AWS:AIDAEXAMPLE", r.UserIdentity.PrincipalID)
+	assert.Equal(t, "192.0.2.1", r.RequestParameters.SourceIPAddress)
+	assert.Equal(t, "bucket", r.S3.Bucket.Name)
+	assert.Equal(t, "dir/hello world+1.txt", r.S3.Object.Key)
+	assert.Equal(t, "0055AED6DCD90281E5", r.S3.Object.Sequencer)
+
+	n, err = Parse([]byte(`{"Service":"Amazon S3","Event":"s3:TestEvent","Bucket":"bucket"}`))
+	require.NoError(t, err)
+	assert.True(t, n.IsTestEvent())
+
+	_, err = Parse([]byte(`{"Records":[{"s3":{"object":{"key":"%zz"}}}]}`))
+	assert.Error(t, err)
+}
+
+func TestEventBefore(t *testing.T) {
+	assert.True(t, Event{Sequencer: "FF"}.Before(Event{Sequencer: "0100"}))
+	assert.False(t, Event{Sequencer: "0100"}.Before(Event{Sequencer: "FF"}))
+}
+
+type sqsStub struct {
+	sqsiface.SQSAPI
+
+	cancel   context.CancelFunc
+	messages []*sqs.Message
+	deleted  []string
+	released []string
+	timeouts []int64
+
+	deleteFailed  []*sqs.BatchResultErrorEntry
+	releaseFailed []*sqs.BatchResultErrorEntry
+}
+
+func (s *sqsStub) ReceiveMessageWithContext(ctx aws.Context, _ *sqs.ReceiveMessageInput, _ ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	msgs := s.messages
+	s.messages = nil
+	if msgs == nil {
+		s.cancel()
+		return nil, ctx.Err()
+	}
+
+	return &sqs.ReceiveMessageOutput{Messages: msgs}, nil
+}
+
+func (s *sqsStub) DeleteMessageBatchWithContext(_ aws.Context, in *sqs.DeleteMessageBatchInput, _ ...request.Option) (*sqs.DeleteMessageBatchOutput, error) {
+	for _, e := range in.Entries {
+		s.deleted = append(s.deleted, aws.StringValue(e.ReceiptHandle))
+	}
+
+	return &sqs.DeleteMessageBatchOutput{Failed: s.deleteFailed}, nil
+}
+
+func (s *sqsStub) ChangeMessageVisibilityBatchWithContext(_ aws.Context, in *sqs.ChangeMessageVisibilityBatchInput, _ ...request.Option) (*sqs.ChangeMessageVisibilityBatchOutput, error) {
+	for _, e := range in.Entries {
+		s.released = append(s.released, aws.StringValue(e.ReceiptHandle))
+		s.timeouts = append(s.timeouts, aws.Int64Value(e.VisibilityTimeout))
+	}
+
+	return &sqs.ChangeMessageVisibilityBatchOutput{Failed: s.releaseFailed}, nil
+}
+
+func TestConsume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stub := &sqsStub{
+		cancel: cancel,
+		messages: []*sqs.Message{
+			{ReceiptHandle: aws.String("ok"), Body: aws.String(notificationJSON)},
+			{ReceiptHandle: aws.String("test"), Body: aws.String(`{"Event":"s3:TestEvent"}`)},
+			{ReceiptHandle: aws.String("broken"), Body: aws.String(`{`)},
+			{ReceiptHandle: aws.String("failed"), Body: aws.String(notificationJSON)},
+		},
+	}
+
+	calls := 0
+	err := Consume(ctx, stub, "https://sqs.example.com/queue", func(ev Event) error {
+		calls++
+		if calls == 2 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []string{"ok", "test"}, stub.deleted)
+	assert.Equal(t, []string{"broken", "failed"}, stub.released)
+	assert.Equal(t, []int64{30, 30}, stub.timeouts)
+
+	t.Run("WithRetryDelay", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stub := &sqsStub{
+			cancel:   cancel,
+			messages: []*sqs.Message{{ReceiptHandle: aws.String("broken"), Body: aws.String(`{`)}},
+		}
+
+		err := Consume(ctx, stub, "https://sqs.example.com/queue", func(Event) error { return nil }, WithRetryDelay(0))
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, []int64{0}, stub.timeouts)
+	})
+}
+
+func TestConsumeBatchFailed(t *testing.T) {
+	stub := &sqsStub{
+		cancel: func() {},
+		messages: []*sqs.Message{
+			{ReceiptHandle: aws.String("ok"), Body: aws.String(notificationJSON)},
+			{ReceiptHandle: aws.String("broken"), Body: aws.String(`{`)},
+		},
+		deleteFailed: []*sqs.BatchResultErrorEntry{
+			{Id: aws.String("0"), Code: aws.String("ReceiptHandleIsInvalid"), Message: aws.String("invalid"), SenderFault: aws.Bool(true)},
+		},
+		releaseFailed: []*sqs.BatchResultErrorEntry{
+			{Id: aws.String("1"), Code: aws.String("InternalError"), Message: aws.String("internal")},
+		},
+	}
+
+	err := Consume(context.Background(), stub, "https://sqs.example.com/queue", func(Event) error { return nil })
+
+	var berrs []*BatchError
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var berr *BatchError
+		require.ErrorAs(t, err, &berr)
+		berrs = append(berrs, berr)
+	}
+	require.Len(t, berrs, 2)
+	assert.Equal(t, "DeleteMessageBatch", berrs[0].Operation)
+	assert.Equal(t, stub.deleteFailed, berrs[0].Failed)
+	assert.Equal(t, "ChangeMessageVisibilityBatch", berrs[1].Operation)
+	assert.Equal(t, stub.releaseFailed, berrs[1].Failed)
+	assert.EqualError(t, berrs[0], "events: DeleteMessageBatch failed for 1 messages: ReceiptHandleIsInvalid: invalid")
+
+	// the release is made even if the delete fails
+	assert.Equal(t, []string{"ok"}, stub.deleted)
+	assert.Equal(t, []string{"broken"}, stub.released)
+}
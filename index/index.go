@@ -0,0 +1,362 @@
+// Package index mirrors the keys, sizes, tags and selected metadata of the objects in a Bucket into a DynamoDB table
+// so that the objects can be found by a tag or a metadata value without listing the bucket.
+//
+// The objects written and deleted through a Bucket with the Option returned by BucketOption are indexed, including the
+// ones written by the helpers such as PutObjectFromFile, CopyObject and DeletePrefix. Backfill indexes the existing objects.
+//
+//	idx := index.New(b, db, "table", index.WithMetadataKeys("owner"))
+//	b = b.WithOptions(idx.BucketOption())
+//
+// The table must have a string partition key "pk" and a string sort key "sk". An object is stored as an item
+// with pk "obj#" + key, and an item is added for each of its tags and indexed metadata so that they can be queried.
+package index
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+)
+
+const (
+	objectSortKey = "obj"
+
+	// maxBatchWriteItems is the maximum number of requests in BatchWriteItem.
+	maxBatchWriteItems = 25
+)
+
+// An Item is an indexed object.
+type Item struct {
+	Key          string            `dynamodbav:"key"`
+	Size         int64             `dynamodbav:"size"`
+	ETag         string            `dynamodbav:"etag"`
+	LastModified time.Time         `dynamodbav:"lastModified"`
+	Tags         map[string]string `dynamodbav:"tags,omitempty"`
+	Metadata     map[string]string `dynamodbav:"metadata,omitempty"`
+}
+
+// record is an item in the table.
+type record struct {
+	PK string `dynamodbav:"pk"`
+	SK string `dynamodbav:"sk"`
+	Item
+}
+
+// An Option configures an Index in New.
+type Option func(i *Index)
+
+// WithMetadataKeys returns an Option that indexes the metadata with the given names, e.g. "Owner".
+// Names are canonicalized as in the Metadata of the SDK. No metadata is indexed by default.
+func WithMetadataKeys(names ...string) Option {
+	return func(i *Index) {
+		for _, n := range names {
+			i.metadataKeys[canonical(n)] = true
+		}
+	}
+}
+
+// An Index maintains the index of the objects in a Bucket in a DynamoDB table.
+type Index struct {
+	bucket       *bucket.Bucket
+	db           dynamodbiface.DynamoDBAPI
+	table        *string
+	metadataKeys map[string]bool
+}
+
+// New returns Index instance for b stored in table.
+func New(b *bucket.Bucket, db dynamodbiface.DynamoDBAPI, table string, opts ...Option) *Index {
+	i := &Index{
+		bucket:       b,
+		db:           db,
+		table:        aws.String(table),
+		metadataKeys: map[string]bool{},
+	}
+
+	for _, f := range opts {
+		f(i)
+	}
+
+	return i
+}
+
+// BucketOption returns a bucket.Option that indexes every object written or deleted through the Bucket after the write.
+// It must be applied to the Bucket given to New, or a Bucket with the same prefix, e.g. with WithOptions, so that the keys
+// match. The size, the ETag, the Last-Modified and the metadata are read with HeadObject and the tags with
+// GetObjectTagging, so they are the ones S3 stores including the defaults of bucket.WithDefaultPutOptions.
+// An error of indexing is returned by the write as *bucket.CallbackError.
+func (i *Index) BucketOption() bucket.Option {
+	return bucket.WithWriteHook(func(ctx aws.Context, ev bucket.WriteEvent) error {
+		return i.Refresh(ctx, ev.Key)
+	})
+}
+
+// Refresh indexes the current object for key or removes key from the index if it does not exist.
+func (i *Index) Refresh(ctx aws.Context, key string) error {
+	head, err := i.bucket.HeadObjectWithContext(ctx, key)
+	if bucket.IsNotFound(err) {
+		return i.Remove(ctx, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	tags, err := i.bucket.GetObjectTaggingWithContext(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return i.Put(ctx, Item{
+		Key:          key,
+		Size:         aws.Int64Value(head.ContentLength),
+		ETag:         aws.StringValue(head.ETag),
+		LastModified: aws.TimeValue(head.LastModified),
+		Tags:         tags,
+		Metadata:     i.selectMetadata(head.Metadata),
+	})
+}
+
+// Backfill indexes the objects with the given prefix, e.g. the ones written without the Index, and returns the number
+// of indexed objects. The tags are read with GetObjectTagging and the metadata with HeadObject.
+func (i *Index) Backfill(ctx aws.Context, prefix string) (int, error) {
+	var objects []*s3.Object
+	err := i.bucket.ListObjectsV2PagesWithContext(ctx, prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		objects = append(objects, page.Contents...)
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for n, o := range objects {
+		item := Item{
+			Key:          aws.StringValue(o.Key),
+			Size:         aws.Int64Value(o.Size),
+			ETag:         aws.StringValue(o.ETag),
+			LastModified: aws.TimeValue(o.LastModified),
+		}
+
+		tags, err := i.bucket.GetObjectTaggingWithContext(ctx, item.Key)
+		if err != nil && !bucket.IsNotFound(err) {
+			return n, err
+		}
+		item.Tags = tags
+
+		if len(i.metadataKeys) > 0 {
+			head, err := i.bucket.HeadObjectWithContext(ctx, item.Key)
+			if err != nil && !bucket.IsNotFound(err) {
+				return n, err
+			}
+			if err == nil {
+				item.Metadata = i.selectMetadata(head.Metadata)
+			}
+		}
+
+		if err := i.Put(ctx, item); err != nil {
+			return n, err
+		}
+	}
+
+	return len(objects), nil
+}
+
+// Get returns the indexed object for key or nil if it is not indexed.
+func (i *Index) Get(ctx aws.Context, key string) (*Item, error) {
+	resp, err := i.db.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      i.table,
+		Key:            primaryKey(objectPK(key), objectSortKey),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil || resp.Item == nil {
+		return nil, err
+	}
+
+	var r record
+	if err := dynamodbattribute.UnmarshalMap(resp.Item, &r); err != nil {
+		return nil, err
+	}
+
+	return &r.Item, nil
+}
+
+// Put indexes item, replacing the previous entry of its key.
+func (i *Index) Put(ctx aws.Context, item Item) error {
+	prev, err := i.Get(ctx, item.Key)
+	if err != nil {
+		return err
+	}
+
+	var reqs []*dynamodb.WriteRequest
+
+	next := map[string]bool{}
+	for _, pk := range attributePKs(item) {
+		next[pk] = true
+	}
+
+	if prev != nil {
+		for _, pk := range attributePKs(*prev) {
+			if !next[pk] {
+				reqs = append(reqs, deleteRequest(pk, item.Key))
+			}
+		}
+	}
+
+	for _, r := range append([]record{{PK: objectPK(item.Key), SK: objectSortKey, Item: item}}, attributeRecords(item)...) {
+		av, err := dynamodbattribute.MarshalMap(r)
+		if err != nil {
+			return err
+		}
+
+		reqs = append(reqs, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: av}})
+	}
+
+	return i.batchWrite(ctx, reqs)
+}
+
+// Remove removes key from the index.
+func (i *Index) Remove(ctx aws.Context, key string) error {
+	prev, err := i.Get(ctx, key)
+	if err != nil || prev == nil {
+		return err
+	}
+
+	reqs := []*dynamodb.WriteRequest{deleteRequest(objectPK(key), objectSortKey)}
+	for _, pk := range attributePKs(*prev) {
+		reqs = append(reqs, deleteRequest(pk, key))
+	}
+
+	return i.batchWrite(ctx, reqs)
+}
+
+// FindByTag returns the indexed objects with the tag name=value in the order of the keys.
+func (i *Index) FindByTag(ctx aws.Context, name, value string) ([]Item, error) {
+	return i.query(ctx, tagPK(name, value))
+}
+
+// FindByMetadata returns the indexed objects with the metadata name=value in the order of the keys.
+// Only the metadata configured by WithMetadataKeys is indexed.
+func (i *Index) FindByMetadata(ctx aws.Context, name, value string) ([]Item, error) {
+	return i.query(ctx, metadataPK(canonical(name), value))
+}
+
+func (i *Index) query(ctx aws.Context, pk string) ([]Item, error) {
+	var (
+		items []Item
+		uerr  error
+	)
+
+	err := i.db.QueryPagesWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              i.table,
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": {S: aws.String(pk)},
+		},
+	}, func(page *dynamodb.QueryOutput, _ bool) bool {
+		var records []record
+		if uerr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &records); uerr != nil {
+			return false
+		}
+
+		for _, r := range records {
+			items = append(items, r.Item)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, uerr
+}
+
+// batchWrite writes reqs in batches, retrying the unprocessed items.
+func (i *Index) batchWrite(ctx aws.Context, reqs []*dynamodb.WriteRequest) error {
+	for len(reqs) > 0 {
+		n := len(reqs)
+		if n > maxBatchWriteItems {
+			n = maxBatchWriteItems
+		}
+
+		pending := map[string][]*dynamodb.WriteRequest{aws.StringValue(i.table): reqs[:n]}
+		for backoff := 50 * time.Millisecond; len(pending) > 0; backoff *= 2 {
+			resp, err := i.db.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return err
+			}
+
+			pending = resp.UnprocessedItems
+			if len(pending) > 0 {
+				if err := aws.SleepWithContext(ctx, backoff); err != nil {
+					return err
+				}
+			}
+		}
+
+		reqs = reqs[n:]
+	}
+
+	return nil
+}
+
+// selectMetadata returns the metadata in md configured by WithMetadataKeys.
+func (i *Index) selectMetadata(md map[string]*string) map[string]string {
+	selected := map[string]string{}
+	for k, v := range md {
+		if i.metadataKeys[canonical(k)] {
+			selected[canonical(k)] = aws.StringValue(v)
+		}
+	}
+
+	return selected
+}
+
+// attributeRecords returns the records to query item by its tags and metadata.
+func attributeRecords(item Item) []record {
+	var records []record
+	for _, pk := range attributePKs(item) {
+		records = append(records, record{PK: pk, SK: item.Key, Item: item})
+	}
+
+	return records
+}
+
+func attributePKs(item Item) []string {
+	var pks []string
+	for k, v := range item.Tags {
+		pks = append(pks, tagPK(k, v))
+	}
+	for k, v := range item.Metadata {
+		pks = append(pks, metadataPK(k, v))
+	}
+
+	return pks
+}
+
+func objectPK(key string) string { return "obj#" + key }
+func tagPK(name, value string) string {
+	return "tag#" + url.QueryEscape(name) + "=" + url.QueryEscape(value)
+}
+func metadataPK(name, value string) string {
+	return "meta#" + url.QueryEscape(name) + "=" + url.QueryEscape(value)
+}
+
+func primaryKey(pk, sk string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"pk": {S: aws.String(pk)},
+		"sk": {S: aws.String(sk)},
+	}
+}
+
+func deleteRequest(pk, sk string) *dynamodb.WriteRequest {
+	return &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: primaryKey(pk, sk)}}
+}
+
+// canonical returns the name of metadata as the SDK returns it, e.g. "Owner" for "owner".
+func canonical(name string) string {
+	return http.CanonicalHeaderKey(name)
+}
@@ -0,0 +1,262 @@
+package index
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// s3Object is an object of s3Server.
+type s3Object struct {
+	data     string
+	tagging  string
+	metadata http.Header
+	modified time.Time
+}
+
+// s3Server is an in-memory S3 serving PutObject, CopyObject, HeadObject, GetObjectTagging, DeleteObject and DeleteObjects.
+type s3Server struct {
+	mu      sync.Mutex
+	objects map[string]*s3Object
+	now     time.Time
+}
+
+func (s *s3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	o, exists := s.objects[key]
+
+	switch {
+	case r.Method == http.MethodPut:
+		s.now = s.now.Add(time.Second)
+
+		if src := r.Header.Get("X-Amz-Copy-Source"); src != "" {
+			srcKey, _ := url.PathUnescape(strings.TrimPrefix(src, "bucket/"))
+			copied := *s.objects[srcKey]
+			copied.modified = s.now
+			s.objects[key] = &copied
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+			return
+		}
+
+		data, _ := ioutil.ReadAll(r.Body)
+		metadata := http.Header{}
+		for k, v := range r.Header {
+			if strings.HasPrefix(k, "X-Amz-Meta-") {
+				metadata[k] = v
+			}
+		}
+		s.objects[key] = &s3Object{data: string(data), tagging: r.Header.Get("X-Amz-Tagging"), metadata: metadata, modified: s.now}
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for k, v := range o.metadata {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(o.data)))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", o.modified.Format(http.TimeFormat))
+	case r.Method == http.MethodGet && r.URL.Query().Has("tagging"):
+		tags, _ := url.ParseQuery(o.tagging)
+		fmt.Fprint(w, `<Tagging><TagSet>`)
+		for k := range tags {
+			fmt.Fprintf(w, `<Tag><Key>%s</Key><Value>%s</Value></Tag>`, k, tags.Get(k))
+		}
+		fmt.Fprint(w, `</TagSet></Tagging>`)
+	case r.Method == http.MethodPost && r.URL.Query().Has("delete"):
+		var in struct {
+			Objects []struct{ Key string } `xml:"Object"`
+		}
+		xml.NewDecoder(r.Body).Decode(&in)
+
+		fmt.Fprint(w, `<DeleteResult>`)
+		for _, o := range in.Objects {
+			delete(s.objects, o.Key)
+			fmt.Fprintf(w, `<Deleted><Key>%s</Key></Deleted>`, o.Key)
+		}
+		fmt.Fprint(w, `</DeleteResult>`)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestBucket(t *testing.T) *bucket.Bucket {
+	srv := httptest.NewServer(&s3Server{
+		objects: map[string]*s3Object{},
+		now:     time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+	})
+	t.Cleanup(srv.Close)
+
+	svc := s3.New(session.Must(session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(srv.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		MaxRetries:       aws.Int(0),
+	})))
+
+	return bucket.New(svc, "bucket")
+}
+
+// tableStub is an in-memory table keyed by pk and sk.
+type tableStub struct {
+	dynamodbiface.DynamoDBAPI
+
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func tableKey(key map[string]*dynamodb.AttributeValue) string {
+	return aws.StringValue(key["pk"].S) + "\x00" + aws.StringValue(key["sk"].S)
+}
+
+func (s *tableStub) GetItemWithContext(_ aws.Context, in *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: s.items[tableKey(in.Key)]}, nil
+}
+
+func (s *tableStub) BatchWriteItemWithContext(_ aws.Context, in *dynamodb.BatchWriteItemInput, _ ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	for _, reqs := range in.RequestItems {
+		if len(reqs) > maxBatchWriteItems {
+			panic("too many items")
+		}
+
+		for _, r := range reqs {
+			if r.PutRequest != nil {
+				s.items[tableKey(r.PutRequest.Item)] = r.PutRequest.Item
+			} else {
+				delete(s.items, tableKey(r.DeleteRequest.Key))
+			}
+		}
+	}
+
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (s *tableStub) QueryPagesWithContext(_ aws.Context, in *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool, _ ...request.Option) error {
+	pk := aws.StringValue(in.ExpressionAttributeValues[":pk"].S)
+
+	var keys []string
+	for k := range s.items {
+		if strings.HasPrefix(k, pk+"\x00") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	out := &dynamodb.QueryOutput{}
+	for _, k := range keys {
+		out.Items = append(out.Items, s.items[k])
+	}
+
+	fn(out, true)
+	return nil
+}
+
+func keys(items []Item) []string {
+	var keys []string
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+
+	return keys
+}
+
+func TestIndex(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	table := &tableStub{items: map[string]map[string]*dynamodb.AttributeValue{}}
+
+	b := newTestBucket(t)
+	idx := New(b, table, "index", WithMetadataKeys("owner"))
+	b = b.WithOptions(idx.BucketOption(), bucket.WithDefaultPutOptions(option.Tagging(map[string]string{"env": "prod"})))
+
+	put := func(key string, tags map[string]string, md map[string]*string) {
+		_, err := b.PutObjectWithContext(ctx, key, strings.NewReader("body"), option.Tagging(tags), func(req *s3.PutObjectInput) {
+			req.Metadata = md
+		})
+		require.NoError(t, err)
+	}
+
+	put("a", map[string]string{"team": "core", "env": "prod"}, map[string]*string{"Owner": aws.String("alice"), "Other": aws.String("x")})
+	put("b", map[string]string{"team": "core", "env": "dev"}, map[string]*string{"Owner": aws.String("bob")})
+
+	item, err := idx.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), item.Size)
+	assert.Equal(t, `"etag"`, item.ETag)
+	assert.Equal(t, time.Date(2006, 1, 2, 15, 4, 6, 0, time.UTC), item.LastModified, "Last-Modified is the one of S3")
+	assert.Equal(t, map[string]string{"team": "core", "env": "prod"}, item.Tags)
+	assert.Equal(t, map[string]string{"Owner": "alice"}, item.Metadata)
+
+	found, err := idx.FindByTag(ctx, "team", "core")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys(found))
+
+	found, err = idx.FindByMetadata(ctx, "owner", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, keys(found))
+
+	found, err = idx.FindByMetadata(ctx, "other", "x")
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	// replacing an object removes its stale tags, and the default tags are indexed
+	_, err = b.PutObjectWithContext(ctx, "a", strings.NewReader("body"))
+	require.NoError(t, err)
+
+	found, err = idx.FindByTag(ctx, "team", "core")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, keys(found))
+
+	found, err = idx.FindByTag(ctx, "env", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, keys(found))
+
+	// a copy is indexed with the tags and the metadata of the source
+	_, err = b.CopyObject("c", "b")
+	require.NoError(t, err)
+
+	found, err = idx.FindByMetadata(ctx, "owner", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, keys(found))
+
+	_, err = b.DeleteObjectWithContext(ctx, "b")
+	require.NoError(t, err)
+
+	item, err = idx.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Nil(t, item)
+
+	_, err = b.DeleteObjects([]*s3.ObjectIdentifier{{Key: aws.String("c")}})
+	require.NoError(t, err)
+
+	found, err = idx.FindByTag(ctx, "team", "core")
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	assert.Len(t, table.items, 2, "only a is indexed")
+}
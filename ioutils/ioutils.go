@@ -3,6 +3,8 @@
 package ioutils
 
 import (
+	"bytes"
+	"errors"
 	"io"
 	"io/ioutil"
 	"os"
@@ -52,3 +54,96 @@ func NewFileReadSeeker(r io.Reader) (*FileReadSeeker, error) {
 		file: f,
 	}, nil
 }
+
+// Peek returns up to n bytes from the current position of rs and rewinds rs to that position.
+func Peek(rs io.ReadSeeker, n int) ([]byte, error) {
+	pos, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, n)
+	read, err := io.ReadFull(rs, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	if _, err := rs.Seek(pos, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return buf[:read], nil
+}
+
+// Remaining returns the number of bytes from the current position to the end of rs and keeps the position.
+func Remaining(rs io.ReadSeeker) (int64, error) {
+	pos, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := rs.Seek(pos, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	return end - pos, nil
+}
+
+// ErrSpoolLimit is returned by Spool when r has more data than the limit.
+var ErrSpoolLimit = errors.New("ioutils: data exceeds the spool limit")
+
+// Spool reads r to the end and returns io.ReadSeekCloser holding the data so that it can be read again.
+// Data up to memLimit bytes is held in memory. Larger data is written to a temporary file in dir, or the default
+// directory for temporary files if dir is empty, and the file is removed by Close.
+// It fails with ErrSpoolLimit if r has more than limit bytes unless limit is zero.
+func Spool(r io.Reader, dir string, memLimit, limit int64) (io.ReadSeekCloser, error) {
+	if limit > 0 {
+		// read one more byte to detect the excess
+		r = io.LimitReader(r, limit+1)
+	}
+
+	buf := &bytes.Buffer{}
+	n, err := io.CopyN(buf, r, memLimit+1)
+	if err == io.EOF {
+		if limit > 0 && n > limit {
+			return nil, ErrSpoolLimit
+		}
+
+		return nopCloser{bytes.NewReader(buf.Bytes())}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile(dir, "spool")
+	if err != nil {
+		return nil, err
+	}
+
+	frs := &FileReadSeeker{file: f}
+
+	written, err := io.Copy(f, io.MultiReader(buf, r))
+	if err == nil && limit > 0 && written > limit {
+		err = ErrSpoolLimit
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		frs.Close()
+		return nil, err
+	}
+
+	return frs, nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
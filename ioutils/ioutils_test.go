@@ -0,0 +1,64 @@
+package ioutils
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpool(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		data     string
+		memLimit int64
+		inFile   bool
+	}{
+		{"hello", 5, false},
+		{"hello", 4, true},
+	} {
+		rs, err := Spool(strings.NewReader(tc.data), dir, tc.memLimit, 0)
+		require.NoError(t, err)
+
+		_, isFile := rs.(*FileReadSeeker)
+		assert.Equal(t, tc.inFile, isFile)
+
+		for i := 0; i < 2; i++ {
+			data, err := ioutil.ReadAll(rs)
+			require.NoError(t, err)
+			assert.Equal(t, tc.data, string(data))
+
+			_, err = rs.Seek(0, io.SeekStart)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, rs.Close())
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	for _, memLimit := range []int64{2, 10} {
+		_, err = Spool(strings.NewReader("hello"), dir, memLimit, 4)
+		assert.Equal(t, ErrSpoolLimit, err)
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	rs := strings.NewReader("hello")
+	_, err := rs.Seek(2, io.SeekStart)
+	require.NoError(t, err)
+
+	n, err := Remaining(rs)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	rest, err := ioutil.ReadAll(rs)
+	require.NoError(t, err)
+	assert.Equal(t, "llo", string(rest), "the position is kept")
+}
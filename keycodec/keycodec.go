@@ -0,0 +1,103 @@
+// Package keycodec provides bucket.KeyCodec implementations that hide key names stored in S3.
+//
+// Both codecs are deterministic: the same key segment always produces the same stored segment, so equal keys,
+// common segments and the length of segments are still visible to whoever can list the bucket.
+package keycodec
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrIrreversible is returned by HMAC.Decode since an HMAC cannot be reversed.
+var ErrIrreversible = errors.New("keycodec: the key cannot be decoded")
+
+// ErrInvalid is returned by AES.Decode when the stored key is not produced by the codec.
+var ErrInvalid = errors.New("keycodec: invalid encoded key")
+
+// AES encrypts key segments with AES-GCM using a nonce derived from the segment with HMAC-SHA256,
+// which makes the encryption deterministic and authenticated. The stored segments are URL-safe base64.
+type AES struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// NewAES returns AES instance with the 32-byte key. The first half of the key derives the nonces and the second half
+// is the AES-128 key.
+func NewAES(key []byte) (*AES, error) {
+	if len(key) != 32 {
+		return nil, errors.New("keycodec: key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key[16:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AES{
+		aead:   aead,
+		macKey: append([]byte(nil), key[:16]...),
+	}, nil
+}
+
+// Encode implements bucket.KeyCodec.
+func (c *AES) Encode(key string) string {
+	nonce := c.nonce([]byte(key))
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte(key), nil))
+}
+
+// Decode implements bucket.KeyCodec.
+func (c *AES) Decode(stored string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(stored)
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return "", ErrInvalid
+	}
+
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil || !hmac.Equal(nonce, c.nonce(plaintext)) {
+		return "", ErrInvalid
+	}
+
+	return string(plaintext), nil
+}
+
+func (c *AES) nonce(plaintext []byte) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write(plaintext)
+
+	return mac.Sum(nil)[:c.aead.NonceSize()]
+}
+
+// HMAC replaces key segments with their hex-encoded HMAC-SHA256. The keys cannot be recovered from listings.
+type HMAC struct {
+	key []byte
+}
+
+// NewHMAC returns HMAC instance with key.
+func NewHMAC(key []byte) *HMAC {
+	return &HMAC{key: append([]byte(nil), key...)}
+}
+
+// Encode implements bucket.KeyCodec.
+func (c *HMAC) Encode(key string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(key))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Decode implements bucket.KeyCodec. It always returns ErrIrreversible.
+func (c *HMAC) Decode(string) (string, error) {
+	return "", ErrIrreversible
+}
@@ -0,0 +1,37 @@
+package keycodec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAES(t *testing.T) {
+	c, err := NewAES(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	for _, key := range []string{"a", "user@example.com", "日本語", "with space+plus%"} {
+		encoded := c.Encode(key)
+		assert.Equal(t, encoded, c.Encode(key), "must be deterministic")
+		assert.NotContains(t, encoded, "/")
+
+		decoded, err := c.Decode(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, key, decoded)
+	}
+
+	_, err = c.Decode("not-encoded")
+	assert.Equal(t, ErrInvalid, err)
+}
+
+func TestHMAC(t *testing.T) {
+	c := NewHMAC([]byte("secret"))
+
+	assert.Equal(t, c.Encode("a"), c.Encode("a"))
+	assert.NotEqual(t, c.Encode("a"), c.Encode("b"))
+
+	_, err := c.Decode(c.Encode("a"))
+	assert.Equal(t, ErrIrreversible, err)
+}
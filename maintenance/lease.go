@@ -0,0 +1,98 @@
+package maintenance
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+// lease is the content of the lock object.
+type lease struct {
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// acquire takes or renews the lease on the lock object and reports whether the runner is the leader.
+// The lease is taken over when it is expired. The clocks of the runners are assumed to be roughly in sync.
+func (r *Runner) acquire(ctx aws.Context) (bool, error) {
+	cur, etag, err := r.loadLease(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if etag != "" && cur.Owner != r.owner && now.Before(cur.Expires) {
+		return false, nil
+	}
+
+	data, err := json.Marshal(lease{Owner: r.owner, Expires: now.Add(r.leaseDuration)})
+	if err != nil {
+		return false, err
+	}
+
+	body := bytes.NewReader(data)
+	if etag == "" {
+		_, err = r.bucket.PutObjectIfNotExists(ctx, r.lockKey, body, option.ContentType("application/json"))
+	} else {
+		_, err = r.bucket.PutObjectIfMatch(ctx, r.lockKey, etag, body, option.ContentType("application/json"))
+	}
+
+	if bucket.IsPreconditionFailed(err) {
+		// another runner took it first
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// release gives up the lease if the runner holds it.
+func (r *Runner) release(ctx aws.Context) error {
+	cur, etag, err := r.loadLease(ctx)
+	if err != nil || etag == "" || cur.Owner != r.owner {
+		return err
+	}
+
+	data, err := json.Marshal(lease{Owner: r.owner})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.bucket.PutObjectIfMatch(ctx, r.lockKey, etag, bytes.NewReader(data), option.ContentType("application/json"))
+	if bucket.IsPreconditionFailed(err) {
+		return nil
+	}
+
+	return err
+}
+
+// loadLease returns the lease and the ETag of the lock object. The ETag is empty if the object does not exist.
+func (r *Runner) loadLease(ctx aws.Context) (lease, string, error) {
+	resp, err := r.bucket.GetObjectWithContext(ctx, r.lockKey)
+	if bucket.IsNotFound(err) {
+		return lease{}, "", nil
+	}
+	if err != nil {
+		return lease{}, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return lease{}, "", err
+	}
+
+	var l lease
+	if err := json.Unmarshal(data, &l); err != nil {
+		return lease{}, "", err
+	}
+
+	return l, aws.StringValue(resp.ETag), nil
+}
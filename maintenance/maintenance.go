@@ -0,0 +1,243 @@
+// Package maintenance runs periodic housekeeping tasks on a Bucket, such as aborting stale multipart uploads.
+//
+// Runners in different processes can share a bucket. Only the runner holding the lease on a lock object
+// in the bucket runs the tasks, and another runner takes over when the lease expires.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nabeken/aws-go-s3/bucket"
+)
+
+const (
+	defaultLockKey       = ".maintenance/lock.json"
+	defaultLeaseDuration = 5 * time.Minute
+	defaultJitter        = 0.1
+	releaseTimeout       = 10 * time.Second
+)
+
+// A Task is a unit of maintenance run periodically by Runner.
+type Task struct {
+	// Name identifies the task in Stats.
+	Name string
+	Run  func(ctx aws.Context) error
+}
+
+// TaskStats are the metrics of a task.
+type TaskStats struct {
+	Runs         int64
+	Failures     int64
+	LastRun      time.Time
+	LastDuration time.Duration
+
+	// LastError is the error of the last run or nil if it succeeded.
+	LastError error
+}
+
+// An Option configures a Runner in New.
+type Option func(r *Runner)
+
+// WithLockKey returns an Option that uses key as the lock object instead of ".maintenance/lock.json".
+func WithLockKey(key string) Option {
+	return func(r *Runner) {
+		r.lockKey = key
+	}
+}
+
+// WithLeaseDuration returns an Option that sets the duration of the lease on the lock. The default is 5 minutes.
+// The lease is renewed before each task, so it should be longer than the longest task.
+func WithLeaseDuration(d time.Duration) Option {
+	return func(r *Runner) {
+		r.leaseDuration = d
+	}
+}
+
+// WithOwner returns an Option that sets the ID of the runner in the lock. The default is made of the hostname and the PID.
+func WithOwner(id string) Option {
+	return func(r *Runner) {
+		r.owner = id
+	}
+}
+
+// WithJitter returns an Option that randomizes each interval by up to the fraction f of it. The default is 0.1.
+func WithJitter(f float64) Option {
+	return func(r *Runner) {
+		r.jitter = f
+	}
+}
+
+type scheduled struct {
+	task     Task
+	interval time.Duration
+	next     time.Time
+}
+
+// A Runner runs the registered tasks periodically while it holds the lease on the lock object.
+type Runner struct {
+	bucket        *bucket.Bucket
+	lockKey       string
+	leaseDuration time.Duration
+	owner         string
+	jitter        float64
+
+	mu    sync.Mutex
+	tasks []*scheduled
+	stats map[string]TaskStats
+}
+
+// New returns Runner instance with the lock object in b.
+func New(b *bucket.Bucket, opts ...Option) *Runner {
+	host, _ := os.Hostname()
+
+	r := &Runner{
+		bucket:        b,
+		lockKey:       defaultLockKey,
+		leaseDuration: defaultLeaseDuration,
+		owner:         fmt.Sprintf("%s-%d-%x", host, os.Getpid(), rand.Int63()),
+		jitter:        defaultJitter,
+		stats:         map[string]TaskStats{},
+	}
+
+	for _, f := range opts {
+		f(r)
+	}
+
+	return r
+}
+
+// Register adds t to be run every interval. It must be called before Run.
+func (r *Runner) Register(t Task, interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tasks = append(r.tasks, &scheduled{task: t, interval: interval})
+}
+
+// Stats returns the metrics of the tasks by name.
+func (r *Runner) Stats() map[string]TaskStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make(map[string]TaskStats, len(r.stats))
+	for name, s := range r.stats {
+		stats[name] = s
+	}
+
+	return stats
+}
+
+// Run runs the tasks one at a time when they are due until ctx is done, and then releases the lease and returns ctx.Err().
+// The first run of each task is spread over the jitter of its interval. A task that is due while another runner
+// holds the lease is skipped until the next interval. An error in acquiring the lease skips the task as well.
+func (r *Runner) Run(ctx aws.Context) error {
+	r.mu.Lock()
+	tasks := r.tasks
+	r.mu.Unlock()
+
+	if len(tasks) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	for _, s := range tasks {
+		s.next = now.Add(time.Duration(rand.Float64() * r.jitter * float64(s.interval)))
+	}
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+		defer cancel()
+
+		r.release(ctx)
+	}()
+
+	for {
+		s := tasks[0]
+		for _, t := range tasks[1:] {
+			if t.next.Before(s.next) {
+				s = t
+			}
+		}
+
+		if err := aws.SleepWithContext(ctx, time.Until(s.next)); err != nil {
+			return ctx.Err()
+		}
+
+		if leader, err := r.acquire(ctx); err == nil && leader {
+			r.runTask(ctx, s.task)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		s.next = time.Now().Add(r.jittered(s.interval))
+	}
+}
+
+// runTask runs t and records its metrics.
+func (r *Runner) runTask(ctx aws.Context, t Task) {
+	start := time.Now()
+	err := t.Run(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.stats[t.Name]
+	s.Runs++
+	if err != nil {
+		s.Failures++
+	}
+	s.LastRun = start
+	s.LastDuration = time.Since(start)
+	s.LastError = err
+	r.stats[t.Name] = s
+}
+
+// jittered returns d randomized by up to the jitter of the runner.
+func (r *Runner) jittered(d time.Duration) time.Duration {
+	return d + time.Duration((rand.Float64()*2-1)*r.jitter*float64(d))
+}
+
+// AbortStaleMultipartUploads returns a Task that aborts the multipart uploads with the given prefix
+// initiated more than olderThan ago. See bucket.Bucket.AbortStaleMultipartUploads.
+func AbortStaleMultipartUploads(b *bucket.Bucket, prefix string, olderThan time.Duration) Task {
+	return Task{
+		Name: "abort-stale-multipart-uploads:" + prefix,
+		Run: func(ctx aws.Context) error {
+			_, err := b.AbortStaleMultipartUploads(ctx, prefix, olderThan)
+			return err
+		},
+	}
+}
+
+// PurgeExpiredDeleteMarkers returns a Task that deletes the delete markers with the given prefix that have
+// no noncurrent versions. See bucket.Bucket.PurgeExpiredDeleteMarkers.
+func PurgeExpiredDeleteMarkers(b *bucket.Bucket, prefix string) Task {
+	return Task{
+		Name: "purge-expired-delete-markers:" + prefix,
+		Run: func(ctx aws.Context) error {
+			_, err := b.PurgeExpiredDeleteMarkers(ctx, prefix)
+			return err
+		},
+	}
+}
+
+// TTLCleanup returns a Task that deletes the objects with the given prefix last modified more than ttl ago.
+// See bucket.Bucket.DeleteObjectsOlderThan.
+func TTLCleanup(b *bucket.Bucket, prefix string, ttl time.Duration) Task {
+	return Task{
+		Name: "ttl-cleanup:" + prefix,
+		Run: func(ctx aws.Context) error {
+			_, err := b.DeleteObjectsOlderThan(ctx, prefix, ttl)
+			return err
+		},
+	}
+}
@@ -0,0 +1,295 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/buckettest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLease(t *testing.T) {
+	ctx := context.Background()
+	b := bucket.New(buckettest.New("bucket"), "bucket")
+
+	r1 := New(b, WithOwner("r1"), WithLeaseDuration(time.Minute))
+	r2 := New(b, WithOwner("r2"), WithLeaseDuration(time.Minute))
+
+	leader, err := r1.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+
+	leader, err = r2.acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, leader, "the lease is held by r1")
+
+	leader, err = r1.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader, "r1 renews its lease")
+
+	require.NoError(t, r2.release(ctx))
+	leader, err = r2.acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, leader, "r2 cannot release the lease of r1")
+
+	require.NoError(t, r1.release(ctx))
+	leader, err = r2.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader, "r2 takes the released lease")
+
+	cur, _, err := r2.loadLease(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "r2", cur.Owner)
+
+	// the lease of r2 expires
+	_, err = b.PutObject(defaultLockKey, strings.NewReader(`{"owner":"r2","expires":"2006-01-02T15:04:05Z"}`))
+	require.NoError(t, err)
+
+	leader, err = r1.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader, "r1 takes over the expired lease")
+
+	cur, _, err = r1.loadLease(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "r1", cur.Owner)
+	assert.True(t, cur.Expires.After(time.Now()))
+}
+
+// conditionalS3 is an in-memory S3 that honors the If-Match and If-None-Match headers of PutObject
+// and can make a write between the read and the conditional write of a runner.
+type conditionalS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	etags   map[string]string
+	seq     int
+
+	// beforePut is called with mu held before a conditional PutObject is checked, e.g. to make a concurrent write.
+	beforePut func(path string)
+}
+
+func (s *conditionalS3) put(path, data string) {
+	s.seq++
+	s.objects[path] = data
+	s.etags[path] = fmt.Sprintf(`"%d"`, s.seq)
+}
+
+func (s *conditionalS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == http.MethodPut && s.beforePut != nil && (r.Header.Get("If-Match") != "" || r.Header.Get("If-None-Match") != "") {
+		s.beforePut(r.URL.Path)
+	}
+
+	data, exists := s.objects[r.URL.Path]
+
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+
+		w.Header().Set("ETag", s.etags[r.URL.Path])
+		fmt.Fprint(w, data)
+	case http.MethodPut:
+		if (r.Header.Get("If-None-Match") == "*" && exists) ||
+			(r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != s.etags[r.URL.Path]) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(w, `<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>`)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		s.put(r.URL.Path, string(body))
+		w.Header().Set("ETag", s.etags[r.URL.Path])
+	}
+}
+
+func TestLeaseRace(t *testing.T) {
+	ctx := context.Background()
+
+	fake := &conditionalS3{objects: map[string]string{}, etags: map[string]string{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	svc := s3.New(session.Must(session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(srv.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		MaxRetries:       aws.Int(0),
+	})))
+	b := bucket.New(svc, "bucket")
+
+	r1 := New(b, WithOwner("r1"), WithLeaseDuration(time.Minute))
+	r2 := New(b, WithOwner("r2"), WithLeaseDuration(time.Minute))
+
+	// r2 takes the lease between the read and the write of r1
+	takenByR2 := func(path string) {
+		fake.put(path, fmt.Sprintf(`{"owner":"r2","expires":%q}`, time.Now().Add(time.Minute).Format(time.RFC3339)))
+	}
+
+	t.Run("NoLease", func(t *testing.T) {
+		fake.beforePut = takenByR2
+		defer func() { fake.beforePut = nil }()
+
+		leader, err := r1.acquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, leader, "r2 created the lease first")
+
+		cur, _, err := r2.loadLease(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "r2", cur.Owner)
+	})
+
+	t.Run("ExpiredLease", func(t *testing.T) {
+		fake.put("/bucket/"+defaultLockKey, `{"owner":"r0","expires":"2006-01-02T15:04:05Z"}`)
+
+		var once sync.Once
+		fake.beforePut = func(path string) { once.Do(func() { takenByR2(path) }) }
+		defer func() { fake.beforePut = nil }()
+
+		leader, err := r1.acquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, leader, "r2 took over the expired lease first")
+
+		cur, _, err := r1.loadLease(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "r2", cur.Owner)
+
+		leader, err = r2.acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, leader, "r2 renews the lease it took over")
+	})
+}
+
+func TestRunner(t *testing.T) {
+	b := bucket.New(buckettest.New("bucket"), "bucket")
+
+	var runs int64
+	r := New(b, WithOwner("r1"), WithLockKey("lock.json"), WithJitter(0))
+	r.Register(Task{Name: "count", Run: func(aws.Context) error {
+		atomic.AddInt64(&runs, 1)
+		return nil
+	}}, 10*time.Millisecond)
+	r.Register(Task{Name: "fail", Run: func(aws.Context) error {
+		return assert.AnError
+	}}, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, r.Run(ctx), context.DeadlineExceeded)
+	assert.Greater(t, atomic.LoadInt64(&runs), int64(1))
+
+	stats := r.Stats()
+	assert.Equal(t, atomic.LoadInt64(&runs), stats["count"].Runs)
+	assert.Zero(t, stats["count"].Failures)
+	assert.Equal(t, stats["fail"].Runs, stats["fail"].Failures)
+	assert.ErrorIs(t, stats["fail"].LastError, assert.AnError)
+
+	cur, _, err := r.loadLease(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "r1", cur.Owner)
+	assert.True(t, cur.Expires.IsZero(), "the lease is released when Run returns")
+}
+
+func TestRunnerNotLeader(t *testing.T) {
+	b := bucket.New(buckettest.New("bucket"), "bucket")
+
+	leader, err := New(b, WithOwner("r1")).acquire(context.Background())
+	require.NoError(t, err)
+	require.True(t, leader)
+
+	r := New(b, WithOwner("r2"), WithJitter(0))
+	r.Register(Task{Name: "task", Run: func(aws.Context) error {
+		t.Error("the task runs without the lease")
+		return nil
+	}}, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, r.Run(ctx), context.DeadlineExceeded)
+	assert.Empty(t, r.Stats())
+
+	cur, _, err := r.loadLease(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "r1", cur.Owner, "the lease of another runner is kept")
+}
+
+func TestTasks(t *testing.T) {
+	ctx := context.Background()
+	fake := buckettest.New("bucket")
+	b := bucket.New(fake, "bucket")
+
+	_, err := b.EnableVersioning()
+	require.NoError(t, err)
+
+	old := time.Now().Add(-2 * time.Hour)
+	fake.Now = func() time.Time { return old }
+
+	_, err = b.PutObject("tmp/old", strings.NewReader("old"))
+	require.NoError(t, err)
+	_, err = fake.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{Bucket: b.Name, Key: aws.String("tmp/stale")})
+	require.NoError(t, err)
+
+	fake.Now = nil
+
+	_, err = b.PutObject("tmp/new", strings.NewReader("new"))
+	require.NoError(t, err)
+	_, err = fake.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{Bucket: b.Name, Key: aws.String("tmp/active")})
+	require.NoError(t, err)
+
+	// "expired" has only a delete marker left and "kept" has a noncurrent version behind its marker
+	put, err := b.PutObject("expired", strings.NewReader("v1"))
+	require.NoError(t, err)
+	_, err = b.DeleteObject("expired")
+	require.NoError(t, err)
+	_, err = fake.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{Bucket: b.Name, Key: aws.String("expired"), VersionId: put.VersionId})
+	require.NoError(t, err)
+	_, err = b.PutObject("kept", strings.NewReader("v1"))
+	require.NoError(t, err)
+	_, err = b.DeleteObject("kept")
+	require.NoError(t, err)
+
+	require.NoError(t, AbortStaleMultipartUploads(b, "tmp/", time.Hour).Run(ctx))
+	uploads, err := fake.ListMultipartUploadsWithContext(ctx, &s3.ListMultipartUploadsInput{Bucket: b.Name})
+	require.NoError(t, err)
+	require.Len(t, uploads.Uploads, 1)
+	assert.Equal(t, "tmp/active", aws.StringValue(uploads.Uploads[0].Key))
+
+	require.NoError(t, TTLCleanup(b, "tmp/", time.Hour).Run(ctx))
+	_, exists, err := b.StatObject("tmp/old")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, exists, err = b.StatObject("tmp/new")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, PurgeExpiredDeleteMarkers(b, "").Run(ctx))
+	var keys []string
+	for e, err := range b.ObjectVersions(ctx, "") {
+		require.NoError(t, err)
+		if e.IsDeleteMarker {
+			keys = append(keys, e.Key)
+		}
+	}
+	assert.Equal(t, []string{"kept", "tmp/old"}, keys)
+}
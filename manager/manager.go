@@ -0,0 +1,123 @@
+// Package manager provides a Manager that hands out Bucket instances for buckets across accounts and regions.
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/nabeken/aws-go-s3/bucket"
+)
+
+// A Role is an IAM role to assume.
+type Role struct {
+	ARN        string
+	ExternalID string
+}
+
+// A Config holds how to access a bucket.
+type Config struct {
+	// Region is the region of the bucket. The region of the session is used if empty.
+	Region string
+
+	// Roles are assumed in order, each one with the credentials of the previous one.
+	// The credentials of the session are used if empty.
+	Roles []Role
+
+	// Options are passed to bucket.New.
+	Options []bucket.Option
+}
+
+// key returns the identifier of the S3 client for c. Buckets with the same key share the client.
+func (c Config) key() string {
+	arns := make([]string, 0, len(c.Roles))
+	for _, r := range c.Roles {
+		arns = append(arns, r.ARN+"#"+r.ExternalID)
+	}
+
+	return c.Region + "|" + strings.Join(arns, ",")
+}
+
+// A Manager maps bucket names to their Config and returns cached Bucket instances.
+// It is safe for concurrent use.
+type Manager struct {
+	sess *session.Session
+
+	mu      sync.Mutex
+	configs map[string]Config
+	clients map[string]s3iface.S3API
+	buckets map[string]*bucket.Bucket
+}
+
+// New returns Manager instance that derives credentials and clients from sess.
+func New(sess *session.Session) *Manager {
+	return &Manager{
+		sess:    sess,
+		configs: map[string]Config{},
+		clients: map[string]s3iface.S3API{},
+		buckets: map[string]*bucket.Bucket{},
+	}
+}
+
+// Register associates cfg with the bucket name. It replaces the previous configuration for name if any.
+func (m *Manager) Register(name string, cfg Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.configs[name] = cfg
+	delete(m.buckets, name)
+}
+
+// Bucket returns Bucket instance for the bucket name. It returns an error if name is not registered.
+func (m *Manager) Bucket(name string) (*bucket.Bucket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if b, ok := m.buckets[name]; ok {
+		return b, nil
+	}
+
+	cfg, ok := m.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("manager: bucket %q is not registered", name)
+	}
+
+	b := bucket.New(m.client(cfg), name, cfg.Options...)
+	m.buckets[name] = b
+
+	return b, nil
+}
+
+// client returns the S3 client for cfg. It must be called with m.mu held.
+func (m *Manager) client(cfg Config) s3iface.S3API {
+	key := cfg.key()
+	if c, ok := m.clients[key]; ok {
+		return c
+	}
+
+	sess := m.sess
+	if cfg.Region != "" {
+		sess = sess.Copy(&aws.Config{Region: aws.String(cfg.Region)})
+	}
+
+	for _, r := range cfg.Roles {
+		r := r
+		creds := stscreds.NewCredentials(sess, r.ARN, func(p *stscreds.AssumeRoleProvider) {
+			if r.ExternalID != "" {
+				p.ExternalID = aws.String(r.ExternalID)
+			}
+		})
+
+		sess = sess.Copy(&aws.Config{Credentials: creds})
+	}
+
+	c := s3.New(sess)
+	m.clients[key] = c
+
+	return c
+}
@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager(t *testing.T) {
+	m := New(session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+	})))
+
+	role := Role{ARN: "arn:aws:iam::123456789012:role/a"}
+
+	m.Register("default", Config{})
+	m.Register("same", Config{})
+	m.Register("region", Config{Region: "ap-northeast-1"})
+	m.Register("role", Config{Roles: []Role{role}})
+	m.Register("role-same", Config{Roles: []Role{role}})
+	m.Register("external-id", Config{Roles: []Role{{ARN: role.ARN, ExternalID: "id"}}})
+	m.Register("region-role", Config{Region: "ap-northeast-1", Roles: []Role{role}})
+
+	buckets := map[string]*s3.S3{}
+	for _, name := range []string{"default", "same", "region", "role", "role-same", "external-id", "region-role"} {
+		b, err := m.Bucket(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, aws.StringValue(b.Name))
+
+		again, err := m.Bucket(name)
+		require.NoError(t, err)
+		assert.Same(t, b, again, "the Bucket is cached")
+
+		buckets[name] = b.S3.(*s3.S3)
+	}
+
+	assert.Same(t, buckets["default"], buckets["same"], "the client is reused")
+	assert.Same(t, buckets["role"], buckets["role-same"], "the client is reused")
+
+	clients := []*s3.S3{buckets["default"], buckets["region"], buckets["role"], buckets["external-id"], buckets["region-role"]}
+	for i, a := range clients {
+		for _, b := range clients[i+1:] {
+			assert.NotSame(t, a, b, "different regions or roles get distinct clients")
+		}
+	}
+
+	assert.Equal(t, "us-east-1", aws.StringValue(buckets["default"].Config.Region))
+	assert.Equal(t, "ap-northeast-1", aws.StringValue(buckets["region"].Config.Region))
+	assert.Equal(t, "ap-northeast-1", aws.StringValue(buckets["region-role"].Config.Region))
+	assert.NotSame(t, buckets["default"].Config.Credentials, buckets["role"].Config.Credentials)
+
+	_, err := m.Bucket("unknown")
+	assert.Error(t, err)
+}
+
+func TestManagerRegisterReplaces(t *testing.T) {
+	m := New(session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+	})))
+
+	m.Register("bucket", Config{})
+	b, err := m.Bucket("bucket")
+	require.NoError(t, err)
+
+	m.Register("bucket", Config{Region: "ap-northeast-1"})
+	replaced, err := m.Bucket("bucket")
+	require.NoError(t, err)
+	assert.NotSame(t, b, replaced)
+	assert.Equal(t, "ap-northeast-1", aws.StringValue(replaced.S3.(*s3.S3).Config.Region))
+}
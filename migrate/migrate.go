@@ -0,0 +1,561 @@
+// Package migrate copies the objects of a Bucket to another Bucket, e.g. to move a live bucket to another account or region.
+//
+// A migration is done in three steps. Copy copies all objects and records its progress in a checkpoint object
+// in the destination so that it resumes where it stopped. Verify compares the listings of both buckets.
+// CatchUp copies the objects written to the source since they were copied, right before the cutover.
+//
+// The objects are downloaded from the source and streamed to the destination, so the buckets can use different
+// credentials, or copied by S3 with WithServerSideCopy when the credentials of the destination can read the source.
+// Metadata and tags are carried over, and the versions and the ACLs with WithVersions and WithACLs.
+// Objects in GLACIER or DEEP_ARCHIVE are skipped since they have to be restored first.
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+const (
+	defaultCheckpointKey = ".migrate/checkpoint.json"
+	defaultConcurrency   = 8
+)
+
+// A Checkpoint is the progress of Copy.
+type Checkpoint struct {
+	// LastKey is the last key copied. Copy resumes after it.
+	LastKey string `json:"lastKey"`
+
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+
+	// Skipped are the keys not copied since they are archived.
+	Skipped []string `json:"skipped,omitempty"`
+
+	Started time.Time `json:"started"`
+	Done    bool      `json:"done"`
+}
+
+// A Diff is the difference of the destination from the source found by Verify.
+type Diff struct {
+	// Missing are the keys only in the source.
+	Missing []string
+
+	// Changed are the keys whose size differs or which are modified in the source after they are copied.
+	Changed []string
+
+	// Extra are the keys only in the destination.
+	Extra []string
+}
+
+// InSync reports whether the destination has the same objects as the source.
+func (d *Diff) InSync() bool {
+	return len(d.Missing) == 0 && len(d.Changed) == 0 && len(d.Extra) == 0
+}
+
+// An Option configures a Migrator in New.
+type Option func(m *Migrator)
+
+// WithPrefix returns an Option that migrates only the objects with prefix.
+func WithPrefix(prefix string) Option {
+	return func(m *Migrator) {
+		m.prefix = prefix
+	}
+}
+
+// WithVersions returns an Option that copies all versions of each object from the oldest to the newest instead of
+// the current version only. The destination must have versioning enabled to keep them.
+// The history of the keys that are currently deleted is not copied.
+func WithVersions() Option {
+	return func(m *Migrator) {
+		m.versions = true
+	}
+}
+
+// WithACLs returns an Option that copies the ACL of each object, or each version, after it is uploaded.
+// The grants to the owner of the source object are given to the owner of the destination object, which may be
+// another account. The buckets must not have ACLs disabled by Object Ownership.
+func WithACLs() Option {
+	return func(m *Migrator) {
+		m.acls = true
+	}
+}
+
+// WithServerSideCopy returns an Option that copies the objects with CopyObjectMultipartFrom of the destination
+// instead of downloading them from the source, so that the data does not pass through the Migrator.
+// The S3 client of the destination must be able to read the source.
+func WithServerSideCopy() Option {
+	return func(m *Migrator) {
+		m.serverSideCopy = true
+	}
+}
+
+// WithCheckpointKey returns an Option that stores the checkpoint in the destination at key
+// instead of ".migrate/checkpoint.json". The key is excluded from Verify.
+func WithCheckpointKey(key string) Option {
+	return func(m *Migrator) {
+		m.checkpointKey = key
+	}
+}
+
+// WithConcurrency returns an Option that sets the number of objects copied at the same time. The default is 8.
+func WithConcurrency(n int) Option {
+	return func(m *Migrator) {
+		m.concurrency = n
+	}
+}
+
+// WithDeleteExtra returns an Option that makes CatchUp delete the objects only in the destination.
+func WithDeleteExtra() Option {
+	return func(m *Migrator) {
+		m.deleteExtra = true
+	}
+}
+
+// A Migrator migrates the objects from a source Bucket to a destination Bucket.
+type Migrator struct {
+	src            *bucket.Bucket
+	dst            *bucket.Bucket
+	prefix         string
+	versions       bool
+	acls           bool
+	serverSideCopy bool
+	checkpointKey  string
+	concurrency    int
+	deleteExtra    bool
+}
+
+// New returns Migrator instance copying from src to dst.
+func New(src, dst *bucket.Bucket, opts ...Option) *Migrator {
+	m := &Migrator{
+		src:           src,
+		dst:           dst,
+		checkpointKey: defaultCheckpointKey,
+		concurrency:   defaultConcurrency,
+	}
+
+	for _, f := range opts {
+		f(m)
+	}
+
+	return m
+}
+
+// Copy copies the objects from the source to the destination in the order of the keys, resuming from the checkpoint
+// if one exists. The checkpoint is saved after each page of the listing. Copy returns the checkpoint at once
+// if it is done. Delete the checkpoint object to start over.
+func (m *Migrator) Copy(ctx aws.Context) (*Checkpoint, error) {
+	cp, err := m.loadCheckpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if cp.Done {
+		return cp, nil
+	}
+
+	var opts []option.ListObjectsV2Input
+	if cp.LastKey != "" {
+		opts = append(opts, option.ListStartAfter(cp.LastKey))
+	}
+
+	var perr error
+	err = m.src.ListObjectsV2PagesWithContext(ctx, m.prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		if len(page.Contents) == 0 {
+			return true
+		}
+
+		var mu sync.Mutex
+		perr = m.each(ctx, page.Contents, func(o *s3.Object) error {
+			n, skipped, err := m.copyKey(ctx, o, time.Time{})
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if skipped {
+				cp.Skipped = append(cp.Skipped, aws.StringValue(o.Key))
+			} else {
+				cp.Objects++
+				cp.Bytes += n
+			}
+
+			return nil
+		})
+		if perr != nil {
+			return false
+		}
+
+		cp.LastKey = aws.StringValue(page.Contents[len(page.Contents)-1].Key)
+		perr = m.saveCheckpoint(ctx, cp)
+
+		return perr == nil
+	}, opts...)
+	if perr != nil {
+		return cp, perr
+	}
+	if err != nil {
+		return cp, err
+	}
+
+	cp.Done = true
+
+	return cp, m.saveCheckpoint(ctx, cp)
+}
+
+// Verify lists both buckets and returns the difference of the destination from the source.
+func (m *Migrator) Verify(ctx aws.Context) (*Diff, error) {
+	diff, _, _, err := m.diff(ctx)
+
+	return diff, err
+}
+
+// diff returns the difference of the destination from the source and the objects in both buckets by key.
+func (m *Migrator) diff(ctx aws.Context) (diff *Diff, src, dst map[string]*s3.Object, err error) {
+	src, err = m.list(ctx, m.src)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	dst, err = m.list(ctx, m.dst)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	delete(dst, m.checkpointKey)
+
+	diff = &Diff{}
+	for key, s := range src {
+		d, ok := dst[key]
+		switch {
+		case !ok:
+			diff.Missing = append(diff.Missing, key)
+		case aws.Int64Value(s.Size) != aws.Int64Value(d.Size) || aws.TimeValue(s.LastModified).After(aws.TimeValue(d.LastModified)):
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+
+	for key := range dst {
+		if _, ok := src[key]; !ok {
+			diff.Extra = append(diff.Extra, key)
+		}
+	}
+
+	return diff, src, dst, nil
+}
+
+// CatchUp copies the missing and the changed objects found by Verify and deletes the extra objects if WithDeleteExtra
+// is set. It returns the difference that it resolves. With WithVersions, only the versions of a changed object newer
+// than the object in the destination are copied. Objects written to the source during CatchUp may be missed;
+// stop the writes to the source before the final CatchUp. The extra objects that fail to be deleted are reported
+// by *bucket.DeleteObjectsError.
+func (m *Migrator) CatchUp(ctx aws.Context) (*Diff, error) {
+	diff, src, dst, err := m.diff(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*s3.Object
+	for _, key := range append(append([]string(nil), diff.Missing...), diff.Changed...) {
+		objects = append(objects, src[key])
+	}
+
+	if err := m.each(ctx, objects, func(o *s3.Object) error {
+		var since time.Time
+		if d, ok := dst[aws.StringValue(o.Key)]; ok {
+			since = aws.TimeValue(d.LastModified)
+		}
+
+		_, _, err := m.copyKey(ctx, o, since)
+		return err
+	}); err != nil {
+		return diff, err
+	}
+
+	if !m.deleteExtra {
+		return diff, nil
+	}
+
+	ids := make([]*s3.ObjectIdentifier, 0, len(diff.Extra))
+	for _, key := range diff.Extra {
+		ids = append(ids, &s3.ObjectIdentifier{Key: aws.String(key)})
+	}
+
+	result, err := m.dst.DeleteObjectsAllWithContext(ctx, ids)
+	if err != nil {
+		return diff, err
+	}
+
+	return diff, result.Err()
+}
+
+// each calls fn for the objects concurrently and returns the first error.
+func (m *Migrator) each(ctx aws.Context, objects []*s3.Object, fn func(o *s3.Object) error) error {
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		ferr error
+		sem  = make(chan struct{}, m.concurrency)
+	)
+
+	for _, o := range objects {
+		if ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(o *s3.Object) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(o); err != nil {
+				once.Do(func() { ferr = err })
+			}
+		}(o)
+	}
+
+	wg.Wait()
+
+	if ferr != nil {
+		return ferr
+	}
+
+	return ctx.Err()
+}
+
+// copyKey copies the object o, or all of its versions modified after since, and returns the number of bytes copied.
+// skipped is true if the object is archived.
+func (m *Migrator) copyKey(ctx aws.Context, o *s3.Object, since time.Time) (n int64, skipped bool, err error) {
+	switch aws.StringValue(o.StorageClass) {
+	case s3.ObjectStorageClassGlacier, s3.ObjectStorageClassDeepArchive:
+		return 0, true, nil
+	}
+
+	key := aws.StringValue(o.Key)
+	if !m.versions {
+		return m.copyObject(ctx, key, "", aws.Int64Value(o.Size))
+	}
+
+	var versions []bucket.VersionEntry
+	var latest *bucket.VersionEntry
+	for e, err := range m.src.ObjectVersions(ctx, key) {
+		if err != nil {
+			return 0, false, err
+		}
+
+		if e.Key != key {
+			break
+		}
+
+		if e.IsDeleteMarker {
+			continue
+		}
+		if latest == nil {
+			latest = &e
+		}
+		if e.LastModified.After(since) {
+			versions = append(versions, e)
+		}
+	}
+
+	// the object changed without a newer version, e.g. the destination was overwritten
+	if len(versions) == 0 && latest != nil {
+		versions = append(versions, *latest)
+	}
+
+	// S3 lists the versions from the newest
+	for i := len(versions) - 1; i >= 0; i-- {
+		c, _, err := m.copyObject(ctx, key, versions[i].VersionID, versions[i].Size)
+		n += c
+		if err != nil {
+			return n, false, err
+		}
+	}
+
+	return n, false, nil
+}
+
+// copyObject copies the version versionID of key, or the current version if versionID is empty, whose size is size
+// from the source to the destination with its metadata and tags, and its ACL if WithACLs is set.
+func (m *Migrator) copyObject(ctx aws.Context, key, versionID string, size int64) (int64, bool, error) {
+	var srcVersionID, dstVersionID *string
+	if m.serverSideCopy {
+		var opts []option.CopyObjectInput
+		if versionID != "" {
+			opts = append(opts, option.CopySourceVersionID(versionID))
+		}
+
+		resp, err := m.dst.CopyObjectMultipartFrom(ctx, key, aws.StringValue(m.src.Name), m.src.ObjectKey(key), bucket.MultipartCopyOptions{}, opts...)
+		if err != nil {
+			return 0, false, err
+		}
+
+		srcVersionID, dstVersionID = resp.CopySourceVersionId, resp.VersionId
+	} else {
+		var err error
+		size, srcVersionID, dstVersionID, err = m.streamObject(ctx, key, versionID)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	if m.acls {
+		if err := m.copyACL(ctx, key, srcVersionID, dstVersionID); err != nil {
+			return 0, false, err
+		}
+	}
+
+	return size, false, nil
+}
+
+// streamObject downloads the version versionID of key from the source and streams it to the destination with its
+// metadata and tags. It returns the size and the versions of the source and the destination.
+func (m *Migrator) streamObject(ctx aws.Context, key, versionID string) (int64, *string, *string, error) {
+	var opts []option.GetObjectInput
+	if versionID != "" {
+		opts = append(opts, option.GetVersionID(versionID))
+	}
+
+	resp, err := m.src.GetObjectWithContext(ctx, key, opts...)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	var tags map[string]string
+	if aws.Int64Value(resp.TagCount) > 0 {
+		var tagOpts []option.GetObjectTaggingInput
+		if resp.VersionId != nil {
+			tagOpts = append(tagOpts, option.TaggingVersionID(aws.StringValue(resp.VersionId)))
+		}
+
+		tags, err = m.src.GetObjectTaggingWithContext(ctx, key, tagOpts...)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+	}
+
+	// the body is not seekable so it is uploaded in parts if it is large, without being spooled
+	put, err := m.dst.PutObjectStreamWithContext(ctx, key, resp.Body, func(req *s3.PutObjectInput) {
+		req.ContentType = resp.ContentType
+		req.ContentEncoding = resp.ContentEncoding
+		req.ContentDisposition = resp.ContentDisposition
+		req.ContentLanguage = resp.ContentLanguage
+		req.CacheControl = resp.CacheControl
+		req.WebsiteRedirectLocation = resp.WebsiteRedirectLocation
+		req.Metadata = resp.Metadata
+
+		if expires, err := http.ParseTime(aws.StringValue(resp.Expires)); err == nil {
+			req.Expires = aws.Time(expires)
+		}
+
+		if len(tags) > 0 {
+			option.Tagging(tags)(req)
+		}
+	})
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return aws.Int64Value(resp.ContentLength), resp.VersionId, put.VersionId, nil
+}
+
+// copyACL sets the grants of the ACL of the version srcVersionID of key in the source on the version dstVersionID
+// of key in the destination. The grants to the owner of the source object are given to the owner of the destination object.
+func (m *Migrator) copyACL(ctx aws.Context, key string, srcVersionID, dstVersionID *string) error {
+	var srcOpts, dstOpts []option.GetObjectACLInput
+	if srcVersionID != nil {
+		srcOpts = append(srcOpts, option.ACLVersionID(aws.StringValue(srcVersionID)))
+	}
+	if dstVersionID != nil {
+		dstOpts = append(dstOpts, option.ACLVersionID(aws.StringValue(dstVersionID)))
+	}
+
+	src, err := m.src.GetObjectACLWithContext(ctx, key, srcOpts...)
+	if err != nil {
+		return err
+	}
+
+	dst, err := m.dst.GetObjectACLWithContext(ctx, key, dstOpts...)
+	if err != nil {
+		return err
+	}
+
+	var srcOwner string
+	if src.Owner != nil {
+		srcOwner = aws.StringValue(src.Owner.ID)
+	}
+
+	grants := make([]*s3.Grant, 0, len(src.Grants))
+	for _, g := range src.Grants {
+		grant := *g
+		if g.Grantee != nil && aws.StringValue(g.Grantee.Type) == s3.TypeCanonicalUser && aws.StringValue(g.Grantee.ID) == srcOwner && dst.Owner != nil {
+			grant.Grantee = &s3.Grantee{Type: aws.String(s3.TypeCanonicalUser), ID: dst.Owner.ID}
+		}
+		grants = append(grants, &grant)
+	}
+
+	_, err = m.dst.PutObjectACLWithContext(ctx, key, "", func(req *s3.PutObjectAclInput) {
+		req.AccessControlPolicy = &s3.AccessControlPolicy{Owner: dst.Owner, Grants: grants}
+		req.VersionId = dstVersionID
+	})
+
+	return err
+}
+
+// list returns the objects under the prefix in b by key.
+func (m *Migrator) list(ctx aws.Context, b *bucket.Bucket) (map[string]*s3.Object, error) {
+	objects := map[string]*s3.Object{}
+	err := b.ListObjectsV2PagesWithContext(ctx, m.prefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			objects[aws.StringValue(o.Key)] = o
+		}
+		return true
+	})
+
+	return objects, err
+}
+
+func (m *Migrator) loadCheckpoint(ctx aws.Context) (*Checkpoint, error) {
+	resp, err := m.dst.GetObjectWithContext(ctx, m.checkpointKey)
+	if bucket.IsNotFound(err) {
+		return &Checkpoint{Started: time.Now().UTC()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+func (m *Migrator) saveCheckpoint(ctx aws.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.dst.PutObjectWithContext(ctx, m.checkpointKey, bytes.NewReader(data), option.ContentType("application/json"))
+
+	return err
+}
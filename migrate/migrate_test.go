@@ -0,0 +1,409 @@
+package migrate
+
+import (
+	"errors"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/buckettest"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listStub struct {
+	s3iface.S3API
+
+	objects []*s3.Object
+}
+
+func (s *listStub) ListObjectsV2PagesWithContext(_ aws.Context, _ *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	fn(&s3.ListObjectsV2Output{Contents: s.objects}, true)
+	return nil
+}
+
+func TestVerify(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	obj := func(key string, size int64, modified time.Time) *s3.Object {
+		return &s3.Object{Key: aws.String(key), Size: aws.Int64(size), LastModified: aws.Time(modified)}
+	}
+
+	src := bucket.New(&listStub{objects: []*s3.Object{
+		obj("same", 1, t0),
+		obj("missing", 1, t0),
+		obj("resized", 1, t0),
+		obj("modified", 1, t0.Add(time.Hour)),
+	}}, "src")
+	dst := bucket.New(&listStub{objects: []*s3.Object{
+		obj(defaultCheckpointKey, 1, t0),
+		obj("same", 1, t0.Add(time.Minute)),
+		obj("resized", 2, t0.Add(time.Minute)),
+		obj("modified", 1, t0.Add(time.Minute)),
+		obj("extra", 1, t0),
+	}}, "dst")
+
+	diff, err := New(src, dst).Verify(aws.BackgroundContext())
+	require.NoError(t, err)
+
+	sort.Strings(diff.Changed)
+	assert.Equal(t, &Diff{
+		Missing: []string{"missing"},
+		Changed: []string{"modified", "resized"},
+		Extra:   []string{"extra"},
+	}, diff)
+	assert.False(t, diff.InSync())
+}
+
+// newFake returns the buckets "src" and "dst" on a Fake whose clock advances a second at each call.
+func newFake(t *testing.T) (*buckettest.Fake, *bucket.Bucket, *bucket.Bucket) {
+	t.Helper()
+
+	fake := buckettest.New("src", "dst")
+
+	var mu sync.Mutex
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake.Now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now = now.Add(time.Second)
+		return now
+	}
+
+	return fake, bucket.New(fake, "src"), bucket.New(fake, "dst")
+}
+
+func putString(t *testing.T, b *bucket.Bucket, key, body string) {
+	t.Helper()
+
+	_, err := b.PutObjectWithContext(aws.BackgroundContext(), key, strings.NewReader(body))
+	require.NoError(t, err)
+}
+
+func getString(t *testing.T, b *bucket.Bucket, key string) string {
+	t.Helper()
+
+	resp, err := b.GetObjectWithContext(aws.BackgroundContext(), key)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestCopyResume(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	_, src, dst := newFake(t)
+
+	for _, key := range []string{"a", "b", "c"} {
+		putString(t, src, key, key+key)
+	}
+
+	m := New(src, dst)
+	require.NoError(t, m.saveCheckpoint(ctx, &Checkpoint{LastKey: "b", Objects: 2, Bytes: 4}))
+
+	cp, err := m.Copy(ctx)
+	require.NoError(t, err)
+	assert.True(t, cp.Done)
+	assert.Equal(t, "c", cp.LastKey)
+	assert.Equal(t, int64(3), cp.Objects)
+	assert.Equal(t, int64(6), cp.Bytes)
+
+	assert.Equal(t, "cc", getString(t, dst, "c"))
+	for _, key := range []string{"a", "b"} {
+		_, err := dst.HeadObjectWithContext(ctx, key)
+		assert.True(t, bucket.IsNotFound(err), key)
+	}
+
+	// a done checkpoint makes Copy a no-op
+	putString(t, src, "d", "dd")
+	cp, err = m.Copy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cp.Objects)
+
+	_, err = dst.HeadObjectWithContext(ctx, "d")
+	assert.True(t, bucket.IsNotFound(err))
+}
+
+// failGet fails GetObject of key.
+type failGet struct {
+	*buckettest.Fake
+
+	key string
+}
+
+var errGet = errors.New("get failed")
+
+func (f *failGet) GetObjectWithContext(ctx aws.Context, in *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	if aws.StringValue(in.Key) == f.key {
+		return nil, errGet
+	}
+
+	return f.Fake.GetObjectWithContext(ctx, in, opts...)
+}
+
+func TestCopyError(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	fake, src, dst := newFake(t)
+
+	for _, key := range []string{"a", "b", "c"} {
+		putString(t, src, key, key)
+	}
+
+	src = bucket.New(&failGet{Fake: fake, key: "b"}, "src")
+
+	cp, err := New(src, dst).Copy(ctx)
+	assert.Equal(t, errGet, err)
+	assert.False(t, cp.Done)
+	assert.Empty(t, cp.LastKey)
+	assert.Equal(t, int64(2), cp.Objects)
+	assert.Equal(t, int64(2), cp.Bytes)
+}
+
+func TestCatchUp(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	_, src, dst := newFake(t)
+
+	putString(t, src, "a", "a1")
+	putString(t, src, "b", "b1")
+
+	m := New(src, dst, WithDeleteExtra())
+	_, err := m.Copy(ctx)
+	require.NoError(t, err)
+
+	putString(t, src, "a", "a2")
+	putString(t, src, "c", "c1")
+	putString(t, dst, "extra", "x")
+
+	diff, err := m.CatchUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Diff{
+		Missing: []string{"c"},
+		Changed: []string{"a"},
+		Extra:   []string{"extra"},
+	}, diff)
+
+	assert.Equal(t, "a2", getString(t, dst, "a"))
+	assert.Equal(t, "c1", getString(t, dst, "c"))
+
+	diff, err = m.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, diff.InSync(), "%+v", diff)
+}
+
+func TestCatchUpVersions(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	_, src, dst := newFake(t)
+
+	for _, b := range []*bucket.Bucket{src, dst} {
+		_, err := b.EnableVersioningWithContext(ctx)
+		require.NoError(t, err)
+	}
+
+	putString(t, src, "a", "a1")
+	putString(t, src, "a", "a2")
+
+	m := New(src, dst, WithVersions())
+	_, err := m.Copy(ctx)
+	require.NoError(t, err)
+
+	putString(t, src, "a", "a3")
+	putString(t, src, "a", "a4")
+
+	diff, err := m.CatchUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, diff.Changed)
+
+	var bodies []string
+	for e, err := range dst.ObjectVersions(ctx, "a") {
+		require.NoError(t, err)
+
+		resp, err := dst.GetObjectWithContext(ctx, e.Key, option.GetVersionID(e.VersionID))
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		bodies = append(bodies, string(data))
+	}
+
+	// only the versions written after Copy are copied again
+	assert.Equal(t, []string{"a4", "a3", "a2", "a1"}, bodies)
+}
+
+// aclFake keeps the ACLs of the objects that the Fake does not implement. The owner is the name of the bucket.
+type aclFake struct {
+	*buckettest.Fake
+
+	mu   sync.Mutex
+	acls map[string][]*s3.Grant
+}
+
+func (f *aclFake) GetObjectAclWithContext(_ aws.Context, in *s3.GetObjectAclInput, _ ...request.Option) (*s3.GetObjectAclOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return &s3.GetObjectAclOutput{
+		Owner:  &s3.Owner{ID: in.Bucket},
+		Grants: f.acls[aws.StringValue(in.Bucket)+"/"+aws.StringValue(in.Key)],
+	}, nil
+}
+
+func (f *aclFake) PutObjectAclWithContext(_ aws.Context, in *s3.PutObjectAclInput, _ ...request.Option) (*s3.PutObjectAclOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.acls[aws.StringValue(in.Bucket)+"/"+aws.StringValue(in.Key)] = in.AccessControlPolicy.Grants
+
+	return &s3.PutObjectAclOutput{}, nil
+}
+
+func TestCopyACLs(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	fake, _, _ := newFake(t)
+
+	owner := func(id string) *s3.Grant {
+		return &s3.Grant{
+			Grantee:    &s3.Grantee{Type: aws.String(s3.TypeCanonicalUser), ID: aws.String(id)},
+			Permission: aws.String(s3.PermissionFullControl),
+		}
+	}
+	public := &s3.Grant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeGroup), URI: aws.String(option.AllUsers)},
+		Permission: aws.String(s3.PermissionRead),
+	}
+
+	acl := &aclFake{Fake: fake, acls: map[string][]*s3.Grant{
+		"src/a": {owner("src"), public},
+	}}
+	src, dst := bucket.New(acl, "src"), bucket.New(acl, "dst")
+
+	putString(t, src, "a", "a")
+
+	_, err := New(src, dst, WithACLs()).Copy(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*s3.Grant{owner("dst"), public}, acl.acls["dst/a"])
+}
+
+func TestCopyServerSideCopy(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	_, src, dst := newFake(t)
+
+	for _, b := range []*bucket.Bucket{src, dst} {
+		_, err := b.EnableVersioningWithContext(ctx)
+		require.NoError(t, err)
+	}
+
+	putString(t, src, "a", "a1")
+	_, err := src.PutObjectWithContext(ctx, "a", strings.NewReader("a2"),
+		option.Tagging(map[string]string{"team": "x"}),
+		func(req *s3.PutObjectInput) { req.Metadata = map[string]*string{"Owner": aws.String("alice")} },
+	)
+	require.NoError(t, err)
+
+	cp, err := New(src, dst, WithVersions(), WithServerSideCopy()).Copy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cp.Objects)
+	assert.Equal(t, int64(4), cp.Bytes)
+
+	var bodies []string
+	for e, err := range dst.ObjectVersions(ctx, "a") {
+		require.NoError(t, err)
+
+		resp, err := dst.GetObjectWithContext(ctx, e.Key, option.GetVersionID(e.VersionID))
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		bodies = append(bodies, string(data))
+	}
+	assert.Equal(t, []string{"a2", "a1"}, bodies)
+
+	head, err := dst.HeadObjectWithContext(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", aws.StringValue(head.Metadata["Owner"]))
+
+	tags, err := dst.GetObjectTaggingWithContext(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "x"}, tags)
+}
+
+func TestCopyStream(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	_, src, dst := newFake(t)
+
+	// larger than a part of PutObjectStream
+	body := strings.Repeat("x", 9<<20)
+	putString(t, src, "large", body)
+
+	cp, err := New(src, dst).Copy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(body)), cp.Bytes)
+
+	head, err := dst.HeadObjectWithContext(ctx, "large")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(body)), aws.Int64Value(head.ContentLength))
+	assert.True(t, strings.HasSuffix(aws.StringValue(head.ETag), `-2"`), "uploaded in parts: %s", aws.StringValue(head.ETag))
+}
+
+// failDelete fails to delete key in DeleteObjects.
+type failDelete struct {
+	*buckettest.Fake
+
+	key string
+}
+
+func (f *failDelete) DeleteObjectsWithContext(ctx aws.Context, in *s3.DeleteObjectsInput, opts ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	var objects []*s3.ObjectIdentifier
+	var errs []*s3.Error
+	for _, o := range in.Delete.Objects {
+		if aws.StringValue(o.Key) == f.key {
+			errs = append(errs, &s3.Error{Key: o.Key, Code: aws.String("AccessDenied"), Message: aws.String("Access Denied")})
+			continue
+		}
+		objects = append(objects, o)
+	}
+
+	in.Delete.Objects = objects
+	resp, err := f.Fake.DeleteObjectsWithContext(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	resp.Errors = append(resp.Errors, errs...)
+
+	return resp, nil
+}
+
+func TestCatchUpDeleteErrors(t *testing.T) {
+	ctx := aws.BackgroundContext()
+	fake, src, dst := newFake(t)
+
+	putString(t, dst, "extra1", "x")
+	putString(t, dst, "extra2", "x")
+
+	dst = bucket.New(&failDelete{Fake: fake, key: "extra2"}, "dst")
+
+	_, err := New(src, dst, WithDeleteExtra()).CatchUp(ctx)
+	var derr *bucket.DeleteObjectsError
+	require.ErrorAs(t, err, &derr)
+	require.Len(t, derr.Errors, 1)
+	assert.Equal(t, "extra2", aws.StringValue(derr.Errors[0].Key))
+
+	_, err = dst.HeadObjectWithContext(ctx, "extra1")
+	assert.True(t, bucket.IsNotFound(err))
+	_, err = dst.HeadObjectWithContext(ctx, "extra2")
+	assert.NoError(t, err)
+}
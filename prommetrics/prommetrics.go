@@ -0,0 +1,97 @@
+// Package prommetrics provides a bucket.MetricsSink that exposes the S3 calls made through a Bucket as Prometheus metrics.
+//
+// The Sink is a prometheus.Collector to be registered with a Prometheus registry:
+//
+//	sink := prommetrics.New("myapp")
+//	prometheus.MustRegister(sink)
+//	b := bucket.New(svc, "bucket", bucket.WithMetrics(sink))
+//	http.Handle("/metrics", promhttp.Handler())
+package prommetrics
+
+import (
+	"strconv"
+
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefBuckets are the upper bounds in seconds of the buckets of the request duration histogram.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// A Sink collects bucket.Metric as Prometheus metrics. The metrics are
+//
+//	s3_requests_total{bucket, operation, status_code, error_code}
+//	s3_request_retries_total{bucket, operation}
+//	s3_request_duration_seconds{bucket, operation} (histogram)
+//	s3_sent_bytes_total{bucket, operation}
+//	s3_received_bytes_total{bucket, operation}
+//
+// prefixed with the namespace given to New.
+type Sink struct {
+	requests *prometheus.CounterVec
+	retries  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	sent     *prometheus.CounterVec
+	received *prometheus.CounterVec
+}
+
+var _ prometheus.Collector = (*Sink)(nil)
+
+// New returns a Sink whose metric names are prefixed with namespace and "_" unless namespace is empty.
+func New(namespace string) *Sink {
+	opLabels := []string{"bucket", "operation"}
+	counter := func(name, help string, labels []string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "s3",
+			Name:      name,
+			Help:      help,
+		}, labels)
+	}
+
+	return &Sink{
+		requests: counter("requests_total", "Number of S3 calls.", []string{"bucket", "operation", "status_code", "error_code"}),
+		retries:  counter("request_retries_total", "Number of retries of S3 calls.", opLabels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "s3",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of S3 calls including retries.",
+			Buckets:   DefBuckets,
+		}, opLabels),
+		sent:     counter("sent_bytes_total", "Bytes sent in S3 request bodies.", opLabels),
+		received: counter("received_bytes_total", "Bytes received in S3 response bodies.", opLabels),
+	}
+}
+
+// ObserveRequest implements bucket.MetricsSink.
+func (s *Sink) ObserveRequest(m bucket.Metric) {
+	s.requests.WithLabelValues(m.Bucket, m.Operation, strconv.Itoa(m.StatusCode), m.ErrorCode).Inc()
+
+	retries := m.Attempts - 1
+	if retries < 0 {
+		retries = 0
+	}
+	s.retries.WithLabelValues(m.Bucket, m.Operation).Add(float64(retries))
+	s.sent.WithLabelValues(m.Bucket, m.Operation).Add(float64(m.BytesSent))
+	s.received.WithLabelValues(m.Bucket, m.Operation).Add(float64(m.BytesReceived))
+	s.duration.WithLabelValues(m.Bucket, m.Operation).Observe(m.Duration.Seconds())
+}
+
+// Describe implements prometheus.Collector.
+func (s *Sink) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range s.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (s *Sink) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range s.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (s *Sink) collectors() []prometheus.Collector {
+	return []prometheus.Collector{s.requests, s.retries, s.duration, s.sent, s.received}
+}
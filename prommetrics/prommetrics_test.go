@@ -0,0 +1,91 @@
+package prommetrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSink(t *testing.T) {
+	s := New("app")
+	s.ObserveRequest(bucket.Metric{
+		Bucket:     "bucket",
+		Operation:  "PutObject",
+		Duration:   20 * time.Millisecond,
+		Attempts:   2,
+		BytesSent:  5,
+		StatusCode: 200,
+	})
+	s.ObserveRequest(bucket.Metric{
+		Bucket:     "bucket",
+		Operation:  "GetObject",
+		Duration:   3 * time.Second,
+		Attempts:   1,
+		StatusCode: 404,
+		ErrorCode:  `No"SuchKey`,
+	})
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(s))
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP app_s3_requests_total Number of S3 calls.
+# TYPE app_s3_requests_total counter
+app_s3_requests_total{bucket="bucket",error_code="",operation="PutObject",status_code="200"} 1
+app_s3_requests_total{bucket="bucket",error_code="No\"SuchKey",operation="GetObject",status_code="404"} 1
+# HELP app_s3_request_retries_total Number of retries of S3 calls.
+# TYPE app_s3_request_retries_total counter
+app_s3_request_retries_total{bucket="bucket",operation="GetObject"} 0
+app_s3_request_retries_total{bucket="bucket",operation="PutObject"} 1
+# HELP app_s3_sent_bytes_total Bytes sent in S3 request bodies.
+# TYPE app_s3_sent_bytes_total counter
+app_s3_sent_bytes_total{bucket="bucket",operation="GetObject"} 0
+app_s3_sent_bytes_total{bucket="bucket",operation="PutObject"} 5
+`), "app_s3_requests_total", "app_s3_request_retries_total", "app_s3_sent_bytes_total")
+	assert.NoError(t, err)
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP app_s3_request_duration_seconds Duration of S3 calls including retries.
+# TYPE app_s3_request_duration_seconds histogram
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="0.005"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="0.01"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="0.025"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="0.05"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="0.1"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="0.25"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="0.5"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="1"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="2.5"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="5"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="10"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="GetObject",le="+Inf"} 1
+app_s3_request_duration_seconds_sum{bucket="bucket",operation="GetObject"} 3
+app_s3_request_duration_seconds_count{bucket="bucket",operation="GetObject"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="0.005"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="0.01"} 0
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="0.025"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="0.05"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="0.1"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="0.25"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="0.5"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="1"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="2.5"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="5"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="10"} 1
+app_s3_request_duration_seconds_bucket{bucket="bucket",operation="PutObject",le="+Inf"} 1
+app_s3_request_duration_seconds_sum{bucket="bucket",operation="PutObject"} 0.02
+app_s3_request_duration_seconds_count{bucket="bucket",operation="PutObject"} 1
+`), "app_s3_request_duration_seconds")
+	assert.NoError(t, err)
+
+	// the metrics are not prefixed without a namespace
+	s = New("")
+	s.ObserveRequest(bucket.Metric{Bucket: "bucket", Operation: "PutObject", Attempts: 1, StatusCode: 200})
+	assert.Equal(t, 1, testutil.CollectAndCount(s, "s3_requests_total"))
+}
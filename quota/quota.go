@@ -0,0 +1,272 @@
+// Package quota tracks and limits the number and the total size of objects under prefixes of a Bucket.
+//
+// The usage of each prefix is kept in a small JSON object in the bucket and is updated with conditional writes,
+// so trackers in different processes can share it. Uploads and deletions must go through the Tracker to be counted.
+package quota
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+	"github.com/nabeken/aws-go-s3/ioutils"
+)
+
+const (
+	defaultStatePrefix = ".quota/"
+	maxAttempts        = 10
+	retryDelay         = 50 * time.Millisecond
+)
+
+// ErrQuotaExceeded is returned when an upload would exceed the quota of its prefix.
+var ErrQuotaExceeded = errors.New("quota: quota exceeded")
+
+// ErrConflict is returned when the usage cannot be updated because of concurrent updates.
+var ErrConflict = errors.New("quota: too many concurrent updates")
+
+// Usage is the number and the total size of objects under a prefix.
+type Usage struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// A Limit is a quota of a prefix. Zero means no limit.
+type Limit struct {
+	MaxObjects int64
+	MaxBytes   int64
+}
+
+// exceeded reports whether u exceeds l in a dimension that delta increases.
+func (l Limit) exceeded(u, delta Usage) bool {
+	return (l.MaxObjects > 0 && delta.Objects > 0 && u.Objects > l.MaxObjects) ||
+		(l.MaxBytes > 0 && delta.Bytes > 0 && u.Bytes > l.MaxBytes)
+}
+
+// A Tracker maintains the usage of the prefixes with a limit and enforces the limits on uploads.
+// It is safe for concurrent use.
+type Tracker struct {
+	bucket      *bucket.Bucket
+	statePrefix string
+	onExceeded  func(prefix string, usage Usage, limit Limit)
+
+	mu     sync.RWMutex
+	limits map[string]Limit
+}
+
+// An Option configures a Tracker in New.
+type Option func(t *Tracker)
+
+// WithStatePrefix returns an Option that stores the usage objects under prefix instead of ".quota/".
+// The prefix must not be under a tracked prefix.
+func WithStatePrefix(prefix string) Option {
+	return func(t *Tracker) {
+		t.statePrefix = prefix
+	}
+}
+
+// WithFlagOnly returns an Option that allows uploads exceeding the quota and calls fn for them instead of rejecting them.
+func WithFlagOnly(fn func(prefix string, usage Usage, limit Limit)) Option {
+	return func(t *Tracker) {
+		t.onExceeded = fn
+	}
+}
+
+// New returns Tracker instance for b.
+func New(b *bucket.Bucket, opts ...Option) *Tracker {
+	t := &Tracker{
+		bucket:      b,
+		statePrefix: defaultStatePrefix,
+		limits:      map[string]Limit{},
+	}
+
+	for _, f := range opts {
+		f(t)
+	}
+
+	return t
+}
+
+// SetLimit sets the quota of prefix. A key belongs to the longest prefix with a limit.
+func (t *Tracker) SetLimit(prefix string, l Limit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.limits[prefix] = l
+}
+
+// Usage returns the recorded usage of prefix.
+func (t *Tracker) Usage(ctx aws.Context, prefix string) (Usage, error) {
+	u, _, err := t.load(ctx, prefix)
+	return u, err
+}
+
+// PutObject puts an object after reserving its size in the quota of the prefix of key.
+// It returns an error wrapping ErrQuotaExceeded without uploading if the quota would be exceeded.
+// Overwriting an existing object only counts the difference in size. If the upload fails, the reservation is released
+// and the error of the release, if any, is joined to the one of the upload.
+func (t *Tracker) PutObject(ctx aws.Context, key string, rs io.ReadSeeker, opts ...option.PutObjectInput) (*s3.PutObjectOutput, error) {
+	prefix, limit, ok := t.prefixFor(key)
+	if !ok {
+		return t.bucket.PutObjectWithContext(ctx, key, rs, opts...)
+	}
+
+	size, err := ioutils.Remaining(rs)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := Usage{Objects: 1, Bytes: size}
+
+	head, err := t.bucket.HeadObjectWithContext(ctx, key)
+	switch {
+	case err == nil:
+		delta = Usage{Bytes: size - aws.Int64Value(head.ContentLength)}
+	case !bucket.IsNotFound(err):
+		return nil, err
+	}
+
+	if err := t.update(ctx, prefix, limit, delta, true); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.bucket.PutObjectWithContext(ctx, key, rs, opts...)
+	if err != nil {
+		// release the reservation
+		if rerr := t.update(ctx, prefix, limit, Usage{Objects: -delta.Objects, Bytes: -delta.Bytes}, false); rerr != nil {
+			return nil, errors.Join(err, fmt.Errorf("quota: release %q: %w", prefix, rerr))
+		}
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// DeleteObject deletes an object and releases its size from the quota of the prefix of key.
+func (t *Tracker) DeleteObject(ctx aws.Context, key string) (*s3.DeleteObjectOutput, error) {
+	prefix, limit, ok := t.prefixFor(key)
+	if !ok {
+		return t.bucket.DeleteObjectWithContext(ctx, key)
+	}
+
+	head, err := t.bucket.HeadObjectWithContext(ctx, key)
+	if bucket.IsNotFound(err) {
+		return t.bucket.DeleteObjectWithContext(ctx, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.bucket.DeleteObjectWithContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := Usage{Objects: -1, Bytes: -aws.Int64Value(head.ContentLength)}
+	if err := t.update(ctx, prefix, limit, delta, false); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// prefixFor returns the longest prefix of key with a limit.
+func (t *Tracker) prefixFor(key string) (string, Limit, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var (
+		found  string
+		limit  Limit
+		exists bool
+	)
+
+	for prefix, l := range t.limits {
+		if strings.HasPrefix(key, prefix) && (!exists || len(prefix) > len(found)) {
+			found, limit, exists = prefix, l, true
+		}
+	}
+
+	return found, limit, exists
+}
+
+// update adds delta to the usage of prefix with a conditional write, retrying on concurrent updates.
+// If check is true, it fails with ErrQuotaExceeded when the new usage exceeds limit.
+func (t *Tracker) update(ctx aws.Context, prefix string, limit Limit, delta Usage, check bool) error {
+	for i := 0; i < maxAttempts; i++ {
+		u, etag, err := t.load(ctx, prefix)
+		if err != nil {
+			return err
+		}
+
+		u.Objects += delta.Objects
+		u.Bytes += delta.Bytes
+
+		if check && limit.exceeded(u, delta) {
+			if t.onExceeded == nil {
+				return fmt.Errorf("%w: %q would have %d objects and %d bytes", ErrQuotaExceeded, prefix, u.Objects, u.Bytes)
+			}
+
+			t.onExceeded(prefix, u, limit)
+		}
+
+		data, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+
+		body := bytes.NewReader(data)
+		if etag == "" {
+			_, err = t.bucket.PutObjectIfNotExists(ctx, t.stateKey(prefix), body, option.ContentType("application/json"))
+		} else {
+			_, err = t.bucket.PutObjectIfMatch(ctx, t.stateKey(prefix), etag, body, option.ContentType("application/json"))
+		}
+
+		if !bucket.IsPreconditionFailed(err) {
+			return err
+		}
+
+		if err := aws.SleepWithContext(ctx, time.Duration(i+1)*retryDelay); err != nil {
+			return err
+		}
+	}
+
+	return ErrConflict
+}
+
+// load returns the usage of prefix and the ETag of its usage object. The ETag is empty if the object does not exist.
+func (t *Tracker) load(ctx aws.Context, prefix string) (Usage, string, error) {
+	resp, err := t.bucket.GetObjectWithContext(ctx, t.stateKey(prefix))
+	if bucket.IsNotFound(err) {
+		return Usage{}, "", nil
+	}
+	if err != nil {
+		return Usage{}, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Usage{}, "", err
+	}
+
+	var u Usage
+	if err := json.Unmarshal(data, &u); err != nil {
+		return Usage{}, "", err
+	}
+
+	return u, aws.StringValue(resp.ETag), nil
+}
+
+func (t *Tracker) stateKey(prefix string) string {
+	return t.statePrefix + prefix + "_usage.json"
+}
@@ -0,0 +1,289 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conditionalS3 is an in-memory S3 that honors the If-Match and If-None-Match headers of PutObject
+// and can make a write between the read and the conditional write of a tracker.
+type conditionalS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	etags   map[string]string
+	seq     int
+
+	// beforePut is called with mu held before a conditional PutObject is checked, e.g. to make a concurrent write.
+	beforePut func(path string)
+
+	// fail is called with mu held for every request and fails it with 500 InternalError if it returns true.
+	fail func(r *http.Request) bool
+}
+
+func (s *conditionalS3) put(path, data string) string {
+	s.seq++
+	s.objects[path] = data
+	s.etags[path] = fmt.Sprintf(`"%d"`, s.seq)
+
+	return s.etags[path]
+}
+
+func (s *conditionalS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == http.MethodPut && s.beforePut != nil && (r.Header.Get("If-Match") != "" || r.Header.Get("If-None-Match") != "") {
+		s.beforePut(r.URL.Path)
+	}
+
+	if s.fail != nil && s.fail(r) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `<Error><Code>InternalError</Code><Message>We encountered an internal error.</Message></Error>`)
+		return
+	}
+
+	data, exists := s.objects[r.URL.Path]
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			}
+			return
+		}
+
+		w.Header().Set("ETag", s.etags[r.URL.Path])
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, data)
+		}
+	case http.MethodPut:
+		if (r.Header.Get("If-None-Match") == "*" && exists) ||
+			(r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != s.etags[r.URL.Path]) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("ETag", s.put(r.URL.Path, string(body)))
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		delete(s.etags, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestBucket(t *testing.T) (*bucket.Bucket, *conditionalS3) {
+	fake := &conditionalS3{objects: map[string]string{}, etags: map[string]string{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	svc := s3.New(session.Must(session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(srv.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		MaxRetries:       aws.Int(0),
+	})))
+
+	return bucket.New(svc, "bucket"), fake
+}
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newTestBucket(t)
+
+	tr := New(b)
+	tr.SetLimit("p/", Limit{MaxObjects: 2, MaxBytes: 10})
+
+	_, err := tr.PutObject(ctx, "p/a", strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	// overwriting only counts the difference in size
+	_, err = tr.PutObject(ctx, "p/a", strings.NewReader("hi"))
+	require.NoError(t, err)
+
+	u, err := tr.Usage(ctx, "p/")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Objects: 1, Bytes: 2}, u)
+
+	_, err = tr.PutObject(ctx, "p/b", strings.NewReader("too large"))
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	_, exists, err := b.StatObject("p/b")
+	require.NoError(t, err)
+	assert.False(t, exists, "the object over quota is not uploaded")
+
+	_, err = tr.PutObject(ctx, "p/b", strings.NewReader("b"))
+	require.NoError(t, err)
+	_, err = tr.PutObject(ctx, "p/c", strings.NewReader("c"))
+	assert.ErrorIs(t, err, ErrQuotaExceeded, "MaxObjects")
+
+	_, err = tr.DeleteObject(ctx, "p/a")
+	require.NoError(t, err)
+	u, err = tr.Usage(ctx, "p/")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Objects: 1, Bytes: 1}, u)
+
+	// a key without a limit is not tracked
+	_, err = tr.PutObject(ctx, "other", strings.NewReader("not tracked at all"))
+	require.NoError(t, err)
+}
+
+func TestTrackerFlagOnly(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newTestBucket(t)
+
+	var flagged []Usage
+	tr := New(b, WithFlagOnly(func(prefix string, u Usage, l Limit) {
+		assert.Equal(t, "p/", prefix)
+		flagged = append(flagged, u)
+	}))
+	tr.SetLimit("p/", Limit{MaxBytes: 3})
+
+	_, err := tr.PutObject(ctx, "p/a", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, []Usage{{Objects: 1, Bytes: 5}}, flagged)
+}
+
+func TestTrackerConcurrentUpdate(t *testing.T) {
+	ctx := context.Background()
+	b, fake := newTestBucket(t)
+
+	tr := New(b)
+	tr.SetLimit("p/", Limit{MaxBytes: 10})
+
+	_, err := tr.PutObject(ctx, "p/a", strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	// another tracker updates the usage between the read and the conditional write once
+	var once sync.Once
+	fake.beforePut = func(path string) {
+		once.Do(func() {
+			fake.put(path, `{"objects":2,"bytes":8}`)
+		})
+	}
+
+	_, err = tr.PutObject(ctx, "p/b", strings.NewReader("b"))
+	require.NoError(t, err)
+
+	u, err := tr.Usage(ctx, "p/")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Objects: 3, Bytes: 9}, u, "the retry applies the delta to the concurrent update")
+
+	// the retry checks the limit against the concurrent update
+	once = sync.Once{}
+	fake.beforePut = func(path string) {
+		once.Do(func() {
+			fake.put(path, `{"objects":3,"bytes":10}`)
+		})
+	}
+
+	_, err = tr.PutObject(ctx, "p/c", strings.NewReader("c"))
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+}
+
+func TestTrackerConflict(t *testing.T) {
+	ctx := context.Background()
+	b, fake := newTestBucket(t)
+
+	tr := New(b)
+	tr.SetLimit("p/", Limit{MaxBytes: 10})
+
+	fake.beforePut = func(path string) {
+		fake.put(path, `{}`)
+	}
+
+	_, err := tr.PutObject(ctx, "p/a", strings.NewReader("a"))
+	assert.ErrorIs(t, err, ErrConflict)
+	_, exists, err := b.StatObject("p/a")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestTrackerPutFails(t *testing.T) {
+	ctx := context.Background()
+	b, fake := newTestBucket(t)
+
+	tr := New(b)
+	tr.SetLimit("p/", Limit{MaxBytes: 10})
+
+	// the upload fails and the reservation is released
+	fake.fail = func(r *http.Request) bool {
+		return r.Method == http.MethodPut && r.URL.Path == "/bucket/p/a"
+	}
+
+	_, err := tr.PutObject(ctx, "p/a", strings.NewReader("hello"))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, requestFailure(t, err).StatusCode())
+	u, err := tr.Usage(ctx, "p/")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{}, u)
+
+	// the upload and the release fail
+	failed := false
+	fake.fail = func(r *http.Request) bool {
+		failed = failed || (r.Method == http.MethodPut && r.URL.Path == "/bucket/p/a")
+		return failed
+	}
+
+	_, err = tr.PutObject(ctx, "p/a", strings.NewReader("hello"))
+	var joined interface{ Unwrap() []error }
+	require.ErrorAs(t, err, &joined)
+	require.Len(t, joined.Unwrap(), 2)
+	assert.Equal(t, http.StatusInternalServerError, requestFailure(t, joined.Unwrap()[0]).StatusCode())
+	assert.Contains(t, joined.Unwrap()[1].Error(), `quota: release "p/"`)
+
+	fake.fail = nil
+	u, err = tr.Usage(ctx, "p/")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Objects: 1, Bytes: 5}, u, "the reservation is kept")
+}
+
+func requestFailure(t *testing.T, err error) awserr.RequestFailure {
+	t.Helper()
+
+	var rerr awserr.RequestFailure
+	require.ErrorAs(t, err, &rerr)
+
+	return rerr
+}
+
+func TestTrackerConcurrentPuts(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newTestBucket(t)
+
+	tr := New(b)
+	tr.SetLimit("p/", Limit{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := tr.PutObject(ctx, fmt.Sprintf("p/%d", i), strings.NewReader("hello"))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	u, err := tr.Usage(ctx, "p/")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Objects: 4, Bytes: 20}, u)
+}
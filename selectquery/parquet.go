@@ -0,0 +1,61 @@
+package selectquery
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ParquetInput returns the input serialization for Parquet objects.
+func ParquetInput() *s3.InputSerialization {
+	return &s3.InputSerialization{Parquet: &s3.ParquetInput{}}
+}
+
+// Projection returns the SELECT list for the exported fields of struct T, named by the `json` tag or the field name,
+// so that JSON output decodes into T. It returns "*" if T is not a struct.
+func Projection[T any]() string {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return "*"
+	}
+
+	var columns []string
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		columns = append(columns, `s."`+strings.ReplaceAll(name, `"`, `""`)+`"`)
+	}
+
+	if len(columns) == 0 {
+		return "*"
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// Expression returns a query selecting the columns of T by Projection. where is appended as the WHERE clause
+// unless it is empty. The object is aliased as s in where.
+func Expression[T any](where string) string {
+	expr := "SELECT " + Projection[T]() + " FROM S3Object s"
+	if where != "" {
+		expr += " WHERE " + where
+	}
+
+	return expr
+}
@@ -0,0 +1,20 @@
+package selectquery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpression(t *testing.T) {
+	type event struct {
+		ID      string `json:"id,omitempty"`
+		Status  string
+		Skipped string `json:"-"`
+		private string
+	}
+
+	assert.Equal(t, `SELECT s."id", s."Status" FROM S3Object s`, Expression[event](""))
+	assert.Equal(t, `SELECT s."id", s."Status" FROM S3Object s WHERE s."Status" = 'failed'`, Expression[*event](`s."Status" = 'failed'`))
+	assert.Equal(t, `SELECT * FROM S3Object s`, Expression[map[string]any](""))
+}
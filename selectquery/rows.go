@@ -0,0 +1,364 @@
+// Package selectquery decodes the results of S3 Select (SelectObjectContent) into Go values.
+package selectquery
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ErrIncompleteStream is returned when the event stream ends without the End event, which means
+// S3 did not finish sending the result.
+var ErrIncompleteStream = errors.New("selectquery: event stream ended before the End event")
+
+// An EventStream is the event stream of SelectObjectContent. *s3.SelectObjectContentEventStream satisfies it.
+type EventStream interface {
+	Events() <-chan s3.SelectObjectContentEventStreamEvent
+	Err() error
+	Close() error
+}
+
+// A Format is the output serialization of the query.
+type Format int
+
+const (
+	// JSON is JSON Lines output, i.e. s3.JSONOutput with the default record delimiter.
+	JSON Format = iota
+
+	// CSV is CSV output with the default delimiters, i.e. s3.CSVOutput.
+	CSV
+)
+
+// Rows is the result of a query decoded into values of T. Its usage follows database/sql.Rows:
+//
+//	defer rows.Close()
+//	for rows.Next() {
+//		var v T
+//		if err := rows.Scan(&v); err != nil { ... }
+//	}
+//	if err := rows.Err(); err != nil { ... }
+//
+// JSON records are decoded with encoding/json. CSV records are decoded into the fields of struct T by the `csv` tag
+// matched against the columns given to NewRows, or by the order of the fields if no columns are given.
+type Rows[T any] struct {
+	events *Reader
+	closer io.Closer
+	next   func() (T, error)
+	cur    T
+	err    error
+}
+
+// NewRows returns Rows decoding the records of stream in format. columns are the names of the CSV columns in
+// the order of the SELECT list and are ignored for JSON.
+func NewRows[T any](stream EventStream, format Format, columns ...string) *Rows[T] {
+	events := NewReader(stream)
+
+	return &Rows[T]{
+		events: events,
+		closer: stream,
+		next:   decoder[T](events, format, columns),
+	}
+}
+
+// NewReaderRows returns Rows decoding the records read from r in format, e.g. the body of an object downloaded
+// without S3 Select. Stats and Progress are always nil.
+func NewReaderRows[T any](r io.ReadCloser, format Format, columns ...string) *Rows[T] {
+	return &Rows[T]{
+		closer: r,
+		next:   decoder[T](r, format, columns),
+	}
+}
+
+func decoder[T any](r io.Reader, format Format, columns []string) func() (T, error) {
+	if format == CSV {
+		return csvDecoder[T](r, columns)
+	}
+
+	dec := json.NewDecoder(r)
+
+	return func() (T, error) {
+		var v T
+		err := dec.Decode(&v)
+		return v, err
+	}
+}
+
+// Next prepares the next row for Scan. It returns false at the end of the result or on an error.
+func (r *Rows[T]) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	v, err := r.next()
+	if err != nil {
+		r.err = err
+		return false
+	}
+
+	r.cur = v
+
+	return true
+}
+
+// Scan copies the current row into dest.
+func (r *Rows[T]) Scan(dest *T) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	*dest = r.cur
+
+	return nil
+}
+
+// Row returns the current row.
+func (r *Rows[T]) Row() T {
+	return r.cur
+}
+
+// Err returns the error that ended the iteration, if any. It is nil at the normal end of the result.
+func (r *Rows[T]) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+
+	return r.err
+}
+
+// Stats returns the statistics of the query. It is nil until the Stats event is received, i.e. the end of the result.
+func (r *Rows[T]) Stats() *s3.Stats {
+	if r.events == nil {
+		return nil
+	}
+
+	return r.events.Stats()
+}
+
+// Progress returns the latest progress of the query if S3 is asked to send progress events.
+func (r *Rows[T]) Progress() *s3.Progress {
+	if r.events == nil {
+		return nil
+	}
+
+	return r.events.Progress()
+}
+
+// Close closes the underlying event stream or reader.
+func (r *Rows[T]) Close() error {
+	return r.closer.Close()
+}
+
+// A Reader reads the records of the event stream of SelectObjectContent as they are serialized by the query.
+// It keeps the statistics and the progress sent along with them.
+type Reader struct {
+	stream   EventStream
+	buf      []byte
+	done     bool
+	stats    *s3.Stats
+	progress *s3.Progress
+}
+
+// NewReader returns Reader over the payload of the Records events of stream.
+// Read fails with ErrIncompleteStream if stream ends without the End event.
+func NewReader(stream EventStream) *Reader {
+	return &Reader{stream: stream}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		ev, ok := <-r.stream.Events()
+		if !ok {
+			if err := r.stream.Err(); err != nil {
+				return 0, err
+			}
+
+			return 0, ErrIncompleteStream
+		}
+
+		switch e := ev.(type) {
+		case *s3.RecordsEvent:
+			r.buf = e.Payload
+		case *s3.StatsEvent:
+			r.stats = e.Details
+		case *s3.ProgressEvent:
+			r.progress = e.Details
+		case *s3.EndEvent:
+			r.done = true
+		}
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+// Stats returns the statistics of the query. It is nil until the Stats event is received, i.e. the end of the result.
+func (r *Reader) Stats() *s3.Stats {
+	return r.stats
+}
+
+// Progress returns the latest progress of the query if S3 is asked to send progress events.
+func (r *Reader) Progress() *s3.Progress {
+	return r.progress
+}
+
+// Close closes the event stream.
+func (r *Reader) Close() error {
+	return r.stream.Close()
+}
+
+// csvDecoder returns a function decoding a CSV record from r into T per each call.
+func csvDecoder[T any](r io.Reader, columns []string) func() (T, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var fields []int
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() == reflect.Struct {
+		fields = csvFields(typ, columns)
+	}
+
+	return func() (T, error) {
+		var v T
+
+		record, err := cr.Read()
+		if err != nil {
+			return v, err
+		}
+
+		rv := reflect.ValueOf(&v).Elem()
+		if fields == nil {
+			if err := setField(rv, record[0]); err != nil {
+				return v, err
+			}
+
+			return v, nil
+		}
+
+		for col, field := range fields {
+			if field < 0 || col >= len(record) {
+				continue
+			}
+
+			if err := setField(rv.Field(field), record[col]); err != nil {
+				return v, fmt.Errorf("selectquery: column %d: %w", col, err)
+			}
+		}
+
+		return v, nil
+	}
+}
+
+// csvFields returns the index of the field of struct typ for each column, or -1 if no field matches.
+func csvFields(typ reflect.Type, columns []string) []int {
+	var exported []int
+	byName := map[string]int{}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("csv") == "-" {
+			continue
+		}
+
+		exported = append(exported, i)
+
+		name := f.Tag.Get("csv")
+		if name == "" {
+			name = f.Name
+		}
+		byName[name] = i
+	}
+
+	if len(columns) == 0 {
+		return exported
+	}
+
+	fields := make([]int, len(columns))
+	for col, name := range columns {
+		if i, ok := byName[name]; ok {
+			fields[col] = i
+		} else {
+			fields[col] = -1
+		}
+	}
+
+	return fields
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setField sets s converted to the type of v.
+func setField(v reflect.Value, s string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	if v.Type() == timeType {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(t))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Ptr:
+		if s == "" {
+			return nil
+		}
+
+		p := reflect.New(v.Type().Elem())
+		if err := setField(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package selectquery
+
+import (
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStream struct {
+	events chan s3.SelectObjectContentEventStreamEvent
+}
+
+func newFakeStream(events ...s3.SelectObjectContentEventStreamEvent) *fakeStream {
+	ch := make(chan s3.SelectObjectContentEventStreamEvent, len(events))
+	for _, ev := range events {
+		ch <- ev
+	}
+	close(ch)
+
+	return &fakeStream{events: ch}
+}
+
+func (s *fakeStream) Events() <-chan s3.SelectObjectContentEventStreamEvent { return s.events }
+func (s *fakeStream) Err() error                                            { return nil }
+func (s *fakeStream) Close() error                                          { return nil }
+
+type record struct {
+	Name  string `json:"name" csv:"name"`
+	Count int    `json:"count" csv:"count"`
+}
+
+func TestRowsJSON(t *testing.T) {
+	// records are split across events on purpose
+	rows := NewRows[record](newFakeStream(
+		&s3.RecordsEvent{Payload: []byte(`{"name":"a","count":1}` + "\n" + `{"name":`)},
+		&s3.RecordsEvent{Payload: []byte(`"b","count":2}` + "\n")},
+		&s3.StatsEvent{Details: &s3.Stats{BytesReturned: aws.Int64(40)}},
+		&s3.EndEvent{},
+	), JSON)
+	defer rows.Close()
+
+	var actual []record
+	for rows.Next() {
+		var r record
+		require.NoError(t, rows.Scan(&r))
+		actual = append(actual, r)
+	}
+
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []record{{"a", 1}, {"b", 2}}, actual)
+	assert.Equal(t, int64(40), aws.Int64Value(rows.Stats().BytesReturned))
+}
+
+func TestRowsCSV(t *testing.T) {
+	rows := NewRows[record](newFakeStream(
+		&s3.RecordsEvent{Payload: []byte("1,a\n2,b\n")},
+		&s3.EndEvent{},
+	), CSV, "count", "name")
+
+	var actual []record
+	for rows.Next() {
+		actual = append(actual, rows.Row())
+	}
+
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []record{{"a", 1}, {"b", 2}}, actual)
+}
+
+func TestRowsIncompleteStream(t *testing.T) {
+	rows := NewRows[record](newFakeStream(
+		&s3.RecordsEvent{Payload: []byte(`{"name":"a","count":1}` + "\n")},
+	), JSON)
+
+	assert.True(t, rows.Next())
+	assert.False(t, rows.Next())
+	assert.Equal(t, ErrIncompleteStream, rows.Err())
+}
+
+func TestReader(t *testing.T) {
+	r := NewReader(newFakeStream(
+		&s3.RecordsEvent{Payload: []byte("a,1\n")},
+		&s3.ProgressEvent{Details: &s3.Progress{BytesScanned: aws.Int64(10)}},
+		&s3.RecordsEvent{Payload: []byte("b,2\n")},
+		&s3.StatsEvent{Details: &s3.Stats{BytesReturned: aws.Int64(8)}},
+		&s3.EndEvent{},
+	))
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "a,1\nb,2\n", string(data))
+	assert.Equal(t, int64(10), aws.Int64Value(r.Progress().BytesScanned))
+	assert.Equal(t, int64(8), aws.Int64Value(r.Stats().BytesReturned))
+
+	_, err = io.ReadAll(NewReader(newFakeStream(&s3.RecordsEvent{Payload: []byte("a,1\n")})))
+	assert.Equal(t, ErrIncompleteStream, err)
+}
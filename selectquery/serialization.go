@@ -0,0 +1,38 @@
+package selectquery
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// CSVInput returns the input serialization for CSV objects. fileHeaderInfo is one of s3.FileHeaderInfo*,
+// e.g. s3.FileHeaderInfoUse to refer to the columns by the names in the first line.
+func CSVInput(fileHeaderInfo string) *s3.InputSerialization {
+	return &s3.InputSerialization{CSV: &s3.CSVInput{FileHeaderInfo: aws.String(fileHeaderInfo)}}
+}
+
+// JSONLinesInput returns the input serialization for objects with a JSON value per line.
+func JSONLinesInput() *s3.InputSerialization {
+	return &s3.InputSerialization{JSON: &s3.JSONInput{Type: aws.String(s3.JSONTypeLines)}}
+}
+
+// JSONDocumentInput returns the input serialization for objects that are a single JSON document.
+func JSONDocumentInput() *s3.InputSerialization {
+	return &s3.InputSerialization{JSON: &s3.JSONInput{Type: aws.String(s3.JSONTypeDocument)}}
+}
+
+// CSVOutput returns the output serialization decoded by the CSV format.
+func CSVOutput() *s3.OutputSerialization {
+	return &s3.OutputSerialization{CSV: &s3.CSVOutput{}}
+}
+
+// JSONOutput returns the output serialization decoded by the JSON format.
+func JSONOutput() *s3.OutputSerialization {
+	return &s3.OutputSerialization{JSON: &s3.JSONOutput{}}
+}
+
+// WithCompression returns in with the objects compressed by compression, one of s3.CompressionType*.
+func WithCompression(in *s3.InputSerialization, compression string) *s3.InputSerialization {
+	in.CompressionType = aws.String(compression)
+	return in
+}
@@ -0,0 +1,193 @@
+// Package tenancy manages tenant namespaces in a shared bucket.
+//
+// Each tenant gets a prefix view of the bucket (see bucket.Bucket.WithPrefix) so that the keys of a tenant never
+// reach the objects of another one, and default options applied to its uploads.
+//
+// The objects are laid out under the root prefix as follows:
+//
+//	<root>_tenants/<id>.json  the Config of the tenant
+//	<root><id>/...            the objects of the tenant
+package tenancy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/option"
+)
+
+const configPrefix = "_tenants/"
+
+var (
+	// ErrTenantExists is returned by Create when the tenant already exists.
+	ErrTenantExists = errors.New("tenancy: tenant already exists")
+
+	// ErrTenantNotFound is returned when the tenant does not exist.
+	ErrTenantNotFound = errors.New("tenancy: tenant not found")
+
+	// ErrInvalidTenantID is returned when the tenant ID cannot be used as a namespace.
+	ErrInvalidTenantID = errors.New("tenancy: invalid tenant ID")
+)
+
+// Config holds the per-tenant defaults applied to uploads and copies of the tenant.
+type Config struct {
+	// SSEKMSKeyID is the KMS key to encrypt the objects of the tenant with. SSE-KMS is not requested if empty.
+	SSEKMSKeyID string `json:",omitempty"`
+
+	// Tags are set on every object uploaded by the tenant. A copied object keeps the tags of the source object.
+	Tags map[string]string `json:",omitempty"`
+}
+
+// putOptions returns the options for PutObject that apply c.
+func (c Config) putOptions() []option.PutObjectInput {
+	var opts []option.PutObjectInput
+	if c.SSEKMSKeyID != "" {
+		opts = append(opts, option.SSEKMSKeyID(c.SSEKMSKeyID))
+	}
+
+	if len(c.Tags) > 0 {
+		opts = append(opts, option.Tagging(c.Tags))
+	}
+
+	return opts
+}
+
+// copyOptions returns the options for CopyObject that apply c.
+func (c Config) copyOptions() []option.CopyObjectInput {
+	var opts []option.CopyObjectInput
+	if c.SSEKMSKeyID != "" {
+		opts = append(opts, option.CopySSEKMSKeyID(c.SSEKMSKeyID))
+	}
+
+	return opts
+}
+
+// A Manager creates, deletes and enumerates the tenants under a root prefix of a bucket.
+type Manager struct {
+	bucket *bucket.Bucket
+}
+
+// New returns Manager instance that keeps the tenants under root in b.
+func New(b *bucket.Bucket, root string) *Manager {
+	return &Manager{bucket: b.WithPrefix(root)}
+}
+
+// A Tenant is a namespace in the bucket. The embedded Bucket is a view under the prefix of the tenant
+// that applies the defaults in Config to every upload and copy before the options given to each call.
+type Tenant struct {
+	*bucket.Bucket
+
+	ID     string
+	Config Config
+}
+
+// Create creates the tenant id with cfg. It returns ErrTenantExists if the tenant already exists.
+func (m *Manager) Create(ctx aws.Context, id string, cfg Config) (*Tenant, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = m.bucket.PutObjectIfNotExists(ctx, configKey(id), bytes.NewReader(data), option.ContentType("application/json"))
+	if bucket.IsPreconditionFailed(err) {
+		return nil, ErrTenantExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return m.tenant(id, cfg), nil
+}
+
+// Tenant returns the tenant id. It returns ErrTenantNotFound if the tenant does not exist.
+func (m *Manager) Tenant(ctx aws.Context, id string) (*Tenant, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	resp, err := m.bucket.GetObjectWithContext(ctx, configKey(id))
+	if bucket.IsNotFound(err) {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return m.tenant(id, cfg), nil
+}
+
+// Delete deletes all objects of the tenant id and then the tenant itself.
+func (m *Manager) Delete(ctx aws.Context, id string) error {
+	t, err := m.Tenant(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := t.DeletePrefix(ctx, ""); err != nil {
+		return err
+	}
+
+	_, err = m.bucket.DeleteObjectWithContext(ctx, configKey(id))
+
+	return err
+}
+
+// Tenants returns the IDs of all tenants.
+func (m *Manager) Tenants(ctx aws.Context) ([]string, error) {
+	var ids []string
+	err := m.bucket.ListObjectsV2PagesWithContext(ctx, configPrefix, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			ids = append(ids, strings.TrimSuffix(strings.TrimPrefix(aws.StringValue(o.Key), configPrefix), ".json"))
+		}
+
+		return true
+	})
+
+	return ids, err
+}
+
+func (m *Manager) tenant(id string, cfg Config) *Tenant {
+	return &Tenant{
+		Bucket: m.bucket.WithPrefix(id+"/").WithOptions(
+			bucket.WithDefaultPutOptions(cfg.putOptions()...),
+			bucket.WithDefaultCopyOptions(cfg.copyOptions()...),
+		),
+		ID:     id,
+		Config: cfg,
+	}
+}
+
+func configKey(id string) string {
+	return configPrefix + id + ".json"
+}
+
+// validateID rejects IDs that would make the namespace overlap with another tenant or the configs.
+func validateID(id string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, "/\\") || strings.HasPrefix(id, "_") {
+		return fmt.Errorf("%w: %q", ErrInvalidTenantID, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package tenancy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nabeken/aws-go-s3/bucket"
+	"github.com/nabeken/aws-go-s3/bucket/buckettest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	b := bucket.New(buckettest.New("bucket"), "bucket")
+	m := New(b, "root/")
+
+	cfg := Config{SSEKMSKeyID: "key", Tags: map[string]string{"tenant": "a"}}
+	tenant, err := m.Create(ctx, "a", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "a", tenant.ID)
+
+	for _, id := range []string{"", ".", "..", "a/b", `a\b`, "_tenants"} {
+		_, err := m.Create(ctx, id, Config{})
+		assert.ErrorIs(t, err, ErrInvalidTenantID, id)
+	}
+
+	tenant, err = m.Tenant(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, cfg, tenant.Config)
+
+	_, err = m.Tenant(ctx, "b")
+	assert.ErrorIs(t, err, ErrTenantNotFound)
+
+	_, err = m.Create(ctx, "b", Config{})
+	require.NoError(t, err)
+
+	ids, err := m.Tenants(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, ids)
+
+	_, err = tenant.PutObject("key", strings.NewReader("hello"))
+	require.NoError(t, err)
+	_, exists, err := b.StatObject("root/a/key")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, m.Delete(ctx, "a"))
+	_, exists, err = b.StatObject("root/a/key")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, err = m.Tenant(ctx, "a")
+	assert.ErrorIs(t, err, ErrTenantNotFound)
+
+	ids, err = m.Tenants(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, ids)
+}
+
+func TestManagerCreateExists(t *testing.T) {
+	// The Fake does not see the If-None-Match header of PutObjectIfNotExists.
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("If-None-Match")
+		w.WriteHeader(http.StatusPreconditionFailed)
+	}))
+	t.Cleanup(srv.Close)
+
+	svc := s3.New(session.Must(session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(srv.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		MaxRetries:       aws.Int(0),
+	})))
+
+	_, err := New(bucket.New(svc, "bucket"), "").Create(context.Background(), "a", Config{})
+	assert.ErrorIs(t, err, ErrTenantExists)
+	assert.Equal(t, "*", header)
+}
+
+func TestTenantDefaults(t *testing.T) {
+	ctx := context.Background()
+	b := bucket.New(buckettest.New("bucket"), "bucket")
+	m := New(b, "")
+
+	tenant, err := m.Create(ctx, "a", Config{SSEKMSKeyID: "key", Tags: map[string]string{"tenant": "a"}})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o600))
+
+	// a body over 8 MiB that is not seekable is uploaded with a multipart upload
+	large := bytes.Repeat([]byte("a"), 9<<20)
+
+	for name, put := range map[string]func(key string) error{
+		"PutObject": func(key string) error {
+			_, err := tenant.PutObject(key, strings.NewReader("hello"))
+			return err
+		},
+		"PutObjectFromReader": func(key string) error {
+			_, err := tenant.PutObjectFromReader(ctx, key, io.MultiReader(strings.NewReader("hello")))
+			return err
+		},
+		"PutObjectStream": func(key string) error {
+			_, err := tenant.PutObjectStream(key, io.MultiReader(bytes.NewReader(large)))
+			return err
+		},
+		"PutObjectFromFile": func(key string) error {
+			_, err := tenant.PutObjectFromFile(ctx, key, path)
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, put(name))
+
+			head, err := b.HeadObject("a/" + name)
+			require.NoError(t, err)
+			assert.Equal(t, s3.ServerSideEncryptionAwsKms, aws.StringValue(head.ServerSideEncryption))
+			assert.Equal(t, "key", aws.StringValue(head.SSEKMSKeyId))
+
+			tags, err := b.GetObjectTagging("a/" + name)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"tenant": "a"}, tags)
+		})
+	}
+
+	t.Run("CopyObject", func(t *testing.T) {
+		_, err := b.PutObject("a/plain", strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		_, err = tenant.CopyObject("copy", "plain")
+		require.NoError(t, err)
+
+		head, err := b.HeadObject("a/copy")
+		require.NoError(t, err)
+		assert.Equal(t, s3.ServerSideEncryptionAwsKms, aws.StringValue(head.ServerSideEncryption))
+		assert.Equal(t, "key", aws.StringValue(head.SSEKMSKeyId))
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		_, err := tenant.PutObject("override", strings.NewReader("hello"), func(req *s3.PutObjectInput) {
+			req.SSEKMSKeyId = aws.String("other")
+		})
+		require.NoError(t, err)
+
+		head, err := b.HeadObject("a/override")
+		require.NoError(t, err)
+		assert.Equal(t, "other", aws.StringValue(head.SSEKMSKeyId))
+	})
+
+	// the defaults do not leak to the bucket the view was made from
+	_, err = b.PutObject("plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+	head, err := b.HeadObject("plain")
+	require.NoError(t, err)
+	assert.Nil(t, head.SSEKMSKeyId)
+}